@@ -1,9 +1,11 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/splitio/split-synchronizer/v5/splitio"
 	"github.com/splitio/split-synchronizer/v5/splitio/common"
@@ -16,6 +18,9 @@ import (
 const (
 	exitCodeSuccess     = 0
 	exitCodeConfigError = 1
+
+	// prefix of the env vars that can be used to set config properties, ie: SPLIT_PROXY_LOG_LEVEL
+	envPrefix = "SPLIT_PROXY"
 )
 
 func parseCliArgs() *cconf.CliFlags {
@@ -26,27 +31,129 @@ func setupConfig(cliArgs *cconf.CliFlags) (*conf.Main, error) {
 	proxyConf := conf.Main{}
 	cconf.PopulateDefaults(&proxyConf)
 
+	// a missing config file is tolerated when the required properties are supplied through env vars or cli args
+	var missingFileErr error
 	if path := *cliArgs.ConfigFile; path != "" {
 		err := cconf.PopulateConfigFromFile(path, &proxyConf)
-		if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			missingFileErr = err
+		} else if err != nil {
 			return nil, fmt.Errorf("error parsing config file: %w", err)
 		}
 	}
 
+	if _, err := cconf.PopulateFromEnvironment(&proxyConf, envPrefix); err != nil {
+		return nil, fmt.Errorf("error parsing environment variables: %w", err)
+	}
+
 	cconf.PopulateFromArguments(&proxyConf, cliArgs.RawConfig)
 
+	// a key read from a file (ie: a mounted secret) takes precedence over an inline one
+	if path := proxyConf.ApikeyFile; path != "" {
+		apikey, err := cconf.ReadSecretFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading SDK key file: %w", err)
+		}
+		proxyConf.Apikey = apikey
+	}
+
+	if missingFileErr != nil {
+		if proxyConf.Apikey == "" {
+			return nil, fmt.Errorf("error parsing config file: %w", missingFileErr)
+		}
+		fmt.Printf("config file not found, using env vars & cli args only: %s\n", missingFileErr.Error())
+	}
+
+	if err := cconf.ValidateEnvironmentLabel(proxyConf.EnvironmentLabel); err != nil {
+		return nil, fmt.Errorf("invalid environment label: %w", err)
+	}
+
 	var err error
 	proxyConf.FlagSetsFilter, err = cconf.ValidateFlagsets(proxyConf.FlagSetsFilter)
 	return &proxyConf, err
 }
 
+// setupConfigReload sets up a reloader that re-reads the config on SIGHUP (and when the config file changes, if enabled),
+// hot-applying the properties that can be updated on a running instance and logging the ones that require a restart.
+// The reloader is not started, so that more handlers can be registered before a reload can happen
+func setupConfigReload(cliArgs *cconf.CliFlags, cfg *conf.Main, logger *log.HistoricLoggerWrapper) *cconf.Reloader {
+	current := *cfg
+	reloader := cconf.NewReloader(&current, func() (interface{}, error) {
+		updated, err := setupConfig(cliArgs)
+		if updated == nil {
+			return nil, err
+		}
+		return updated, nil
+	}, logger)
+	reloader.OnChange("logging.level", func(value interface{}) { logger.SetLevel(log.ParseLevel(value.(string))) })
+
+	if !cfg.ConfigReloadEnabled {
+		return reloader
+	}
+
+	path := *cliArgs.ConfigFile
+	if path == "" {
+		logger.Warning("config reload is enabled but no config file was provided. Ignoring")
+		return reloader
+	}
+
+	if err := reloader.WatchFile(path, time.Duration(cfg.ConfigReloadRateMs)*time.Millisecond); err != nil {
+		logger.Error("error setting up config file watcher: ", err)
+	}
+	return reloader
+}
+
+// checkConfig prints the outcome of the config validation along with the effective values (with secrets redacted),
+// and returns the code to exit with
+func checkConfig(cfg *conf.Main, err error) int {
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		fmt.Println("invalid configuration: ", err)
+		return exitCodeConfigError
+	}
+
+	redacted := cfg.Redacted()
+	if err := cconf.WriteConfigSummary(os.Stdout, &redacted); err != nil {
+		fmt.Println("error printing configuration: ", err)
+		return exitCodeConfigError
+	}
+
+	fmt.Println("configuration is valid")
+	return exitCodeSuccess
+}
+
+// printVersion prints the version & build info in the requested format, and returns the code to exit with
+func printVersion(format string) int {
+	info := splitio.GetBuildInfo()
+	switch format {
+	case "json":
+		serialized, err := json.Marshal(info)
+		if err != nil {
+			fmt.Println("error serializing version info: ", err)
+			return exitCodeConfigError
+		}
+		fmt.Println(string(serialized))
+	case "text":
+		fmt.Print(info.String())
+	default:
+		fmt.Printf("unknown version output format '%s'. Supported ones are: text, json\n", format)
+		return exitCodeConfigError
+	}
+	return exitCodeSuccess
+}
+
 func main() {
+	cliArgs := parseCliArgs()
+	if *cliArgs.VersionInfo && *cliArgs.VersionOutput == "json" { // keep the output machine-parseable
+		os.Exit(printVersion(*cliArgs.VersionOutput))
+	}
+
 	fmt.Println(splitio.ASCILogo)
 	fmt.Printf("\nSplit Proxy - Version: %s (%s) \n", splitio.Version, splitio.CommitVersion)
-
-	cliArgs := parseCliArgs()
-	if *cliArgs.VersionInfo { //already printed, we can now exit
-		os.Exit(exitCodeSuccess)
+	if *cliArgs.VersionInfo {
+		os.Exit(printVersion(*cliArgs.VersionOutput))
 	}
 
 	if fn := *cliArgs.WriteDefaultConfigFile; fn != "" {
@@ -59,6 +166,10 @@ func main() {
 	}
 
 	cfg, err := setupConfig(cliArgs)
+	if *cliArgs.CheckConfig {
+		os.Exit(checkConfig(cfg, err))
+	}
+
 	if err != nil {
 		var fsErr cconf.FlagSetValidationError
 		if errors.As(err, &fsErr) {
@@ -70,7 +181,11 @@ func main() {
 	}
 
 	logger := log.BuildFromConfig(&cfg.Logging, "Split-Proxy", &cfg.Integrations.Slack)
-	err = proxy.Start(logger, cfg)
+	if redacted, err := json.Marshal(cfg.Redacted()); err == nil {
+		logger.Debug("effective configuration: ", string(redacted))
+	}
+	reloader := setupConfigReload(cliArgs, cfg, logger)
+	err = proxy.Start(logger, cfg, reloader)
 
 	if err == nil {
 		return
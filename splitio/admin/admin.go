@@ -5,14 +5,19 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/splitio/go-split-commons/v6/synchronizer/worker/segment"
+	"github.com/splitio/go-split-commons/v6/synchronizer/worker/split"
 	"github.com/splitio/go-toolkit/v5/logging"
 	adminCommon "github.com/splitio/split-synchronizer/v5/splitio/admin/common"
 	"github.com/splitio/split-synchronizer/v5/splitio/admin/controllers"
 	"github.com/splitio/split-synchronizer/v5/splitio/common"
 	cstorage "github.com/splitio/split-synchronizer/v5/splitio/common/storage"
+	"github.com/splitio/split-synchronizer/v5/splitio/log"
 	"github.com/splitio/split-synchronizer/v5/splitio/producer/evcalc"
 	"github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/application"
 	"github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/services"
+	pstorage "github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+	"github.com/splitio/split-synchronizer/v5/splitio/util"
 
 	"github.com/gin-gonic/gin"
 )
@@ -29,6 +34,7 @@ type Options struct {
 	Proxy             bool
 	Username          string
 	Password          string
+	Token             string
 	Logger            logging.LoggerInterface
 	Storages          adminCommon.Storages
 	ImpressionsEvCalc evcalc.Monitor
@@ -37,9 +43,44 @@ type Options struct {
 	HcAppMonitor      application.MonitorIterface
 	HcServicesMonitor services.MonitorIterface
 	Snapshotter       cstorage.Snapshotter
+	Compactor         cstorage.Compactor
 	TLS               *tls.Config
 	FullConfig        interface{}
 	FlagSpecVersion   string
+
+	// Tasks posting data to Split servers, whose flushed/retried/dropped counters are included in the dashboard stats
+	SinkStats map[string]controllers.SinkStatsProvider
+
+	// Latency & errors of the persistent storage operations, included in the dashboard stats & prometheus metrics (omitted if nil)
+	DBMetrics controllers.DBMetricsProvider
+
+	// Whether the proxy is ready to serve sdk requests, exposed through the admin API (not mounted if nil)
+	Readiness controllers.ReadinessProvider
+
+	// Circuit breaker around upstream feature flag fetches, whose state is exposed through the admin API (not mounted if nil)
+	UpstreamBreaker controllers.BreakerStatusProvider
+
+	// Failover between primary & secondary upstream urls, whose state is reported by the upstream healthcheck (omitted if nil)
+	UpstreamFailover controllers.FailoverStatusProvider
+
+	// Used to force an immediate feature flags/segments synchronization through the admin API (endpoints are not mounted if nil)
+	SplitUpdater   split.Updater
+	SegmentUpdater segment.Updater
+
+	// Whether healthcheck endpoints require admin credentials as well
+	SecureHealthcheck bool
+
+	// Middlewares applied to every admin route
+	Middlewares []gin.HandlerFunc
+
+	// Whether to mount the endpoint listing feature flags that reference a segment
+	ExposeSegmentUsage bool
+
+	// Whether to mount the endpoint with status code counts per proxy endpoint (ignored in producer mode)
+	ExposeStatusCodes bool
+
+	// Whether to mount the endpoint exposing proxy endpoint telemetry in prometheus format (ignored in producer mode)
+	ExposePrometheus bool
 }
 
 type AdminServer struct {
@@ -49,13 +90,18 @@ type AdminServer struct {
 // NewServer instantiates a new admin server
 func NewServer(options *Options) (*AdminServer, error) {
 	router := gin.New()
+	router.Use(options.Middlewares...)
 	admin := router.Group(baseAdminPath)
 	info := router.Group(baseInfoPath)
 	shutdown := router.Group(baseShutdownPath)
-	if options.Username != "" && options.Password != "" {
-		admin = router.Group(baseAdminPath, gin.BasicAuth(gin.Accounts{options.Username: options.Password}))
-		info = router.Group(baseInfoPath, gin.BasicAuth(gin.Accounts{options.Username: options.Password}))
-		shutdown = router.Group(baseShutdownPath, gin.BasicAuth(gin.Accounts{options.Username: options.Password}))
+	healthcheck := router.Group("/")
+	if auth := newAuthMiddleware(options.Username, options.Password, options.Token); auth != nil {
+		admin.Use(auth)
+		info.Use(auth)
+		shutdown.Use(auth)
+		if options.SecureHealthcheck {
+			healthcheck.Use(auth)
+		}
 	}
 
 	dashboardController, err := controllers.NewDashboardController(
@@ -68,6 +114,8 @@ func NewServer(options *Options) (*AdminServer, error) {
 		options.Runtime,
 		options.HcAppMonitor,
 		options.FlagSpecVersion,
+		options.SinkStats,
+		options.DBMetrics,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("error instantiating dashboard controller: %w", err)
@@ -81,11 +129,14 @@ func NewServer(options *Options) (*AdminServer, error) {
 		options.Logger,
 		options.HcAppMonitor,
 		options.HcServicesMonitor,
+		options.UpstreamFailover,
 	)
-	healthcheckController.Register(router)
+	healthcheckController.Register(healthcheck)
+	healthcheckController.RegisterUpstream(admin)
 
 	infoController := controllers.NewInfoController(options.Proxy, options.Runtime, options.FullConfig)
 	infoController.Register(info)
+	infoController.RegisterConfig(admin)
 
 	observabilityController, err := controllers.NewObservabilityController(options.Proxy, options.Logger, options.Storages)
 	if err != nil {
@@ -98,6 +149,69 @@ func NewServer(options *Options) (*AdminServer, error) {
 		snapshotController.Register(admin)
 	}
 
+	if options.Proxy {
+		seedExportController := controllers.NewSeedExportController(options.Logger, options.Storages.SplitStorage, options.Storages.SegmentStorage)
+		seedExportController.Register(admin)
+	}
+
+	if options.Compactor != nil {
+		compactionController := controllers.NewCompactionController(options.Logger, options.Compactor)
+		compactionController.Register(admin)
+	}
+
+	if options.SplitUpdater != nil && options.SegmentUpdater != nil {
+		refreshController := controllers.NewRefreshController(options.Logger, options.SplitUpdater, options.SegmentUpdater)
+		refreshController.Register(admin)
+	}
+
+	if options.Readiness != nil {
+		readinessController := controllers.NewReadinessController(options.Readiness)
+		readinessController.Register(admin)
+	}
+
+	if options.UpstreamBreaker != nil {
+		breakerController := controllers.NewBreakerController(options.UpstreamBreaker)
+		breakerController.Register(admin)
+	}
+
+	dumpController := controllers.NewDumpController(options.Logger, options.Storages.SplitStorage, options.Storages.SegmentStorage)
+	dumpController.Register(admin)
+
+	if levelController, ok := options.Logger.(log.LevelController); ok {
+		logLevelController := controllers.NewLogLevelController(options.Logger, levelController)
+		logLevelController.Register(admin)
+	}
+
+	if historicLogger, ok := options.Logger.(log.HistoricLogger); ok {
+		logsController := controllers.NewLogsController(historicLogger)
+		logsController.Register(admin)
+	}
+
+	if options.ExposeSegmentUsage {
+		segmentUsageController := controllers.NewSegmentUsageController(options.Logger, options.Storages.SplitStorage)
+		segmentUsageController.Register(admin)
+	}
+
+	if options.Proxy {
+		telemetry, ok := options.Storages.LocalTelemetryStorage.(pstorage.TimeslicedProxyEndpointTelemetry)
+		if !ok {
+			return nil, fmt.Errorf("invalid local telemetry storage supplied: %T", options.Storages.LocalTelemetryStorage)
+		}
+
+		statsResetController := controllers.NewStatsResetController(options.Logger, telemetry)
+		statsResetController.Register(admin)
+
+		if options.ExposeStatusCodes {
+			statusCodesController := controllers.NewStatusCodesController(options.Logger, telemetry)
+			statusCodesController.Register(admin)
+		}
+
+		if options.ExposePrometheus {
+			prometheusController := controllers.NewPrometheusController(options.Logger, telemetry, options.DBMetrics)
+			prometheusController.Register(admin)
+		}
+	}
+
 	return &AdminServer{
 		server: &http.Server{
 			Addr:      fmt.Sprintf("%s:%d", options.Host, options.Port),
@@ -107,9 +221,7 @@ func NewServer(options *Options) (*AdminServer, error) {
 	}, nil
 }
 
+// Start binds the admin port & serves the admin endpoints in the background
 func (a *AdminServer) Start() error {
-	if a.server.TLSConfig != nil {
-		return a.server.ListenAndServeTLS("", "") // cert & key set in TLSConfig option
-	}
-	return a.server.ListenAndServe()
+	return util.ServeInBackground(a.server)
 }
@@ -0,0 +1,40 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newAuthMiddleware returns a handler that accepts requests carrying either the configured basic auth credentials
+// or bearer token, rejecting the rest with a 401. Nil is returned if no credentials are configured
+func newAuthMiddleware(username string, password string, token string) gin.HandlerFunc {
+	basicEnabled := username != "" && password != ""
+	if !basicEnabled && token == "" {
+		return nil
+	}
+
+	return func(ctx *gin.Context) {
+		header := ctx.GetHeader("Authorization")
+		if token != "" && strings.HasPrefix(header, "Bearer ") && secureEqual(strings.TrimPrefix(header, "Bearer "), token) {
+			ctx.Next()
+			return
+		}
+
+		if basicEnabled {
+			if user, pass, ok := ctx.Request.BasicAuth(); ok && secureEqual(user, username) && secureEqual(pass, password) {
+				ctx.Next()
+				return
+			}
+			ctx.Header("WWW-Authenticate", `Basic realm="Authorization Required"`)
+		}
+
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+	}
+}
+
+func secureEqual(a string, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,45 @@
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	assert.Nil(t, newAuthMiddleware("", "", ""))
+	assert.Nil(t, newAuthMiddleware("user", "", ""))
+
+	router := gin.New()
+	router.GET("/admin/stats", newAuthMiddleware("user", "pass", "s3cr3t"), func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	do := func(setup func(*http.Request)) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, "/admin/stats", nil)
+		setup(req)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := do(func(*http.Request) {})
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.NotEmpty(t, resp.Header().Get("WWW-Authenticate"))
+
+	assert.Equal(t, http.StatusOK, do(func(r *http.Request) { r.SetBasicAuth("user", "pass") }).Code)
+	assert.Equal(t, http.StatusUnauthorized, do(func(r *http.Request) { r.SetBasicAuth("user", "wrong") }).Code)
+	assert.Equal(t, http.StatusOK, do(func(r *http.Request) { r.Header.Set("Authorization", "Bearer s3cr3t") }).Code)
+	assert.Equal(t, http.StatusUnauthorized, do(func(r *http.Request) { r.Header.Set("Authorization", "Bearer wrong") }).Code)
+
+	tokenOnly := gin.New()
+	tokenOnly.GET("/admin/stats", newAuthMiddleware("", "", "s3cr3t"), func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	req, _ := http.NewRequest(http.MethodGet, "/admin/stats", nil)
+	req.SetBasicAuth("user", "pass")
+	resp = httptest.NewRecorder()
+	tokenOnly.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Empty(t, resp.Header().Get("WWW-Authenticate"))
+}
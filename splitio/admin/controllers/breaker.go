@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/breaker"
+)
+
+// BreakerStatusProvider is implemented by circuit breakers around upstream calls
+type BreakerStatusProvider interface {
+	Status() breaker.Status
+}
+
+// BreakerController exposes the state of the circuit breaker around upstream feature flag fetches
+type BreakerController struct {
+	breaker BreakerStatusProvider
+}
+
+// NewBreakerController constructs a new circuit breaker controller
+func NewBreakerController(breaker BreakerStatusProvider) *BreakerController {
+	return &BreakerController{breaker: breaker}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *BreakerController) Register(router gin.IRouter) {
+	router.GET("/upstream/breaker", c.status)
+}
+
+func (c *BreakerController) status(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.breaker.Status())
+}
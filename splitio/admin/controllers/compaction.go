@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/storage"
+)
+
+// CompactionController exposes an endpoint to reclaim unused space from the persistent storage
+type CompactionController struct {
+	logger logging.LoggerInterface
+	db     storage.Compactor
+}
+
+// NewCompactionController constructs a new compaction controller
+func NewCompactionController(logger logging.LoggerInterface, db storage.Compactor) *CompactionController {
+	return &CompactionController{logger: logger, db: db}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *CompactionController) Register(router gin.IRouter) {
+	router.POST("/storage/compact", c.compact)
+}
+
+func (c *CompactionController) compact(ctx *gin.Context) {
+	c.logger.Info("storage compaction requested through the admin API")
+	before, after, err := c.db.Compact()
+	if err != nil {
+		c.logger.Error("error compacting storage: ", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.logger.Info(fmt.Sprintf("storage compacted. file size went from %d to %d bytes", before, after))
+	ctx.JSON(http.StatusOK, gin.H{"sizeBefore": before, "sizeAfter": after})
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+type compactorMock struct {
+	before int64
+	after  int64
+	err    error
+}
+
+func (m *compactorMock) Compact() (int64, int64, error) {
+	return m.before, m.after, m.err
+}
+
+func TestCompactionEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	compactor := &compactorMock{before: 2048, after: 1024}
+	router := gin.New()
+	NewCompactionController(logging.NewLogger(nil), compactor).Register(router)
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/storage/compact", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{"sizeBefore": 2048, "sizeAfter": 1024}`, resp.Body.String())
+
+	compactor.err = errors.New("something")
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/storage/compact", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, 500, resp.Code)
+}
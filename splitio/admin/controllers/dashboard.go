@@ -13,9 +13,11 @@ import (
 	adminCommon "github.com/splitio/split-synchronizer/v5/splitio/admin/common"
 	"github.com/splitio/split-synchronizer/v5/splitio/admin/views/dashboard"
 	"github.com/splitio/split-synchronizer/v5/splitio/common"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/stats"
 	"github.com/splitio/split-synchronizer/v5/splitio/log"
 	"github.com/splitio/split-synchronizer/v5/splitio/producer/evcalc"
 	"github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/application"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/persistent"
 )
 
 // DashboardController contains handlers for rendering the dashboard and its associated FE queries
@@ -29,9 +31,21 @@ type DashboardController struct {
 	eventsEvCalc      evcalc.Monitor
 	runtime           common.Runtime
 	appMonitor        application.MonitorIterface
+	sinks             map[string]SinkStatsProvider
+	dbMetrics         DBMetricsProvider
 	FlagSpecVersion   string
 }
 
+// SinkStatsProvider is implemented by tasks that post data to Split servers and keep track of the outcome
+type SinkStatsProvider interface {
+	Stats() stats.SaveStats
+}
+
+// DBMetricsProvider is implemented by persistent storages tracking the latency & errors of their operations
+type DBMetricsProvider interface {
+	Metrics() map[string]persistent.OperationMetrics
+}
+
 // NewDashboardController instantiates a new dashboard controller
 func NewDashboardController(
 	name string,
@@ -43,6 +57,8 @@ func NewDashboardController(
 	runtime common.Runtime,
 	appMonitor application.MonitorIterface,
 	flagSpecVersion string,
+	sinks map[string]SinkStatsProvider,
+	dbMetrics DBMetricsProvider,
 ) (*DashboardController, error) {
 
 	toReturn := &DashboardController{
@@ -54,6 +70,8 @@ func NewDashboardController(
 		eventsEvCalc:      eventsEvCalc,
 		impressionsEvCalc: impressionEvCalc,
 		appMonitor:        appMonitor,
+		sinks:             sinks,
+		dbMetrics:         dbMetrics,
 		FlagSpecVersion:   flagSpecVersion,
 	}
 
@@ -87,9 +105,20 @@ func (c *DashboardController) dashboard(ctx *gin.Context) {
 	ctx.Writer.Write(dashboard)
 }
 
-// stats returns stats for dashboard
+// stats returns stats for dashboard, or the stable StatsV2 payload when `?format=v2` is requested
 func (c *DashboardController) stats(ctx *gin.Context) {
-	ctx.JSON(http.StatusOK, c.gatherStats())
+	switch ctx.Query("format") {
+	case "":
+		ctx.JSON(http.StatusOK, c.gatherStats())
+	case "v2":
+		stats := newStatsV2(c.gatherStats())
+		if c.dbMetrics != nil {
+			stats.DB = c.dbMetrics.Metrics()
+		}
+		ctx.JSON(http.StatusOK, stats)
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "unknown format. Supported ones are: v2"})
+	}
 }
 
 // segmentKeys returns a keys for a given segment
@@ -162,5 +191,6 @@ func (c *DashboardController) gatherStats() *dashboard.GlobalStats {
 		LoggedMessages:         errorMessages,
 		Uptime:                 int64(c.runtime.Uptime().Seconds()),
 		FlagSets:               getFlagSetsInfo(c.storages.SplitStorage),
+		Sinks:                  getSinksInfo(c.sinks),
 	}
 }
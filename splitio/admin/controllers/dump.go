@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v6/storage"
+	"github.com/splitio/go-toolkit/v5/datastructures/set"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/persistent"
+)
+
+const (
+	defaultDumpPageSize = 1000
+	maxDumpPageSize     = 10000
+)
+
+// DumpController exposes the current feature flags & segments snapshot, to be compared against the one in Split servers
+type DumpController struct {
+	logger   logging.LoggerInterface
+	splits   storage.SplitStorageConsumer
+	segments storage.SegmentStorageConsumer
+}
+
+// NewDumpController constructs a new dump controller
+func NewDumpController(
+	logger logging.LoggerInterface,
+	splits storage.SplitStorageConsumer,
+	segments storage.SegmentStorageConsumer,
+) *DumpController {
+	return &DumpController{logger: logger, splits: splits, segments: segments}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *DumpController) Register(router gin.IRouter) {
+	router.GET("/dump/splits", c.dumpSplits)
+	router.GET("/dump/segments", c.dumpSegments)
+	router.GET("/dump/segments/:name", c.dumpSegmentKeys)
+}
+
+func (c *DumpController) dumpSplits(ctx *gin.Context) {
+	cn, err := c.splits.ChangeNumber()
+	if err != nil {
+		c.logger.Error("error fetching feature flags change number for dump: ", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	splits := c.splits.All()
+	sort.Slice(splits, func(i, j int) bool { return splits[i].Name < splits[j].Name })
+	ctx.JSON(http.StatusOK, gin.H{"changeNumber": cn, "splits": splits})
+}
+
+func (c *DumpController) dumpSegments(ctx *gin.Context) {
+	names := sortedStrings(c.splits.SegmentNames())
+	segments := make([]gin.H, 0, len(names))
+	for _, name := range names {
+		cn, _ := c.segments.ChangeNumber(name)
+		segments = append(segments, gin.H{"name": name, "changeNumber": cn, "keys": countKeys(c.segments.Keys(name))})
+	}
+	ctx.JSON(http.StatusOK, gin.H{"segments": segments})
+}
+
+func (c *DumpController) dumpSegmentKeys(ctx *gin.Context) {
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative integer"})
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultDumpPageSize)))
+	if err != nil || limit < 1 || limit > maxDumpPageSize {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit must be an integer between 1 and " + strconv.Itoa(maxDumpPageSize)})
+		return
+	}
+
+	name := ctx.Param("name")
+	if !c.splits.SegmentNames().Has(name) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "segment not referenced by any feature flag"})
+		return
+	}
+
+	cn, _ := c.segments.ChangeNumber(name)
+	keys := sortedStrings(c.segments.Keys(name)) // sorted so that pages are stable across requests
+
+	start, end := min(offset, len(keys)), min(offset+limit, len(keys))
+	ctx.JSON(http.StatusOK, gin.H{
+		"name":         name,
+		"changeNumber": cn,
+		"total":        len(keys),
+		"offset":       offset,
+		"limit":        limit,
+		"keys":         keys[start:end],
+	})
+}
+
+// countKeys returns the number of keys in a segment, without copying nor sorting them.
+// Removed keys in proxy segment storages are not counted, in line with sortedStrings
+func countKeys(items *set.ThreadUnsafeSet) int {
+	if items == nil {
+		return 0
+	}
+
+	count := items.Size()
+	items.Each(func(item interface{}) bool {
+		if k, ok := item.(persistent.SegmentKey); ok && k.Removed {
+			count--
+		}
+		return true
+	})
+	return count
+}
+
+// sortedStrings returns the (sorted) string items in a set, which may be nil.
+// Proxy segment storages return persisted keys, in which case only the ones not removed are included
+func sortedStrings(items *set.ThreadUnsafeSet) []string {
+	toRet := make([]string, 0)
+	if items == nil {
+		return toRet
+	}
+
+	items.Each(func(item interface{}) bool {
+		switch k := item.(type) {
+		case string:
+			toRet = append(toRet, k)
+		case persistent.SegmentKey:
+			if !k.Removed {
+				toRet = append(toRet, k.Name)
+			}
+		}
+		return true
+	})
+	sort.Strings(toRet)
+	return toRet
+}
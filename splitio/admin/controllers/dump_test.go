@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-split-commons/v6/storage/mocks"
+	"github.com/splitio/go-toolkit/v5/datastructures/set"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/persistent"
+)
+
+func TestDumpEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	splitStorage := &mocks.MockSplitStorage{
+		ChangeNumberCall: func() (int64, error) { return 123, nil },
+		AllCall: func() []dtos.SplitDTO {
+			return []dtos.SplitDTO{{Name: "split2"}, {Name: "split1"}}
+		},
+		SegmentNamesCall: func() *set.ThreadUnsafeSet { return set.NewSet("segment1") },
+	}
+	segmentStorage := &mocks.MockSegmentStorage{
+		ChangeNumberCall: func(string) (int64, error) { return 456, nil },
+		KeysCall: func(string) *set.ThreadUnsafeSet {
+			return set.NewSet("k3", "k1", persistent.SegmentKey{Name: "k2"}, persistent.SegmentKey{Name: "k4", Removed: true})
+		},
+	}
+
+	router := gin.New()
+	NewDumpController(logging.NewLogger(nil), splitStorage, segmentStorage).Register(router)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := get("/dump/splits")
+	assert.Equal(t, 200, resp.Code)
+	var splits struct {
+		ChangeNumber int64           `json:"changeNumber"`
+		Splits       []dtos.SplitDTO `json:"splits"`
+	}
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &splits))
+	assert.Equal(t, int64(123), splits.ChangeNumber)
+	assert.Equal(t, []dtos.SplitDTO{{Name: "split1"}, {Name: "split2"}}, splits.Splits)
+
+	resp = get("/dump/segments")
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{"segments": [{"name": "segment1", "changeNumber": 456, "keys": 3}]}`, resp.Body.String())
+
+	resp = get("/dump/segments/segment1?offset=1&limit=1")
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{"name": "segment1", "changeNumber": 456, "total": 3, "offset": 1, "limit": 1, "keys": ["k2"]}`, resp.Body.String())
+
+	resp = get("/dump/segments/segment1?offset=10")
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{"name": "segment1", "changeNumber": 456, "total": 3, "offset": 10, "limit": 1000, "keys": []}`, resp.Body.String())
+
+	assert.Equal(t, 400, get("/dump/segments/segment1?limit=0").Code)
+	assert.Equal(t, 400, get("/dump/segments/segment1?offset=-1").Code)
+	assert.Equal(t, 404, get("/dump/segments/nonexistent").Code)
+}
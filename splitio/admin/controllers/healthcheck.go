@@ -2,18 +2,27 @@ package controllers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/splitio/go-toolkit/v5/logging"
 	"github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/application"
+	"github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/application/counter"
 	"github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/services"
+	"github.com/splitio/split-synchronizer/v5/splitio/util"
 )
 
+// FailoverStatusProvider is implemented by failovers between upstream urls
+type FailoverStatusProvider interface {
+	Status() util.FailoverStatus
+}
+
 // HealthCheckController description
 type HealthCheckController struct {
 	logger              logging.LoggerInterface
 	appMonitor          application.MonitorIterface
 	dependenciesMonitor services.MonitorIterface
+	failover            FailoverStatusProvider
 }
 
 func (c *HealthCheckController) appHealth(ctx *gin.Context) {
@@ -29,21 +38,82 @@ func (c *HealthCheckController) dependenciesHealth(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, c.dependenciesMonitor.GetHealthStatus())
 }
 
+// upstreamHealthDto summarizes whether split servers can be reached and when data was last synchronized
+type upstreamHealthDto struct {
+	Healthy            bool                    `json:"healthy"`
+	Dependencies       []upstreamDependencyDto `json:"dependencies"`
+	LastSuccessfulSync *time.Time              `json:"lastSuccessfulSync,omitempty"`
+	Failover           *util.FailoverStatus    `json:"failover,omitempty"`
+}
+
+type upstreamDependencyDto struct {
+	Service string     `json:"service"`
+	Healthy bool       `json:"healthy"`
+	LastHit *time.Time `json:"lastHit,omitempty"`
+}
+
+// upstreamHealth reports the reachability of split servers as last checked by the dependencies monitor (which
+// periodically hits each one of them in the background), and returns a 500 if a critical one is down.
+// When an upstream failover is set up, the upstream currently in use is reported as well
+func (c *HealthCheckController) upstreamHealth(ctx *gin.Context) {
+	dependencies := c.dependenciesMonitor.GetHealthStatus()
+	response := upstreamHealthDto{
+		Healthy:      dependencies.Status != services.DownStatus,
+		Dependencies: make([]upstreamDependencyDto, 0, len(dependencies.Items)),
+	}
+
+	for _, item := range dependencies.Items {
+		response.Dependencies = append(response.Dependencies, upstreamDependencyDto{
+			Service: item.Service,
+			Healthy: item.Healthy,
+			LastHit: item.LastHit,
+		})
+	}
+
+	for _, item := range c.appMonitor.GetHealthStatus().Items {
+		if item.Name != counter.SplitsName && item.Name != counter.SegmentsName || item.LastHit == nil {
+			continue
+		}
+
+		if response.LastSuccessfulSync == nil || item.LastHit.After(*response.LastSuccessfulSync) {
+			response.LastSuccessfulSync = item.LastHit
+		}
+	}
+
+	if c.failover != nil {
+		status := c.failover.Status()
+		response.Failover = &status
+	}
+
+	if !response.Healthy {
+		ctx.JSON(http.StatusInternalServerError, response)
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
 // Register the dashboard endpoints
 func (c *HealthCheckController) Register(router gin.IRouter) {
 	router.GET("/health/application", c.appHealth)
 	router.GET("/health/dependencies", c.dependenciesHealth)
 }
 
+// RegisterUpstream mounts the upstream reachability endpoint in the provided (admin) router
+func (c *HealthCheckController) RegisterUpstream(router gin.IRouter) {
+	router.GET("/healthcheck", c.upstreamHealth)
+}
+
 // NewHealthCheckController instantiates a new HealthCheck controller
 func NewHealthCheckController(
 	logger logging.LoggerInterface,
 	appMonitor application.MonitorIterface,
 	dependenciesMonitor services.MonitorIterface,
+	failover FailoverStatusProvider,
 ) *HealthCheckController {
 	return &HealthCheckController{
 		logger:              logger,
 		appMonitor:          appMonitor,
 		dependenciesMonitor: dependenciesMonitor,
+		failover:            failover,
 	}
 }
@@ -6,10 +6,15 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/splitio/go-toolkit/v5/logging"
 	"github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/application"
+	"github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/application/counter"
+	"github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/services"
+	"github.com/splitio/split-synchronizer/v5/splitio/util"
+	"github.com/stretchr/testify/assert"
 )
 
 type monitorMock struct {
@@ -25,6 +30,86 @@ func (m *monitorMock) Reset(counterType int, value int) {}
 func (m *monitorMock) Start()                           {}
 func (m *monitorMock) Stop()                            {}
 
+type servicesMonitorMock struct {
+	status services.HealthDto
+}
+
+func (m *servicesMonitorMock) GetHealthStatus() services.HealthDto { return m.status }
+func (m *servicesMonitorMock) Start()                              {}
+func (m *servicesMonitorMock) Stop()                               {}
+
+type failoverMock struct {
+	status util.FailoverStatus
+}
+
+func (m *failoverMock) Status() util.FailoverStatus { return m.status }
+
+func TestUpstreamHealthCheckEndpoint(t *testing.T) {
+	older := time.Now().Add(-time.Minute)
+	newer := time.Now()
+	appHC := &monitorMock{statusCall: func() application.HealthDto {
+		return application.HealthDto{Healthy: true, Items: []application.ItemDto{
+			{Name: counter.SplitsName, Healthy: true, LastHit: &older},
+			{Name: counter.SegmentsName, Healthy: true, LastHit: &newer},
+		}}
+	}}
+	servicesHC := &servicesMonitorMock{status: services.HealthDto{Status: services.DegradedStatus, Items: []services.ItemDto{
+		{Service: "https://sdk.split.io", Healthy: true, LastHit: &newer},
+		{Service: "https://streaming.split.io", Healthy: false},
+	}}}
+
+	ctrl := NewHealthCheckController(logging.NewLogger(nil), appHC, servicesHC, nil)
+	resp := httptest.NewRecorder()
+	ctx, router := gin.CreateTestContext(resp)
+	ctrl.RegisterUpstream(router)
+
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/healthcheck", nil)
+	router.ServeHTTP(resp, ctx.Request)
+	assert.Equal(t, 200, resp.Code)
+
+	var result upstreamHealthDto
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.True(t, result.Healthy)
+	assert.Equal(t, 2, len(result.Dependencies))
+	assert.Equal(t, "https://sdk.split.io", result.Dependencies[0].Service)
+	assert.True(t, result.Dependencies[0].Healthy)
+	assert.False(t, result.Dependencies[1].Healthy)
+	assert.True(t, newer.Equal(*result.LastSuccessfulSync))
+	assert.Nil(t, result.Failover)
+
+	// a critical dependency is down
+	servicesHC.status.Status = services.DownStatus
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, ctx.Request)
+	assert.Equal(t, 500, resp.Code)
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.False(t, result.Healthy)
+}
+
+func TestUpstreamHealthCheckReportsFailover(t *testing.T) {
+	appHC := &monitorMock{statusCall: func() application.HealthDto { return application.HealthDto{Healthy: true} }}
+	servicesHC := &servicesMonitorMock{status: services.HealthDto{Status: services.HealthyStatus}}
+	failover := &failoverMock{status: util.FailoverStatus{
+		Active:              util.UpstreamSecondary,
+		SdkURL:              "https://sdk.secondary.io/api",
+		EventsURL:           "https://events.secondary.io/api",
+		ConsecutiveFailures: 3,
+	}}
+
+	ctrl := NewHealthCheckController(logging.NewLogger(nil), appHC, servicesHC, failover)
+	resp := httptest.NewRecorder()
+	ctx, router := gin.CreateTestContext(resp)
+	ctrl.RegisterUpstream(router)
+
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/healthcheck", nil)
+	router.ServeHTTP(resp, ctx.Request)
+	assert.Equal(t, 200, resp.Code)
+
+	var result upstreamHealthDto
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Equal(t, failover.status, *result.Failover)
+}
+
 func TestApplicationHealthCheckEndpointErr(t *testing.T) {
 
 	appHC := &monitorMock{}
@@ -34,7 +119,7 @@ func TestApplicationHealthCheckEndpointErr(t *testing.T) {
 		}
 	}
 
-	ctrl := NewHealthCheckController(logging.NewLogger(nil), appHC, nil)
+	ctrl := NewHealthCheckController(logging.NewLogger(nil), appHC, nil, nil)
 
 	resp := httptest.NewRecorder()
 	ctx, router := gin.CreateTestContext(resp)
@@ -67,7 +152,7 @@ func TestApplicationHealthCheckEndpointOk(t *testing.T) {
 		}
 	}
 
-	ctrl := NewHealthCheckController(logging.NewLogger(nil), appHC, nil)
+	ctrl := NewHealthCheckController(logging.NewLogger(nil), appHC, nil, nil)
 
 	resp := httptest.NewRecorder()
 	ctx, router := gin.CreateTestContext(resp)
@@ -235,6 +235,24 @@ func getFlagSetsInfo(splitsStorage storage.SplitStorage) []dashboard.FlagSetsSum
 	return summaries
 }
 
+func getSinksInfo(sinks map[string]SinkStatsProvider) []dashboard.SinkSummary {
+	summaries := make([]dashboard.SinkSummary, 0, len(sinks))
+	for name, sink := range sinks {
+		stats := sink.Stats()
+		summaries = append(summaries, dashboard.SinkSummary{
+			Name:    name,
+			Flushed: stats.Flushed,
+			Retried: stats.Retried,
+			Dropped: stats.Dropped,
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[j].Name > summaries[i].Name
+	})
+
+	return summaries
+}
+
 func getImpressionSize(impressionStorage storage.ImpressionMultiSdkConsumer) int64 {
 	if impressionStorage == nil {
 		return 0
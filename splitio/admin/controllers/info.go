@@ -35,6 +35,11 @@ func (c *InfoController) Register(router gin.IRouter) {
 	router.GET("/config", c.config)
 }
 
+// RegisterConfig mounts the effective (redacted) config endpoint in the provided (admin) router
+func (c *InfoController) RegisterConfig(router gin.IRouter) {
+	router.GET("/config", c.config)
+}
+
 func (c *InfoController) config(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"config": c.cfg})
 }
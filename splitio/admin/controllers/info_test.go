@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type fixedUptimeRuntime struct{ uptime time.Duration }
+
+func (r *fixedUptimeRuntime) Uptime() time.Duration { return r.uptime }
+func (r *fixedUptimeRuntime) Shutdown()             {}
+func (r *fixedUptimeRuntime) Kill()                 {}
+
+func TestUptimeBoundaries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := map[time.Duration]string{
+		59 * time.Second:         "59s",
+		59600 * time.Millisecond: "1m0s",
+		60 * time.Second:         "1m0s",
+		3599 * time.Second:       "59m59s",
+		3600 * time.Second:       "1h0m0s",
+		86399 * time.Second:      "23h59m59s",
+		86400 * time.Second:      "24h0m0s",
+	}
+
+	for uptime, expected := range cases {
+		resp := httptest.NewRecorder()
+		ctx, router := gin.CreateTestContext(resp)
+		NewInfoController(false, &fixedUptimeRuntime{uptime: uptime}, nil).Register(router)
+
+		ctx.Request, _ = http.NewRequest(http.MethodGet, "/uptime", nil)
+		router.ServeHTTP(resp, ctx.Request)
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		var body map[string]string
+		assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &body))
+		assert.Equal(t, expected, body["uptime"], "uptime: %s", uptime)
+	}
+}
+
+func TestConfigEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	resp := httptest.NewRecorder()
+	ctx, router := gin.CreateTestContext(resp)
+	controller := NewInfoController(true, &fixedUptimeRuntime{}, map[string]string{"apikey": "****1234"})
+	controller.RegisterConfig(router.Group("/admin"))
+
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/admin/config", nil)
+	router.ServeHTTP(resp, ctx.Request)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var body map[string]map[string]string
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	assert.Equal(t, "****1234", body["config"]["apikey"])
+}
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/log"
+)
+
+// LogLevelController exposes endpoints to read & update the log level at runtime
+type LogLevelController struct {
+	logger     logging.LoggerInterface
+	controller log.LevelController
+}
+
+// NewLogLevelController constructs a new log level controller
+func NewLogLevelController(logger logging.LoggerInterface, controller log.LevelController) *LogLevelController {
+	return &LogLevelController{logger: logger, controller: controller}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *LogLevelController) Register(router gin.IRouter) {
+	router.GET("/loglevel", c.getLevel)
+	router.POST("/loglevel", c.setLevel)
+}
+
+func (c *LogLevelController) getLevel(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"level": log.LevelName(c.controller.Level())})
+}
+
+func (c *LogLevelController) setLevel(ctx *gin.Context) {
+	var body struct {
+		Level string `json:"level"`
+	}
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "expected a body like {\"level\": \"debug\"}"})
+		return
+	}
+
+	level, ok := log.LevelFromName(body.Level)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "unknown log level: " + body.Level})
+		return
+	}
+
+	previous := c.controller.Level()
+	c.controller.SetLevel(level)
+	c.logger.Info("log level updated through the admin API from ", log.LevelName(previous), " to ", log.LevelName(level))
+	ctx.JSON(http.StatusOK, gin.H{"level": log.LevelName(level)})
+}
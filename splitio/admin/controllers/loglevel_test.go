@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/log"
+)
+
+func TestLogLevelEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := log.NewHistoricLoggerWrapper(log.NewLevelFilter(logging.NewLogger(nil), logging.LevelInfo), [5]bool{}, 5)
+	ctrl := NewLogLevelController(logger, logger)
+
+	router := gin.New()
+	ctrl.Register(router)
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/loglevel", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{"level": "INFO"}`, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/loglevel", strings.NewReader(`{"level": "debug"}`))
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{"level": "DEBUG"}`, resp.Body.String())
+	assert.Equal(t, logging.LevelDebug, logger.Level())
+
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/loglevel", strings.NewReader(`{"level": "loud"}`))
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, 400, resp.Code)
+	assert.Equal(t, logging.LevelDebug, logger.Level())
+
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/loglevel", strings.NewReader(`not json`))
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, 400, resp.Code)
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/log"
+)
+
+// LogsController exposes the messages kept in the historic logger buffers
+type LogsController struct {
+	logger log.HistoricLogger
+}
+
+// RecentLogs contains the buffered messages of a specific level, along with the total number of messages logged
+type RecentLogs struct {
+	Total    int64    `json:"total"`
+	Messages []string `json:"messages"`
+}
+
+// NewLogsController constructs a new logs controller
+func NewLogsController(logger log.HistoricLogger) *LogsController {
+	return &LogsController{logger: logger}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *LogsController) Register(router gin.IRouter) {
+	router.GET("/logs/recent", c.recent)
+}
+
+func (c *LogsController) recent(ctx *gin.Context) {
+	toRet := make(map[string]RecentLogs)
+	for level := logging.LevelError; level <= logging.LevelVerbose; level++ {
+		if !c.logger.Buffered(level) {
+			continue
+		}
+		toRet[log.LevelName(level)] = RecentLogs{Total: c.logger.TotalCount(level), Messages: c.logger.Messages(level)}
+	}
+	ctx.JSON(http.StatusOK, toRet)
+}
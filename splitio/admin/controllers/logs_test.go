@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/log"
+)
+
+func TestRecentLogsEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := log.NewHistoricLoggerWrapper(logging.NewLogger(&logging.LoggerOptions{LogLevel: logging.LevelNone}), [5]bool{true, true, false, false, false}, 2)
+	logger.Error("e1")
+	logger.Error("e2")
+	logger.Error("e3")
+	logger.Warning("w1")
+	logger.Info("i1")
+
+	router := gin.New()
+	NewLogsController(logger).Register(router)
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/logs/recent", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, 200, resp.Code)
+
+	var body map[string]RecentLogs
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	assert.Equal(t, map[string]RecentLogs{
+		"ERROR":   {Total: 3, Messages: []string{"e2", "e3"}},
+		"WARNING": {Total: 1, Messages: []string{"w1"}},
+	}, body)
+}
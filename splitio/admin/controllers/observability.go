@@ -57,13 +57,29 @@ func (c *ProxyObservabilityController) Register(router gin.IRouter) {
 }
 
 func (c *ProxyObservabilityController) observability(ctx *gin.Context) {
-	ctx.JSON(200, gin.H{
+	payload := gin.H{
 		"activeSplits":            c.splits.SplitNames(),
 		"activeSegments":          c.segments.NamesAndCount(),
 		"activeFlagSets":          c.splits.GetAllFlagSetNames(),
 		"proxyEndpointStats":      c.telemetry.TimeslicedReport(),
 		"proxyEndpointStatsTotal": c.telemetry.TotalMetricsReport(),
-	})
+	}
+
+	if reporter, ok := c.splits.(pstorage.PayloadCacheReporter); ok {
+		stats := reporter.PayloadCacheStats()
+		var hitRatio float64
+		if total := stats.Hits + stats.Misses; total > 0 {
+			hitRatio = float64(stats.Hits) / float64(total)
+		}
+		payload["splitChangesCache"] = gin.H{"hits": stats.Hits, "misses": stats.Misses, "size": stats.Size, "hitRatio": hitRatio}
+	}
+
+	if reporter, ok := c.splits.(pstorage.ChangesSummaryReporter); ok {
+		stats := reporter.ChangesSummaryStats()
+		payload["splitChangesRecipes"] = gin.H{"retained": stats.Recipes, "evicted": stats.Evicted, "upstreamFallbacks": stats.Fallbacks}
+	}
+
+	ctx.JSON(200, payload)
 }
 
 // NewObservabilityController constructs and returns the appropriate struct dependeing on whether the app is split-proxy or split-sync
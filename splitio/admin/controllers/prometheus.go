@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	pstorage "github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/persistent"
+)
+
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// PrometheusController renders the proxy endpoint telemetry in prometheus text exposition format
+type PrometheusController struct {
+	logger    logging.LoggerInterface
+	telemetry pstorage.TimeslicedProxyEndpointTelemetry
+	dbMetrics DBMetricsProvider
+}
+
+// NewPrometheusController constructs a new prometheus metrics controller
+func NewPrometheusController(
+	logger logging.LoggerInterface,
+	telemetry pstorage.TimeslicedProxyEndpointTelemetry,
+	dbMetrics DBMetricsProvider,
+) *PrometheusController {
+	return &PrometheusController{logger: logger, telemetry: telemetry, dbMetrics: dbMetrics}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *PrometheusController) Register(router gin.IRouter) {
+	router.GET("/metrics/prometheus", c.metrics)
+}
+
+func (c *PrometheusController) metrics(ctx *gin.Context) {
+	var sb strings.Builder
+	writePrometheusTotals(&sb, c.telemetry.TotalMetricsReport())
+	writePrometheusLatestTimeSlice(&sb, c.telemetry.TimeslicedReport())
+	fmt.Fprintln(&sb, "# HELP split_proxy_degraded_responses_total splitChanges responses built from cached data because Split servers could not be reached.")
+	fmt.Fprintln(&sb, "# TYPE split_proxy_degraded_responses_total counter")
+	fmt.Fprintf(&sb, "split_proxy_degraded_responses_total %d\n", c.telemetry.PeekDegradedResponses())
+	fmt.Fprintln(&sb, "# HELP split_proxy_impression_listener_drops_total Impression bulks that could not be forwarded to the impression listener.")
+	fmt.Fprintln(&sb, "# TYPE split_proxy_impression_listener_drops_total counter")
+	fmt.Fprintf(&sb, "split_proxy_impression_listener_drops_total %d\n", c.telemetry.PeekListenerDrops())
+	writePrometheusSpecVersions(&sb, c.telemetry.PeekSpecVersionRequests())
+	if c.dbMetrics != nil {
+		writePrometheusDBMetrics(&sb, c.dbMetrics.Metrics())
+	}
+	ctx.Data(http.StatusOK, prometheusContentType, []byte(sb.String()))
+}
+
+func writePrometheusSpecVersions(w io.Writer, requests map[string]int64) {
+	versions := make([]string, 0, len(requests))
+	for version := range requests {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+
+	fmt.Fprintln(w, "# HELP split_proxy_split_changes_spec_requests_total splitChanges requests by the flag spec version sent by SDKs.")
+	fmt.Fprintln(w, "# TYPE split_proxy_split_changes_spec_requests_total counter")
+	for _, version := range versions {
+		fmt.Fprintf(w, "split_proxy_split_changes_spec_requests_total{spec=%q} %d\n", version, requests[version])
+	}
+}
+
+func writePrometheusTotals(w io.Writer, totals map[string]pstorage.ForResource) {
+	resources := sortedResources(totals)
+
+	fmt.Fprintln(w, "# HELP split_proxy_endpoint_latency_milliseconds Latency of the requests served by each proxy endpoint.")
+	fmt.Fprintln(w, "# TYPE split_proxy_endpoint_latency_milliseconds histogram")
+	for _, resource := range resources {
+		writePrometheusHistogram(w, "split_proxy_endpoint_latency_milliseconds", "resource", resource, totals[resource].Latencies)
+	}
+
+	fmt.Fprintln(w, "# HELP split_proxy_endpoint_responses_total Responses served by each proxy endpoint, by status code.")
+	fmt.Fprintln(w, "# TYPE split_proxy_endpoint_responses_total counter")
+	for _, resource := range resources {
+		statusCodes := totals[resource].StatusCodes
+		codes := make([]int, 0, len(statusCodes))
+		for code := range statusCodes {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			fmt.Fprintf(w, "split_proxy_endpoint_responses_total{resource=%q,code=\"%d\"} %d\n", resource, code, statusCodes[code])
+		}
+	}
+}
+
+func writePrometheusDBMetrics(w io.Writer, metrics map[string]persistent.OperationMetrics) {
+	operations := make([]string, 0, len(metrics))
+	for operation := range metrics {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+
+	fmt.Fprintln(w, "# HELP split_proxy_db_operation_latency_milliseconds Latency of the read, write & compaction operations on the persistent storage.")
+	fmt.Fprintln(w, "# TYPE split_proxy_db_operation_latency_milliseconds histogram")
+	for _, operation := range operations {
+		writePrometheusHistogram(w, "split_proxy_db_operation_latency_milliseconds", "operation", operation, metrics[operation].Latencies)
+	}
+
+	fmt.Fprintln(w, "# HELP split_proxy_db_operation_errors_total Failed operations on the persistent storage.")
+	fmt.Fprintln(w, "# TYPE split_proxy_db_operation_errors_total counter")
+	for _, operation := range operations {
+		fmt.Fprintf(w, "split_proxy_db_operation_errors_total{operation=%q} %d\n", operation, metrics[operation].Errors)
+	}
+}
+
+// writePrometheusHistogram renders latencies bucketed by the commons telemetry package as a cumulative histogram.
+// Actual latencies are not kept, so the sum is estimated from the buckets
+func writePrometheusHistogram(w io.Writer, name string, label string, value string, latencies []int64) {
+	var cumulative int64
+	for idx, count := range latencies {
+		cumulative += count
+		le := "+Inf"
+		if idx < len(pstorage.LatencyBucketBounds) { // the last bucket is unbounded, so it's rendered as `+Inf`
+			le = strconv.FormatFloat(pstorage.LatencyBucketBounds[idx], 'f', -1, 64)
+		}
+		fmt.Fprintf(w, "%s_bucket{%s=%q,le=%q} %d\n", name, label, value, le, cumulative)
+	}
+	fmt.Fprintf(w, "%s_sum{%s=%q} %s\n", name, label, value, strconv.FormatFloat(pstorage.EstimateLatencySum(latencies), 'f', -1, 64))
+	fmt.Fprintf(w, "%s_count{%s=%q} %d\n", name, label, value, cumulative)
+}
+
+func writePrometheusLatestTimeSlice(w io.Writer, report pstorage.TimeSliceData) {
+	if len(report) == 0 {
+		return
+	}
+
+	latest := report[len(report)-1] // time-slices are sorted in ascending order
+
+	fmt.Fprintln(w, "# HELP split_proxy_timeslice_start_seconds Start of the current telemetry time-slice.")
+	fmt.Fprintln(w, "# TYPE split_proxy_timeslice_start_seconds gauge")
+	fmt.Fprintf(w, "split_proxy_timeslice_start_seconds %d\n", latest.TimeSlice)
+
+	fmt.Fprintln(w, "# HELP split_proxy_timeslice_requests Requests served by each proxy endpoint in the current telemetry time-slice.")
+	fmt.Fprintln(w, "# TYPE split_proxy_timeslice_requests gauge")
+	for _, resource := range sortedResources(latest.Resources) {
+		fmt.Fprintf(w, "split_proxy_timeslice_requests{resource=%q} %d\n", resource, latest.Resources[resource].RequestCount)
+	}
+}
+
+func sortedResources(data map[string]pstorage.ForResource) []string {
+	resources := make([]string, 0, len(data))
+	for resource := range data {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	return resources
+}
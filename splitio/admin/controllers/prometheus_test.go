@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/persistent"
+)
+
+func TestPrometheusEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	telemetry := storage.NewTimeslicedProxyEndpointTelemetry(storage.NewProxyTelemetryFacade(), 60, 5)
+	telemetry.RecordEndpointLatency(storage.SplitChangesEndpoint, 1*time.Millisecond)
+	telemetry.RecordEndpointLatency(storage.SplitChangesEndpoint, 10*time.Second)
+	telemetry.IncrEndpointStatus(storage.SplitChangesEndpoint, 200)
+	telemetry.IncrEndpointStatus(storage.SplitChangesEndpoint, 500)
+	telemetry.IncrDegradedResponses()
+	telemetry.IncrListenerDrops()
+
+	resp := httptest.NewRecorder()
+	ctx, router := gin.CreateTestContext(resp)
+	NewPrometheusController(logging.NewLogger(nil), telemetry, nil).Register(router)
+
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/metrics/prometheus", nil)
+	router.ServeHTTP(resp, ctx.Request)
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, prometheusContentType, resp.Header().Get("Content-Type"))
+
+	body := resp.Body.String()
+	assert.Contains(t, body, "# TYPE split_proxy_endpoint_latency_milliseconds histogram\n")
+	assert.Contains(t, body, `split_proxy_endpoint_latency_milliseconds_bucket{resource="splitChanges",le="1"} 1`+"\n")
+	assert.Contains(t, body, `split_proxy_endpoint_latency_milliseconds_bucket{resource="splitChanges",le="4987.89"} 1`+"\n")
+	assert.Contains(t, body, `split_proxy_endpoint_latency_milliseconds_bucket{resource="splitChanges",le="+Inf"} 2`+"\n")
+	assert.Contains(t, body, `split_proxy_endpoint_latency_milliseconds_sum{resource="splitChanges"} 4988.39`+"\n")
+	assert.Contains(t, body, `split_proxy_endpoint_latency_milliseconds_count{resource="splitChanges"} 2`+"\n")
+	assert.Contains(t, body, `split_proxy_endpoint_latency_milliseconds_sum{resource="mySegments"} 0`+"\n")
+	assert.Contains(t, body, `split_proxy_endpoint_latency_milliseconds_count{resource="mySegments"} 0`+"\n")
+	assert.Contains(t, body, `split_proxy_endpoint_responses_total{resource="splitChanges",code="200"} 1`+"\n")
+	assert.Contains(t, body, `split_proxy_endpoint_responses_total{resource="splitChanges",code="500"} 1`+"\n")
+	assert.Contains(t, body, `split_proxy_timeslice_requests{resource="splitChanges"} 2`+"\n")
+	assert.Contains(t, body, "split_proxy_degraded_responses_total 1\n")
+	assert.Contains(t, body, "split_proxy_impression_listener_drops_total 1\n")
+}
+
+type dbMetricsMock struct{}
+
+func (dbMetricsMock) Metrics() map[string]persistent.OperationMetrics {
+	latencies := make([]int64, 23)
+	latencies[0] = 3
+	return map[string]persistent.OperationMetrics{
+		persistent.OperationRead:  {Count: 3, Errors: 1, Latencies: latencies},
+		persistent.OperationWrite: {Count: 0, Errors: 0, Latencies: make([]int64, 23)},
+	}
+}
+
+func TestPrometheusEndpointDBMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	telemetry := storage.NewTimeslicedProxyEndpointTelemetry(storage.NewProxyTelemetryFacade(), 60, 5)
+	resp := httptest.NewRecorder()
+	ctx, router := gin.CreateTestContext(resp)
+	NewPrometheusController(logging.NewLogger(nil), telemetry, dbMetricsMock{}).Register(router)
+
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/metrics/prometheus", nil)
+	router.ServeHTTP(resp, ctx.Request)
+	assert.Equal(t, 200, resp.Code)
+
+	body := resp.Body.String()
+	assert.Contains(t, body, "# TYPE split_proxy_db_operation_latency_milliseconds histogram\n")
+	assert.Contains(t, body, `split_proxy_db_operation_latency_milliseconds_bucket{operation="read",le="1"} 3`+"\n")
+	assert.Contains(t, body, `split_proxy_db_operation_latency_milliseconds_bucket{operation="read",le="+Inf"} 3`+"\n")
+	assert.Contains(t, body, `split_proxy_db_operation_latency_milliseconds_sum{operation="read"} 1.5`+"\n")
+	assert.Contains(t, body, `split_proxy_db_operation_latency_milliseconds_count{operation="write"} 0`+"\n")
+	assert.Contains(t, body, `split_proxy_db_operation_errors_total{operation="read"} 1`+"\n")
+	assert.Contains(t, body, `split_proxy_db_operation_errors_total{operation="write"} 0`+"\n")
+}
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadinessProvider is implemented by components tracking whether the proxy is ready to serve sdk requests
+type ReadinessProvider interface {
+	IsReady() bool
+}
+
+// ReadinessController exposes whether the proxy is ready to serve sdk requests (ie: for load balancer health checks)
+type ReadinessController struct {
+	readiness ReadinessProvider
+}
+
+// NewReadinessController constructs a new readiness controller
+func NewReadinessController(readiness ReadinessProvider) *ReadinessController {
+	return &ReadinessController{readiness: readiness}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *ReadinessController) Register(router gin.IRouter) {
+	router.GET("/ready", c.ready)
+}
+
+func (c *ReadinessController) ready(ctx *gin.Context) {
+	if !c.readiness.IsReady() {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"ready": false})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"ready": true})
+}
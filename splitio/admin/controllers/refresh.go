@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v6/synchronizer/worker/segment"
+	"github.com/splitio/go-split-commons/v6/synchronizer/worker/split"
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+// RefreshController exposes endpoints to force an immediate synchronization of feature flags & segments
+type RefreshController struct {
+	logger         logging.LoggerInterface
+	splitUpdater   split.Updater
+	segmentUpdater segment.Updater
+}
+
+// NewRefreshController constructs a new refresh controller
+func NewRefreshController(logger logging.LoggerInterface, splitUpdater split.Updater, segmentUpdater segment.Updater) *RefreshController {
+	return &RefreshController{logger: logger, splitUpdater: splitUpdater, segmentUpdater: segmentUpdater}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *RefreshController) Register(router gin.IRouter) {
+	router.POST("/refresh/splits", c.refreshSplits)
+	router.POST("/refresh/segments", c.refreshSegments)
+}
+
+func (c *RefreshController) refreshSplits(ctx *gin.Context) {
+	c.logger.Info("feature flags refresh requested through the admin API")
+	result, err := c.splitUpdater.SynchronizeSplits(nil)
+	if err != nil {
+		c.logger.Error("error refreshing feature flags: ", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"changeNumber": result.NewChangeNumber, "updated": result.UpdatedSplits})
+}
+
+func (c *RefreshController) refreshSegments(ctx *gin.Context) {
+	c.logger.Info("segments refresh requested through the admin API")
+	results, err := c.segmentUpdater.SynchronizeSegments()
+	if err != nil {
+		c.logger.Error("error refreshing segments: ", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	changeNumbers := make(map[string]int64, len(results))
+	for name, result := range results {
+		changeNumbers[name] = result.NewChangeNumber
+	}
+	ctx.JSON(http.StatusOK, gin.H{"changeNumbers": changeNumbers})
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v6/synchronizer/worker/segment"
+	"github.com/splitio/go-split-commons/v6/synchronizer/worker/split"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+type splitUpdaterMock struct {
+	split.Updater
+	result *split.UpdateResult
+	err    error
+}
+
+func (m *splitUpdaterMock) SynchronizeSplits(till *int64) (*split.UpdateResult, error) {
+	return m.result, m.err
+}
+
+type segmentUpdaterMock struct {
+	segment.Updater
+	results map[string]segment.UpdateResult
+	err     error
+}
+
+func (m *segmentUpdaterMock) SynchronizeSegments() (map[string]segment.UpdateResult, error) {
+	return m.results, m.err
+}
+
+func TestRefreshEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	splits := &splitUpdaterMock{result: &split.UpdateResult{NewChangeNumber: 123, UpdatedSplits: []string{"split1"}}}
+	segments := &segmentUpdaterMock{results: map[string]segment.UpdateResult{"segment1": {NewChangeNumber: 456}}}
+	ctrl := NewRefreshController(logging.NewLogger(nil), splits, segments)
+
+	resp := httptest.NewRecorder()
+	ctx, router := gin.CreateTestContext(resp)
+	ctrl.Register(router)
+
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/refresh/splits", nil)
+	router.ServeHTTP(resp, ctx.Request)
+	assert.Equal(t, 200, resp.Code)
+	var splitsBody struct {
+		ChangeNumber int64    `json:"changeNumber"`
+		Updated      []string `json:"updated"`
+	}
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &splitsBody))
+	assert.Equal(t, int64(123), splitsBody.ChangeNumber)
+	assert.Equal(t, []string{"split1"}, splitsBody.Updated)
+
+	resp = httptest.NewRecorder()
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/refresh/segments", nil)
+	router.ServeHTTP(resp, ctx.Request)
+	assert.Equal(t, 200, resp.Code)
+	var segmentsBody struct {
+		ChangeNumbers map[string]int64 `json:"changeNumbers"`
+	}
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &segmentsBody))
+	assert.Equal(t, map[string]int64{"segment1": 456}, segmentsBody.ChangeNumbers)
+
+	splits.err = errors.New("some error")
+	resp = httptest.NewRecorder()
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/refresh/splits", nil)
+	router.ServeHTTP(resp, ctx.Request)
+	assert.Equal(t, 500, resp.Code)
+}
@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v6/storage"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/seed"
+)
+
+// SeedExportController exposes the current feature flags & segments in the seed file format, so that the state of a
+// warm proxy can be used to warm up new instances
+type SeedExportController struct {
+	logger   logging.LoggerInterface
+	splits   storage.SplitStorageConsumer
+	segments storage.SegmentStorageConsumer
+}
+
+// NewSeedExportController constructs a new seed export controller
+func NewSeedExportController(
+	logger logging.LoggerInterface,
+	splits storage.SplitStorageConsumer,
+	segments storage.SegmentStorageConsumer,
+) *SeedExportController {
+	return &SeedExportController{logger: logger, splits: splits, segments: segments}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *SeedExportController) Register(router gin.IRouter) {
+	router.GET("/snapshot/export", c.export)
+}
+
+func (c *SeedExportController) export(ctx *gin.Context) {
+	// curl http://localhost:3010/admin/snapshot/export --output seed.json
+	ctx.Writer.Header().Set("Content-Type", "application/json")
+	ctx.Writer.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="split.proxy.%d.seed.json"`, time.Now().UnixNano()))
+	ctx.Status(http.StatusOK)
+
+	// the response is streamed, so once the first bytes are sent there's no way to report the error to the client
+	if err := seed.Write(ctx.Writer, c.splits, c.segments); err != nil {
+		c.logger.Error("error exporting seed file: ", err)
+	}
+}
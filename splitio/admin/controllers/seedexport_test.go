@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-split-commons/v6/flagsets"
+	"github.com/splitio/go-toolkit/v5/datastructures/set"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/seed"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/persistent"
+)
+
+func TestSeedExport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logging.NewLogger(nil)
+	dbw, err := persistent.NewBoltWrapper(persistent.BoltInMemoryMode, nil)
+	assert.Nil(t, err)
+	splits := storage.NewProxySplitStorage(dbw, logger, flagsets.NewFlagSetFilter(nil), false, nil, 0, 0)
+	segments := storage.NewProxySegmentStorage(dbw, logger, false)
+	splits.Update([]dtos.SplitDTO{{Name: "f1", ChangeNumber: 2, Status: "ACTIVE"}}, nil, 2)
+	segments.Update("s1", set.NewSet("k1"), set.NewSet(), 3)
+
+	resp := httptest.NewRecorder()
+	ctx, router := gin.CreateTestContext(resp)
+	NewSeedExportController(logger, splits, segments).Register(router)
+
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/snapshot/export", nil)
+	router.ServeHTTP(resp, ctx.Request)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+	assert.Contains(t, resp.Header().Get("Content-Disposition"), "attachment")
+
+	var decoded seed.File
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &decoded))
+	assert.Equal(t, seed.FormatVersion, decoded.Version)
+	assert.Equal(t, int64(2), decoded.Splits.ChangeNumber)
+	assert.Len(t, decoded.Splits.Splits, 1)
+	assert.Empty(t, decoded.Segments) // s1 is not referenced by any feature flag
+}
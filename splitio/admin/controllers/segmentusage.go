@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-split-commons/v6/storage"
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+// SegmentUsageController exposes which feature flags depend on a given segment
+type SegmentUsageController struct {
+	logger logging.LoggerInterface
+	splits storage.SplitStorageConsumer
+}
+
+// NewSegmentUsageController constructs a new segment usage controller
+func NewSegmentUsageController(logger logging.LoggerInterface, splits storage.SplitStorageConsumer) *SegmentUsageController {
+	return &SegmentUsageController{logger: logger, splits: splits}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *SegmentUsageController) Register(router gin.IRouter) {
+	router.GET("/segment/:name/flags", c.flagsForSegment)
+}
+
+func (c *SegmentUsageController) flagsForSegment(ctx *gin.Context) {
+	name := ctx.Param("name")
+	if name == "" {
+		ctx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"segment": name,
+		"flags":   flagsReferencingSegment(c.splits.All(), name),
+	})
+}
+
+// flagsReferencingSegment returns the (sorted) names of the feature flags with at least one
+// matcher that depends on the supplied segment
+func flagsReferencingSegment(splits []dtos.SplitDTO, segment string) []string {
+	toRet := make([]string, 0)
+	for idx := range splits {
+		if splitReferencesSegment(&splits[idx], segment) {
+			toRet = append(toRet, splits[idx].Name)
+		}
+	}
+	sort.Strings(toRet)
+	return toRet
+}
+
+func splitReferencesSegment(split *dtos.SplitDTO, segment string) bool {
+	for _, condition := range split.Conditions {
+		for _, matcher := range condition.MatcherGroup.Matchers {
+			if matcher.UserDefinedSegment != nil && matcher.UserDefinedSegment.SegmentName == segment {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-split-commons/v6/storage/mocks"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegmentUsageEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	inSegment := func(name string) dtos.ConditionDTO {
+		return dtos.ConditionDTO{MatcherGroup: dtos.MatcherGroupDTO{Matchers: []dtos.MatcherDTO{{
+			MatcherType:        "IN_SEGMENT",
+			UserDefinedSegment: &dtos.UserDefinedSegmentMatcherDataDTO{SegmentName: name},
+		}}}}
+	}
+
+	splitStorage := &mocks.MockSplitStorage{
+		AllCall: func() []dtos.SplitDTO {
+			return []dtos.SplitDTO{
+				{Name: "split3", Conditions: []dtos.ConditionDTO{inSegment("segment1")}},
+				{Name: "split1", Conditions: []dtos.ConditionDTO{inSegment("segment2"), inSegment("segment1")}},
+				{Name: "split2", Conditions: []dtos.ConditionDTO{inSegment("segment2")}},
+				{Name: "split4", Conditions: []dtos.ConditionDTO{{}}},
+			}
+		},
+	}
+
+	resp := httptest.NewRecorder()
+	ctx, router := gin.CreateTestContext(resp)
+	NewSegmentUsageController(logging.NewLogger(nil), splitStorage).Register(router)
+
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/segment/segment1/flags", nil)
+	router.ServeHTTP(resp, ctx.Request)
+	assert.Equal(t, 200, resp.Code)
+
+	var result struct {
+		Segment string   `json:"segment"`
+		Flags   []string `json:"flags"`
+	}
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Equal(t, "segment1", result.Segment)
+	assert.Equal(t, []string{"split1", "split3"}, result.Flags)
+
+	resp = httptest.NewRecorder()
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/segment/nonexistent/flags", nil)
+	router.ServeHTTP(resp, ctx.Request)
+	assert.Equal(t, 200, resp.Code)
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Equal(t, []string{}, result.Flags)
+}
@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	pstorage "github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+)
+
+// StatsResetController exposes an endpoint to zero out the proxy endpoint telemetry without restarting (ie: between load tests)
+type StatsResetController struct {
+	logger    logging.LoggerInterface
+	telemetry pstorage.TimeslicedProxyEndpointTelemetry
+}
+
+// NewStatsResetController constructs a new stats reset controller
+func NewStatsResetController(logger logging.LoggerInterface, telemetry pstorage.TimeslicedProxyEndpointTelemetry) *StatsResetController {
+	return &StatsResetController{logger: logger, telemetry: telemetry}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *StatsResetController) Register(router gin.IRouter) {
+	router.POST("/stats/reset", c.reset)
+}
+
+// reset returns the data collected up to this point, so that the last window isn't lost.
+// Requests served between taking the snapshot & resetting the counters are not accounted for in either
+func (c *StatsResetController) reset(ctx *gin.Context) {
+	snapshot := gin.H{
+		"totals":            c.telemetry.TotalMetricsReport(),
+		"timeslices":        c.telemetry.TimeslicedReport(),
+		"degradedResponses": c.telemetry.PeekDegradedResponses(),
+		"listenerDrops":     c.telemetry.PeekListenerDrops(),
+		"specVersions":      c.telemetry.PeekSpecVersionRequests(),
+	}
+	c.telemetry.ResetEndpointTelemetry()
+	c.logger.Info("proxy endpoint telemetry reset through the admin API")
+	ctx.JSON(http.StatusOK, snapshot)
+}
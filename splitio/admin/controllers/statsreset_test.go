@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+)
+
+func TestStatsResetEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	telemetry := storage.NewTimeslicedProxyEndpointTelemetry(storage.NewProxyTelemetryFacade(), 60, 5)
+	telemetry.RecordEndpointLatency(storage.SplitChangesEndpoint, 1*time.Millisecond)
+	telemetry.IncrEndpointStatus(storage.SplitChangesEndpoint, 200)
+	telemetry.IncrEndpointStatus(storage.SplitChangesEndpoint, 500)
+	telemetry.IncrDegradedResponses()
+	telemetry.IncrListenerDrops()
+
+	resp := httptest.NewRecorder()
+	ctx, router := gin.CreateTestContext(resp)
+	NewStatsResetController(logging.NewLogger(nil), telemetry).Register(router)
+
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/stats/reset", nil)
+	router.ServeHTTP(resp, ctx.Request)
+	assert.Equal(t, 200, resp.Code)
+
+	var snapshot struct {
+		Totals            map[string]storage.ForResource `json:"totals"`
+		TimeSlices        storage.TimeSliceData          `json:"timeslices"`
+		DegradedResponses int64                          `json:"degradedResponses"`
+		ListenerDrops     int64                          `json:"listenerDrops"`
+	}
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &snapshot))
+	assert.Equal(t, 2, snapshot.Totals["splitChanges"].RequestCount)
+	assert.Equal(t, 1, len(snapshot.TimeSlices))
+	assert.Equal(t, int64(1), snapshot.DegradedResponses)
+	assert.Equal(t, int64(1), snapshot.ListenerDrops)
+
+	// counters are zeroed after the snapshot is taken
+	assert.Equal(t, 0, telemetry.TotalMetricsReport()["splitChanges"].RequestCount)
+	assert.Equal(t, int64(0), telemetry.TotalMetricsReport()["splitChanges"].Latencies[0])
+	assert.Equal(t, 0, len(telemetry.TimeslicedReport()))
+	assert.Equal(t, int64(0), telemetry.PeekDegradedResponses())
+	assert.Equal(t, int64(0), telemetry.PeekListenerDrops())
+}
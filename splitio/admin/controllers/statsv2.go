@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"github.com/splitio/split-synchronizer/v5/splitio/admin/views/dashboard"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/persistent"
+)
+
+// StatsSchemaVersion is the version of the stats payload served by `GET /dashboard/stats?format=v2`.
+// Fields may be added within a version, but renaming, removing or changing the meaning of an existing one
+// requires bumping it.
+const StatsSchemaVersion = 2
+
+// StatsV2 is the stable, machine-readable representation of the runtime stats:
+//
+//	{
+//	  "schemaVersion": 2,
+//	  "uptimeSeconds": 3600,
+//	  "requests": {
+//	    "sdk":      {"ok": 10, "errored": 1, "total": 11},   // requests served to SDKs (proxy only)
+//	    "upstream": {"ok": 20, "errored": 0, "total": 20}    // requests made to Split servers
+//	  },
+//	  "latencies": {
+//	    "sdk":      [{"endpoint": "/api/splitChanges", "buckets": [0, 3, ...]}],  // proxy only
+//	    "upstream": [{"endpoint": "/api/splitChanges", "buckets": [0, 1, ...]}]
+//	  },
+//	  "queues": {                                             // synchronizer only
+//	    "impressions": {"size": 100, "lambda": 1.2},
+//	    "events":      {"size": 30, "lambda": 0.8}
+//	  },
+//	  "cache": {"featureFlags": 12, "segments": 3, "flagSets": 2},
+//	  "errors": {"logged": 1, "recent": ["..."]},
+//	  "db": {                                                 // proxy only
+//	    "read":       {"count": 500, "errors": 0, "latencies": [490, 10, ...]},
+//	    "write":      {"count": 40, "errors": 1, "latencies": [30, 8, ...]},
+//	    "compaction": {"count": 1, "errors": 0, "latencies": [0, 0, ...]}
+//	  }
+//	}
+//
+// Latency buckets follow the go-split-commons telemetry bucketing (22 buckets, same bounds as the prometheus histograms).
+type StatsV2 struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	UptimeSeconds int64             `json:"uptimeSeconds"`
+	Requests      StatsV2Requests   `json:"requests"`
+	Latencies     StatsV2Latencies  `json:"latencies"`
+	Queues        StatsV2Queues     `json:"queues"`
+	Cache         StatsV2CacheSizes `json:"cache"`
+	Errors        StatsV2Errors     `json:"errors"`
+
+	DB map[string]persistent.OperationMetrics `json:"db,omitempty"`
+}
+
+// StatsV2Requests groups request counters by direction
+type StatsV2Requests struct {
+	SDK      StatsV2RequestCount `json:"sdk"`
+	Upstream StatsV2RequestCount `json:"upstream"`
+}
+
+// StatsV2RequestCount holds the amount of successful & failed requests
+type StatsV2RequestCount struct {
+	Ok      int64 `json:"ok"`
+	Errored int64 `json:"errored"`
+	Total   int64 `json:"total"`
+}
+
+// StatsV2Latencies groups per-endpoint latency buckets by direction
+type StatsV2Latencies struct {
+	SDK      []StatsV2EndpointLatencies `json:"sdk"`
+	Upstream []StatsV2EndpointLatencies `json:"upstream"`
+}
+
+// StatsV2EndpointLatencies holds the latency buckets of a single endpoint
+type StatsV2EndpointLatencies struct {
+	Endpoint string  `json:"endpoint"`
+	Buckets  []int64 `json:"buckets"`
+}
+
+// StatsV2Queues holds the status of the impressions & events queues
+type StatsV2Queues struct {
+	Impressions StatsV2Queue `json:"impressions"`
+	Events      StatsV2Queue `json:"events"`
+}
+
+// StatsV2Queue holds the size & eviction lambda of a queue
+type StatsV2Queue struct {
+	Size   int64   `json:"size"`
+	Lambda float64 `json:"lambda"`
+}
+
+// StatsV2CacheSizes holds the amount of cached items
+type StatsV2CacheSizes struct {
+	FeatureFlags int `json:"featureFlags"`
+	Segments     int `json:"segments"`
+	FlagSets     int `json:"flagSets"`
+}
+
+// StatsV2Errors holds the logged errors
+type StatsV2Errors struct {
+	Logged int64    `json:"logged"`
+	Recent []string `json:"recent"`
+}
+
+func newStatsV2(stats *dashboard.GlobalStats) *StatsV2 {
+	recent := stats.LoggedMessages
+	if recent == nil {
+		recent = []string{}
+	}
+
+	return &StatsV2{
+		SchemaVersion: StatsSchemaVersion,
+		UptimeSeconds: stats.Uptime,
+		Requests: StatsV2Requests{
+			SDK:      StatsV2RequestCount{Ok: stats.RequestsOk, Errored: stats.RequestsErrored, Total: stats.SdksTotalRequests},
+			Upstream: StatsV2RequestCount{Ok: stats.BackendRequestsOk, Errored: stats.BackendRequestsErrored, Total: stats.BackendTotalRequests},
+		},
+		Latencies: StatsV2Latencies{
+			SDK:      newStatsV2EndpointLatencies(stats.Latencies),
+			Upstream: newStatsV2EndpointLatencies(stats.BackendLatencies),
+		},
+		Queues: StatsV2Queues{
+			Impressions: StatsV2Queue{Size: stats.ImpressionsQueueSize, Lambda: stats.ImpressionsLambda},
+			Events:      StatsV2Queue{Size: stats.EventsQueueSize, Lambda: stats.EventsLambda},
+		},
+		Cache: StatsV2CacheSizes{
+			FeatureFlags: len(stats.FeatureFlags),
+			Segments:     len(stats.Segments),
+			FlagSets:     len(stats.FlagSets),
+		},
+		Errors: StatsV2Errors{Logged: stats.LoggedErrors, Recent: recent},
+	}
+}
+
+func newStatsV2EndpointLatencies(charts []dashboard.ChartJSData) []StatsV2EndpointLatencies {
+	toReturn := make([]StatsV2EndpointLatencies, 0, len(charts))
+	for _, chart := range charts {
+		buckets := make([]int64, 0, len(chart.Data))
+		for _, item := range chart.Data {
+			if asInt, ok := item.(int64); ok {
+				buckets = append(buckets, asInt)
+			}
+		}
+		toReturn = append(toReturn, StatsV2EndpointLatencies{Endpoint: chart.Label, Buckets: buckets})
+	}
+	return toReturn
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/admin/views/dashboard"
+)
+
+func TestStatsV2(t *testing.T) {
+	stats := newStatsV2(&dashboard.GlobalStats{
+		RequestsOk:           9,
+		RequestsErrored:      1,
+		SdksTotalRequests:    10,
+		BackendRequestsOk:    5,
+		BackendTotalRequests: 5,
+		LoggedErrors:         2,
+		FeatureFlags:         []dashboard.SplitSummary{{Name: "f1"}, {Name: "f2"}},
+		Segments:             []dashboard.SegmentSummary{{Name: "s1"}},
+		Latencies:            []dashboard.ChartJSData{{Label: "/api/splitChanges", Data: int64ToInterfaceSlice([]int64{1, 2, 3})}},
+		ImpressionsQueueSize: 7,
+		ImpressionsLambda:    1.5,
+		Uptime:               120,
+	})
+
+	assert.Equal(t, StatsSchemaVersion, stats.SchemaVersion)
+	assert.Equal(t, int64(120), stats.UptimeSeconds)
+	assert.Equal(t, StatsV2RequestCount{Ok: 9, Errored: 1, Total: 10}, stats.Requests.SDK)
+	assert.Equal(t, StatsV2RequestCount{Ok: 5, Errored: 0, Total: 5}, stats.Requests.Upstream)
+	assert.Equal(t, []StatsV2EndpointLatencies{{Endpoint: "/api/splitChanges", Buckets: []int64{1, 2, 3}}}, stats.Latencies.SDK)
+	assert.Equal(t, []StatsV2EndpointLatencies{}, stats.Latencies.Upstream)
+	assert.Equal(t, StatsV2Queue{Size: 7, Lambda: 1.5}, stats.Queues.Impressions)
+	assert.Equal(t, StatsV2CacheSizes{FeatureFlags: 2, Segments: 1}, stats.Cache)
+	assert.Equal(t, StatsV2Errors{Logged: 2, Recent: []string{}}, stats.Errors)
+
+	// empty collections must be serialized as [] rather than null so that consumers can rely on the types
+	serialized, err := json.Marshal(stats)
+	assert.Nil(t, err)
+	var raw map[string]interface{}
+	assert.Nil(t, json.Unmarshal(serialized, &raw))
+	assert.Equal(t, float64(2), raw["schemaVersion"])
+	assert.Equal(t, []interface{}{}, raw["latencies"].(map[string]interface{})["upstream"])
+	assert.Equal(t, []interface{}{}, raw["errors"].(map[string]interface{})["recent"])
+}
@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	pstorage "github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+)
+
+// StatusCodesController exposes the count of each http status code served by the proxy, per endpoint
+type StatusCodesController struct {
+	logger    logging.LoggerInterface
+	telemetry pstorage.TimeslicedProxyEndpointTelemetry
+}
+
+// NewStatusCodesController constructs a new status codes controller
+func NewStatusCodesController(logger logging.LoggerInterface, telemetry pstorage.TimeslicedProxyEndpointTelemetry) *StatusCodesController {
+	return &StatusCodesController{logger: logger, telemetry: telemetry}
+}
+
+// Register mounts the endpoints int he provided router
+func (c *StatusCodesController) Register(router gin.IRouter) {
+	router.GET("/status-codes", c.statusCodes)
+}
+
+func (c *StatusCodesController) statusCodes(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.telemetry.StatusCodesReport())
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+)
+
+func TestStatusCodesEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	telemetry := storage.NewTimeslicedProxyEndpointTelemetry(storage.NewProxyTelemetryFacade(), 60, 5)
+	telemetry.IncrEndpointStatus(storage.SplitChangesEndpoint, 200)
+	telemetry.IncrEndpointStatus(storage.SplitChangesEndpoint, 500)
+	telemetry.IncrEndpointStatus(storage.EventsBulkEndpoint, 400)
+
+	resp := httptest.NewRecorder()
+	ctx, router := gin.CreateTestContext(resp)
+	NewStatusCodesController(logging.NewLogger(nil), telemetry).Register(router)
+
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/status-codes", nil)
+	router.ServeHTTP(resp, ctx.Request)
+	assert.Equal(t, 200, resp.Code)
+
+	var result storage.StatusCodesReport
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Equal(t, map[int]int64{200: 1, 500: 1}, result.Total["splitChanges"])
+	assert.Equal(t, map[int]int64{400: 1}, result.Total["eventsBulk"])
+	assert.Equal(t, map[int]int64{}, result.Total["mySegments"])
+	assert.Equal(t, 1, len(result.TimeSlices))
+	assert.Equal(t, map[int]int64{200: 1, 500: 1}, result.TimeSlices[0].Resources["splitChanges"])
+}
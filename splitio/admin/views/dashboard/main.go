@@ -106,6 +106,7 @@ type GlobalStats struct {
 	EventsLambda           float64           `json:"eventsLambda"`
 	Uptime                 int64             `json:"uptime"`
 	FlagSets               []FlagSetsSummary `json:"flagSets"`
+	Sinks                  []SinkSummary     `json:"sinks"`
 }
 
 // SplitSummary encapsulates a minimalistic view of feature flag properties to be presented in the dashboard
@@ -143,6 +144,14 @@ type FlagSetsSummary struct {
 	FeatureFlags           string `json:"featureFlags"`
 }
 
+// SinkSummary encapsulates the number of bulks posted by a synchronization task, grouped by outcome
+type SinkSummary struct {
+	Name    string `json:"name"`
+	Flushed int64  `json:"flushed"`
+	Retried int64  `json:"retried"`
+	Dropped int64  `json:"dropped"`
+}
+
 // RGBA bundles input to CSS's rgba function
 type RGBA struct {
 	Red   int32
@@ -0,0 +1,48 @@
+package splitio
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// BuildDate is the moment the binary was built. It's injected at link time by the Makefile
+// (-ldflags "-X github.com/splitio/split-synchronizer/v5/splitio.BuildDate=...")
+var BuildDate = "unknown"
+
+// fipsEnforced is flipped when building with the `enforce_fips` tag
+var fipsEnforced = false
+
+// BuildInfo bundles the version & build metadata of the running binary
+type BuildInfo struct {
+	Version      string `json:"version"`
+	Commit       string `json:"commit"`
+	GoVersion    string `json:"goVersion"`
+	BuildDate    string `json:"buildDate"`
+	Platform     string `json:"platform"`
+	FIPSEnforced bool   `json:"fipsEnforced"`
+}
+
+// GetBuildInfo returns the version & build metadata of the running binary
+func GetBuildInfo() BuildInfo {
+	return BuildInfo{
+		Version:      Version,
+		Commit:       CommitVersion,
+		GoVersion:    runtime.Version(),
+		BuildDate:    BuildDate,
+		Platform:     runtime.GOOS + "/" + runtime.GOARCH,
+		FIPSEnforced: fipsEnforced,
+	}
+}
+
+// String returns a human readable multi-line representation of the build info
+func (b BuildInfo) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Version:       %s\n", b.Version)
+	fmt.Fprintf(&sb, "Commit:        %s\n", b.Commit)
+	fmt.Fprintf(&sb, "Go version:    %s\n", b.GoVersion)
+	fmt.Fprintf(&sb, "Build date:    %s\n", b.BuildDate)
+	fmt.Fprintf(&sb, "Platform:      %s\n", b.Platform)
+	fmt.Fprintf(&sb, "FIPS enforced: %t\n", b.FIPSEnforced)
+	return sb.String()
+}
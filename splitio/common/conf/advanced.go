@@ -2,12 +2,14 @@ package conf
 
 import (
 	"os"
+	"strings"
 
 	"github.com/splitio/go-split-commons/v6/conf"
 )
 
 // InitAdvancedOptions initializes an advanced config with default values + overriden urls.
-func InitAdvancedOptions(proxy bool) *conf.AdvancedConfig {
+// Urls set in the upstream config take precedence over the ones in env vars.
+func InitAdvancedOptions(proxy bool, upstream *Upstream) *conf.AdvancedConfig {
 
 	prefix := "SPLIT_SYNC_"
 	if proxy {
@@ -15,25 +17,22 @@ func InitAdvancedOptions(proxy bool) *conf.AdvancedConfig {
 	}
 
 	advanced := conf.GetDefaultAdvancedConfig()
-	if envSdkURL := os.Getenv(prefix + "SDK_URL"); envSdkURL != "" {
-		advanced.SdkURL = envSdkURL
-	}
-
-	if envEventsURL := os.Getenv(prefix + "EVENTS_URL"); envEventsURL != "" {
-		advanced.EventsURL = envEventsURL
-	}
-
-	if authServiceURL := os.Getenv(prefix + "AUTH_SERVICE_URL"); authServiceURL != "" {
-		advanced.AuthServiceURL = authServiceURL
-	}
+	advanced.SdkURL = resolveURL(upstream.SdkURL, prefix+"SDK_URL", advanced.SdkURL)
+	advanced.EventsURL = resolveURL(upstream.EventsURL, prefix+"EVENTS_URL", advanced.EventsURL)
+	advanced.AuthServiceURL = resolveURL(upstream.AuthURL, prefix+"AUTH_SERVICE_URL", advanced.AuthServiceURL)
+	advanced.StreamingServiceURL = resolveURL(upstream.StreamingURL, prefix+"STREAMING_SERVICE_URL", advanced.StreamingServiceURL)
+	advanced.TelemetryServiceURL = resolveURL(upstream.TelemetryURL, prefix+"TELEMETRY_SERVICE_URL", advanced.TelemetryServiceURL)
+	return &advanced
+}
 
-	if streamingServiceURL := os.Getenv(prefix + "STREAMING_SERVICE_URL"); streamingServiceURL != "" {
-		advanced.StreamingServiceURL = streamingServiceURL
+func resolveURL(configured string, envVar string, fallback string) string {
+	if configured != "" {
+		return strings.TrimSuffix(configured, "/")
 	}
 
-	if telemetryServiceURL := os.Getenv(prefix + "TELEMETRY_SERVICE_URL"); telemetryServiceURL != "" {
-		advanced.TelemetryServiceURL = telemetryServiceURL
+	if fromEnv := os.Getenv(envVar); fromEnv != "" {
+		return fromEnv
 	}
 
-	return &advanced
+	return fallback
 }
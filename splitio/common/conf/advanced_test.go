@@ -0,0 +1,28 @@
+package conf
+
+import (
+	"testing"
+
+	"github.com/splitio/go-split-commons/v6/conf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInitAdvancedOptionsURLs(t *testing.T) {
+	defaults := conf.GetDefaultAdvancedConfig()
+	t.Setenv("SPLIT_PROXY_SDK_URL", "https://sdk.from.env/api")
+	t.Setenv("SPLIT_PROXY_EVENTS_URL", "https://events.from.env/api")
+
+	advanced := InitAdvancedOptions(true, &Upstream{
+		EventsURL:    "https://events.onprem.local/api/",
+		StreamingURL: "https://streaming.onprem.local/sse",
+	})
+	assert.Equal(t, "https://sdk.from.env/api", advanced.SdkURL)
+	assert.Equal(t, "https://events.onprem.local/api", advanced.EventsURL)
+	assert.Equal(t, "https://streaming.onprem.local/sse", advanced.StreamingServiceURL)
+	assert.Equal(t, defaults.AuthServiceURL, advanced.AuthServiceURL)
+	assert.Equal(t, defaults.TelemetryServiceURL, advanced.TelemetryServiceURL)
+
+	advanced = InitAdvancedOptions(false, &Upstream{})
+	assert.Equal(t, defaults.SdkURL, advanced.SdkURL)
+	assert.Equal(t, defaults.EventsURL, advanced.EventsURL)
+}
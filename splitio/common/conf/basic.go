@@ -9,6 +9,8 @@ type CliFlags struct {
 	ConfigFile             *string
 	WriteDefaultConfigFile *string
 	VersionInfo            *bool
+	VersionOutput          *string
+	CheckConfig            *bool
 	RawConfig              ArgMap
 }
 
@@ -18,7 +20,9 @@ func ParseCliArgs(definition interface{}) *CliFlags {
 	flags := &CliFlags{
 		ConfigFile:             flag.String("config", "", "a configuration file"),
 		WriteDefaultConfigFile: flag.String("write-default-config", "", "write a default configuration file"),
-		VersionInfo:            flag.Bool("version", false, "Print the version"),
+		VersionInfo:            flag.Bool("version", false, "Print the version & build info"),
+		VersionOutput:          flag.String("o", "text", "Output format for -version (text|json)"),
+		CheckConfig:            flag.Bool("check-config", false, "Validate the configuration, print the effective values & exit"),
 		RawConfig:              MakeCliArgMapFor(definition),
 	}
 
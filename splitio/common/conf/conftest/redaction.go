@@ -0,0 +1,32 @@
+// Package conftest contains helpers shared by the config tests of both the producer & the proxy
+package conftest
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+// AssertSecretsNotLogged logs the supplied (redacted) config the same way the binaries do, both as json & as a struct,
+// and fails the test if any of the secrets is found in the output
+func AssertSecretsNotLogged(t *testing.T, redacted interface{}, secrets ...string) {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	logger := logging.NewLogger(&logging.LoggerOptions{LogLevel: logging.LevelDebug, DebugWriter: &buffer, InfoWriter: &buffer})
+	asJSON, err := json.Marshal(redacted)
+	if err != nil {
+		t.Error("error serializing config: ", err)
+	}
+	logger.Debug("effective configuration: ", string(asJSON))
+	logger.Info("effective configuration: ", redacted)
+
+	for _, secret := range secrets {
+		if strings.Contains(buffer.String(), secret) {
+			t.Error("secret found in logger output: ", secret)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package conf
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EnvVarName returns the environment variable mapped to a cli argument, ie: (SPLIT_PROXY, log-level) -> SPLIT_PROXY_LOG_LEVEL.
+// This is the same mapping used by the docker entrypoints to translate env vars into cli arguments
+func EnvVarName(prefix string, cliArgName string) string {
+	return strings.ToUpper(strings.ReplaceAll(prefix+"_"+cliArgName, "-", "_"))
+}
+
+// PopulateFromEnvironment examines target fields by reflection and populates the ones that have a cli argument
+// with the value of the matching environment variable (see EnvVarName), if set.
+// It returns the number of properties populated
+func PopulateFromEnvironment(target interface{}, prefix string) (int, error) {
+	return populateFromEnvRecursive(reflect.ValueOf(target).Elem(), prefix, "")
+}
+
+func populateFromEnvRecursive(val reflect.Value, envPrefix string, cliPrefix string) (int, error) {
+	var populated int
+	for i := 0; i < val.NumField(); i++ {
+		typeField := val.Type().Field(i)
+		tag := typeField.Tag
+
+		if len(tag.Get(tagNested)) > 0 {
+			count, err := populateFromEnvRecursive(val.Field(i), envPrefix, buildPrefix(cliPrefix, tag.Get(tagCliPrefix)))
+			populated += count
+			if err != nil {
+				return populated, err
+			}
+		}
+
+		cliArgName := tag.Get(tagCliArgName)
+		if len(cliArgName) <= 0 {
+			continue
+		}
+
+		if len(cliPrefix) > 0 {
+			cliArgName = fmt.Sprintf("%s-%s", cliPrefix, cliArgName)
+		}
+
+		envVar := EnvVarName(envPrefix, cliArgName)
+		raw, ok := os.LookupEnv(envVar)
+		if !ok {
+			continue
+		}
+
+		switch typeField.Type.String() {
+		case typeString:
+			val.Field(i).SetString(raw)
+		case typeStringSlice:
+			items := strings.Split(raw, ",")
+			rval := reflect.MakeSlice(typeField.Type, len(items), len(items))
+			for idx, item := range items {
+				rval.Index(idx).SetString(item)
+			}
+			val.Field(i).Set(rval)
+		case typeInt, typeInt64:
+			parsed, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return populated, fmt.Errorf("%s expected int, got '%s'", envVar, raw)
+			}
+			val.Field(i).SetInt(parsed)
+		case typeBool:
+			parsed, err := strconv.ParseBool(raw)
+			if err != nil {
+				return populated, fmt.Errorf("%s expected bool, got '%s'", envVar, raw)
+			}
+			val.Field(i).SetBool(parsed)
+		default:
+			continue
+		}
+		populated++
+	}
+	return populated, nil
+}
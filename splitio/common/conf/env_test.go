@@ -0,0 +1,46 @@
+package conf
+
+import (
+	"testing"
+)
+
+func TestEnvVarName(t *testing.T) {
+	if n := EnvVarName("SPLIT_PROXY", "admin-tls-enabled"); n != "SPLIT_PROXY_ADMIN_TLS_ENABLED" {
+		t.Error("unexpected env var name: ", n)
+	}
+}
+
+func TestPopulateFromEnvironment(t *testing.T) {
+	t.Setenv("SPLIT_TEST_F0", "7")
+	t.Setenv("SPLIT_TEST_F2", "CHAU")
+	t.Setenv("SPLIT_TEST_F3", "true")
+	t.Setenv("SPLIT_TEST_F4", "e3,e4,e5")
+	t.Setenv("SPLIT_TEST_NEST_FF1", "nested")
+
+	var cfg someConf
+	PopulateDefaults(&cfg)
+	populated, err := PopulateFromEnvironment(&cfg, "SPLIT_TEST")
+	if err != nil {
+		t.Error("no error should be returned. Got: ", err)
+	}
+
+	if populated != 5 {
+		t.Error("5 properties should have been populated. Got: ", populated)
+	}
+
+	if cfg.F0 != 7 || cfg.F1 != 123 || cfg.F2 != "CHAU" || !cfg.F3 || len(cfg.F4) != 3 || cfg.F4[2] != "e5" {
+		t.Error("config not properly populated: ", cfg)
+	}
+
+	if cfg.F5.F1 != "CHAU" || cfg.F6.F1 != "nested" {
+		t.Error("nested config not properly populated: ", cfg.F5, cfg.F6)
+	}
+}
+
+func TestPopulateFromEnvironmentInvalidValue(t *testing.T) {
+	t.Setenv("SPLIT_TEST_F1", "abc")
+	var cfg someConf
+	if _, err := PopulateFromEnvironment(&cfg, "SPLIT_TEST"); err == nil || err.Error() != "SPLIT_TEST_F1 expected int, got 'abc'" {
+		t.Error("an error with the offending env var should be returned. Got: ", err)
+	}
+}
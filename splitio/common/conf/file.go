@@ -4,16 +4,36 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math"
 	"os"
 	"reflect"
-
-	validator "github.com/splitio/go-toolkit/v5/json-struct-validator"
+	"sort"
+	"strings"
 )
 
 // ErrNoFile is the error to return when an empty config file si passed
 var ErrNoFile = errors.New("no config file provided")
 
+// ErrEmptySecretFile is the error to return when a secret file has no contents
+var ErrEmptySecretFile = errors.New("secret file is empty")
+
+// ReadSecretFile reads a secret (ie: an SDK key) from a file, such as the ones mounted by Kubernetes/Docker secrets.
+// Trailing whitespace & newlines are trimmed
+func ReadSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading secret file (%s): %w", path, err)
+	}
+
+	secret := strings.TrimRight(string(data), " \t\r\n")
+	if secret == "" {
+		return "", fmt.Errorf("%w (%s)", ErrEmptySecretFile, path)
+	}
+	return secret, nil
+}
+
 // PopulateConfigFromFile parses a json config file and populates the config struct passed as an argument
 func PopulateConfigFromFile(path string, target interface{}) error {
 	if _, err := os.Stat(path); err != nil {
@@ -25,29 +45,126 @@ func PopulateConfigFromFile(path string, target interface{}) error {
 		return fmt.Errorf("error reading config file (%s): %w", path, err)
 	}
 
-	err = json.Unmarshal(data, target)
-	if err != nil {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
 		return fmt.Errorf("error parsing JSON config file (%s): %w", path, err)
 	}
 
-	// This function does a couple of things (to keep the caller clean):
-	// - read the config file
-	// - populate the struct with the appropriate values
-	// - check that there are no extra fields in the json file
-	// On top of that, the function needs to be generic, to work with different configs (sync || proxy).
-	// The function needs a pointer to a struct to update it (modify it's contents) and it needs an actual struct
-	// to inspect the fields. The `target` interface (which is already based on a pinter) parameter contains a pointer as well
-	// to the struct being populated/validated. In order to validate, we need an `interface{}` object pointing to the same struct,
-	// but without the extra indirection
-	targetForValidation := reflect.Indirect(reflect.ValueOf(target)).Interface()
-	err = validator.ValidateConfiguration(targetForValidation, data)
-	if err != nil {
-		return fmt.Errorf("error validating provided JSON file (%s): %w", path, err)
+	// Check the file contents against the config struct before populating it, so that every mistyped or unknown
+	// property is reported at once (with its full path) instead of failing on the first one with a terse message
+	if issues := validateConfigFields(raw, reflect.Indirect(reflect.ValueOf(target)).Type(), ""); len(issues) > 0 {
+		return fmt.Errorf("error validating provided JSON file (%s): %s", path, strings.Join(issues, "; "))
+	}
+
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("error parsing JSON config file (%s): %w", path, err)
 	}
 
 	return nil
 }
 
+func validateConfigFields(raw map[string]interface{}, structType reflect.Type, prefix string) []string {
+	fields := make(map[string]reflect.StructField, structType.NumField())
+	for i := 0; i < structType.NumField(); i++ {
+		name, _, _ := strings.Cut(structType.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = structType.Field(i)
+		}
+	}
+
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var issues []string
+	for _, key := range keys {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		field, ok := fields[key]
+		if !ok {
+			issues = append(issues, fmt.Sprintf("unknown property %s", path))
+			continue
+		}
+
+		value := raw[key]
+		if value == nil { // nulls are ignored when populating the config, same as missing properties
+			continue
+		}
+
+		if asMap, ok := value.(map[string]interface{}); ok && field.Type.Kind() == reflect.Struct {
+			issues = append(issues, validateConfigFields(asMap, field.Type, path)...)
+			continue
+		}
+
+		if !jsonValueFits(value, field.Type) {
+			issues = append(issues, fmt.Sprintf("%s expected %s, got %s", path, describeConfigType(field.Type), describeJSONValue(value)))
+		}
+	}
+	return issues
+}
+
+func jsonValueFits(value interface{}, target reflect.Type) bool {
+	switch target.Kind() {
+	case reflect.String:
+		_, ok := value.(string)
+		return ok
+	case reflect.Bool:
+		_, ok := value.(bool)
+		return ok
+	case reflect.Int, reflect.Int64:
+		asNumber, ok := value.(float64)
+		return ok && asNumber == math.Trunc(asNumber)
+	case reflect.Slice:
+		items, ok := value.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, item := range items {
+			if !jsonValueFits(item, target.Elem()) {
+				return false
+			}
+		}
+		return true
+	}
+	return true // types not used in config sections are left for the json decoder to check
+}
+
+func describeConfigType(target reflect.Type) string {
+	switch target.Kind() {
+	case reflect.Int, reflect.Int64:
+		return "int"
+	case reflect.Slice:
+		return "list of " + describeConfigType(target.Elem())
+	case reflect.Struct:
+		return "object"
+	}
+	return target.Kind().String()
+}
+
+func describeJSONValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case float64:
+		if v != math.Trunc(v) {
+			return "decimal number"
+		}
+		return "int"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
 // WriteDefaultConfigFile writes the default config defition to a JSON file
 func WriteDefaultConfigFile(name string, definition interface{}) error {
 	if name == "" {
@@ -69,3 +186,14 @@ func WriteDefaultConfigFile(name string, definition interface{}) error {
 
 	return nil
 }
+
+// WriteConfigSummary writes the supplied config as indented JSON, to be presented to a user
+func WriteConfigSummary(w io.Writer, cfg interface{}) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing config: %w", err)
+	}
+
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
@@ -0,0 +1,81 @@
+package conf
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type fileNestedConf struct {
+	MaxSize int64 `json:"maxSize"`
+}
+
+type fileConf struct {
+	Name    string         `json:"name"`
+	Enabled bool           `json:"enabled"`
+	Tags    []string       `json:"tags"`
+	Nested  fileNestedConf `json:"nested" s-nested:"true"`
+}
+
+func writeTempConfig(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal("error writing config file: ", err)
+	}
+	return path
+}
+
+func TestPopulateConfigFromFile(t *testing.T) {
+	path := writeTempConfig(t, `{"name": "some", "enabled": true, "tags": ["a", "b"], "nested": {"maxSize": 12}}`)
+	var cfg fileConf
+	if err := PopulateConfigFromFile(path, &cfg); err != nil {
+		t.Error("no error should be returned. Got: ", err)
+	}
+
+	if cfg.Name != "some" || !cfg.Enabled || len(cfg.Tags) != 2 || cfg.Nested.MaxSize != 12 {
+		t.Error("config not properly populated: ", cfg)
+	}
+}
+
+func TestPopulateConfigFromFileValidationMessages(t *testing.T) {
+	path := writeTempConfig(t, `{"name": 3, "enabled": "yes", "tags": ["a", 1], "nested": {"maxSize": "12", "minSize": 1}, "other": {}}`)
+	var cfg fileConf
+	err := PopulateConfigFromFile(path, &cfg)
+	if err == nil {
+		t.Fatal("an error should be returned")
+	}
+
+	for _, expected := range []string{
+		"enabled expected bool, got string",
+		"name expected string, got int",
+		"nested.maxSize expected int, got string",
+		"unknown property nested.minSize",
+		"unknown property other",
+		"tags expected list of string, got list",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("error should contain '%s'. Got: %s", expected, err.Error())
+		}
+	}
+}
+
+func TestReadSecretFile(t *testing.T) {
+	secret, err := ReadSecretFile(writeTempConfig(t, "some-sdk-key \r\n\n"))
+	if err != nil {
+		t.Error("no error expected. Got: ", err)
+	}
+	if secret != "some-sdk-key" {
+		t.Errorf("trailing whitespace should be trimmed. Got: '%s'", secret)
+	}
+
+	if _, err := ReadSecretFile(writeTempConfig(t, " \n")); !errors.Is(err, ErrEmptySecretFile) {
+		t.Error("an empty secret file should fail. Got: ", err)
+	}
+
+	if _, err := ReadSecretFile(filepath.Join(t.TempDir(), "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("a missing secret file should fail. Got: ", err)
+	}
+}
@@ -0,0 +1,68 @@
+package conf
+
+import (
+	"net/url"
+	"strings"
+)
+
+const redactedVisibleChars = 4
+
+// fully masked secrets are replaced by this value, so that not even their length is given away
+const maskedSecret = "xxxxxxxxxxxxxxx"
+
+// RedactSecret masks an identifier-like secret (ie: an apikey), keeping only the last 4 characters visible so that it
+// can still be told apart from others. Secrets too short to keep anything visible without giving them away are fully
+// masked. Passwords, tokens & urls with embedded credentials must be masked with MaskSecret instead
+func RedactSecret(secret string) string {
+	if len(secret) <= 2*redactedVisibleChars {
+		return strings.Repeat("*", len(secret))
+	}
+	return strings.Repeat("*", len(secret)-redactedVisibleChars) + secret[len(secret)-redactedVisibleChars:]
+}
+
+// MaskSecret fully masks a secret (ie: a password). Empty values are kept as-is, so that it's still possible to tell
+// whether the secret is set
+func MaskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return maskedSecret
+}
+
+// RedactSecrets returns a copy of the supplied list with every secret masked
+func RedactSecrets(secrets []string) []string {
+	if secrets == nil {
+		return nil
+	}
+
+	redacted := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		redacted = append(redacted, RedactSecret(secret))
+	}
+	return redacted
+}
+
+// Redacted returns a copy of the admin config with the credentials masked
+func (a Admin) Redacted() Admin {
+	a.Password = MaskSecret(a.Password)
+	a.Token = MaskSecret(a.Token)
+	return a
+}
+
+// Redacted returns a copy of the integrations config with the credentials & webhook urls masked
+func (i Integrations) Redacted() Integrations {
+	i.Slack.Webhook = MaskSecret(i.Slack.Webhook)
+	i.ImpressionListener.Endpoint = MaskSecret(i.ImpressionListener.Endpoint)
+	return i
+}
+
+// Redacted returns a copy of the upstream config with the proxy credentials (if any) masked
+func (u Upstream) Redacted() Upstream {
+	parsed, err := url.Parse(u.ProxyURL)
+	if err != nil {
+		return u
+	}
+
+	u.ProxyURL = parsed.Redacted()
+	return u
+}
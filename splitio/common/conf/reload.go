@@ -0,0 +1,227 @@
+package conf
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/splitio/go-toolkit/v5/asynctask"
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+// reloadableFields lists the config properties (as json paths) that can be applied on a running instance.
+// Everything else requires a restart, either because it's bound to a resource created at startup (listening ports,
+// storage backends, tls material, http clients) or because it's captured by a component that cannot be updated
+// in place (ie: refresh rates & buffer sizes are used to build periodic tasks & queues at startup)
+var reloadableFields = map[string]struct{}{
+	"logging.level":          {},
+	"server.ingestRateLimit": {},
+	"server.ingestRateBurst": {},
+}
+
+// IsReloadable returns true if the config property identified by the supplied json path can be hot-applied
+func IsReloadable(path string) bool {
+	_, ok := reloadableFields[path]
+	return ok
+}
+
+// ChangedFields compares two config structs of the same type and returns the json paths of the properties that differ
+func ChangedFields(old interface{}, new interface{}) []string {
+	return changedFieldsRecursive(reflect.Indirect(reflect.ValueOf(old)), reflect.Indirect(reflect.ValueOf(new)), "")
+}
+
+func changedFieldsRecursive(old reflect.Value, new reflect.Value, prefix string) []string {
+	var changed []string
+	for i := 0; i < old.NumField(); i++ {
+		typeField := old.Type().Field(i)
+		name, _, _ := strings.Cut(typeField.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		if len(prefix) > 0 {
+			name = prefix + "." + name
+		}
+
+		if len(typeField.Tag.Get(tagNested)) > 0 {
+			changed = append(changed, changedFieldsRecursive(old.Field(i), new.Field(i), name)...)
+			continue
+		}
+
+		if !reflect.DeepEqual(old.Field(i).Interface(), new.Field(i).Interface()) {
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
+
+// fieldByPath returns the config property identified by the supplied json path
+func fieldByPath(cfg reflect.Value, path string) (reflect.Value, bool) {
+	current := reflect.Indirect(cfg)
+	for _, name := range strings.Split(path, ".") {
+		found := false
+		for i := 0; i < current.NumField(); i++ {
+			if tagName, _, _ := strings.Cut(current.Type().Field(i).Tag.Get("json"), ","); tagName == name {
+				current = current.Field(i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return reflect.Value{}, false
+		}
+	}
+	return current, true
+}
+
+// Reloader re-reads the config on demand, hot-applies the changed properties that support it & reports the ones
+// that require a restart to take effect
+type Reloader struct {
+	current  interface{}
+	load     func() (interface{}, error)
+	handlers map[string]func(value interface{})
+	watcher  *FileWatcher
+	logger   logging.LoggerInterface
+	mutex    sync.Mutex
+}
+
+// NewReloader constructs a reloader. `current` must be a pointer to the config in use, and `load` must return a pointer
+// to a freshly parsed config of the same type (or nil if it cannot be parsed)
+func NewReloader(current interface{}, load func() (interface{}, error), logger logging.LoggerInterface) *Reloader {
+	return &Reloader{
+		current:  current,
+		load:     load,
+		handlers: make(map[string]func(value interface{})),
+		logger:   logger,
+	}
+}
+
+// OnChange registers a handler that applies the new value of a reloadable property (identified by its json path)
+func (r *Reloader) OnChange(path string, handler func(value interface{})) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.handlers[path] = handler
+}
+
+// Reload re-reads the config & applies the changes. Returns the properties that were applied & the ones
+// that require a restart. The latter keep being reported on subsequent reloads until the instance is restarted
+func (r *Reloader) Reload() (applied []string, restartRequired []string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	updated, err := r.load()
+	if updated == nil {
+		r.logger.Error("error reloading config, keeping current settings: ", err)
+		return nil, nil
+	}
+
+	for _, field := range ChangedFields(r.current, updated) {
+		handler, ok := r.handlers[field]
+		if !ok || !IsReloadable(field) {
+			r.logger.Warning(fmt.Sprintf("config property '%s' has changed and requires a restart to take effect", field))
+			restartRequired = append(restartRequired, field)
+			continue
+		}
+
+		newValue, _ := fieldByPath(reflect.ValueOf(updated), field)
+		currentValue, _ := fieldByPath(reflect.ValueOf(r.current), field)
+		handler(newValue.Interface())
+		currentValue.Set(newValue)
+		r.logger.Info(fmt.Sprintf("config property '%s' reloaded", field))
+		applied = append(applied, field)
+	}
+
+	if len(applied) == 0 && len(restartRequired) == 0 {
+		r.logger.Info("config reloaded, no changes found")
+	}
+	return applied, restartRequired
+}
+
+// WatchFile makes the reloader re-read the config whenever the supplied file is modified, once started
+func (r *Reloader) WatchFile(path string, period time.Duration) error {
+	watcher, err := NewFileWatcher(path, period, func() { r.Reload() }, r.logger)
+	if err != nil {
+		return err
+	}
+	r.watcher = watcher
+	return nil
+}
+
+// Start begins reloading the config every time the process receives a SIGHUP (and when the watched file changes, if any).
+// Must be called once every handler has been registered, otherwise an early reload would report (and skip) the
+// properties whose handlers are not registered yet
+func (r *Reloader) Start() {
+	if r.watcher != nil {
+		r.watcher.Start()
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		for range signals {
+			r.logger.Info("SIGHUP received, reloading config")
+			r.Reload()
+		}
+	}()
+}
+
+// FileWatcher periodically checks a config file and invokes a callback when it's been modified
+type FileWatcher struct {
+	path     string
+	task     *asynctask.AsyncTask
+	lastMod  time.Time
+	lastSize int64
+}
+
+// NewFileWatcher constructs a new watcher for the supplied file. The callback is executed in the watcher's goroutine
+func NewFileWatcher(path string, period time.Duration, onChange func(), logger logging.LoggerInterface) (*FileWatcher, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error looking for config file (%s): %w", path, err)
+	}
+
+	periodSecs := int(period.Seconds())
+	if periodSecs < 1 {
+		periodSecs = 1
+	}
+
+	watcher := &FileWatcher{path: path, lastMod: info.ModTime(), lastSize: info.Size()}
+	watcher.task = asynctask.NewAsyncTask("config-file-watcher", func(l logging.LoggerInterface) error {
+		if watcher.modified(l) {
+			onChange()
+		}
+		return nil
+	}, periodSecs, nil, nil, logger)
+	return watcher, nil
+}
+
+// Start begins watching the file
+func (w *FileWatcher) Start() {
+	w.task.Start()
+}
+
+// Stop stops watching the file
+func (w *FileWatcher) Stop() {
+	w.task.Stop(false)
+}
+
+func (w *FileWatcher) modified(logger logging.LoggerInterface) bool {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		logger.Warning(fmt.Sprintf("error checking config file (%s) for changes: %s", w.path, err.Error()))
+		return false
+	}
+
+	if info.ModTime().Equal(w.lastMod) && info.Size() == w.lastSize {
+		return false
+	}
+
+	w.lastMod = info.ModTime()
+	w.lastSize = info.Size()
+	return true
+}
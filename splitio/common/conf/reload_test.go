@@ -0,0 +1,149 @@
+package conf
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangedFields(t *testing.T) {
+	type inner struct {
+		Level string `json:"level"`
+		Size  int64  `json:"size"`
+	}
+
+	type main struct {
+		Apikey  string   `json:"apikey"`
+		Sets    []string `json:"sets"`
+		Logging inner    `json:"logging" s-nested:"true"`
+	}
+
+	old := main{Apikey: "some", Sets: []string{"a"}, Logging: inner{Level: "info", Size: 1}}
+	new := old
+	assert.Empty(t, ChangedFields(&old, &new))
+
+	new.Sets = []string{"a", "b"}
+	new.Logging.Level = "debug"
+	assert.ElementsMatch(t, []string{"sets", "logging.level"}, ChangedFields(&old, &new))
+}
+
+func TestIsReloadable(t *testing.T) {
+	assert.True(t, IsReloadable("logging.level"))
+	assert.False(t, IsReloadable("logging.output"))
+	assert.False(t, IsReloadable("admin.port"))
+}
+
+func TestFileWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.Nil(t, os.WriteFile(path, []byte(`{}`), 0644))
+
+	var calls int32
+	watcher, err := NewFileWatcher(path, time.Second, func() { atomic.AddInt32(&calls, 1) }, logging.NewLogger(nil))
+	assert.Nil(t, err)
+	watcher.Start()
+	defer watcher.Stop()
+
+	time.Sleep(1500 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+
+	assert.Nil(t, os.WriteFile(path, []byte(`{"logging": {}}`), 0644))
+	time.Sleep(1500 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestFileWatcherMissingFile(t *testing.T) {
+	_, err := NewFileWatcher(filepath.Join(t.TempDir(), "nope.json"), time.Second, func() {}, logging.NewLogger(nil))
+	assert.NotNil(t, err)
+}
+
+func TestReloader(t *testing.T) {
+	type loggingSection struct {
+		Level  string `json:"level"`
+		Output string `json:"output"`
+	}
+
+	type serverSection struct {
+		IngestRateLimit int64 `json:"ingestRateLimit"`
+	}
+
+	type main struct {
+		Logging loggingSection `json:"logging" s-nested:"true"`
+		Server  serverSection  `json:"server" s-nested:"true"`
+	}
+
+	current := main{Logging: loggingSection{Level: "info", Output: "stdout"}}
+	next := current
+	var loadErr error
+	reloader := NewReloader(&current, func() (interface{}, error) {
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		updated := next
+		return &updated, nil
+	}, logging.NewLogger(nil))
+
+	var level string
+	reloader.OnChange("logging.level", func(value interface{}) { level = value.(string) })
+
+	applied, restart := reloader.Reload()
+	assert.Empty(t, applied)
+	assert.Empty(t, restart)
+
+	next.Logging.Level = "debug"
+	next.Logging.Output = "/var/log/split.log"
+	next.Server.IngestRateLimit = 10 // reloadable, but no handler registered
+	applied, restart = reloader.Reload()
+	assert.Equal(t, []string{"logging.level"}, applied)
+	assert.ElementsMatch(t, []string{"logging.output", "server.ingestRateLimit"}, restart)
+	assert.Equal(t, "debug", level)
+	assert.Equal(t, "debug", current.Logging.Level)
+	assert.Equal(t, "stdout", current.Logging.Output)
+
+	// changes requiring a restart keep being reported, applied ones are not re-applied
+	level = ""
+	applied, restart = reloader.Reload()
+	assert.Empty(t, applied)
+	assert.ElementsMatch(t, []string{"logging.output", "server.ingestRateLimit"}, restart)
+	assert.Equal(t, "", level)
+
+	loadErr = errors.New("some parse error")
+	next.Logging.Level = "error"
+	applied, restart = reloader.Reload()
+	assert.Empty(t, applied)
+	assert.Empty(t, restart)
+	assert.Equal(t, "debug", current.Logging.Level)
+}
+
+func TestReloaderWatchesFileOnceStarted(t *testing.T) {
+	type config struct {
+		Apikey string `json:"apikey"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.Nil(t, os.WriteFile(path, []byte(`{}`), 0644))
+
+	var loads int32
+	current := config{Apikey: "some"}
+	reloader := NewReloader(&current, func() (interface{}, error) {
+		atomic.AddInt32(&loads, 1)
+		return &config{Apikey: "some"}, nil
+	}, logging.NewLogger(nil))
+	assert.Nil(t, reloader.WatchFile(path, time.Second))
+	assert.NotNil(t, reloader.WatchFile(filepath.Join(t.TempDir(), "nope.json"), time.Second))
+
+	// changes made before the reloader is started are not applied until then
+	assert.Nil(t, os.WriteFile(path, []byte(`{"apikey": "some"}`), 0644))
+	time.Sleep(1500 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&loads))
+
+	reloader.Start()
+	defer reloader.watcher.Stop()
+	time.Sleep(1500 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loads))
+}
@@ -10,12 +10,16 @@ type Logging struct {
 
 // Admin configuration options
 type Admin struct {
-	Host     string `json:"host" s-cli:"admin-host" s-def:"0.0.0.0" s-desc:"Host where the admin server will listen"`
-	Port     int64  `json:"port" s-cli:"admin-port" s-def:"3010" s-desc:"Admin port where incoming connections will be accepted"`
-	Username string `json:"username" s-cli:"admin-username" s-def:"" s-desc:"HTTP basic auth username for admin endpoints"`
-	Password string `json:"password" s-cli:"admin-password" s-def:"" s-desc:"HTTP basic auth password for admin endpoints"`
-	SecureHC bool   `json:"secureChecks" s-cli:"admin-secure-hc" s-def:"false" s-desc:"Secure Healthcheck endpoints as well."`
-	TLS      TLS    `json:"tls" s-nested:"true" s-cli-prefix:"admin"`
+	Host               string `json:"host" s-cli:"admin-host" s-def:"0.0.0.0" s-desc:"Host where the admin server will listen"`
+	Port               int64  `json:"port" s-cli:"admin-port" s-def:"3010" s-desc:"Admin port where incoming connections will be accepted"`
+	Username           string `json:"username" s-cli:"admin-username" s-def:"" s-desc:"HTTP basic auth username for admin endpoints"`
+	Password           string `json:"password" s-cli:"admin-password" s-def:"" s-desc:"HTTP basic auth password for admin endpoints"`
+	Token              string `json:"token" s-cli:"admin-token" s-def:"" s-desc:"Bearer token accepted by admin endpoints (alongside basic auth credentials if set)"`
+	SecureHC           bool   `json:"secureChecks" s-cli:"admin-secure-hc" s-def:"false" s-desc:"Require admin credentials on healthcheck endpoints as well"`
+	TLS                TLS    `json:"tls" s-nested:"true" s-cli-prefix:"admin"`
+	ExposeSegmentUsage bool   `json:"exposeSegmentUsage" s-cli:"admin-expose-segment-usage" s-def:"false" s-desc:"Expose which feature flags reference each segment"`
+	ExposeStatusCodes  bool   `json:"exposeStatusCodes" s-cli:"admin-expose-status-codes" s-def:"false" s-desc:"Expose the count of each status code served per endpoint (proxy only)"`
+	ExposePrometheus   bool   `json:"exposePrometheus" s-cli:"admin-expose-prometheus" s-def:"false" s-desc:"Expose proxy endpoint telemetry in prometheus format (proxy only)"`
 }
 
 // Integrations configuration options
@@ -26,14 +30,17 @@ type Integrations struct {
 
 // ImpressionListener configuration options
 type ImpressionListener struct {
-	Endpoint  string `json:"endpoint" s-cli:"impression-listener-endpoint" s-def:"" s-desc:"HTTP endpoint to forward impressions to"`
-	QueueSize int64  `json:"queueSize" s-cli:"impression-listener-queue-size" s-def:"100" s-desc:"max number of impressions bulks to queue"`
+	Endpoint          string `json:"endpoint" s-cli:"impression-listener-endpoint" s-def:"" s-desc:"HTTP endpoint to forward impressions to"`
+	QueueSize         int64  `json:"queueSize" s-cli:"impression-listener-queue-size" s-def:"100" s-desc:"max number of impressions bulks to queue"`
+	TimeoutMs         int64  `json:"timeoutMs" s-cli:"impression-listener-timeout-ms" s-def:"30000" s-desc:"http timeout when posting impressions to the listener"`
+	SubmitConcurrency int64  `json:"submitConcurrency" s-cli:"impression-listener-submit-concurrency" s-def:"16" s-desc:"max number of incoming impression bulks being prepared for the listener at once (proxy only)"`
 }
 
 // Slack configuration options
 type Slack struct {
-	Webhook string `json:"webhook" s-cli:"slack-webhook" s-def:"" s-desc:"slack webhook to post log messages"`
-	Channel string `json:"channel" s-cli:"slack-channel" s-def:"" s-desc:"slack channel to post log messages"`
+	Webhook         string `json:"webhook" s-cli:"slack-webhook" s-def:"" s-desc:"slack webhook to post log messages"`
+	Channel         string `json:"channel" s-cli:"slack-channel" s-def:"" s-desc:"slack channel to post log messages"`
+	BatchPeriodSecs int64  `json:"batchPeriodSecs" s-cli:"slack-batch-period-secs" s-def:"0" s-desc:"coalesce log messages into a single slack post every N seconds, suppressing duplicates (0 = post each message)"`
 }
 
 // TLS config options
@@ -47,3 +54,36 @@ type TLS struct {
 	MinTLSVersion            string `json:"minTlsVersion" s-cli:"tls-min-tls-version" s-def:"1.3" s-desc:"Minimum TLS version to allow X.Y"`
 	AllowedCipherSuites      string `json:"allowedCipherSuites" s-cli:"tls-allowed-cipher-suites" s-def:"" s-desc:"Comma-separated list of cipher suites to allow"`
 }
+
+// Upstream configuration options for the connections to Split servers
+type Upstream struct {
+	ProxyURL        string   `json:"proxyUrl" s-cli:"upstream-proxy-url" s-def:"" s-desc:"HTTP/HTTPS proxy to go through when connecting to Split servers (defaults to the HTTP_PROXY/HTTPS_PROXY env vars, which are always used by the streaming connection)"`
+	ClientCertFN    string   `json:"clientCertFn" s-cli:"upstream-client-cert-fn" s-def:"" s-desc:"X509 client certificate to present to Split servers (or the egress proxy in front of them). Not used by the streaming connection"`
+	ClientKeyFN     string   `json:"clientKeyFn" s-cli:"upstream-client-key-fn" s-def:"" s-desc:"PEM private key of the upstream client certificate"`
+	RootCAsFN       string   `json:"rootCAsFn" s-cli:"upstream-root-cas-fn" s-def:"" s-desc:"PEM bundle of CAs used to verify upstream servers (defaults to the system ones). Not used by the streaming connection"`
+	UserAgentSuffix string   `json:"userAgentSuffix" s-cli:"upstream-user-agent-suffix" s-def:"" s-desc:"Appended to the User-Agent sent to Split servers, to tell apart instances in a fleet"`
+	SdkURL          string   `json:"sdkUrl" s-cli:"upstream-sdk-url" s-def:"" s-desc:"Base url of the SDK API (defaults to the <MODE>_SDK_URL env var or Split's cloud)"`
+	EventsURL       string   `json:"eventsUrl" s-cli:"upstream-events-url" s-def:"" s-desc:"Base url of the events API (defaults to the <MODE>_EVENTS_URL env var or Split's cloud)"`
+	AuthURL         string   `json:"authUrl" s-cli:"upstream-auth-url" s-def:"" s-desc:"Base url of the auth API (defaults to the <MODE>_AUTH_SERVICE_URL env var or Split's cloud)"`
+	StreamingURL    string   `json:"streamingUrl" s-cli:"upstream-streaming-url" s-def:"" s-desc:"Url of the streaming service (defaults to the <MODE>_STREAMING_SERVICE_URL env var or Split's cloud)"`
+	TelemetryURL    string   `json:"telemetryUrl" s-cli:"upstream-telemetry-url" s-def:"" s-desc:"Base url of the telemetry API (defaults to the <MODE>_TELEMETRY_SERVICE_URL env var or Split's cloud)"`
+	Failover        Failover `json:"failover" s-nested:"true"`
+}
+
+// Tracing configuration options for emitting OpenTelemetry spans
+type Tracing struct {
+	Enabled       bool   `json:"enabled" s-cli:"tracing-enabled" s-def:"false" s-desc:"Emit OpenTelemetry spans for incoming SDK requests & calls made to Split servers"`
+	Exporter      string `json:"exporter" s-cli:"tracing-exporter" s-def:"otlp" s-desc:"Where to send spans (otlp|stdout)"`
+	OTLPEndpoint  string `json:"otlpEndpoint" s-cli:"tracing-otlp-endpoint" s-def:"localhost:4318" s-desc:"host:port of the OTLP/HTTP collector"`
+	OTLPInsecure  bool   `json:"otlpInsecure" s-cli:"tracing-otlp-insecure" s-def:"false" s-desc:"Send spans to the collector over plain HTTP"`
+	SamplePercent int64  `json:"samplePercent" s-cli:"tracing-sample-percent" s-def:"100" s-desc:"Percentage of the traces started by this instance to sample (0-100). The decision made by callers is honored for propagated traces"`
+	ServiceName   string `json:"serviceName" s-cli:"tracing-service-name" s-def:"" s-desc:"Service name reported along with the spans (defaults to split-proxy or split-synchronizer, depending on the mode)"`
+}
+
+// Failover configuration options for switching to a secondary set of upstream urls when the primary SDK API is unhealthy
+type Failover struct {
+	SecondarySdkURL    string `json:"secondarySdkUrl" s-cli:"upstream-secondary-sdk-url" s-def:"" s-desc:"Base url of the SDK API to fail over to when the primary one is unhealthy (empty = failover disabled)"`
+	SecondaryEventsURL string `json:"secondaryEventsUrl" s-cli:"upstream-secondary-events-url" s-def:"" s-desc:"Base url of the events API to fail over to (empty = keep posting to the primary one)"`
+	ProbePeriodMs      int64  `json:"probePeriodMs" s-cli:"upstream-failover-probe-period-ms" s-def:"10000" s-desc:"How often to health-check the primary SDK API"`
+	Threshold          int    `json:"threshold" s-cli:"upstream-failover-threshold" s-def:"3" s-desc:"Consecutive failed (or successful) health checks needed to fail over (or back)"`
+}
@@ -1,7 +1,11 @@
 package conf
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
 	"strings"
+	"unicode"
 
 	"github.com/splitio/go-split-commons/v6/flagsets"
 )
@@ -26,3 +30,48 @@ func ValidateFlagsets(sets []string) ([]string, error) {
 	}
 	return sanitizedFlagSets, toRet
 }
+
+const maxEnvironmentLabelLength = 64
+
+// ValidateEnvironmentLabel makes sure the label can be safely sent as an http header value
+func ValidateEnvironmentLabel(label string) error {
+	if len(label) > maxEnvironmentLabelLength {
+		return fmt.Errorf("environment label cannot exceed %d characters", maxEnvironmentLabelLength)
+	}
+
+	for _, r := range label {
+		if unicode.IsControl(r) {
+			return errors.New("environment label cannot contain control characters")
+		}
+	}
+	return nil
+}
+
+// ValidateUpstreamURLs makes sure every custom upstream url is an absolute http(s) url
+func ValidateUpstreamURLs(upstream *Upstream) error {
+	urls := map[string]string{
+		"sdk":              upstream.SdkURL,
+		"events":           upstream.EventsURL,
+		"auth":             upstream.AuthURL,
+		"streaming":        upstream.StreamingURL,
+		"telemetry":        upstream.TelemetryURL,
+		"secondary sdk":    upstream.Failover.SecondarySdkURL,
+		"secondary events": upstream.Failover.SecondaryEventsURL,
+	}
+
+	if upstream.Failover.SecondarySdkURL == "" && upstream.Failover.SecondaryEventsURL != "" {
+		return errors.New("a secondary upstream events url requires a secondary sdk url")
+	}
+
+	for name, raw := range urls {
+		if raw == "" {
+			continue
+		}
+
+		parsed, err := url.Parse(raw)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("invalid upstream %s url '%s': must be an absolute http(s) url", name, raw)
+		}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package conf
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/splitio/go-split-commons/v6/dtos"
@@ -22,3 +23,22 @@ func TestFlagSetValidationError(t *testing.T) {
 			"start with a letter or number, be in lowercase, alphanumeric and have a max length of 50 characters. 123#@flagset was discarded."},
 	}, asFVE.wrapped)
 }
+
+func TestValidateEnvironmentLabel(t *testing.T) {
+	assert.Nil(t, ValidateEnvironmentLabel(""))
+	assert.Nil(t, ValidateEnvironmentLabel("staging-us-east"))
+	assert.NotNil(t, ValidateEnvironmentLabel("staging\r\nX-Injected: 1"))
+	assert.NotNil(t, ValidateEnvironmentLabel("prod\x00"))
+	assert.NotNil(t, ValidateEnvironmentLabel(strings.Repeat("a", 65)))
+}
+
+func TestValidateUpstreamURLs(t *testing.T) {
+	assert.Nil(t, ValidateUpstreamURLs(&Upstream{}))
+	assert.Nil(t, ValidateUpstreamURLs(&Upstream{SdkURL: "https://split.onprem.local/api", StreamingURL: "http://10.0.0.1:8080/sse"}))
+	assert.NotNil(t, ValidateUpstreamURLs(&Upstream{EventsURL: "split.onprem.local/api"}))
+	assert.NotNil(t, ValidateUpstreamURLs(&Upstream{AuthURL: "ftp://split.onprem.local"}))
+	assert.NotNil(t, ValidateUpstreamURLs(&Upstream{TelemetryURL: "https://"}))
+	assert.Nil(t, ValidateUpstreamURLs(&Upstream{Failover: Failover{SecondarySdkURL: "https://sdk.backup.local/api"}}))
+	assert.NotNil(t, ValidateUpstreamURLs(&Upstream{Failover: Failover{SecondarySdkURL: "sdk.backup.local"}}))
+	assert.NotNil(t, ValidateUpstreamURLs(&Upstream{Failover: Failover{SecondaryEventsURL: "https://events.backup.local/api"}}))
+}
@@ -4,7 +4,20 @@ import (
 	"fmt"
 )
 
-// Exit codes
+// Exit codes. Values are part of the public contract (orchestrators may react differently to each of them),
+// so new codes must be appended at the end:
+//
+//	0  ExitSuccess                   clean shutdown
+//	1  ExitInvalidApikey             malformed SDK key, or rejected by Split servers (401/403)
+//	2  ExitInvalidConfiguration      invalid config file/env vars/cli args
+//	3  ExitRedisInitializationFailed cannot connect to/prepare redis (synchronizer only)
+//	4  ExitErrorDB                   cannot open/prepare the persistent storage (proxy only)
+//	5  ExitTaskInitialization        a background task or integration failed to start
+//	6  ExitAdminError                admin server misconfigured
+//	7  ExitTLSError                  invalid TLS/upstream transport setup
+//	8  ExitUndefined                 unexpected error
+//	9  ExitUpstreamUnreachable       initial synchronization failed & no snapshot to fall back to
+//	10 ExitPortBindFailed            the admin or proxy port could not be bound (ie: already in use)
 const (
 	ExitSuccess = iota
 	ExitInvalidApikey
@@ -15,6 +28,8 @@ const (
 	ExitAdminError
 	ExitTLSError
 	ExitUndefined
+	ExitUpstreamUnreachable
+	ExitPortBindFailed
 )
 
 // InitializationError wraps an error and an exit code
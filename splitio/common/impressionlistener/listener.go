@@ -6,6 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/stats"
 
 	"github.com/splitio/go-split-commons/v6/dtos"
 
@@ -24,6 +27,13 @@ var ErrAlreadyRunning = errors.New("listener is already running")
 // ErrNotRunning is returned when attempting to stop a non-running listener
 var ErrNotRunning = errors.New("listener is not running")
 
+// bulks failing to be posted are retried this many times, doubling the wait between attempts
+const (
+	maxPostRetries   = 3
+	defaultRetryWait = time.Second
+	defaultTimeout   = 30 * time.Second
+)
+
 // ImpressionBulkListener speciefies the interface of a secondary impression listener
 type ImpressionBulkListener interface {
 	Submit(imps []ImpressionsForListener, metadata *dtos.Metadata) error
@@ -45,12 +55,14 @@ type ImpressionBulkListenerImpl struct {
 	endpoint   string
 	httpClient *http.Client
 	queue      chan impressionListenerPostBody
+	retryWait  time.Duration
+	counter    stats.SaveCounter
 }
 
 // NewImpressionBulkListener constructs a new impression listner
 func NewImpressionBulkListener(endpoint string, queueSize int, httpClient *http.Client) (*ImpressionBulkListenerImpl, error) {
 	if httpClient == nil {
-		httpClient = &http.Client{}
+		httpClient = &http.Client{Timeout: defaultTimeout}
 	}
 
 	if queueSize < 1 {
@@ -61,6 +73,7 @@ func NewImpressionBulkListener(endpoint string, queueSize int, httpClient *http.
 		endpoint:   endpoint,
 		httpClient: httpClient,
 		queue:      make(chan impressionListenerPostBody, queueSize),
+		retryWait:  defaultRetryWait,
 	}
 	listener.lifecycle.Setup()
 	return listener, nil
@@ -78,10 +91,17 @@ func (l *ImpressionBulkListenerImpl) Submit(imps []ImpressionsForListener, metad
 	}:
 		return nil
 	default:
+		l.counter.Drop()
 		return ErrQueueFull
 	}
 }
 
+// Stats returns the number of bulks delivered, retried & dropped since the listener was created.
+// Bulks discarded because the queue was full are counted as dropped
+func (l *ImpressionBulkListenerImpl) Stats() stats.SaveStats {
+	return l.counter.Stats()
+}
+
 // Start the bg task that will take bulks from the queue and post them
 func (l *ImpressionBulkListenerImpl) Start() error {
 	if !l.lifecycle.BeginInitialization() {
@@ -99,7 +119,7 @@ func (l *ImpressionBulkListenerImpl) Start() error {
 			case <-l.lifecycle.ShutdownRequested():
 				return
 			case imps := <-l.queue:
-				l.post(imps)
+				l.postWithRetries(imps)
 			}
 		}
 	}()
@@ -120,18 +140,47 @@ func (l *ImpressionBulkListenerImpl) Stop(blocking bool) error {
 	return nil
 }
 
-func (l *ImpressionBulkListenerImpl) post(imps impressionListenerPostBody) error {
+// postWithRetries keeps posting the bulk until it succeeds, the retries are exhausted or a shutdown is requested.
+// Retries happen in the posting goroutine, so ingest is only affected once the queue fills up
+func (l *ImpressionBulkListenerImpl) postWithRetries(imps impressionListenerPostBody) error {
 	data, err := json.Marshal(imps)
 	if err != nil {
+		l.counter.Drop()
 		return fmt.Errorf("error serializing impressions: %w", err)
 	}
 
+	wait := l.retryWait
+	for attempt := 0; ; attempt++ {
+		if err = l.post(data, &imps); err == nil || attempt == maxPostRetries {
+			l.counter.Save(attempt+1, err)
+			return err
+		}
+
+		select {
+		case <-l.lifecycle.ShutdownRequested():
+			l.counter.Save(attempt+1, err)
+			return err
+		case <-time.After(wait):
+			wait *= 2
+		}
+	}
+}
+
+func (l *ImpressionBulkListenerImpl) post(data []byte, imps *impressionListenerPostBody) error {
 	request, _ := http.NewRequest("POST", l.endpoint, bytes.NewBuffer(data))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("SplitSDKVersion", imps.SdkVersion)
+	request.Header.Set("SplitSDKMachineIP", imps.MachineIP)
+	request.Header.Set("SplitSDKMachineName", imps.MachineName)
 	response, err := l.httpClient.Do(request)
 	if err != nil {
 		return err
 	}
 	response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("listener responded with status code %d", response.StatusCode)
+	}
 	return nil
 }
 
@@ -5,7 +5,11 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/stats"
 
 	"github.com/splitio/go-split-commons/v6/dtos"
 )
@@ -116,3 +120,50 @@ func TestImpressionListener(t *testing.T) {
 
 	<-reqsDone
 }
+
+func TestImpressionListenerRetries(t *testing.T) {
+	var attempts int32
+	reqsDone := make(chan struct{}, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() { reqsDone <- struct{}{} }()
+		if r.Header.Get("SplitSDKVersion") != "go-1.1.1" || r.Header.Get("SplitSDKMachineIP") != "1.2.3.4" || r.Header.Get("SplitSDKMachineName") != "ip-1-2-3-4" {
+			t.Error("invalid metadata headers")
+		}
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	listener, err := NewImpressionBulkListener(ts.URL, 10, nil)
+	if err != nil {
+		t.Error("error cannot be nil: ", err)
+	}
+	listener.retryWait = 10 * time.Millisecond
+
+	if err = listener.Start(); err != nil {
+		t.Error("start() should not fail. Got: ", err)
+	}
+	defer listener.Stop(true)
+
+	listener.Submit([]ImpressionsForListener{{TestName: "t1", KeyImpressions: []ImpressionForListener{{KeyName: "k1", Treatment: "on", Time: 1}}}},
+		&dtos.Metadata{SDKVersion: "go-1.1.1", MachineIP: "1.2.3.4", MachineName: "ip-1-2-3-4"})
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-reqsDone:
+		case <-time.After(time.Second):
+			t.Fatal("bulk should have been retried until accepted")
+		}
+	}
+
+	select {
+	case <-reqsDone:
+		t.Error("no more attempts should be made once the bulk is accepted")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if got := listener.Stats(); got != (stats.SaveStats{Retried: 1}) {
+		t.Error("unexpected listener stats: ", got)
+	}
+}
@@ -0,0 +1,46 @@
+package stats
+
+import (
+	"sync/atomic"
+)
+
+// SaveStats contains the number of bulks saved to a sink, grouped by outcome
+type SaveStats struct {
+	Flushed int64 `json:"flushed"` // posted successfully on the first attempt
+	Retried int64 `json:"retried"` // posted successfully after one or more failed attempts
+	Dropped int64 `json:"dropped"` // discarded without being posted
+}
+
+// SaveCounter keeps track of the outcome of bulks posted to a sink. It's safe for concurrent use
+// and the zero value is ready to be used
+type SaveCounter struct {
+	flushed int64
+	retried int64
+	dropped int64
+}
+
+// Save records the outcome of a bulk that took `attempts` posts, the last one failing with `err` if not nil
+func (c *SaveCounter) Save(attempts int, err error) {
+	switch {
+	case err != nil:
+		atomic.AddInt64(&c.dropped, 1)
+	case attempts > 1:
+		atomic.AddInt64(&c.retried, 1)
+	default:
+		atomic.AddInt64(&c.flushed, 1)
+	}
+}
+
+// Drop records a bulk discarded before attempting to post it
+func (c *SaveCounter) Drop() {
+	atomic.AddInt64(&c.dropped, 1)
+}
+
+// Stats returns the number of bulks flushed, retried & dropped since the counter was created
+func (c *SaveCounter) Stats() SaveStats {
+	return SaveStats{
+		Flushed: atomic.LoadInt64(&c.flushed),
+		Retried: atomic.LoadInt64(&c.retried),
+		Dropped: atomic.LoadInt64(&c.dropped),
+	}
+}
@@ -0,0 +1,19 @@
+package stats
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSaveCounter(t *testing.T) {
+	var counter SaveCounter
+	counter.Save(1, nil)
+	counter.Save(1, nil)
+	counter.Save(3, nil)
+	counter.Save(4, errors.New("some"))
+	counter.Drop()
+
+	if stats := counter.Stats(); stats != (SaveStats{Flushed: 2, Retried: 1, Dropped: 2}) {
+		t.Error("unexpected stats: ", stats)
+	}
+}
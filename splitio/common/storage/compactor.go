@@ -0,0 +1,6 @@
+package storage
+
+// Compactor interface to be implemented by storages that can reclaim unused space from their backing file
+type Compactor interface {
+	Compact() (sizeBefore int64, sizeAfter int64, err error)
+}
@@ -0,0 +1,48 @@
+package upstream
+
+import (
+	"net/http"
+
+	"github.com/splitio/go-split-commons/v6/conf"
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-split-commons/v6/service/api"
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+// NewSplitAPI builds the go-split-commons fetchers & recorders used to talk to Split servers, all of them going through
+// the supplied transport
+func NewSplitAPI(apikey string, cfg *conf.AdvancedConfig, transport http.RoundTripper, logger logging.LoggerInterface, metadata dtos.Metadata) (*api.SplitAPI, error) {
+	splitAPI := api.NewSplitAPI(apikey, *cfg, logger, metadata)
+	components := []interface{}{
+		splitAPI.AuthClient,
+		splitAPI.SplitFetcher,
+		splitAPI.SegmentFetcher,
+		splitAPI.ImpressionRecorder,
+		splitAPI.EventRecorder,
+		splitAPI.TelemetryRecorder,
+	}
+	for _, component := range components {
+		if err := UseTransport(component, transport); err != nil {
+			return nil, err
+		}
+	}
+	return splitAPI, nil
+}
+
+// NewImpressionRecorder builds a go-split-commons impressions recorder going through the supplied transport
+func NewImpressionRecorder(apikey string, cfg *conf.AdvancedConfig, transport http.RoundTripper, logger logging.LoggerInterface) (*api.HTTPImpressionRecorder, error) {
+	recorder := api.NewHTTPImpressionRecorder(apikey, *cfg, logger)
+	return recorder, UseTransport(recorder, transport)
+}
+
+// NewEventsRecorder builds a go-split-commons events recorder going through the supplied transport
+func NewEventsRecorder(apikey string, cfg *conf.AdvancedConfig, transport http.RoundTripper, logger logging.LoggerInterface) (*api.HTTPEventsRecorder, error) {
+	recorder := api.NewHTTPEventsRecorder(apikey, *cfg, logger)
+	return recorder, UseTransport(recorder, transport)
+}
+
+// NewTelemetryRecorder builds a go-split-commons telemetry recorder going through the supplied transport
+func NewTelemetryRecorder(apikey string, cfg *conf.AdvancedConfig, transport http.RoundTripper, logger logging.LoggerInterface) (*api.HTTPTelemetryRecorder, error) {
+	recorder := api.NewHTTPTelemetryRecorder(apikey, *cfg, logger)
+	return recorder, UseTransport(recorder, transport)
+}
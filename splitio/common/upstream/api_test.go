@@ -0,0 +1,148 @@
+package upstream
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/splitio/go-split-commons/v6/conf"
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-split-commons/v6/service"
+	"github.com/splitio/go-split-commons/v6/service/api"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestFetchersGoThroughTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer someApikey", r.Header.Get("Authorization"))
+		assert.Equal(t, "go-6.0.0", r.Header.Get("SplitSDKVersion"))
+		switch r.URL.Path {
+		case "/api/splitChanges":
+			assert.Equal(t, "set1,set2", r.URL.Query().Get("sets"))
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			json.NewEncoder(gz).Encode(dtos.SplitChangesDTO{Since: -1, Till: 5, Splits: []dtos.SplitDTO{{Name: "split1"}}})
+			gz.Close()
+		case "/api/segmentChanges/segment1":
+			json.NewEncoder(w).Encode(dtos.SegmentChangesDTO{Name: "segment1", Added: []string{"k1"}, Since: -1, Till: 3})
+		case "/auth/api/v2/auth":
+			w.Write([]byte(`{"pushEnabled": false}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var calls int
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	cfg := &conf.AdvancedConfig{
+		SdkURL:         server.URL + "/api",
+		AuthServiceURL: server.URL + "/auth",
+		HTTPTimeout:    1,
+		FlagSetsFilter: []string{"set1", "set2"},
+	}
+	splitAPI, err := NewSplitAPI("someApikey", cfg, transport, logging.NewLogger(nil), dtos.Metadata{SDKVersion: "go-6.0.0"})
+	assert.Nil(t, err)
+
+	splitChanges, err := splitAPI.SplitFetcher.Fetch(service.MakeFlagRequestParams().WithChangeNumber(-1))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), splitChanges.Till)
+	assert.Equal(t, "split1", splitChanges.Splits[0].Name)
+
+	segmentChanges, err := splitAPI.SegmentFetcher.Fetch("segment1", service.MakeSegmentRequestParams().WithChangeNumber(-1))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"k1"}, segmentChanges.Added)
+
+	token, err := splitAPI.AuthClient.Authenticate()
+	assert.Nil(t, err)
+	assert.False(t, token.PushEnabled)
+
+	_, err = splitAPI.SegmentFetcher.Fetch("nonexistant", service.MakeSegmentRequestParams().WithChangeNumber(-1))
+	var httpErr *dtos.HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusNotFound, httpErr.Code)
+
+	assert.Equal(t, 4, calls)
+}
+
+func TestRecordersGoThroughTransport(t *testing.T) {
+	var paths []string
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer someApikey", r.Header.Get("Authorization"))
+		assert.Equal(t, "python-1.2.3", r.Header.Get("SplitSDKVersion"))
+		body, _ := io.ReadAll(r.Body)
+		paths = append(paths, r.URL.Path)
+		bodies = append(bodies, string(body))
+		if r.URL.Path == "/events/events/bulk" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	var calls int
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	cfg := &conf.AdvancedConfig{EventsURL: server.URL + "/events", TelemetryServiceURL: server.URL + "/telemetry", HTTPTimeout: 1}
+	metadata := dtos.Metadata{SDKVersion: "python-1.2.3"}
+	logger := logging.NewLogger(nil)
+
+	impressions, err := NewImpressionRecorder("someApikey", cfg, transport, logger)
+	assert.Nil(t, err)
+	assert.Nil(t, impressions.Record([]dtos.ImpressionsDTO{{TestName: "split1"}}, metadata, map[string]string{"SplitSDKImpressionsMode": "OPTIMIZED"}))
+	assert.Nil(t, impressions.RecordImpressionsCount(dtos.ImpressionsCountDTO{}, metadata)) // nothing to post
+	assert.Nil(t, impressions.RecordRaw("/testImpressions/count", []byte(`{"pf":[]}`), metadata, nil))
+
+	telemetry, err := NewTelemetryRecorder("someApikey", cfg, transport, logger)
+	assert.Nil(t, err)
+	assert.Nil(t, telemetry.RecordUniqueKeys(dtos.Uniques{}, metadata)) // nothing to post
+	assert.Nil(t, telemetry.RecordStats(dtos.Stats{}, metadata))
+
+	events, err := NewEventsRecorder("someApikey", cfg, transport, logger)
+	assert.Nil(t, err)
+	err = events.Record([]dtos.EventDTO{{Key: "k1"}}, metadata)
+	var httpErr *dtos.HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusInternalServerError, httpErr.Code)
+
+	assert.Equal(t, []string{"/events/testImpressions/bulk", "/events/testImpressions/count", "/telemetry/metrics/usage", "/events/events/bulk"}, paths)
+	assert.Contains(t, bodies[0], `"f":"split1"`)
+	assert.Equal(t, 4, calls)
+}
+
+func TestUseTransport(t *testing.T) {
+	var calls int
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+	})
+
+	client := api.NewHTTPClient("", conf.AdvancedConfig{}, "http://localhost:1", logging.NewLogger(nil), dtos.Metadata{})
+	assert.Nil(t, UseTransport(client, transport))
+	body, err := client.Get("/health", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, 1, calls)
+
+	assert.Nil(t, UseTransport(client, nil), "a nil transport should leave the client as is")
+	assert.NotNil(t, UseTransport(&struct{ client int }{}, transport))
+	assert.NotNil(t, UseTransport((*api.HTTPClient)(nil), transport))
+	assert.NotNil(t, UseTransport("not a client", transport))
+}
@@ -0,0 +1,50 @@
+package upstream
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+var httpClientType = reflect.TypeOf((*http.Client)(nil))
+
+// UseTransport makes a go-split-commons http client, or a fetcher/recorder/auth client built around one, go through
+// the supplied transport instead of the default one.
+// go-split-commons v6 doesn't let callers supply a transport, nor exposes the http.Client it builds, so it's looked up
+// in its (unexported) `client` & `httpClient` fields. An error is returned if they can't be found, so that a
+// go-split-commons upgrade changing them fails at startup (and in tests) instead of silently bypassing the upstream config.
+// This should go away once go-split-commons accepts a transport.
+func UseTransport(component interface{}, transport http.RoundTripper) error {
+	if transport == nil {
+		return nil
+	}
+
+	client, err := commonsHTTPClient(reflect.ValueOf(component))
+	if err != nil {
+		return fmt.Errorf("cannot set the upstream transport on %T: %w", component, err)
+	}
+	client.Transport = transport
+	return nil
+}
+
+func commonsHTTPClient(value reflect.Value) (*http.Client, error) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil, fmt.Errorf("nil %s", value.Type())
+		}
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unexpected %s", value.Type())
+	}
+
+	if field := value.FieldByName("httpClient"); field.IsValid() && field.Type() == httpClientType && !field.IsNil() {
+		return (*http.Client)(field.UnsafePointer()), nil
+	}
+
+	if field := value.FieldByName("client"); field.IsValid() {
+		return commonsHTTPClient(field)
+	}
+	return nil, fmt.Errorf("no http client found in %s", value.Type())
+}
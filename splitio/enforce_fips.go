@@ -6,3 +6,7 @@ package splitio
 import (
 	_ "crypto/tls/fipsonly"
 )
+
+func init() {
+	fipsEnforced = true
+}
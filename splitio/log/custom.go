@@ -76,6 +76,7 @@ type HistoricLogger interface {
 	logging.LoggerInterface
 	Messages(level int) []string
 	TotalCount(level int) int64
+	Buffered(level int) bool
 }
 
 // NewHistoricLoggerWrapper constructs a new historic logger
@@ -145,4 +146,26 @@ func (l *HistoricLoggerWrapper) TotalCount(level int) int64 {
 	return l.buffers[bufferIndex].totalCount()
 }
 
+// Buffered returns true if messages of a specific level are being kept in the historic buffer
+func (l *HistoricLoggerWrapper) Buffered(level int) bool {
+	bufferIndex := level - logging.LevelError
+	return l.buffers[bufferIndex].enabled
+}
+
+// SetLevel updates the level of the wrapped logger, if it supports being changed at runtime
+func (l *HistoricLoggerWrapper) SetLevel(level int) {
+	if controller, ok := l.LoggerInterface.(LevelController); ok {
+		controller.SetLevel(level)
+	}
+}
+
+// Level returns the level of the wrapped logger, or LevelAll if it can't be changed at runtime
+func (l *HistoricLoggerWrapper) Level() int {
+	if controller, ok := l.LoggerInterface.(LevelController); ok {
+		return controller.Level()
+	}
+	return logging.LevelAll
+}
+
 var _ HistoricLogger = (*HistoricLoggerWrapper)(nil)
+var _ LevelController = (*HistoricLoggerWrapper)(nil)
@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/splitio/go-toolkit/v5/logging"
 	"github.com/splitio/split-synchronizer/v5/splitio/common/conf"
@@ -44,28 +45,13 @@ func BuildFromConfig(cfg *conf.Logging, prefix string, slackCfg *conf.Slack) *Hi
 	nonDebugWriter := mainWriter
 	_, err = url.ParseRequestURI(slackCfg.Webhook)
 	if err == nil && slackCfg.Channel != "" {
-		nonDebugWriter = io.MultiWriter(mainWriter, NewSlackWriter(slackCfg.Webhook, slackCfg.Channel))
-	}
-
-	var level int
-	switch strings.ToUpper(cfg.Level) {
-	case "VERBOSE":
-		level = logging.LevelVerbose
-	case "DEBUG":
-		level = logging.LevelDebug
-	case "INFO":
-		level = logging.LevelInfo
-	case "WARNING", "WARN":
-		level = logging.LevelError
-	case "ERROR":
-		level = logging.LevelWarning
-	case "NONE":
-		level = logging.LevelNone
+		nonDebugWriter = io.MultiWriter(mainWriter, NewSlackWriter(slackCfg.Webhook, slackCfg.Channel, time.Duration(slackCfg.BatchPeriodSecs)*time.Second))
 	}
 
 	// buffer error, warning & info. don't buffer debug and verbose
 	buffered := [5]bool{true, true, true, false, false}
-	return NewHistoricLoggerWrapper(logging.NewLogger(&logging.LoggerOptions{
+	// the underlying logger lets everything through, filtering is done by a wrapper whose level can be updated at runtime
+	return NewHistoricLoggerWrapper(NewLevelFilter(logging.NewLogger(&logging.LoggerOptions{
 		StandardLoggerFlags: log.Ldate | log.Ltime | log.Lshortfile,
 		Prefix:              prefix,
 		VerboseWriter:       mainWriter,
@@ -73,7 +59,7 @@ func BuildFromConfig(cfg *conf.Logging, prefix string, slackCfg *conf.Slack) *Hi
 		InfoWriter:          nonDebugWriter,
 		WarningWriter:       nonDebugWriter,
 		ErrorWriter:         nonDebugWriter,
-		LogLevel:            level,
-		ExtraFramesToSkip:   1,
-	}), buffered, 5)
+		LogLevel:            logging.LevelAll,
+		ExtraFramesToSkip:   2,
+	}), ParseLevel(cfg.Level)), buffered, 5)
 }
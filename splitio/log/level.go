@@ -0,0 +1,121 @@
+package log
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+// LevelController is implemented by loggers whose level can be changed at runtime
+type LevelController interface {
+	SetLevel(level int)
+	Level() int
+}
+
+// ParseLevel maps a config log level name to the toolkit's numeric level. Unknown names map to LevelError.
+// Prior to 5.9.0 `warning` & `error` were swapped, and unknown names discarded every message
+func ParseLevel(name string) int {
+	if level, ok := LevelFromName(name); ok {
+		return level
+	}
+	return logging.LevelError
+}
+
+// LevelFromName maps a log level name to the toolkit's numeric level. The second return value is false for unknown names
+func LevelFromName(name string) (int, bool) {
+	switch strings.ToUpper(name) {
+	case "VERBOSE":
+		return logging.LevelVerbose, true
+	case "DEBUG":
+		return logging.LevelDebug, true
+	case "INFO":
+		return logging.LevelInfo, true
+	case "WARNING", "WARN":
+		return logging.LevelWarning, true
+	case "ERROR":
+		return logging.LevelError, true
+	case "NONE":
+		return logging.LevelNone, true
+	}
+	return 0, false
+}
+
+// LevelName returns the config name of a numeric log level
+func LevelName(level int) string {
+	switch level {
+	case logging.LevelVerbose:
+		return "VERBOSE"
+	case logging.LevelDebug:
+		return "DEBUG"
+	case logging.LevelInfo:
+		return "INFO"
+	case logging.LevelWarning:
+		return "WARNING"
+	case logging.LevelError:
+		return "ERROR"
+	case logging.LevelNone:
+		return "NONE"
+	}
+	return "UNKNOWN"
+}
+
+// LevelFilter forwards messages to the delegate logger only if their level is enabled.
+// Unlike the toolkit's filtered wrapper, the level can be changed while the logger is in use
+type LevelFilter struct {
+	delegate logging.LoggerInterface
+	level    int32
+}
+
+// NewLevelFilter constructs a new level filter on top of the supplied logger
+func NewLevelFilter(delegate logging.LoggerInterface, level int) *LevelFilter {
+	return &LevelFilter{delegate: delegate, level: int32(level)}
+}
+
+// SetLevel updates the level used to filter messages
+func (l *LevelFilter) SetLevel(level int) {
+	atomic.StoreInt32(&l.level, int32(level))
+}
+
+// Level returns the level currently used to filter messages
+func (l *LevelFilter) Level() int {
+	return int(atomic.LoadInt32(&l.level))
+}
+
+// Error forwards error messages
+func (l *LevelFilter) Error(msg ...interface{}) {
+	if l.Level() >= logging.LevelError {
+		l.delegate.Error(msg...)
+	}
+}
+
+// Warning forwards warning messages
+func (l *LevelFilter) Warning(msg ...interface{}) {
+	if l.Level() >= logging.LevelWarning {
+		l.delegate.Warning(msg...)
+	}
+}
+
+// Info forwards info messages
+func (l *LevelFilter) Info(msg ...interface{}) {
+	if l.Level() >= logging.LevelInfo {
+		l.delegate.Info(msg...)
+	}
+}
+
+// Debug forwards debug messages
+func (l *LevelFilter) Debug(msg ...interface{}) {
+	if l.Level() >= logging.LevelDebug {
+		l.delegate.Debug(msg...)
+	}
+}
+
+// Verbose forwards verbose messages
+func (l *LevelFilter) Verbose(msg ...interface{}) {
+	if l.Level() >= logging.LevelVerbose {
+		l.delegate.Verbose(msg...)
+	}
+}
+
+var _ logging.LoggerInterface = (*LevelFilter)(nil)
+var _ LevelController = (*LevelFilter)(nil)
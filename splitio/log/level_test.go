@@ -0,0 +1,52 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/splitio/go-toolkit/v5/logging/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLevel(t *testing.T) {
+	assert.Equal(t, logging.LevelVerbose, ParseLevel("verbose"))
+	assert.Equal(t, logging.LevelDebug, ParseLevel("DEBUG"))
+	assert.Equal(t, logging.LevelInfo, ParseLevel("info"))
+	assert.Equal(t, logging.LevelWarning, ParseLevel("warning"))
+	assert.Equal(t, logging.LevelWarning, ParseLevel("warn"))
+	assert.Equal(t, logging.LevelError, ParseLevel("error"))
+	assert.Equal(t, logging.LevelNone, ParseLevel("none"))
+	assert.Equal(t, logging.LevelError, ParseLevel("something"))
+
+	_, ok := LevelFromName("something")
+	assert.False(t, ok)
+	for _, level := range []int{logging.LevelVerbose, logging.LevelDebug, logging.LevelInfo, logging.LevelWarning, logging.LevelError, logging.LevelNone} {
+		parsed, ok := LevelFromName(LevelName(level))
+		assert.True(t, ok)
+		assert.Equal(t, level, parsed)
+	}
+}
+
+func TestLevelFilterUpdate(t *testing.T) {
+	var infos, debugs int
+	delegate := &mocks.MockLogger{
+		InfoCall:  func(msg ...interface{}) { infos++ },
+		DebugCall: func(msg ...interface{}) { debugs++ },
+	}
+
+	logger := NewHistoricLoggerWrapper(NewLevelFilter(delegate, logging.LevelInfo), [5]bool{}, 5)
+	logger.Info("a")
+	logger.Debug("b")
+	assert.Equal(t, 1, infos)
+	assert.Equal(t, 0, debugs)
+
+	logger.SetLevel(logging.LevelDebug)
+	logger.Info("a")
+	logger.Debug("b")
+	assert.Equal(t, 2, infos)
+	assert.Equal(t, 1, debugs)
+
+	logger.SetLevel(logging.LevelError)
+	logger.Info("a")
+	assert.Equal(t, 2, infos)
+}
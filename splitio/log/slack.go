@@ -3,28 +3,48 @@ package log
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"regexp"
+	"strconv"
 	"time"
 )
 
+const (
+	slackDefaultFlushPeriod = 500 * time.Millisecond
+	slackMaxPendingMessages = 1000
+	slackMaxBatchLines      = 100
+	slackMinBackoff         = time.Second
+	slackMaxBackoff         = 5 * time.Minute
+)
+
+// used to tell apart messages that only differ in their timestamp when coalescing them
+var logTimestampRegex = regexp.MustCompile(`\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(\.\d+)? `)
+
 // SlackWriter writes messages to Slack user or channel. Implements io.Writer interface
 type SlackWriter struct {
-	webhookURL string
-	httpClient http.Client
-	channel    string
-	buffer     chan []byte
-	lastSent   time.Time
+	webhookURL  string
+	httpClient  http.Client
+	channel     string
+	buffer      chan []byte
+	lastSent    time.Time
+	batchPeriod time.Duration
+	backoff     time.Duration
 }
 
-// NewSlackWriter constructs a slack writer
-func NewSlackWriter(webhookURL string, channel string) *SlackWriter {
+// NewSlackWriter constructs a slack writer.
+// If batchPeriod is zero, every message is posted individually. Otherwise, messages are coalesced into a single post
+// every batchPeriod, in which repeated messages are only included once along with the number of duplicates suppressed.
+// In both cases, posting is paused when slack responds with a `429 Too Many Requests`
+func NewSlackWriter(webhookURL string, channel string, batchPeriod time.Duration) *SlackWriter {
 	toRet := &SlackWriter{
-		webhookURL: webhookURL,
-		channel:    channel,
-		buffer:     make(chan []byte, 200),
+		webhookURL:  webhookURL,
+		channel:     channel,
+		buffer:      make(chan []byte, 200),
+		batchPeriod: batchPeriod,
 	}
 
 	go toRet.poster()
@@ -45,21 +65,111 @@ func (w *SlackWriter) Write(p []byte) (n int, err error) {
 }
 
 func (w *SlackWriter) poster() {
-	timer := time.NewTimer(500 * time.Millisecond) // TODO(mredolatti): make this configurable in final release
+	timer := time.NewTimer(w.nextFlush())
 	localBuffer := make([][]byte, 0, 20)
 	for {
 		select {
 		// TODO(mredolatti): add an exit path that flushes all messages on shutdown
 		case message := <-w.buffer:
-			localBuffer = append(localBuffer, message)
-		case <-timer.C:
-			for _, message := range localBuffer {
-				w.postMessage(message, nil)
+			if len(localBuffer) < slackMaxPendingMessages { // can only grow this much if slack is rate-limiting us
+				localBuffer = append(localBuffer, message)
 			}
-			localBuffer = localBuffer[:0] // reset the slice without releasing/reallocating memory
-			timer.Reset(500 * time.Millisecond)
+		case <-timer.C:
+			localBuffer = w.flush(localBuffer)
+			timer.Reset(w.nextFlush())
+		}
+	}
+}
+
+// flush posts the pending messages & returns the ones that couldn't be sent due to rate limiting
+func (w *SlackWriter) flush(pending [][]byte) [][]byte {
+	if len(pending) == 0 {
+		return pending
+	}
+
+	if w.batchPeriod > 0 {
+		if w.handlePostResult(w.postMessage(coalesceMessages(pending), nil)) {
+			return pending
+		}
+		return pending[:0] // reset the slice without releasing/reallocating memory
+	}
+
+	for idx, message := range pending {
+		if w.handlePostResult(w.postMessage(message, nil)) {
+			return append(pending[:0], pending[idx:]...)
+		}
+	}
+	return pending[:0]
+}
+
+// handlePostResult updates the backoff according to the result of a post, and returns true if it was rate-limited
+func (w *SlackWriter) handlePostResult(err error) bool {
+	var rateLimited *slackRateLimitedError
+	if !errors.As(err, &rateLimited) {
+		w.backoff = 0
+		return false
+	}
+
+	switch {
+	case rateLimited.retryAfter > 0:
+		w.backoff = rateLimited.retryAfter
+	case w.backoff == 0:
+		w.backoff = slackMinBackoff
+	default:
+		w.backoff *= 2
+	}
+
+	if w.backoff > slackMaxBackoff {
+		w.backoff = slackMaxBackoff
+	}
+	return true
+}
+
+func (w *SlackWriter) nextFlush() time.Duration {
+	period := slackDefaultFlushPeriod
+	if w.batchPeriod > 0 {
+		period = w.batchPeriod
+	}
+
+	if w.backoff > period {
+		return w.backoff
+	}
+	return period
+}
+
+// coalesceMessages builds a single message with every distinct line, followed by the number of times it was repeated
+func coalesceMessages(messages [][]byte) []byte {
+	counts := make(map[string]int, len(messages))
+	order := make([][]byte, 0, len(messages))
+	for _, message := range messages {
+		key := logTimestampRegex.ReplaceAllString(string(message), "")
+		if counts[key] == 0 {
+			order = append(order, message)
+		}
+		counts[key]++
+	}
+
+	var buf bytes.Buffer
+	var suppressed int
+	for idx, message := range order {
+		count := counts[logTimestampRegex.ReplaceAllString(string(message), "")]
+		if idx >= slackMaxBatchLines {
+			suppressed += count
+			continue
+		}
+
+		buf.Write(bytes.TrimRight(message, "\n"))
+		if count > 1 {
+			fmt.Fprintf(&buf, " (repeated %d more times)", count-1)
+			suppressed += count - 1
 		}
+		buf.WriteByte('\n')
 	}
+
+	if suppressed > 0 {
+		fmt.Fprintf(&buf, "%d duplicate or excess messages suppressed\n", suppressed)
+	}
+	return buf.Bytes()
 }
 
 func (w *SlackWriter) postMessage(msg []byte, attachements []SlackMessageAttachment) (err error) {
@@ -87,6 +197,11 @@ func (w *SlackWriter) postMessage(msg []byte, attachements []SlackMessageAttachm
 		// If message has been written successfully (http 200 OK)
 		return nil
 	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return &slackRateLimitedError{retryAfter: time.Duration(retryAfter) * time.Second}
+	}
+
 	body, _ := ioutil.ReadAll(resp.Body)
 	return fmt.Errorf("Error posting log message to Slack %s, with message %s", resp.Status, body)
 }
@@ -96,6 +211,14 @@ func (w *SlackWriter) PostNow(msg []byte, attachements []SlackMessageAttachment)
 	return w.postMessage(msg, attachements)
 }
 
+type slackRateLimitedError struct {
+	retryAfter time.Duration
+}
+
+func (e *slackRateLimitedError) Error() string {
+	return fmt.Sprintf("slack rate-limited log messages, retry after %s", e.retryAfter)
+}
+
 type messagePayload struct {
 	Channel     string `json:"channel"`
 	Username    string `json:"username"`
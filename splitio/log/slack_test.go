@@ -0,0 +1,100 @@
+package log
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type slackServerMock struct {
+	mutex    sync.Mutex
+	received []string
+	times    []time.Time
+	statuses []int
+}
+
+func (s *slackServerMock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var payload messagePayload
+	json.NewDecoder(r.Body).Decode(&payload)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.received = append(s.received, payload.Text)
+	s.times = append(s.times, time.Now())
+	if len(s.statuses) > 0 {
+		status := s.statuses[0]
+		s.statuses = s.statuses[1:]
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+		}
+		w.WriteHeader(status)
+	}
+}
+
+func (s *slackServerMock) messages() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.received...)
+}
+
+func TestCoalesceMessages(t *testing.T) {
+	result := string(coalesceMessages([][]byte{
+		[]byte("Split-Sync - ERROR - 2024/01/01 10:00:00 file.go:10: something failed\n"),
+		[]byte("Split-Sync - ERROR - 2024/01/01 10:00:01 file.go:10: something failed\n"),
+		[]byte("Split-Sync - WARNING - 2024/01/01 10:00:01 file.go:20: something else\n"),
+		[]byte("Split-Sync - ERROR - 2024/01/01 10:00:02 file.go:10: something failed\n"),
+	}))
+
+	expected := "Split-Sync - ERROR - 2024/01/01 10:00:00 file.go:10: something failed (repeated 2 more times)\n" +
+		"Split-Sync - WARNING - 2024/01/01 10:00:01 file.go:20: something else\n" +
+		"2 duplicate or excess messages suppressed\n"
+	if result != expected {
+		t.Error("unexpected coalesced message: ", result)
+	}
+}
+
+func TestSlackWriterBatching(t *testing.T) {
+	mock := &slackServerMock{}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	writer := NewSlackWriter(server.URL, "someChannel", 200*time.Millisecond)
+	for i := 0; i < 10; i++ {
+		writer.Write([]byte("Split-Sync - ERROR - 2024/01/01 10:00:00 file.go:10: something failed\n"))
+	}
+
+	time.Sleep(500 * time.Millisecond)
+	received := mock.messages()
+	if len(received) != 1 {
+		t.Fatal("all messages should have been coalesced into one. got: ", received)
+	}
+
+	if !strings.Contains(received[0], "(repeated 9 more times)") {
+		t.Error("duplicate count not included in message: ", received[0])
+	}
+}
+
+func TestSlackWriterBacksOffWhenRateLimited(t *testing.T) {
+	mock := &slackServerMock{statuses: []int{http.StatusTooManyRequests}}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	writer := NewSlackWriter(server.URL, "someChannel", 0)
+	writer.Write([]byte("message 1\n"))
+	writer.Write([]byte("message 2\n"))
+
+	time.Sleep(2 * time.Second)
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+	if strings.Join(mock.received, "") != "message 1\nmessage 1\nmessage 2\n" {
+		t.Fatal("the rate-limited message should have been retried, followed by the rest. got: ", mock.received)
+	}
+
+	if elapsed := mock.times[1].Sub(mock.times[0]); elapsed < time.Second {
+		t.Error("the retry should have waited for the period indicated in the Retry-After header. waited: ", elapsed)
+	}
+}
@@ -7,22 +7,28 @@ import (
 
 // Main configuration options
 type Main struct {
-	Apikey           string            `json:"apikey" s-cli:"apikey" s-def:"" s-desc:"Split server side SDK key"`
-	IPAddressEnabled bool              `json:"ipAddressEnabled" s-cli:"ip-address-enabled" s-def:"true" s-desc:"Bundle host's ip address when sending data to Split"`
-	FlagSetsFilter   []string          `json:"flagSetsFilter" s-cli:"flag-sets-filter" s-def:"" s-desc:"Flag Sets Filter provided"`
-	Initialization   Initialization    `json:"initialization" s-nested:"true"`
-	Storage          Storage           `json:"storage" s-nested:"true"`
-	Sync             Sync              `json:"sync" s-nested:"true"`
-	Admin            conf.Admin        `json:"admin" s-nested:"true"`
-	Integrations     conf.Integrations `json:"integrations" s-nested:"true"`
-	Logging          conf.Logging      `json:"logging" s-nested:"true"`
-	Healthcheck      Healthcheck       `json:"healthcheck" s-nested:"true"`
-	FlagSpecVersion  string            `json:"flagSpecVersion" s-cli:"flag-spec-version" s-def:"1.1" s-desc:"Spec version for flags"`
+	Apikey              string            `json:"apikey" s-cli:"apikey" s-def:"" s-desc:"Split server side SDK key"`
+	ApikeyFile          string            `json:"apikeyFile" s-cli:"apikey-file" s-def:"" s-desc:"File to read the SDK key from (ie: a mounted secret). Takes precedence over apikey"`
+	IPAddressEnabled    bool              `json:"ipAddressEnabled" s-cli:"ip-address-enabled" s-def:"true" s-desc:"Bundle host's ip address when sending data to Split"`
+	EnvironmentLabel    string            `json:"environmentLabel" s-cli:"environment-label" s-def:"" s-desc:"Label sent along with every request posted to Split, used to tell deployments apart"`
+	FlagSetsFilter      []string          `json:"flagSetsFilter" s-cli:"flag-sets-filter" s-def:"" s-desc:"Flag Sets Filter provided"`
+	Initialization      Initialization    `json:"initialization" s-nested:"true"`
+	Storage             Storage           `json:"storage" s-nested:"true"`
+	Sync                Sync              `json:"sync" s-nested:"true"`
+	Admin               conf.Admin        `json:"admin" s-nested:"true"`
+	Integrations        conf.Integrations `json:"integrations" s-nested:"true"`
+	Upstream            conf.Upstream     `json:"upstream" s-nested:"true"`
+	Logging             conf.Logging      `json:"logging" s-nested:"true"`
+	Healthcheck         Healthcheck       `json:"healthcheck" s-nested:"true"`
+	Tracing             conf.Tracing      `json:"tracing" s-nested:"true"`
+	FlagSpecVersion     string            `json:"flagSpecVersion" s-cli:"flag-spec-version" s-def:"1.1" s-desc:"Spec version for flags"`
+	ConfigReloadEnabled bool              `json:"configReloadEnabled" s-cli:"config-reload-enabled" s-def:"false" s-desc:"Watch the config file & hot-apply changes to supported properties (currently the log level). The config is also reloaded on SIGHUP"`
+	ConfigReloadRateMs  int64             `json:"configReloadRateMs" s-cli:"config-reload-rate-ms" s-def:"10000" s-desc:"How often to check the config file for changes"`
 }
 
 // BuildAdvancedConfig generates a commons-compatible advancedconfig with default + overriden parameters
 func (m *Main) BuildAdvancedConfig() *cconf.AdvancedConfig {
-	tmp := conf.InitAdvancedOptions(false) // defaults + url overrides
+	tmp := conf.InitAdvancedOptions(false, &m.Upstream) // defaults + url overrides
 	tmp.HTTPTimeout = int(m.Sync.Advanced.HTTPTimeoutMs / 1000)
 	tmp.StreamingEnabled = m.Sync.Advanced.StreamingEnabled
 	tmp.SplitsRefreshRate = int(m.Sync.SplitRefreshRateMs / 1000)
@@ -30,6 +36,17 @@ func (m *Main) BuildAdvancedConfig() *cconf.AdvancedConfig {
 	return tmp
 }
 
+// Redacted returns a copy of the config with every secret masked, suitable for logging & admin endpoints
+func (m *Main) Redacted() Main {
+	redacted := *m
+	redacted.Apikey = conf.RedactSecret(m.Apikey)
+	redacted.Storage.Redis.Pass = conf.MaskSecret(m.Storage.Redis.Pass)
+	redacted.Admin = m.Admin.Redacted()
+	redacted.Integrations = m.Integrations.Redacted()
+	redacted.Upstream = m.Upstream.Redacted()
+	return redacted
+}
+
 // Initialization configuration options
 type Initialization struct {
 	TimeoutMs int64 `json:"timeoutMS" s-cli:"timeout-ms" s-def:"10000" s-desc:"How long to wait until the synchronizer is ready"`
@@ -68,7 +85,7 @@ type AdvancedSync struct {
 	EventsProcessConcurrency         int   `json:"eventsProcessConcurrency" s-cli:"events-process-concurrency" s-def:"0" s-desc:"#Threads for processing imps"`
 	EventsProcessBatchSize           int   `json:"eventsProcessBatchSize" s-cli:"events-process-batch-size" s-def:"0" s-desc:"Size of imp processing batchs"`
 	EventsPostConcurrency            int   `json:"eventsPostConcurrency" s-cli:"events-post-concurrency" s-def:"0" s-desc:"#concurrent imp post threads"`
-	EventsPostSize                   int   `json:"eventsPostSize" s-cli:"events-post-size" s-def:"0" s-desc:"Max #impressions to send per POST"`
+	EventsPostSize                   int   `json:"eventsPostSize" s-cli:"events-post-size" s-def:"0" s-desc:"Max #events to send per POST. Larger bulks are split (0 = 5000)"`
 	EventsAccumWaitMs                int64 `json:"eventsAccumWaitMs" s-cli:"events-accum-wait-ms" s-def:"0" s-desc:"Max ms to wait to close an events bulk"`
 	UniqueKeysFetchSize              int64 `json:"uniqueKeysFetchSize" s-cli:"unique-keys-fetch-size" s-def:"0" s-desc:"How many unique keys to pop from storage at once"`
 	UniqueKeysProcessConcurrency     int   `json:"uniqueKeysProcessConcurrency" s-cli:"unique-keys-process-concurrency" s-def:"0" s-desc:"#Threads for processing uniques"`
@@ -76,6 +93,9 @@ type AdvancedSync struct {
 	UniqueKeysPostConcurrency        int   `json:"uniqueKeysPostConcurrency" s-cli:"unique-keys-post-concurrency" s-def:"0" s-desc:"#concurrent uniques post threads"`
 	UniqueKeysAccumWaitMs            int64 `json:"uniqueKeysAccumWaitMs" s-cli:"unique-keys-accum-wait-ms" s-def:"0" s-desc:"Max ms to wait to close an uniques bulk"`
 	ImpressionsCountWorkerReadRateMs int64 `json:"impressionsCountWorkerReadRateMs" s-cli:"impressions-count-worker-read-rate-ms" s-def:"60000" s-desc:"how often read in redis impression count comming from sdks"`
+	PostAttempts                     int   `json:"postAttempts" s-cli:"post-attempts" s-def:"3" s-desc:"Max #attempts when posting impressions/events/uniques bulks"`
+	PostBackoffBaseMs                int64 `json:"postBackoffBaseMs" s-cli:"post-backoff-base-ms" s-def:"1000" s-desc:"Wait before the first retry of a failed post, doubled on each subsequent retry"`
+	ShutdownGracePeriodMs            int64 `json:"shutdownGracePeriodMs" s-cli:"shutdown-grace-period-ms" s-def:"10000" s-desc:"Max time to wait for impressions/events/unique keys already fetched from redis to be posted when shutting down (shared by all tasks)"`
 }
 
 // Redis configuration options
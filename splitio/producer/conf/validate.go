@@ -0,0 +1,11 @@
+package conf
+
+import (
+	"github.com/splitio/split-synchronizer/v5/splitio/common/conf"
+)
+
+// Validate checks the settings that cannot be enforced by the parser alone, so that an invalid config is
+// reported both by --check-config & on startup
+func (m *Main) Validate() error {
+	return conf.ValidateUpstreamURLs(&m.Upstream)
+}
@@ -0,0 +1,20 @@
+package conf
+
+import (
+	"testing"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/conf"
+)
+
+func TestValidate(t *testing.T) {
+	var cfg Main
+	conf.PopulateDefaults(&cfg)
+	if err := cfg.Validate(); err != nil {
+		t.Error("the default config should be valid. got: ", err)
+	}
+
+	cfg.Upstream.EventsURL = "ftp://events.split.io"
+	if cfg.Validate() == nil {
+		t.Error("an invalid upstream url should be rejected")
+	}
+}
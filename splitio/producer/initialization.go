@@ -3,13 +3,13 @@ package producer
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"time"
 
 	cconf "github.com/splitio/go-split-commons/v6/conf"
 	"github.com/splitio/go-split-commons/v6/dtos"
 	"github.com/splitio/go-split-commons/v6/flagsets"
 	"github.com/splitio/go-split-commons/v6/provisional/strategy"
-	"github.com/splitio/go-split-commons/v6/service/api"
 	"github.com/splitio/go-split-commons/v6/storage/filter"
 	"github.com/splitio/go-split-commons/v6/storage/inmemory"
 	"github.com/splitio/go-split-commons/v6/storage/redis"
@@ -20,12 +20,16 @@ import (
 	"github.com/splitio/go-split-commons/v6/tasks"
 	"github.com/splitio/go-split-commons/v6/telemetry"
 	"github.com/splitio/go-toolkit/v5/logging"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 
 	"github.com/splitio/split-synchronizer/v5/splitio/admin"
 	adminCommon "github.com/splitio/split-synchronizer/v5/splitio/admin/common"
+	"github.com/splitio/split-synchronizer/v5/splitio/admin/controllers"
 	"github.com/splitio/split-synchronizer/v5/splitio/common"
+	commonConf "github.com/splitio/split-synchronizer/v5/splitio/common/conf"
 	"github.com/splitio/split-synchronizer/v5/splitio/common/impressionlistener"
 	ssync "github.com/splitio/split-synchronizer/v5/splitio/common/sync"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/upstream"
 	"github.com/splitio/split-synchronizer/v5/splitio/producer/conf"
 	"github.com/splitio/split-synchronizer/v5/splitio/producer/evcalc"
 	"github.com/splitio/split-synchronizer/v5/splitio/producer/storage"
@@ -45,12 +49,50 @@ const (
 
 // Start initialize the producer mode
 func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
+	if err := cfg.Validate(); err != nil {
+		return common.NewInitError(err, common.ExitInvalidConfiguration)
+	}
+
+	upstreamTransport, err := util.NewUpstreamTransport(&cfg.Upstream)
+	if err != nil {
+		return common.NewInitError(fmt.Errorf("error setting up upstream http transport: %w", err), common.ExitTLSError)
+	}
+
 	// Getting initial config data
 	advanced := cfg.BuildAdvancedConfig()
 	advanced.AuthSpecVersion = cfg.FlagSpecVersion
 	advanced.FlagsSpecVersion = cfg.FlagSpecVersion
 	advanced.FlagSetsFilter = cfg.FlagSetsFilter
 	metadata := util.GetMetadata(false, cfg.IPAddressEnabled)
+	outboundHeaders := util.GetOutboundHeaders(cfg.EnvironmentLabel)
+	logger.Info(fmt.Sprintf("Config summary: sdk key %s, environment label %q (sent along with requests posted to Split)",
+		commonConf.RedactSecret(cfg.Apikey), cfg.EnvironmentLabel))
+
+	util.WarnUnscopedUpstreamSettings(&cfg.Upstream, advanced.StreamingEnabled, logger)
+
+	// Failover to secondary upstream urls. Set up before any fetcher/recorder so that all of them go through it
+	var upstreamFailover *util.UpstreamFailover
+	var failoverStatus controllers.FailoverStatusProvider // left nil when disabled, so that it's not reported
+	if cfg.Upstream.Failover.SecondarySdkURL != "" {
+		var err error
+		upstreamFailover, err = util.StartUpstreamFailover(&cfg.Upstream.Failover, advanced.SdkURL, advanced.EventsURL, upstreamTransport, logger)
+		if err != nil {
+			return common.NewInitError(fmt.Errorf("error setting up upstream failover: %w", err), common.ExitInvalidConfiguration)
+		}
+		failoverStatus = upstreamFailover
+	}
+
+	// OpenTelemetry tracing. Set up before any fetcher/recorder so that all calls to Split servers are traced
+	var tracerProvider *sdktrace.TracerProvider
+	if cfg.Tracing.Enabled {
+		provider, err := util.StartTracing(&cfg.Tracing, "split-synchronizer")
+		if err != nil {
+			return common.NewInitError(fmt.Errorf("error setting up tracing: %w", err), common.ExitInvalidConfiguration)
+		}
+		upstreamTransport.SetTracer(provider.Tracer(util.TracerName))
+		tracerProvider = provider
+		logger.Info(fmt.Sprintf("Tracing enabled, exporting spans through %s (sampling %d%% of new traces)", cfg.Tracing.Exporter, cfg.Tracing.SamplePercent))
+	}
 
 	clientKey, err := util.GetClientKey(cfg.Apikey)
 	if err != nil {
@@ -58,11 +100,17 @@ func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
 	}
 
 	// Setup fetchers & recorders
-	splitAPI := api.NewSplitAPI(cfg.Apikey, *advanced, logger, metadata)
+	splitAPI, err := upstream.NewSplitAPI(cfg.Apikey, advanced, upstreamTransport, logger, metadata)
+	if err != nil {
+		return common.NewInitError(fmt.Errorf("error setting up fetchers & recorders: %w", err), common.ExitTaskInitialization)
+	}
 
 	// Check if SDK key is valid
-	if !isValidApikey(splitAPI.SplitFetcher) {
-		return common.NewInitError(errors.New("invalid SDK key"), common.ExitInvalidApikey)
+	if err := util.VerifyApikey(splitAPI.SplitFetcher); err != nil {
+		if errors.Is(err, util.ErrApikeyRejected) {
+			return common.NewInitError(fmt.Errorf("invalid SDK key: %w", err), common.ExitInvalidApikey)
+		}
+		return common.NewInitError(fmt.Errorf("error reaching Split servers: %w", err), common.ExitUpstreamUnreachable)
 	}
 
 	// Redis Storages
@@ -113,7 +161,7 @@ func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
 	// Healcheck Monitor
 	splitsConfig, segmentsConfig, storageConfig := getAppCounterConfigs(storages.SplitStorage)
 	appMonitor := hcApplication.NewMonitorImp(splitsConfig, segmentsConfig, &storageConfig, logger)
-	servicesMonitor := hcServices.NewMonitorImp(getServicesCountersConfig(advanced), logger)
+	servicesMonitor := hcServices.NewMonitorImp(getServicesCountersConfig(advanced, upstreamTransport), logger)
 
 	impressionsCounter := strategy.NewImpressionsCounter()
 	impressionObserver, err := strategy.NewImpressionObserver(impressionObserverSize)
@@ -146,21 +194,25 @@ func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
 
 	impressionEvictionMonitor := evcalc.New(1)
 	var impListener impressionlistener.ImpressionBulkListener
-	if cfg.Integrations.ImpressionListener.Endpoint != "" {
-		var err error
-		impListener, err = impressionlistener.NewImpressionBulkListener(
-			cfg.Integrations.ImpressionListener.Endpoint,
-			int(cfg.Integrations.ImpressionListener.QueueSize),
-			nil)
+	sinkStats := make(map[string]controllers.SinkStatsProvider)
+	if ilcfg := cfg.Integrations.ImpressionListener; ilcfg.Endpoint != "" {
+		listener, err := impressionlistener.NewImpressionBulkListener(
+			ilcfg.Endpoint,
+			int(ilcfg.QueueSize),
+			&http.Client{Timeout: time.Duration(ilcfg.TimeoutMs) * time.Millisecond})
 		if err != nil {
 			return common.NewInitError(fmt.Errorf("error instantiating impression listener: %w", err), common.ExitTaskInitialization)
 		}
-		impListener.Start()
+		listener.Start()
+		impListener = listener
+		sinkStats["impressionListener"] = listener
 	}
 
 	impManager := buildImpressionManager(cfg.Sync.ImpressionsMode, impListener, syncTelemetryStorage, impressionObserver, impressionsCounter)
 
 	// Impression & events pipelined tasks @{
+	// a single deadline is shared by all tasks, so that posting in-flight data on shutdown takes at most the grace period
+	shutdownDeadline := util.NewShutdownDeadline(time.Duration(cfg.Sync.Advanced.ShutdownGracePeriodMs) * time.Millisecond)
 	impWorker, err := task.NewImpressionWorker(&task.ImpressionWorkerConfig{
 		Logger:              logger,
 		Storage:             storages.ImpressionStorage,
@@ -170,6 +222,7 @@ func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
 		ImpressionsListener: impListener,
 		FetchSize:           int(cfg.Sync.Advanced.ImpressionsFetchSize),
 		ImpressionManager:   impManager,
+		ExtraHeaders:        outboundHeaders,
 	})
 	if err != nil {
 		return common.NewInitError(fmt.Errorf("error instantiating impressions worker: %w", err), common.ExitTaskInitialization)
@@ -184,6 +237,10 @@ func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
 		PostConcurrency:    cfg.Sync.Advanced.ImpressionsPostConcurrency,
 		MaxAccumWait:       time.Duration(cfg.Sync.Advanced.ImpressionsAccumWaitMs) * time.Millisecond,
 		HTTPTimeout:        time.Millisecond * time.Duration(cfg.Sync.Advanced.HTTPTimeoutMs),
+		PostAttempts:       cfg.Sync.Advanced.PostAttempts,
+		PostBackoffBase:    time.Millisecond * time.Duration(cfg.Sync.Advanced.PostBackoffBaseMs),
+		Transport:          upstreamTransport,
+		ShutdownDeadline:   shutdownDeadline,
 	})
 	if err != nil {
 		return common.NewInitError(fmt.Errorf("error instantiating impressions pipelined task: %w", err), common.ExitTaskInitialization)
@@ -196,6 +253,8 @@ func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
 		EvictionMonitor: eventEvictionMonitor,
 		Apikey:          cfg.Apikey,
 		FetchSize:       int(cfg.Sync.Advanced.EventsFetchSize),
+		PostSize:        cfg.Sync.Advanced.EventsPostSize,
+		ExtraHeaders:    outboundHeaders,
 	})
 	if err != nil {
 		return common.NewInitError(fmt.Errorf("error instantiating events worker: %w", err), common.ExitTaskInitialization)
@@ -210,6 +269,10 @@ func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
 		PostConcurrency:    cfg.Sync.Advanced.ImpressionsPostConcurrency,
 		MaxAccumWait:       time.Duration(cfg.Sync.Advanced.EventsAccumWaitMs) * time.Millisecond,
 		HTTPTimeout:        time.Millisecond * time.Duration(cfg.Sync.Advanced.HTTPTimeoutMs),
+		PostAttempts:       cfg.Sync.Advanced.PostAttempts,
+		PostBackoffBase:    time.Millisecond * time.Duration(cfg.Sync.Advanced.PostBackoffBaseMs),
+		Transport:          upstreamTransport,
+		ShutdownDeadline:   shutdownDeadline,
 	})
 	if err != nil {
 		return common.NewInitError(fmt.Errorf("error instantiating events pipelined task: %w", err), common.ExitTaskInitialization)
@@ -225,6 +288,7 @@ func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
 		Apikey:            cfg.Apikey,
 		FetchSize:         int(cfg.Sync.Advanced.UniqueKeysFetchSize),
 		Metadata:          metadata,
+		ExtraHeaders:      outboundHeaders,
 	})
 
 	uniquesTask, err := task.NewPipelinedTask(&task.Config{
@@ -236,6 +300,10 @@ func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
 		PostConcurrency:    cfg.Sync.Advanced.UniqueKeysPostConcurrency,
 		MaxAccumWait:       time.Duration(cfg.Sync.Advanced.UniqueKeysAccumWaitMs) * time.Millisecond,
 		HTTPTimeout:        time.Millisecond * time.Duration(cfg.Sync.Advanced.HTTPTimeoutMs),
+		PostAttempts:       cfg.Sync.Advanced.PostAttempts,
+		PostBackoffBase:    time.Millisecond * time.Duration(cfg.Sync.Advanced.PostBackoffBaseMs),
+		Transport:          upstreamTransport,
+		ShutdownDeadline:   shutdownDeadline,
 	})
 	if err != nil {
 		return common.NewInitError(fmt.Errorf("error instantiating uniques pipelined task: %w", err), common.ExitTaskInitialization)
@@ -281,31 +349,42 @@ func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
 		return common.NewInitError(fmt.Errorf("error setting up proxy TLS config: %w", err), common.ExitTLSError)
 	}
 
-	cfgForAdmin := *cfg
-	cfgForAdmin.Apikey = logging.ObfuscateAPIKey(cfgForAdmin.Apikey)
-	cfgForAdmin.Storage.Redis.Pass = "xxxxxxxxxxxxxxx"
+	sinkStats["impressions"] = impTask
+	sinkStats["events"] = evTask
+	sinkStats["uniques"] = uniquesTask
+
+	cfgForAdmin := cfg.Redacted()
 	adminServer, err := admin.NewServer(&admin.Options{
-		Host:              cfg.Admin.Host,
-		Port:              int(cfg.Admin.Port),
-		Name:              "Split Synchronizer dashboard",
-		Proxy:             false,
-		Username:          cfg.Admin.Username,
-		Password:          cfg.Admin.Password,
-		Logger:            logger,
-		Storages:          storages,
-		ImpressionsEvCalc: impressionEvictionMonitor,
-		EventsEvCalc:      eventEvictionMonitor,
-		Runtime:           rtm,
-		HcAppMonitor:      appMonitor,
-		HcServicesMonitor: servicesMonitor,
-		FullConfig:        cfgForAdmin,
-		TLS:               adminTLSConfig,
-		FlagSpecVersion:   cfg.FlagSpecVersion,
+		Host:               cfg.Admin.Host,
+		Port:               int(cfg.Admin.Port),
+		Name:               "Split Synchronizer dashboard",
+		Proxy:              false,
+		Username:           cfg.Admin.Username,
+		Password:           cfg.Admin.Password,
+		Token:              cfg.Admin.Token,
+		SecureHealthcheck:  cfg.Admin.SecureHC,
+		Logger:             logger,
+		Storages:           storages,
+		ImpressionsEvCalc:  impressionEvictionMonitor,
+		EventsEvCalc:       eventEvictionMonitor,
+		Runtime:            rtm,
+		HcAppMonitor:       appMonitor,
+		HcServicesMonitor:  servicesMonitor,
+		SplitUpdater:       workers.SplitUpdater,
+		SegmentUpdater:     workers.SegmentUpdater,
+		FullConfig:         cfgForAdmin,
+		TLS:                adminTLSConfig,
+		FlagSpecVersion:    cfg.FlagSpecVersion,
+		ExposeSegmentUsage: cfg.Admin.ExposeSegmentUsage,
+		SinkStats:          sinkStats,
+		UpstreamFailover:   failoverStatus,
 	})
 	if err != nil {
 		panic(err.Error())
 	}
-	go adminServer.Start()
+	if err := adminServer.Start(); err != nil {
+		return common.NewInitError(fmt.Errorf("error binding admin port: %w", err), common.ExitPortBindFailed)
+	}
 
 	// Run Sync Manager
 	before := time.Now()
@@ -340,5 +419,13 @@ func Start(logger logging.LoggerInterface, cfg *conf.Main) error {
 
 	rtm.RegisterShutdownHandler()
 	rtm.Block()
+
+	if upstreamFailover != nil {
+		upstreamFailover.Stop()
+	}
+
+	if tracerProvider != nil {
+		util.StopTracing(tracerProvider, logger)
+	}
 	return nil
 }
@@ -53,8 +53,8 @@ func TestIsApikeyValidOk(t *testing.T) {
 		},
 	}
 
-	if !isValidApikey(httpSplitFetcher) {
-		t.Error("APIKEY should be valid.")
+	if err := util.VerifyApikey(httpSplitFetcher); err != nil {
+		t.Error("APIKEY should be valid.", err)
 	}
 }
 
@@ -73,8 +73,12 @@ func TestIsApikeyValidNotOk(t *testing.T) {
 		},
 	}
 
-	if isValidApikey(httpSplitFetcher) {
-		t.Error("APIKEY should be invalid.")
+	err := util.VerifyApikey(httpSplitFetcher)
+	if err == nil {
+		t.Error("APIKEY check should fail.")
+	}
+	if errors.Is(err, util.ErrApikeyRejected) {
+		t.Error("a non-auth failure should not be reported as a rejected key.")
 	}
 }
 
@@ -27,12 +27,17 @@ type EventWorkerConfig struct {
 	URL             string
 	Apikey          string
 	FetchSize       int
+	PostSize        int // max #events per bulk. Larger groups of events with the same metadata are split
+	ExtraHeaders    map[string]string
 }
 
 func (c *EventWorkerConfig) normalize() {
 	if c.FetchSize == 0 {
 		c.FetchSize = defaultImpFetchSize
 	}
+	if c.PostSize <= 0 {
+		c.PostSize = defaultBulkSize
+	}
 }
 
 // EventsPipelineWorker implements all the required  methods to work with a pipelined task
@@ -41,10 +46,12 @@ type EventsPipelineWorker struct {
 	storage         storage.EventMultiSdkConsumer
 	evictionMonitor evcalc.Monitor
 
-	url       string
-	apikey    string
-	fetchSize int64
-	pool      eventsMemoryPool
+	url          string
+	apikey       string
+	fetchSize    int64
+	postSize     int
+	pool         eventsMemoryPool
+	extraHeaders map[string]string
 }
 
 // NewEventsWorker builds a pipeline-suited events worker
@@ -57,7 +64,9 @@ func NewEventsWorker(cfg *EventWorkerConfig) (*EventsPipelineWorker, error) {
 		url:             cfg.URL + "/events/bulk",
 		apikey:          cfg.Apikey,
 		fetchSize:       int64(cfg.FetchSize),
+		postSize:        cfg.PostSize,
 		pool:            newEventWorkerMemoryPool(cfg.FetchSize, defaultMetasPerBulk, defaultEventsPerBulk),
+		extraHeaders:    cfg.ExtraHeaders,
 	}, nil
 }
 
@@ -77,7 +86,7 @@ func (i *EventsPipelineWorker) Fetch() ([]string, error) {
 
 // Process parses the raw data and packages the events
 func (i *EventsPipelineWorker) Process(raws [][]byte, sink chan<- interface{}) error {
-	batches := newEventBatches(i.pool)
+	batches := newEventBatches(i.pool, i.postSize)
 	// After processing of these events is done, we release temporary structures but NOT the final data
 	// which will be released after imrpessions have been successfully posted
 	defer batches.recycleContainer()
@@ -117,20 +126,25 @@ func (i *EventsPipelineWorker) BuildRequest(data interface{}) (*http.Request, er
 	req.Header.Add("SplitSDKVersion", ewm.metadata.SDKVersion)
 	req.Header.Add("SplitSDKMachineIp", ewm.metadata.MachineIP)
 	req.Header.Add("SplitSDKMachineName", ewm.metadata.MachineName)
+	for name, value := range i.extraHeaders {
+		req.Header.Add(name, value)
+	}
 	return req, nil
 }
 
 type eventBatches struct {
-	groups eventsWithMetaSlice
-	index  metadataMap
-	pool   eventsMemoryPool
+	groups   eventsWithMetaSlice
+	index    metadataMap
+	pool     eventsMemoryPool
+	postSize int
 }
 
-func newEventBatches(pool eventsMemoryPool) *eventBatches {
+func newEventBatches(pool eventsMemoryPool, postSize int) *eventBatches {
 	toRet := &eventBatches{
-		groups: pool.acquireEventsWithMeta(),
-		index:  pool.acquireMetadataMap(),
-		pool:   pool,
+		groups:   pool.acquireEventsWithMeta(),
+		index:    pool.acquireMetadataMap(),
+		pool:     pool,
+		postSize: postSize,
 	}
 	return toRet
 }
@@ -142,11 +156,11 @@ func (i *eventBatches) recycleContainer() {
 }
 
 // add an event to a bulk
-// after identifying the correct bulk (or creating one if necessary), the call is forwarded
-// to such structure. (see eventsWithMetaSlice.add)
+// after identifying the correct bulk (or creating one if necessary, when there's none for this metadata or it's full),
+// the call is forwarded to such structure. (see eventsWithMetaSlice.add)
 func (i *eventBatches) add(queueObj *dtos.QueueStoredEventDTO) {
 	idx, ok := i.index[queueObj.Metadata]
-	if !ok || i.groups[idx].count > defaultBulkSize {
+	if !ok || i.groups[idx].count >= i.postSize {
 		i.groups = append(i.groups, newEventsWithMetadata(i.pool, &queueObj.Metadata))
 		idx = len(i.groups) - 1
 		i.index[queueObj.Metadata] = idx
@@ -205,3 +205,68 @@ func TestEventsIntegration(t *testing.T) {
 		t.Error("machine2 should have 500 events. Has ", r)
 	}
 }
+
+func TestEventsRequestIncludesExtraHeaders(t *testing.T) {
+	w, err := NewEventsWorker(&EventWorkerConfig{
+		EvictionMonitor: evcalc.New(1),
+		Logger:          logging.NewLogger(nil),
+		Storage:         mocks.MockEventStorage{},
+		URL:             "http://test",
+		Apikey:          "someApikey",
+		FetchSize:       100,
+		ExtraHeaders:    map[string]string{"SplitSyncEnvironmentLabel": "staging"},
+	})
+	if err != nil {
+		t.Error("there should be no error. Got: ", err)
+	}
+
+	sinker := make(chan interface{}, 100)
+	w.Process(makeSerializedEvents(1, 10), sinker)
+	bulk := <-sinker
+	req, err := w.BuildRequest(bulk)
+	if err != nil {
+		t.Error("there should be no error. Got: ", err)
+	}
+
+	if l := req.Header.Get("SplitSyncEnvironmentLabel"); l != "staging" {
+		t.Error("environment label header should be set. Got: ", l)
+	}
+	bulk.(recyclable).recycle()
+}
+
+func TestEventsBulksAreChunked(t *testing.T) {
+	poolWrapper := newEventTrackingAllocator()
+	w, err := NewEventsWorker(&EventWorkerConfig{
+		EvictionMonitor: evcalc.New(1),
+		Logger:          logging.NewLogger(nil),
+		Storage:         mocks.MockEventStorage{},
+		URL:             "http://test",
+		Apikey:          "someApikey",
+		FetchSize:       100,
+		PostSize:        40,
+	})
+	w.pool = poolWrapper
+	if err != nil {
+		t.Error("there should be no error. Got: ", err)
+	}
+
+	sinker := make(chan interface{}, 100)
+	w.Process(makeSerializedEvents(2, 100), sinker)
+	if len(sinker) != 6 {
+		t.Error("there should be 3 bulks per metadata ready for submission. Got: ", len(sinker))
+	}
+
+	sizes := make(map[string][]int)
+	for len(sinker) > 0 {
+		bulk := (<-sinker).(eventsWithMetadata)
+		sizes[bulk.metadata.MachineName] = append(sizes[bulk.metadata.MachineName], len(bulk.events))
+		bulk.recycle()
+	}
+
+	for _, machine := range []string{"machine_0", "machine_1"} {
+		if s := sizes[machine]; len(s) != 3 || s[0] != 40 || s[1] != 40 || s[2] != 20 {
+			t.Errorf("unexpected bulk sizes for %s: %v", machine, s)
+		}
+	}
+	poolWrapper.validate(t)
+}
@@ -34,6 +34,7 @@ type ImpressionWorkerConfig struct {
 	Apikey              string
 	FetchSize           int
 	ImpressionManager   provisional.ImpressionManager
+	ExtraHeaders        map[string]string
 }
 
 func (c *ImpressionWorkerConfig) normalize() {
@@ -50,10 +51,11 @@ type ImpressionsPipelineWorker struct {
 	impListener     impressionlistener.ImpressionBulkListener
 	evictionMonitor evcalc.Monitor
 
-	url       string
-	apikey    string
-	fetchSize int64
-	pool      impressionsMemoryPool
+	url          string
+	apikey       string
+	fetchSize    int64
+	pool         impressionsMemoryPool
+	extraHeaders map[string]string
 }
 
 // NewImpressionWorker builds a pipeline-suited impressions worker
@@ -70,6 +72,7 @@ func NewImpressionWorker(cfg *ImpressionWorkerConfig) (*ImpressionsPipelineWorke
 		fetchSize:       int64(cfg.FetchSize),
 		evictionMonitor: cfg.EvictionMonitor,
 		pool:            newImpWorkerMemoryPool(cfg.FetchSize, defaultMetasPerBulk, defaultFeatureCount, defaultImpsPerFeature),
+		extraHeaders:    cfg.ExtraHeaders,
 	}, nil
 }
 
@@ -145,6 +148,9 @@ func (i *ImpressionsPipelineWorker) BuildRequest(data interface{}) (*http.Reques
 	req.Header.Add("SplitSDKMachineIp", iwm.metadata.MachineIP)
 	req.Header.Add("SplitSDKMachineName", iwm.metadata.MachineName)
 	req.Header.Add("SplitSDKImpressionsMode", "optimized") // TODO(mredolatti): populate this correctly
+	for name, value := range i.extraHeaders {
+		req.Header.Add(name, value)
+	}
 	return req, nil
 }
 
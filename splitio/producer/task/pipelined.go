@@ -12,6 +12,9 @@ import (
 
 	"github.com/splitio/go-toolkit/v5/common"
 	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/stats"
+	"github.com/splitio/split-synchronizer/v5/splitio/util"
 )
 
 const (
@@ -20,6 +23,8 @@ const (
 	defaultMaxConcurrency   = 2000
 	defaultMaxAccumSecs     = 5
 	defaultHTTPTimeoutSecs  = 3
+	defaultPostAttempts     = 3
+	maxPostBackoff          = 30 * time.Second
 )
 
 // Config contains the set of options/parameters to setup the eviction component
@@ -33,6 +38,10 @@ type Config struct {
 	PostConcurrency    int
 	MaxAccumWait       time.Duration
 	HTTPTimeout        time.Duration
+	PostAttempts       int
+	PostBackoffBase    time.Duration
+	Transport          *util.UpstreamTransport // used to post to Split servers. The default http transport is used if nil
+	ShutdownDeadline   *util.ShutdownDeadline  // bounds the time spent posting in-flight data when stopping. No bound if nil
 }
 
 // Worker defines the methods that should be implemented by pipeline-suited data-flows
@@ -66,6 +75,14 @@ func (c *Config) normalize() {
 	if c.MaxAccumWait == 0 {
 		c.MaxAccumWait = defaultMaxAccumSecs * time.Second
 	}
+
+	if c.PostAttempts <= 0 {
+		c.PostAttempts = defaultPostAttempts
+	}
+
+	if c.PostBackoffBase < 0 {
+		c.PostBackoffBase = 0
+	}
 }
 
 // PipelinedSyncTask implements a fetch-process-evict buffered flow
@@ -84,6 +101,9 @@ type PipelinedSyncTask struct {
 	processConcurrency int
 	processBatchSize   int
 	maxAccumWait       time.Duration
+	postAttempts       int
+	postBackoffBase    time.Duration
+	shutdownDeadline   *util.ShutdownDeadline
 
 	// synchronization elements
 	inputBuffer     chan []string
@@ -91,25 +111,40 @@ type PipelinedSyncTask struct {
 	waiter          sync.WaitGroup
 	running         *tsync.AtomicBool
 	shutdown        chan struct{}
+	counter         stats.SaveCounter
 }
 
 // NewPipelinedTask constructs a pipelined task
 func NewPipelinedTask(config *Config) (*PipelinedSyncTask, error) {
-	t := http.DefaultTransport.(*http.Transport).Clone()
 	config.normalize()
-	t.MaxConnsPerHost = config.PostConcurrency
-	t.MaxIdleConns = config.PostConcurrency
-	t.MaxIdleConnsPerHost = config.PostConcurrency
+	tune := func(t *http.Transport) {
+		t.MaxConnsPerHost = config.PostConcurrency
+		t.MaxIdleConns = config.PostConcurrency
+		t.MaxIdleConnsPerHost = config.PostConcurrency
+	}
+
+	var transport http.RoundTripper
+	if config.Transport != nil {
+		transport = config.Transport.Tuned(tune)
+	} else {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		tune(t)
+		transport = t
+	}
+
 	return &PipelinedSyncTask{
 		name:               config.Name,
 		logger:             config.Logger,
 		worker:             config.Worker,
-		httpClient:         http.Client{Transport: t, Timeout: config.HTTPTimeout},
+		httpClient:         http.Client{Transport: transport, Timeout: config.HTTPTimeout},
 		pool:               newTaskMemoryPool(config.ProcessBatchSize),
 		processBatchSize:   config.ProcessBatchSize,
 		postConcurrency:    config.PostConcurrency,
 		processConcurrency: config.ProcessConcurrency,
 		maxAccumWait:       config.MaxAccumWait,
+		postAttempts:       config.PostAttempts,
+		postBackoffBase:    config.PostBackoffBase,
+		shutdownDeadline:   config.ShutdownDeadline,
 		running:            tsync.NewAtomicBool(true),
 		inputBuffer:        make(chan []string, config.InputBufferSize),
 		preSubmitBuffer:    make(chan interface{}, config.PostConcurrency*4),
@@ -117,6 +152,11 @@ func NewPipelinedTask(config *Config) (*PipelinedSyncTask, error) {
 	}, nil
 }
 
+// Stats returns the number of bulks flushed, retried & dropped since the task was created
+func (p *PipelinedSyncTask) Stats() stats.SaveStats {
+	return p.counter.Stats()
+}
+
 // Start begins execution
 func (p *PipelinedSyncTask) Start() {
 	p.waiter.Add(p.postConcurrency + p.processConcurrency + 1)
@@ -141,14 +181,29 @@ func (p *PipelinedSyncTask) Start() {
 	go p.filler()
 }
 
-// Stop the task and drain the pipe
+// Stop the task and drain the pipe. When blocking, data already fetched is processed & posted before returning,
+// waiting at most until the shutdown deadline (if any) is reached
 func (p *PipelinedSyncTask) Stop(blocking bool) error {
 	if !p.running.TestAndClear() {
 		return errTaskRunning
 	}
 	p.shutdown <- struct{}{}
-	if blocking {
+	if !blocking {
+		return nil
+	}
+
+	if p.shutdownDeadline == nil {
+		p.waiter.Wait()
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
 		p.waiter.Wait()
+		close(done)
+	}()
+	if !p.shutdownDeadline.Wait(done) {
+		p.logger.Warning(fmt.Sprintf("[pipelined/%s] grace period expired before posting all fetched data. It will be lost", p.name))
 	}
 	return nil
 }
@@ -255,7 +310,12 @@ func (p *PipelinedSyncTask) sinker() {
 				defer asRecyblable.recycle()
 			}
 
-			err := common.WithAttempts(3, func() error {
+			attempts := 0
+			err := common.WithAttempts(p.postAttempts, func() error {
+				if attempts > 0 {
+					time.Sleep(p.backoff(attempts))
+				}
+				attempts++
 				p.logger.Debug(fmt.Sprintf("[pipelined/%s] - impressions post ready. making request", p.name))
 				req, err := p.worker.BuildRequest(bulk)
 				if err != nil {
@@ -267,16 +327,18 @@ func (p *PipelinedSyncTask) sinker() {
 					return fmt.Errorf(fmt.Sprintf("[pipelined/%s] error posting: %s", p.name, err))
 				}
 
+				if resp.Body != nil {
+					resp.Body.Close()
+				}
+
 				if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 					return fmt.Errorf(fmt.Sprintf("[pipelined/%s] bad status code when sinking data: %d", p.name, resp.StatusCode))
 				}
 
-				if resp.Body != nil {
-					resp.Body.Close()
-				}
 				p.logger.Debug(fmt.Sprintf("[pipelined/%s] - impressions posted successfully", p.name))
 				return nil
 			})
+			p.counter.Save(attempts, err)
 			if err != nil {
 				p.logger.Error(err)
 			}
@@ -284,6 +346,19 @@ func (p *PipelinedSyncTask) sinker() {
 	}
 }
 
+// backoff returns the time to wait before retrying a post that has already failed `failures` times.
+// The wait doubles on each failure, starting at the configured base & capped at `maxPostBackoff`
+func (p *PipelinedSyncTask) backoff(failures int) time.Duration {
+	wait := p.postBackoffBase
+	for i := 1; i < failures && wait < maxPostBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxPostBackoff {
+		return maxPostBackoff
+	}
+	return wait
+}
+
 type rawBuffer = [][]byte
 
 type taskMemoryPool interface {
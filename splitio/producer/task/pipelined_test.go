@@ -167,3 +167,114 @@ func TestPipelineTask(t *testing.T) {
 
 	poolWrapper.validate(t)
 }
+
+func TestPipelineTaskSinkStats(t *testing.T) {
+	var flakyCalls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("which") {
+		case "flaky":
+			if atomic.AddInt64(&flakyCalls, 1) == 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		case "broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	var fetchCalls int64
+	w := &mockWorker{
+		fetchCall: func() ([]string, error) {
+			if atomic.AddInt64(&fetchCalls, 1) > 1 {
+				return nil, nil
+			}
+			return []string{"a"}, nil
+		},
+		processCall: func(rawData [][]byte, sink chan<- interface{}) error {
+			sink <- "ok"
+			sink <- "flaky"
+			sink <- "broken"
+			return nil
+		},
+		buildRequestCall: func(data interface{}) (*http.Request, error) {
+			r, _ := http.NewRequest("POST", server.URL, nil)
+			r.Header.Add("which", data.(string))
+			return r, nil
+		},
+	}
+
+	task, err := NewPipelinedTask(&Config{Worker: w, Logger: logging.NewLogger(nil), ProcessConcurrency: 1, MaxAccumWait: 100 * time.Millisecond})
+	if err != nil {
+		t.Error("task init: ", err)
+	}
+	task.Start()
+	time.Sleep(1 * time.Second)
+	task.Stop(true)
+
+	stats := task.Stats()
+	if stats.Flushed != 1 || stats.Retried != 1 || stats.Dropped != 1 {
+		t.Error("expected one bulk flushed, one retried & one dropped. Got: ", stats)
+	}
+}
+
+func TestPipelineTaskPostBackoff(t *testing.T) {
+	task, _ := NewPipelinedTask(&Config{Worker: &mockWorker{}, Logger: logging.NewLogger(nil), PostBackoffBase: time.Second})
+	if task.postAttempts != defaultPostAttempts {
+		t.Error("post attempts should default to ", defaultPostAttempts, ". Got: ", task.postAttempts)
+	}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, maxPostBackoff, maxPostBackoff}
+	for idx, wait := range expected {
+		if b := task.backoff(idx + 1); b != wait {
+			t.Error("unexpected backoff after ", idx+1, " failures: ", b)
+		}
+	}
+
+	noBackoff, _ := NewPipelinedTask(&Config{Worker: &mockWorker{}, Logger: logging.NewLogger(nil), PostAttempts: 5})
+	if noBackoff.postAttempts != 5 || noBackoff.backoff(3) != 0 {
+		t.Error("attempts should be taken from config & backoff should be disabled when no base is set")
+	}
+}
+
+func TestPipelineTaskRetriesFailedPosts(t *testing.T) {
+	var httpCalls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&httpCalls, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	var fetchCalls int64
+	w := &mockWorker{
+		fetchCall: func() ([]string, error) {
+			if atomic.AddInt64(&fetchCalls, 1) > 1 {
+				return nil, nil
+			}
+			return []string{"a"}, nil
+		},
+		processCall: func(rawData [][]byte, sink chan<- interface{}) error {
+			sink <- "message"
+			return nil
+		},
+		buildRequestCall: func(data interface{}) (*http.Request, error) {
+			return http.NewRequest("POST", server.URL, nil)
+		},
+	}
+
+	task, err := NewPipelinedTask(&Config{Worker: w, Logger: logging.NewLogger(nil), ProcessConcurrency: 1, MaxAccumWait: 100 * time.Millisecond})
+	if err != nil {
+		t.Error("task init: ", err)
+	}
+	task.Start()
+	time.Sleep(1 * time.Second)
+	task.Stop(true)
+
+	if c := atomic.LoadInt64(&httpCalls); c != 3 {
+		t.Error("the post should be attempted 3 times. Got: ", c)
+	}
+
+	if stats := task.Stats(); stats.Retried != 1 || stats.Flushed != 0 || stats.Dropped != 0 {
+		t.Error("the bulk should only be accounted as retried. Got: ", stats)
+	}
+}
@@ -21,6 +21,7 @@ type UniqueWorkerConfig struct {
 	Apikey            string
 	FetchSize         int
 	Metadata          dtos.Metadata
+	ExtraHeaders      map[string]string
 }
 
 // UniqueKeysPipelineWorker implements all the required  methods to work with a pipelined task
@@ -29,10 +30,11 @@ type UniqueKeysPipelineWorker struct {
 	storage           storage.UniqueKeysMultiSdkConsumer
 	uniqueKeysTracker strategy.UniqueKeysTracker
 
-	url       string
-	apikey    string
-	fetchSize int64
-	metadata  dtos.Metadata
+	url          string
+	apikey       string
+	fetchSize    int64
+	metadata     dtos.Metadata
+	extraHeaders map[string]string
 }
 
 func NewUniqueKeysWorker(cfg *UniqueWorkerConfig) Worker {
@@ -44,6 +46,7 @@ func NewUniqueKeysWorker(cfg *UniqueWorkerConfig) Worker {
 		apikey:            cfg.Apikey,
 		fetchSize:         int64(cfg.FetchSize),
 		metadata:          cfg.Metadata,
+		extraHeaders:      cfg.ExtraHeaders,
 	}
 }
 
@@ -105,6 +108,9 @@ func (u *UniqueKeysPipelineWorker) BuildRequest(data interface{}) (*http.Request
 	req.Header.Add("SplitSDKVersion", u.metadata.SDKVersion)
 	req.Header.Add("SplitSDKMachineIp", u.metadata.MachineIP)
 	req.Header.Add("SplitSDKMachineName", u.metadata.MachineName)
+	for name, value := range u.extraHeaders {
+		req.Header.Add(name, value)
+	}
 	return req, nil
 }
 
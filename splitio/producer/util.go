@@ -7,15 +7,14 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
-	"time"
 
 	config "github.com/splitio/go-split-commons/v6/conf"
 	"github.com/splitio/go-split-commons/v6/provisional"
 	"github.com/splitio/go-split-commons/v6/provisional/strategy"
-	"github.com/splitio/go-split-commons/v6/service"
 	storageCommon "github.com/splitio/go-split-commons/v6/storage"
 	"github.com/splitio/go-split-commons/v6/storage/redis"
 	"github.com/splitio/go-toolkit/v5/logging"
@@ -110,11 +109,6 @@ func parseRedisOptions(cfg *conf.Redis) (*config.RedisConfig, error) {
 	return redisCfg, nil
 }
 
-func isValidApikey(splitFetcher service.SplitFetcher) bool {
-	_, err := splitFetcher.Fetch(service.MakeFlagRequestParams().WithCacheControl(false).WithChangeNumber(time.Now().UnixNano() / int64(time.Millisecond)))
-	return err == nil
-}
-
 func sanitizeRedis(cfg *conf.Main, miscStorage *redis.MiscStorage, logger logging.LoggerInterface) error {
 	if miscStorage == nil {
 		return errors.New("could not sanitize redis")
@@ -142,10 +136,10 @@ func sanitizeRedis(cfg *conf.Main, miscStorage *redis.MiscStorage, logger loggin
 }
 
 func getAppCounterConfigs(storage storageCommon.SplitStorage) (hcAppCounter.ThresholdConfig, hcAppCounter.ThresholdConfig, hcAppCounter.PeriodicConfig) {
-	splitsConfig := hcAppCounter.DefaultThresholdConfig("Splits")
-	segmentsConfig := hcAppCounter.DefaultThresholdConfig("Segments")
+	splitsConfig := hcAppCounter.DefaultThresholdConfig(hcAppCounter.SplitsName)
+	segmentsConfig := hcAppCounter.DefaultThresholdConfig(hcAppCounter.SegmentsName)
 	storageConfig := hcAppCounter.PeriodicConfig{
-		Name:                     "Storage",
+		Name:                     hcAppCounter.StorageName,
 		MaxErrorsAllowedInPeriod: 5,
 		Period:                   3600,
 		Severity:                 hcAppCounter.Low,
@@ -161,7 +155,7 @@ func getAppCounterConfigs(storage storageCommon.SplitStorage) (hcAppCounter.Thre
 	return splitsConfig, segmentsConfig, storageConfig
 }
 
-func getServicesCountersConfig(advanced *config.AdvancedConfig) []hcServicesCounter.Config {
+func getServicesCountersConfig(advanced *config.AdvancedConfig, transport http.RoundTripper) []hcServicesCounter.Config {
 	var cfgs []hcServicesCounter.Config
 
 	apiConfig := hcServicesCounter.DefaultConfig("API", advanced.SdkURL, "/version")
@@ -180,7 +174,11 @@ func getServicesCountersConfig(advanced *config.AdvancedConfig) []hcServicesCoun
 	}
 	streamingConfig := hcServicesCounter.DefaultConfig("Streaming", fmt.Sprintf("%s://%s", streamingURL.Scheme, streamingURL.Host), "/health")
 
-	return append(cfgs, telemetryConfig, authConfig, apiConfig, eventsConfig, streamingConfig)
+	cfgs = append(cfgs, telemetryConfig, authConfig, apiConfig, eventsConfig, streamingConfig)
+	for idx := range cfgs {
+		cfgs[idx].Transport = transport
+	}
+	return cfgs
 }
 
 func buildImpressionManager(
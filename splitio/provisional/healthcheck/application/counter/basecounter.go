@@ -24,6 +24,13 @@ const (
 	Storage
 )
 
+// Counter names, as reported in the health status items
+const (
+	SplitsName   = "Splits"
+	SegmentsName = "Segments"
+	StorageName  = "Storage"
+)
+
 // HealthyResult description
 type HealthyResult struct {
 	Name       string
@@ -3,6 +3,7 @@ package counter
 import (
 	"container/list"
 	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
@@ -12,6 +13,8 @@ import (
 
 	"github.com/splitio/go-toolkit/v5/asynctask"
 	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/upstream"
 )
 
 const (
@@ -69,6 +72,7 @@ type Config struct {
 	ServiceHealthEndpoint string
 	Severity              int
 	TaskPeriod            int
+	Transport             http.RoundTripper // used to reach the service. The default http transport is used if nil
 }
 
 func (c *ByPercentageImp) calculateHealthy() {
@@ -169,6 +173,9 @@ func NewCounterByPercentage(
 	}
 
 	client := api.NewHTTPClient("", conf.AdvancedConfig{}, config.ServiceURL, logger, dtos.Metadata{})
+	if err := upstream.UseTransport(client, config.Transport); err != nil {
+		logger.Error(fmt.Sprintf("%s health probes will not use the upstream config: %s", config.Name, err))
+	}
 
 	taskFunc := func(logger logging.LoggerInterface) error {
 		status := 200
@@ -8,10 +8,11 @@ import (
 	"github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/services/counter"
 )
 
+// Statuses reported by the monitor
 const (
-	healthyStatus  = "healthy"
-	downStatus     = "down"
-	degradedStatus = "degraded"
+	HealthyStatus  = "healthy"
+	DownStatus     = "down" // a critical service cannot be reached
+	DegradedStatus = "degraded"
 )
 
 // HealthDto description
@@ -94,12 +95,12 @@ func (m *MonitorImp) GetHealthStatus() HealthDto {
 		})
 	}
 
-	status := healthyStatus
+	status := HealthyStatus
 
 	if criticalCount > 0 {
-		status = downStatus
+		status = DownStatus
 	} else if degradedCount > 0 {
-		status = degradedStatus
+		status = DegradedStatus
 	}
 
 	return HealthDto{
@@ -42,7 +42,7 @@ func TestGetHealthStatusByPercentage(t *testing.T) {
 
 	res := m.GetHealthStatus()
 
-	if res.Status != healthyStatus {
+	if res.Status != HealthyStatus {
 		t.Errorf("Status should be healthy - Actual status: %s", res.Status)
 	}
 
@@ -50,7 +50,7 @@ func TestGetHealthStatusByPercentage(t *testing.T) {
 
 	res = m.GetHealthStatus()
 
-	if res.Status != degradedStatus {
+	if res.Status != DegradedStatus {
 		t.Errorf("Status should be degraded")
 	}
 
@@ -58,7 +58,7 @@ func TestGetHealthStatusByPercentage(t *testing.T) {
 
 	res = m.GetHealthStatus()
 
-	if res.Status != downStatus {
+	if res.Status != DownStatus {
 		t.Errorf("Status should be down")
 	}
 
@@ -67,7 +67,7 @@ func TestGetHealthStatusByPercentage(t *testing.T) {
 
 	res = m.GetHealthStatus()
 
-	if res.Status != degradedStatus {
+	if res.Status != DegradedStatus {
 		t.Errorf("Status should be degraded - Actual status: %s", res.Status)
 	}
 
@@ -75,7 +75,7 @@ func TestGetHealthStatusByPercentage(t *testing.T) {
 
 	res = m.GetHealthStatus()
 
-	if res.Status != degradedStatus {
+	if res.Status != DegradedStatus {
 		t.Errorf("Status should be degraded - Actual status: %s", res.Status)
 	}
 
@@ -83,7 +83,7 @@ func TestGetHealthStatusByPercentage(t *testing.T) {
 
 	res = m.GetHealthStatus()
 
-	if res.Status != healthyStatus {
+	if res.Status != HealthyStatus {
 		t.Errorf("Status should be healthy - Actual status: %s", res.Status)
 	}
 
@@ -91,7 +91,7 @@ func TestGetHealthStatusByPercentage(t *testing.T) {
 
 	res = m.GetHealthStatus()
 
-	if res.Status != healthyStatus {
+	if res.Status != HealthyStatus {
 		t.Errorf("Status should be healthy - Actual status: %s", res.Status)
 	}
 
@@ -99,7 +99,7 @@ func TestGetHealthStatusByPercentage(t *testing.T) {
 
 	res = m.GetHealthStatus()
 
-	if res.Status != healthyStatus {
+	if res.Status != HealthyStatus {
 		t.Errorf("Status should be healthy - Actual status: %s", res.Status)
 	}
 }
@@ -106,6 +106,13 @@ func (t *ActiveSegmentTracker) Update(name string, added int, removed int) {
 	t.activeSegmentMap[name] = current
 }
 
+// Remove stops tracking a segment
+func (t *ActiveSegmentTracker) Remove(name string) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	delete(t.activeSegmentMap, name)
+}
+
 // NamesAndCount returns a map of segment names to key count
 func (t *ActiveSegmentTracker) NamesAndCount() map[string]int {
 	t.mtx.RLock()
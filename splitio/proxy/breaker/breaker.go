@@ -0,0 +1,166 @@
+package breaker
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-split-commons/v6/service"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+)
+
+// ErrCircuitOpen is returned when upstream fetches are being short-circuited and no cached data is available
+var ErrCircuitOpen = errors.New("upstream circuit breaker is open")
+
+// Circuit breaker states
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open"
+)
+
+// Status is a snapshot of the breaker state, as exposed by the admin API
+type Status struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	Threshold           int        `json:"threshold"`
+	OpenedAt            *time.Time `json:"openedAt,omitempty"`
+	RetryAt             *time.Time `json:"retryAt,omitempty"`
+}
+
+// SplitFetcher wraps the upstream fetcher used on cache misses. After `threshold` consecutive failures, fetches are
+// short-circuited for `cooldown`, serving the whole set of cached feature flags instead. Once the cooldown expires, a
+// single request is let through to probe upstream: the breaker closes if it succeeds & re-opens otherwise
+type SplitFetcher struct {
+	wrapped   service.SplitFetcher
+	fallback  storage.ProxySplitStorage
+	logger    logging.LoggerInterface
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mutex    sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+}
+
+// NewSplitFetcher constructs a new circuit breaker around the supplied fetcher
+func NewSplitFetcher(
+	wrapped service.SplitFetcher,
+	fallback storage.ProxySplitStorage,
+	threshold int,
+	cooldown time.Duration,
+	logger logging.LoggerInterface,
+) *SplitFetcher {
+	return &SplitFetcher{
+		wrapped:   wrapped,
+		fallback:  fallback,
+		logger:    logger,
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		state:     StateClosed,
+	}
+}
+
+// Fetch forwards the request upstream unless the breaker is open
+func (f *SplitFetcher) Fetch(params *service.FlagRequestParams) (*dtos.SplitChangesDTO, error) {
+	if !f.allow() {
+		return f.fromCache(params)
+	}
+
+	changes, err := f.wrapped.Fetch(params)
+	if err != nil {
+		f.onFailure()
+		return nil, err
+	}
+
+	f.onSuccess()
+	return changes, nil
+}
+
+// Status returns the current state of the breaker
+func (f *SplitFetcher) Status() Status {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	status := Status{State: f.state, ConsecutiveFailures: f.failures, Threshold: f.threshold}
+	if f.state != StateClosed {
+		openedAt, retryAt := f.openedAt, f.openedAt.Add(f.cooldown)
+		status.OpenedAt, status.RetryAt = &openedAt, &retryAt
+	}
+	return status
+}
+
+// allow returns whether the request should hit upstream, moving to half-open (& letting a single probe through) when
+// the cooldown has expired
+func (f *SplitFetcher) allow() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	switch f.state {
+	case StateClosed:
+		return true
+	case StateOpen:
+		if f.now().Sub(f.openedAt) < f.cooldown {
+			return false
+		}
+		f.state = StateHalfOpen
+		f.logger.Info("upstream circuit breaker cooldown expired. probing Split servers")
+		return true
+	default: // half-open, a probe is already in flight
+		return false
+	}
+}
+
+func (f *SplitFetcher) onSuccess() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.state != StateClosed {
+		f.logger.Info("upstream circuit breaker closed. Split servers are reachable again")
+	}
+	f.state = StateClosed
+	f.failures = 0
+}
+
+func (f *SplitFetcher) onFailure() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.failures++
+	if f.state == StateHalfOpen || f.failures >= f.threshold {
+		if f.state == StateClosed {
+			f.logger.Warning(fmt.Sprintf("upstream circuit breaker opened after %d consecutive failures. retrying in %s", f.failures, f.cooldown))
+		}
+		f.state = StateOpen
+		f.openedAt = f.now()
+	}
+}
+
+// fromCache returns all the cached feature flags (matching the requested flag sets if any), like replicas do
+func (f *SplitFetcher) fromCache(params *service.FlagRequestParams) (*dtos.SplitChangesDTO, error) {
+	// flag sets are only exposed as part of the query string
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	if err := params.Apply(req); err != nil {
+		return nil, fmt.Errorf("error parsing request params: %w", err)
+	}
+
+	var sets []string
+	if raw := req.URL.Query().Get("sets"); raw != "" {
+		sets = strings.Split(raw, ",")
+	}
+
+	changes, err := f.fallback.ChangesSince(-1, sets)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCircuitOpen, err.Error())
+	}
+
+	changes.Since = params.ChangeNumber()
+	return changes, nil
+}
+
+var _ service.SplitFetcher = (*SplitFetcher)(nil)
@@ -0,0 +1,71 @@
+package breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-split-commons/v6/flagsets"
+	"github.com/splitio/go-split-commons/v6/service"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/persistent"
+)
+
+type fetcherStub struct {
+	calls int
+	err   error
+}
+
+func (f *fetcherStub) Fetch(params *service.FlagRequestParams) (*dtos.SplitChangesDTO, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &dtos.SplitChangesDTO{Since: params.ChangeNumber(), Till: 20}, nil
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	dbw, err := persistent.NewBoltWrapper(persistent.BoltInMemoryMode, nil)
+	assert.Nil(t, err)
+	splitStorage := storage.NewProxySplitStorage(dbw, logging.NewLogger(nil), flagsets.NewFlagSetFilter(nil), false, nil, 0, 0)
+	splitStorage.Update([]dtos.SplitDTO{{Name: "f1", ChangeNumber: 10, Status: "ACTIVE"}}, nil, 10)
+
+	upstream := &fetcherStub{err: errors.New("upstream down")}
+	fetcher := NewSplitFetcher(upstream, splitStorage, 2, time.Minute, logging.NewLogger(nil))
+	now := time.Now()
+	fetcher.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		_, err = fetcher.Fetch(service.MakeFlagRequestParams().WithChangeNumber(5))
+		assert.NotNil(t, err)
+	}
+	assert.Equal(t, StateOpen, fetcher.Status().State)
+	assert.Equal(t, 2, fetcher.Status().ConsecutiveFailures)
+
+	// while open, cached data is served without hitting upstream
+	changes, err := fetcher.Fetch(service.MakeFlagRequestParams().WithChangeNumber(5))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), changes.Since)
+	assert.Equal(t, int64(10), changes.Till)
+	assert.Len(t, changes.Splits, 1)
+	assert.Equal(t, 2, upstream.calls)
+
+	// a failed probe after the cooldown re-opens the breaker
+	now = now.Add(time.Minute)
+	_, err = fetcher.Fetch(service.MakeFlagRequestParams().WithChangeNumber(5))
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, upstream.calls)
+	assert.Equal(t, StateOpen, fetcher.Status().State)
+
+	// a successful one closes it
+	now = now.Add(time.Minute)
+	upstream.err = nil
+	changes, err = fetcher.Fetch(service.MakeFlagRequestParams().WithChangeNumber(5))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(20), changes.Till)
+	assert.Equal(t, Status{State: StateClosed, Threshold: 2}, fetcher.Status())
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/splitio/gincache"
 	"github.com/splitio/go-split-commons/v6/dtos"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/controllers/middleware"
 )
 
 const (
@@ -43,6 +45,8 @@ func MakeMySegmentsEntries(key string) []string {
 	return []string{
 		"/api/mySegments/" + key,
 		"gzip::/api/mySegments/" + key,
+		"br::/api/mySegments/" + key,
+		"br::gzip::/api/mySegments/" + key,
 	}
 }
 
@@ -61,9 +65,13 @@ func MakeProxyCache() *gincache.Middleware {
 
 func keyFactoryFN(ctx *gin.Context) string {
 
+	// brotli may or may not be enabled, so every combination of accepted encodings gets its own entry
 	var encodingPrefix string
-	if strings.Contains(ctx.Request.Header.Get("Accept-Encoding"), "gzip") {
-		encodingPrefix = "gzip::"
+	if middleware.AcceptsBrotli(ctx.Request.Header.Get("Accept-Encoding")) {
+		encodingPrefix = "br::"
+	}
+	if middleware.AcceptsGzip(ctx.Request.Header.Get("Accept-Encoding")) {
+		encodingPrefix += "gzip::"
 	}
 
 	if strings.HasPrefix(ctx.Request.URL.Path, "/api/auth") || strings.HasPrefix(ctx.Request.URL.Path, "/api/v2/auth") {
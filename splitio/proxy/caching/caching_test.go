@@ -30,6 +30,8 @@ func TestMySegmentKeyGeneration(t *testing.T) {
 	entries := MakeMySegmentsEntries("k1")
 	assert.Equal(t, "/api/mySegments/k1", entries[0])
 	assert.Equal(t, "gzip::/api/mySegments/k1", entries[1])
+	assert.Equal(t, "br::/api/mySegments/k1", entries[2])
+	assert.Equal(t, "br::gzip::/api/mySegments/k1", entries[3])
 }
 
 func TestMySegmentsSurrogates(t *testing.T) {
@@ -1,6 +1,13 @@
 package caching
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/splitio/go-split-commons/v6/dtos"
 	"github.com/splitio/go-split-commons/v6/flagsets"
 	"github.com/splitio/go-split-commons/v6/healthcheck/application"
@@ -13,11 +20,20 @@ import (
 	"github.com/splitio/gincache"
 )
 
+// ChangeNotifier is told about feature flag & segment changes once they've been applied to the storages
+type ChangeNotifier interface {
+	NotifySplitUpdate(changeNumber int64)
+	NotifySplitKill(splitName string, defaultTreatment string, changeNumber int64)
+	NotifySegmentUpdate(segmentName string, changeNumber int64)
+	NotifyMySegmentsUpdate(keys []string, changeNumber int64)
+}
+
 // CacheAwareSplitSynchronizer wraps a SplitSynchronizer and flushes cache when an update happens
 type CacheAwareSplitSynchronizer struct {
 	splitStorage storage.SplitStorage
 	wrapped      split.Updater
 	cacheFlusher gincache.CacheFlusher
+	notifier     ChangeNotifier
 }
 
 // NewCacheAwareSplitSync constructs a split-sync wrapper that evicts cache on updates
@@ -29,11 +45,13 @@ func NewCacheAwareSplitSync(
 	cacheFlusher gincache.CacheFlusher,
 	appMonitor application.MonitorProducerInterface,
 	flagSetsFilter flagsets.FlagSetFilter,
+	notifier ChangeNotifier,
 ) *CacheAwareSplitSynchronizer {
 	return &CacheAwareSplitSynchronizer{
 		wrapped:      split.NewSplitUpdater(splitStorage, splitFetcher, logger, runtimeTelemetry, appMonitor, flagSetsFilter),
 		splitStorage: splitStorage,
 		cacheFlusher: cacheFlusher,
+		notifier:     notifier,
 	}
 }
 
@@ -41,10 +59,7 @@ func NewCacheAwareSplitSync(
 func (c *CacheAwareSplitSynchronizer) SynchronizeSplits(till *int64) (*split.UpdateResult, error) {
 	previous, _ := c.splitStorage.ChangeNumber()
 	result, err := c.wrapped.SynchronizeSplits(till)
-	if current, _ := c.splitStorage.ChangeNumber(); current > previous || (previous != -1 && current == -1) {
-		// if the changenumber was updated, evict splitChanges responses from cache
-		c.cacheFlusher.EvictBySurrogate(SplitSurrogate)
-	}
+	c.onSplitsSynchronized(previous)
 	return result, err
 }
 
@@ -53,25 +68,47 @@ func (c *CacheAwareSplitSynchronizer) LocalKill(splitName string, defaultTreatme
 	c.wrapped.LocalKill(splitName, defaultTreatment, changeNumber)
 	// Since a feature flag was killed, unconditionally flush all feature flag changes
 	c.cacheFlusher.EvictBySurrogate(SplitSurrogate)
+	if c.notifier != nil {
+		c.notifier.NotifySplitKill(splitName, defaultTreatment, changeNumber)
+	}
 }
 
 // SynchronizeFeatureFlags synchronizes feature flags and if something changes, purges the cache appropriately
 func (c *CacheAwareSplitSynchronizer) SynchronizeFeatureFlags(ffChange *dtos.SplitChangeUpdate) (*split.UpdateResult, error) {
 	previous, _ := c.splitStorage.ChangeNumber()
 	result, err := c.wrapped.SynchronizeFeatureFlags(ffChange)
-	if current, _ := c.splitStorage.ChangeNumber(); current > previous || (previous != -1 && current == -1) {
+	c.onSplitsSynchronized(previous)
+	return result, err
+}
+
+func (c *CacheAwareSplitSynchronizer) onSplitsSynchronized(previous int64) {
+	current, _ := c.splitStorage.ChangeNumber()
+	if current > previous || (previous != -1 && current == -1) {
 		// if the changenumber was updated, evict splitChanges responses from cache
 		c.cacheFlusher.EvictBySurrogate(SplitSurrogate)
 	}
-	return result, err
+
+	if current > previous && c.notifier != nil {
+		c.notifier.NotifySplitUpdate(current)
+	}
 }
 
+const (
+	rateLimitedSegmentRetries = 3
+	rateLimitedSegmentBackoff = time.Second
+)
+
 // CacheAwareSegmentSynchronizer wraps a segment-sync with cache-friendly logic
 type CacheAwareSegmentSynchronizer struct {
 	wrapped        segment.Updater
 	splitStorage   storage.SplitStorage
 	segmentStorage storage.SegmentStorage
 	cacheFlusher   gincache.CacheFlusher
+	notifier       ChangeNotifier
+	logger         logging.LoggerInterface
+	concurrency    int
+	backoff        time.Duration
+	syncedOnce     int32
 }
 
 // NewCacheAwareSegmentSync constructs a new cache-aware segment sync
@@ -83,21 +120,34 @@ func NewCacheAwareSegmentSync(
 	runtimeTelemetry storage.TelemetryRuntimeProducer,
 	cacheFlusher gincache.CacheFlusher,
 	appMonitor application.MonitorProducerInterface,
+	notifier ChangeNotifier,
 ) *CacheAwareSegmentSynchronizer {
 	return &CacheAwareSegmentSynchronizer{
 		wrapped:        segment.NewSegmentUpdater(splitStorage, segmentStorage, segmentFetcher, logger, runtimeTelemetry, appMonitor),
 		cacheFlusher:   cacheFlusher,
 		splitStorage:   splitStorage,
 		segmentStorage: segmentStorage,
+		notifier:       notifier,
+		logger:         logger,
+		backoff:        rateLimitedSegmentBackoff,
 	}
 }
 
+// SetConcurrency sets the max number of segments fetched in parallel when synchronizing all of them.
+// If 0, the wrapped updater is used as is (with its own fixed concurrency)
+func (c *CacheAwareSegmentSynchronizer) SetConcurrency(concurrency int) {
+	c.concurrency = concurrency
+}
+
 // SynchronizeSegment synchronizes a segment and if it was updated, flushes all entries associated with it from the http cache
 func (c *CacheAwareSegmentSynchronizer) SynchronizeSegment(name string, till *int64) (*segment.UpdateResult, error) {
 	previous, _ := c.segmentStorage.ChangeNumber(name)
 	result, err := c.wrapped.SynchronizeSegment(name, till)
 	if current := result.NewChangeNumber; current > previous || (previous != -1 && current == -1) {
 		c.cacheFlusher.EvictBySurrogate(MakeSurrogateForSegmentChanges(name))
+		if current > previous && c.notifier != nil {
+			c.notifier.NotifySegmentUpdate(name, current)
+		}
 	}
 
 	// remove individual entries for each affected key
@@ -106,12 +156,19 @@ func (c *CacheAwareSegmentSynchronizer) SynchronizeSegment(name string, till *in
 			c.cacheFlusher.Evict(key)
 		}
 	}
+	if len(result.UpdatedKeys) > 0 && c.notifier != nil {
+		c.notifier.NotifyMySegmentsUpdate(result.UpdatedKeys, result.NewChangeNumber)
+	}
 
 	return result, err
 }
 
 // SynchronizeSegments syncs all the segments cached and purges cache appropriately if needed
 func (c *CacheAwareSegmentSynchronizer) SynchronizeSegments() (map[string]segment.UpdateResult, error) {
+	if c.concurrency > 0 {
+		return c.synchronizeSegmentsInParallel()
+	}
+
 	// we need to keep track of all change numbers here to see if anything changed
 	previousSegmentNames := c.splitStorage.SegmentNames()
 	previousCNs := make(map[string]int64, previousSegmentNames.Size())
@@ -129,6 +186,9 @@ func (c *CacheAwareSegmentSynchronizer) SynchronizeSegments() (map[string]segmen
 		if pcn, _ := previousCNs[segmentName]; ccn > pcn || (pcn > 0 && ccn == -1) {
 			// if the segment was updated or the segment was removed, evict it
 			c.cacheFlusher.EvictBySurrogate(MakeSurrogateForSegmentChanges(segmentName))
+			if ccn > pcn && c.notifier != nil {
+				c.notifier.NotifySegmentUpdate(segmentName, ccn)
+			}
 		}
 
 		for idx := range result.UpdatedKeys {
@@ -136,12 +196,90 @@ func (c *CacheAwareSegmentSynchronizer) SynchronizeSegments() (map[string]segmen
 				c.cacheFlusher.Evict(key)
 			}
 		}
+		if len(result.UpdatedKeys) > 0 && c.notifier != nil {
+			c.notifier.NotifyMySegmentsUpdate(result.UpdatedKeys, ccn)
+		}
 
 	}
 
 	return results, err // return original segment sync error
 }
 
+// synchronizeSegmentsInParallel syncs every segment referenced by feature flags, running up to `concurrency` fetches at a time.
+// Progress is logged as info on the first run (startup), and as debug afterwards
+func (c *CacheAwareSegmentSynchronizer) synchronizeSegmentsInParallel() (map[string]segment.UpdateResult, error) {
+	names := c.splitStorage.SegmentNames().List()
+	total := len(names)
+	logProgress := c.logger.Debug
+	if atomic.CompareAndSwapInt32(&c.syncedOnce, 0, 1) {
+		logProgress = c.logger.Info
+	}
+	logProgress(fmt.Sprintf("Synchronizing %d segments (up to %d in parallel)", total, c.concurrency))
+
+	progressStep := total / 10
+	if progressStep < 1 {
+		progressStep = 1
+	}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	var done int64
+	results := make(map[string]segment.UpdateResult, total)
+	failed := make(map[string]error)
+	slots := make(chan struct{}, c.concurrency)
+	start := time.Now()
+	for _, name := range names {
+		strName, ok := name.(string)
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			result, err := c.synchronizeSegmentWithRetries(name)
+			mutex.Lock()
+			if result != nil {
+				results[name] = *result
+			}
+			if err != nil {
+				failed[name] = err
+			}
+			mutex.Unlock()
+
+			if current := atomic.AddInt64(&done, 1); current%int64(progressStep) == 0 && current < int64(total) {
+				logProgress(fmt.Sprintf("Segment sync progress: %d/%d", current, total))
+			}
+		}(strName)
+	}
+	wg.Wait()
+
+	logProgress(fmt.Sprintf("%d/%d segments synchronized in %s", total-len(failed), total, time.Since(start).Round(time.Millisecond)))
+	if len(failed) > 0 {
+		return results, fmt.Errorf("the following errors happened when synchronizing segments: %v", failed)
+	}
+	return results, nil
+}
+
+// synchronizeSegmentWithRetries retries fetches rejected by rate limiting, backing off exponentially
+func (c *CacheAwareSegmentSynchronizer) synchronizeSegmentWithRetries(name string) (*segment.UpdateResult, error) {
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		result, err := c.SynchronizeSegment(name, nil)
+		var httpErr *dtos.HTTPError
+		if attempt >= rateLimitedSegmentRetries || !errors.As(err, &httpErr) || httpErr.Code != http.StatusTooManyRequests {
+			return result, err
+		}
+
+		c.logger.Warning(fmt.Sprintf("Rate limited when fetching segment '%s'. Retrying in %s", name, backoff))
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
 // SegmentNames forwards the call to the wrapped sync
 func (c *CacheAwareSegmentSynchronizer) SegmentNames() []interface{} {
 	return c.wrapped.SegmentNames()
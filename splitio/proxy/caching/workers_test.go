@@ -2,6 +2,7 @@ package caching
 
 import (
 	"testing"
+	"time"
 
 	"github.com/splitio/gincache"
 	"github.com/splitio/go-split-commons/v6/dtos"
@@ -9,6 +10,7 @@ import (
 	"github.com/splitio/go-split-commons/v6/synchronizer/worker/segment"
 	"github.com/splitio/go-split-commons/v6/synchronizer/worker/split"
 	"github.com/splitio/go-toolkit/v5/datastructures/set"
+	"github.com/splitio/go-toolkit/v5/logging"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -148,6 +150,8 @@ func TestCacheAwareSegmentSyncSingle(t *testing.T) {
 	cacheFlusher.On("EvictBySurrogate", MakeSurrogateForSegmentChanges("segment1")).Times(2)
 	cacheFlusher.On("Evict", "/api/mySegments/k1").Times(2)
 	cacheFlusher.On("Evict", "gzip::/api/mySegments/k1").Times(2)
+	cacheFlusher.On("Evict", "br::/api/mySegments/k1").Times(2)
+	cacheFlusher.On("Evict", "br::gzip::/api/mySegments/k1").Times(2)
 
 	var segmentStorage segmentStorageMock
 	segmentStorage.On("ChangeNumber", "segment1").Return(int64(0), nil).Once()
@@ -193,6 +197,8 @@ func TestCacheAwareSegmentSyncAllSegments(t *testing.T) {
 	cacheFlusher.On("EvictBySurrogate", MakeSurrogateForSegmentChanges("segment2")).Times(1)
 	cacheFlusher.On("Evict", "/api/mySegments/k1").Times(3)
 	cacheFlusher.On("Evict", "gzip::/api/mySegments/k1").Times(3)
+	cacheFlusher.On("Evict", "br::/api/mySegments/k1").Times(3)
+	cacheFlusher.On("Evict", "br::gzip::/api/mySegments/k1").Times(3)
 
 	var segmentStorage segmentStorageMock
 	segmentStorage.On("ChangeNumber", "segment2").Return(int64(0), nil).Once()
@@ -238,6 +244,48 @@ func TestCacheAwareSegmentSyncAllSegments(t *testing.T) {
 	cacheFlusher.AssertExpectations(t)
 }
 
+func TestCacheAwareSegmentSyncAllSegmentsInParallel(t *testing.T) {
+	var segmentUpdater segmentUpdaterMock
+	segmentUpdater.On("SynchronizeSegment", "segment1", (*int64)(nil)).Return(&segment.UpdateResult{NewChangeNumber: 1}, nil).Once()
+	segmentUpdater.On("SynchronizeSegment", "segment2", (*int64)(nil)).
+		Return(&segment.UpdateResult{NewChangeNumber: -1}, &dtos.HTTPError{Code: 429}).Once()
+	segmentUpdater.On("SynchronizeSegment", "segment2", (*int64)(nil)).Return(&segment.UpdateResult{NewChangeNumber: 2}, nil).Once()
+	segmentUpdater.On("SynchronizeSegment", "segment3", (*int64)(nil)).
+		Return(&segment.UpdateResult{NewChangeNumber: -1}, &dtos.HTTPError{Code: 500}).Once()
+
+	var splitStorage splitStorageMock
+	splitStorage.On("SegmentNames").Return(set.NewSet("segment1", "segment2", "segment3")).Once()
+
+	var cacheFlusher cacheFlusherMock
+	cacheFlusher.On("EvictBySurrogate", MakeSurrogateForSegmentChanges("segment1")).Once()
+	cacheFlusher.On("EvictBySurrogate", MakeSurrogateForSegmentChanges("segment2")).Once()
+
+	var segmentStorage segmentStorageMock
+	segmentStorage.On("ChangeNumber", mock.Anything).Return(int64(-1), nil)
+
+	css := CacheAwareSegmentSynchronizer{
+		splitStorage:   &splitStorage,
+		segmentStorage: &segmentStorage,
+		wrapped:        &segmentUpdater,
+		cacheFlusher:   &cacheFlusher,
+		logger:         logging.NewLogger(nil),
+		backoff:        time.Millisecond,
+	}
+	css.SetConcurrency(2)
+
+	res, err := css.SynchronizeSegments()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "segment3")
+	assert.NotContains(t, err.Error(), "segment2")
+	assert.Equal(t, int64(1), res["segment1"].NewChangeNumber)
+	assert.Equal(t, int64(2), res["segment2"].NewChangeNumber)
+	assert.Equal(t, int64(-1), res["segment3"].NewChangeNumber)
+
+	segmentUpdater.AssertExpectations(t)
+	splitStorage.AssertExpectations(t)
+	cacheFlusher.AssertExpectations(t)
+}
+
 // Borrowed mocks: These sohuld be in go-split-commons. but we need to wait until testify is adopted there
 
 type splitUpdaterMock struct {
@@ -377,3 +425,68 @@ var _ storage.SplitStorage = (*splitStorageMock)(nil)
 var _ gincache.CacheFlusher = (*cacheFlusherMock)(nil)
 var _ segment.Updater = (*segmentUpdaterMock)(nil)
 var _ storage.SegmentStorage = (*segmentStorageMock)(nil)
+
+type changeNotifierMock struct {
+	mock.Mock
+}
+
+func (m *changeNotifierMock) NotifySplitUpdate(changeNumber int64) {
+	m.Called(changeNumber)
+}
+
+func (m *changeNotifierMock) NotifySplitKill(splitName string, defaultTreatment string, changeNumber int64) {
+	m.Called(splitName, defaultTreatment, changeNumber)
+}
+
+func (m *changeNotifierMock) NotifySegmentUpdate(segmentName string, changeNumber int64) {
+	m.Called(segmentName, changeNumber)
+}
+
+func (m *changeNotifierMock) NotifyMySegmentsUpdate(keys []string, changeNumber int64) {
+	m.Called(keys, changeNumber)
+}
+
+func TestCacheAwareSyncNotifiesChanges(t *testing.T) {
+	var notifier changeNotifierMock
+	notifier.On("NotifySplitUpdate", int64(2)).Once()
+	notifier.On("NotifySplitKill", "someSplit", "off", int64(3)).Once()
+	notifier.On("NotifySegmentUpdate", "segment1", int64(5)).Once()
+	notifier.On("NotifyMySegmentsUpdate", []string{"k1", "k2"}, int64(5)).Once()
+
+	var cacheFlusher cacheFlusherMock
+	cacheFlusher.On("EvictBySurrogate", mock.Anything)
+	cacheFlusher.On("Evict", mock.Anything)
+
+	var splitSyncMock splitUpdaterMock
+	splitSyncMock.On("SynchronizeSplits", (*int64)(nil)).Return((*split.UpdateResult)(nil), error(nil)).Twice()
+	splitSyncMock.On("LocalKill", "someSplit", "off", int64(3)).Return(nil).Once()
+	var splitStorage splitStorageMock
+	splitStorage.On("ChangeNumber").Return(int64(1), error(nil)).Once()
+	splitStorage.On("ChangeNumber").Return(int64(2), error(nil)).Times(3)
+
+	splitSync := CacheAwareSplitSynchronizer{
+		splitStorage: &splitStorage,
+		wrapped:      &splitSyncMock,
+		cacheFlusher: &cacheFlusher,
+		notifier:     &notifier,
+	}
+	splitSync.SynchronizeSplits(nil)
+	splitSync.SynchronizeSplits(nil) // no changes, no notification
+	splitSync.LocalKill("someSplit", "off", 3)
+
+	var segmentUpdater segmentUpdaterMock
+	segmentUpdater.On("SynchronizeSegment", "segment1", (*int64)(nil)).Return(&segment.UpdateResult{UpdatedKeys: []string{"k1", "k2"}, NewChangeNumber: 5}, nil).Once()
+	var segmentStorage segmentStorageMock
+	segmentStorage.On("ChangeNumber", "segment1").Return(int64(4), nil).Once()
+
+	segmentSync := CacheAwareSegmentSynchronizer{
+		splitStorage:   &splitStorage,
+		segmentStorage: &segmentStorage,
+		wrapped:        &segmentUpdater,
+		cacheFlusher:   &cacheFlusher,
+		notifier:       &notifier,
+	}
+	segmentSync.SynchronizeSegment("segment1", nil)
+
+	notifier.AssertExpectations(t)
+}
@@ -8,24 +8,31 @@ import (
 // Main configuration options
 type Main struct {
 	Apikey                string            `json:"apikey" s-cli:"apikey" s-def:"" s-desc:"Split server side SDK key"`
+	ApikeyFile            string            `json:"apikeyFile" s-cli:"apikey-file" s-def:"" s-desc:"File to read the SDK key from (ie: a mounted secret). Takes precedence over apikey"`
 	IPAddressEnabled      bool              `json:"ipAddressEnabled" s-cli:"ip-address-enabled" s-def:"true" s-desc:"Bundle host's ip address when sending data to Split"`
+	EnvironmentLabel      string            `json:"environmentLabel" s-cli:"environment-label" s-def:"" s-desc:"Label sent along with every request posted to Split, used to tell deployments apart"`
 	FlagSetsFilter        []string          `json:"flagSetsFilter" s-cli:"flag-sets-filter" s-def:"" s-desc:"Flag Sets Filter provided"`
 	FlagSetStrictMatching bool              `json:"flagSetStrictMatching" s-cli:"flag-sets-strict-matching" s-def:"false" s-desc:"filter sets not present in cache when building splitChanges responses"`
 	Initialization        Initialization    `json:"initialization" s-nested:"true"`
 	Server                Server            `json:"server" s-nested:"true"`
 	Admin                 conf.Admin        `json:"admin" s-nested:"true"`
 	Storage               Storage           `json:"storage" s-nested:"true"`
+	Replica               Replica           `json:"replica" s-nested:"true"`
 	Sync                  Sync              `json:"sync" s-nested:"true"`
 	Integrations          conf.Integrations `json:"integrations" s-nested:"true"`
+	Upstream              conf.Upstream     `json:"upstream" s-nested:"true"`
 	Logging               conf.Logging      `json:"logging" s-nested:"true"`
 	Healthcheck           Healthcheck       `json:"healthcheck" s-nested:"true"`
 	Observability         Observability     `json:"observability" s-nested:"true"`
+	Tracing               conf.Tracing      `json:"tracing" s-nested:"true"`
 	FlagSpecVersion       string            `json:"flagSpecVersion" s-cli:"flag-spec-version" s-def:"1.1" s-desc:"Spec version for flags"`
+	ConfigReloadEnabled   bool              `json:"configReloadEnabled" s-cli:"config-reload-enabled" s-def:"false" s-desc:"Watch the config file & hot-apply changes to supported properties (currently the log level & ingest rate limits). The config is also reloaded on SIGHUP"`
+	ConfigReloadRateMs    int64             `json:"configReloadRateMs" s-cli:"config-reload-rate-ms" s-def:"10000" s-desc:"How often to check the config file for changes"`
 }
 
 // BuildAdvancedConfig generates a commons-compatible advancedconfig with default + overriden parameters
 func (m *Main) BuildAdvancedConfig() *cconf.AdvancedConfig {
-	tmp := conf.InitAdvancedOptions(true) // defaults + url overrides
+	tmp := conf.InitAdvancedOptions(true, &m.Upstream) // defaults + url overrides
 	tmp.HTTPTimeout = int(m.Sync.Advanced.HTTPTimeoutMs / 1000)
 	tmp.ImpressionsQueueSize = int(m.Sync.Advanced.ImpressionsBuffer / 1000)
 	tmp.EventsQueueSize = int(m.Sync.Advanced.EventsBuffer)
@@ -35,20 +42,70 @@ func (m *Main) BuildAdvancedConfig() *cconf.AdvancedConfig {
 	return tmp
 }
 
+// Redacted returns a copy of the config with every secret masked, suitable for logging & admin endpoints
+func (m *Main) Redacted() Main {
+	redacted := *m
+	redacted.Apikey = conf.RedactSecret(m.Apikey)
+	redacted.Server.ClientApikeys = conf.RedactSecrets(m.Server.ClientApikeys)
+	redacted.Server.StreamingTokenSecret = conf.MaskSecret(m.Server.StreamingTokenSecret)
+	redacted.Admin = m.Admin.Redacted()
+	redacted.Integrations = m.Integrations.Redacted()
+	redacted.Upstream = m.Upstream.Redacted()
+	return redacted
+}
+
 // Initialization configuration options
 type Initialization struct {
 	TimeoutMs         int64  `json:"timeoutMS" s-cli:"timeout-ms" s-def:"10000" s-desc:"How long to wait until the synchronizer is ready"`
 	Snapshot          string `json:"snapshot" s-cli:"snapshot" s-def:"" s-desc:"Snapshot file to use as a starting point"`
+	SeedFile          string `json:"seedFile" s-cli:"seed-file" s-def:"" s-desc:"JSON file with feature flags & segments to serve while the initial sync runs in background"`
 	ForceFreshStartup bool   `json:"forceFreshStartup" s-cli:"force-fresh-startup" s-def:"false" s-desc:"Wipe storage before starting the synchronizer"`
 }
 
 // Server configuration options
 type Server struct {
-	ClientApikeys []string `json:"apikeys" s-cli:"client-apikeys" s-def:"SDK_API_KEY" s-desc:"Apikeys that clients connecting to this proxy will use."`
-	Host          string   `json:"host" s-cli:"server-host" s-def:"0.0.0.0" s-desc:"Host/IP to start the proxy server on"`
-	Port          int64    `json:"port" s-cli:"server-port" s-def:"3000" s-desc:"Port to listten for incoming requests from SDKs"`
-	CacheSize     int64    `json:"httpCacheSize" s-cli:"http-cache-size" s-def:"1000000" s-desc:"How many responses to cache"`
-	TLS           conf.TLS `json:"tls" s-nested:"true" s-cli-prefix:"server"`
+	ClientApikeys          []string `json:"apikeys" s-cli:"client-apikeys" s-def:"SDK_API_KEY" s-desc:"Apikeys that clients connecting to this proxy will use."`
+	Host                   string   `json:"host" s-cli:"server-host" s-def:"0.0.0.0" s-desc:"Host/IP to start the proxy server on"`
+	Port                   int64    `json:"port" s-cli:"server-port" s-def:"3000" s-desc:"Port to listten for incoming requests from SDKs"`
+	CacheSize              int64    `json:"httpCacheSize" s-cli:"http-cache-size" s-def:"1000000" s-desc:"How many responses to cache"`
+	BrotliEnabled          bool     `json:"brotliEnabled" s-cli:"brotli-enabled" s-def:"false" s-desc:"Compress responses with brotli for clients that accept it (preferred over gzip)"`
+	BrotliLevel            int64    `json:"brotliLevel" s-cli:"brotli-level" s-def:"4" s-desc:"Brotli compression level (0-11)"`
+	GzipLevel              int64    `json:"gzipLevel" s-cli:"gzip-level" s-def:"-1" s-desc:"Gzip compression level (1-9, -1 for the default level, -2 for huffman-only)"`
+	GzipMinSizeBytes       int64    `json:"gzipMinSizeBytes" s-cli:"gzip-min-size-bytes" s-def:"1024" s-desc:"Responses smaller than this are sent uncompressed (applies to both gzip & brotli)"`
+	IngestRateLimit        int64    `json:"ingestRateLimit" s-cli:"ingest-rate-limit" s-def:"0" s-desc:"Max requests per second each client (address & SDK key) can make to impressions/events/metrics endpoints (0 = unlimited)"`
+	IngestRateBurst        int64    `json:"ingestRateBurst" s-cli:"ingest-rate-burst" s-def:"0" s-desc:"Max burst of requests allowed per client on top of the ingest rate limit (0 = same as the rate)"`
+	InstanceID             string   `json:"instanceId" s-cli:"instance-id" s-def:"" s-desc:"Value of the X-Split-Proxy-Instance header added to every response (defaults to the hostname)"`
+	ReadTimeoutMs          int64    `json:"readTimeoutMs" s-cli:"server-read-timeout-ms" s-def:"30000" s-desc:"Max time to read an entire request, including the body (0 = no timeout)"`
+	WriteTimeoutMs         int64    `json:"writeTimeoutMs" s-cli:"server-write-timeout-ms" s-def:"30000" s-desc:"Max time to write a response (0 = no timeout)"`
+	IdleTimeoutMs          int64    `json:"idleTimeoutMs" s-cli:"server-idle-timeout-ms" s-def:"60000" s-desc:"Max time to keep idle keep-alive connections open (0 = same as the read timeout)"`
+	MaxIngestBodySizeBytes int64    `json:"maxIngestBodySizeBytes" s-cli:"max-ingest-body-size-bytes" s-def:"26214400" s-desc:"Max size of request bodies accepted on impressions/events/metrics endpoints (and of gRPC ingest messages). Larger ones get a 413 (0 = unlimited)"`
+	SegmentChangesMaxKeys  int64    `json:"segmentChangesMaxKeys" s-cli:"segment-changes-max-keys" s-def:"0" s-desc:"Max number of keys per segmentChanges response. Larger segments are paginated using the till/since cursor (0 = unlimited)"`
+	MySegmentsBulkMaxKeys  int64    `json:"mySegmentsBulkMaxKeys" s-cli:"my-segments-bulk-max-keys" s-def:"1000" s-desc:"Max number of keys accepted in a single POST /mySegmentsBulk request. The request body is capped to 1KB per key"`
+	CacheControlMaxAgeSecs int64    `json:"cacheControlMaxAgeSecs" s-cli:"cache-control-max-age-secs" s-def:"0" s-desc:"max-age to send in the Cache-Control header of splitChanges/segmentChanges responses, so that CDNs can cache them (0 = disabled)"`
+	StreamingEnabled       bool     `json:"streamingEnabled" s-cli:"server-streaming-enabled" s-def:"false" s-desc:"Let SDKs connect to this proxy's /sse endpoint to be notified of feature flag & segment changes as soon as the proxy applies them"`
+	StreamingTokenSecret   string   `json:"streamingTokenSecret" s-cli:"server-streaming-token-secret" s-def:"" s-desc:"Secret to sign the streaming tokens handed to SDKs. Must be the same on every replica behind a load balancer (empty = random key per instance)"`
+	AccessLogEnabled       bool     `json:"accessLogEnabled" s-cli:"access-log-enabled" s-def:"false" s-desc:"Log one line per request served to SDKs (method, path, status, latency & SDK headers)"`
+	AccessLogSamplePercent int64    `json:"accessLogSamplePercent" s-cli:"access-log-sample-percent" s-def:"100" s-desc:"Percentage of requests to include in the access log (0-100)"`
+	AccessLogLevel         string   `json:"accessLogLevel" s-cli:"access-log-level" s-def:"info" s-desc:"Level to write access log lines at (info|debug)"`
+	TLS                    conf.TLS `json:"tls" s-nested:"true" s-cli-prefix:"server"`
+	CORS                   CORS     `json:"cors" s-nested:"true"`
+	GRPC                   GRPC     `json:"grpc" s-nested:"true"`
+}
+
+// GRPC ingest server configuration options
+type GRPC struct {
+	Enabled bool  `json:"enabled" s-cli:"grpc-ingest-enabled" s-def:"false" s-desc:"Accept impressions & events over gRPC, as protobuf messages defined in splitio/proxy/grpcingest/ingestpb/ingest.proto (on top of the HTTP endpoints)"`
+	Port    int64 `json:"port" s-cli:"grpc-ingest-port" s-def:"3002" s-desc:"Port to listen for incoming gRPC ingest connections (on the same host as the proxy server)"`
+}
+
+// CORS configuration options for browser SDKs connecting to the proxy
+type CORS struct {
+	Enabled          bool     `json:"enabled" s-cli:"cors-enabled" s-def:"true" s-desc:"Emit CORS headers & answer preflight requests on proxy endpoints"`
+	AllowedOrigins   []string `json:"allowedOrigins" s-cli:"cors-allowed-origins" s-def:"*" s-desc:"Origins allowed to make cross-origin requests ('*' = any)"`
+	AllowedMethods   []string `json:"allowedMethods" s-cli:"cors-allowed-methods" s-def:"GET,POST,PUT,PATCH,DELETE,HEAD,OPTIONS" s-desc:"Methods allowed in cross-origin requests"`
+	AllowedHeaders   []string `json:"allowedHeaders" s-cli:"cors-allowed-headers" s-def:"" s-desc:"Headers allowed in cross-origin requests, on top of the ones sent by Split SDKs"`
+	AllowCredentials bool     `json:"allowCredentials" s-cli:"cors-allow-credentials" s-def:"false" s-desc:"Allow cross-origin requests with credentials (requires explicit origins)"`
+	MaxAgeSecs       int64    `json:"maxAgeSecs" s-cli:"cors-max-age-secs" s-def:"43200" s-desc:"How long browsers can cache preflight responses"`
 }
 
 // Storage configuration options
@@ -59,31 +116,53 @@ type Storage struct {
 
 // Volatile storage configuration options
 type Volatile struct {
+	SplitChangesCacheSize  int64 `json:"splitChangesCacheSize" s-cli:"split-changes-cache-size" s-def:"100" s-desc:"How many computed splitChanges payloads (one per since/flag sets combination) to keep in memory until the next update (0 = disabled)"`
+	MaxSplitChangesRecipes int64 `json:"maxSplitChangesRecipes" s-cli:"max-split-changes-recipes" s-def:"0" s-desc:"Max number of feature flag change recipes to retain for serving arbitrary since values. Older ones are evicted & fetched from upstream when requested (0 = unlimited)"`
 }
 
 // Persistent storage configuration options
 type Persistent struct {
-	Filename string `json:"filename" s-cli:"persistent-storage-fn" s-def:"" s-desc:"Where to store flags & user-generated data. (Default: temporary file)"`
+	Filename         string `json:"filename" s-cli:"persistent-storage-fn" s-def:"" s-desc:"Where to store flags & user-generated data. Data in this file is restored on startup. Each proxy instance requires its own file. (Default: temporary file)"`
+	CompactOnStartup bool   `json:"compactOnStartup" s-cli:"persistent-storage-compact-on-startup" s-def:"false" s-desc:"Rewrite the persistent storage file on startup to reclaim space left behind by deleted items"`
+	Disabled         bool   `json:"disabled" s-cli:"persistent-storage-disabled" s-def:"false" s-desc:"Keep feature flags in memory only, relying on Split servers (or a seed file) on every startup. Segments are still kept in a temporary file"`
+}
+
+// Replica configuration options
+type Replica struct {
+	Enabled      bool   `json:"enabled" s-cli:"replica-mode" s-def:"false" s-desc:"Serve feature flags & segments from a persistent storage file replicated from another proxy, without connecting to Split servers. Data posted by SDKs is discarded & health monitors are not started"`
+	SourceFile   string `json:"sourceFile" s-cli:"replica-source-fn" s-def:"" s-desc:"Persistent storage file replicated from the primary proxy"`
+	ReloadRateMs int64  `json:"reloadRateMs" s-cli:"replica-reload-rate-ms" s-def:"10000" s-desc:"How often to check the replicated file for changes"`
 }
 
 // Sync configuration options
 type Sync struct {
 	SplitRefreshRateMs   int64        `json:"splitRefreshRateMs" s-cli:"split-refresh-rate-ms" s-def:"60000" s-desc:"How often to refresh feature flags"`
 	SegmentRefreshRateMs int64        `json:"segmentRefreshRateMs" s-cli:"segment-refresh-rate-ms" s-def:"60000" s-desc:"How often to refresh segments"`
+	ImpressionsMode      string       `json:"impressionsMode" s-cli:"impressions-mode" s-def:"" s-desc:"Impressions mode applied to impressions posted by SDKs (optimized/debug). Each SDK's own mode is respected if empty"`
 	Advanced             AdvancedSync `json:"advanced" s-nested:"true"`
 }
 
 // AdvancedSync configuration options
 type AdvancedSync struct {
-	StreamingEnabled      bool  `json:"streamingEnabled" s-cli:"streaming-enabled" s-def:"true" s-desc:"Enable/disable streaming functionality"`
-	HTTPTimeoutMs         int64 `json:"httpTimeoutMs" s-cli:"http-timeout-ms" s-def:"30000" s-desc:"Total http request timeout"`
-	ImpressionsBuffer     int64 `json:"impressionsBufferSize" s-cli:"impressions-buffer-size" s-def:"500" s-dec:"How many impressions bulks to keep in memory"`
-	EventsBuffer          int64 `json:"eventsBufferSize" s-cli:"events-buffer-size" s-def:"500" s-dec:"How many events bulks to keep in memory"`
-	TelemetryBuffer       int64 `json:"telemetryBufferSize" s-cli:"telemetry-buffer-size" s-def:"500" s-dec:"How many telemetry bulks to keep in memory"`
-	ImpressionsWorkers    int64 `json:"impressionsWorkers" s-cli:"impressions-workers" s-def:"10" s-desc:"#workers to forward impressions to Split servers"`
-	EventsWorkers         int64 `json:"eventsWorkers" s-cli:"events-workers" s-def:"10" s-desc:"#workers to forward events to Split servers"`
-	TelemetryWorkers      int64 `json:"telemetryWorkers" s-cli:"telemetry-workers" s-def:"10" s-desc:"#workers to forward telemetry to Split servers"`
-	InternalMetricsRateMs int64 `json:"internalTelemetryRateMs" s-cli:"internal-metrics-rate-ms" s-def:"3600000" s-desc:"How often to send internal metrics"`
+	StreamingEnabled            bool     `json:"streamingEnabled" s-cli:"streaming-enabled" s-def:"true" s-desc:"Enable/disable streaming functionality"`
+	HTTPTimeoutMs               int64    `json:"httpTimeoutMs" s-cli:"http-timeout-ms" s-def:"30000" s-desc:"Total http request timeout"`
+	ImpressionsBuffer           int64    `json:"impressionsBufferSize" s-cli:"impressions-buffer-size" s-def:"500" s-dec:"How many impressions bulks to keep in memory"`
+	EventsBuffer                int64    `json:"eventsBufferSize" s-cli:"events-buffer-size" s-def:"500" s-dec:"How many events bulks to keep in memory"`
+	TelemetryBuffer             int64    `json:"telemetryBufferSize" s-cli:"telemetry-buffer-size" s-def:"500" s-dec:"How many telemetry bulks to keep in memory"`
+	ImpressionsWorkers          int64    `json:"impressionsWorkers" s-cli:"impressions-workers" s-def:"10" s-desc:"#workers to forward impressions to Split servers"`
+	ImpressionsFlushRateMs      int64    `json:"impressionsFlushRateMs" s-cli:"impressions-flush-rate-ms" s-def:"1000" s-desc:"Max time impression bulks posted by SDKs are buffered before being forwarded to Split servers (whole seconds, min 1s)"`
+	ImpressionsFlushSize        int64    `json:"impressionsFlushSize" s-cli:"impressions-flush-size" s-def:"0" s-desc:"Buffered impression bulks that trigger an early flush (0 = when the buffer is full)"`
+	EventsWorkers               int64    `json:"eventsWorkers" s-cli:"events-workers" s-def:"10" s-desc:"#workers to forward events to Split servers"`
+	TelemetryWorkers            int64    `json:"telemetryWorkers" s-cli:"telemetry-workers" s-def:"10" s-desc:"#workers to forward telemetry to Split servers"`
+	ImpressionObserverSize      int64    `json:"impressionObserverCacheSize" s-cli:"impression-observer-cache-size" s-def:"0" s-desc:"How many recently seen impressions to track in order to drop duplicates posted by SDKs in optimized mode, counting them instead (0 = disabled)"`
+	InternalMetricsRateMs       int64    `json:"internalTelemetryRateMs" s-cli:"internal-metrics-rate-ms" s-def:"3600000" s-desc:"How often to send internal metrics"`
+	WarnUnsupportedMatchers     bool     `json:"warnUnsupportedMatchers" s-cli:"warn-unsupported-matchers" s-def:"false" s-desc:"Log a warning when feature flags use matcher types not supported by this version"`
+	ShutdownGracePeriodMs       int64    `json:"shutdownGracePeriodMs" s-cli:"shutdown-grace-period-ms" s-def:"10000" s-desc:"Max time to wait for buffered impressions/events/telemetry to be flushed when shutting down (shared by all tasks)"`
+	UpstreamBreakerThreshold    int64    `json:"upstreamBreakerThreshold" s-cli:"upstream-breaker-threshold" s-def:"5" s-desc:"Consecutive failed upstream feature flag fetches after which they're short-circuited, serving cached data instead (0 = disabled)"`
+	UpstreamBreakerCooldownMs   int64    `json:"upstreamBreakerCooldownMs" s-cli:"upstream-breaker-cooldown-ms" s-def:"30000" s-desc:"How long to short-circuit upstream fetches before probing Split servers again"`
+	KnownMatchers               []string `json:"knownMatchers" s-cli:"known-matchers" s-def:"" s-desc:"Matcher types considered supported when checking feature flags (default: all matchers supported by this version)"`
+	SegmentSyncConcurrency      int64    `json:"segmentSyncConcurrency" s-cli:"segment-sync-concurrency" s-def:"10" s-desc:"Max segments fetched in parallel when synchronizing all of them (ie: on startup)"`
+	UnreferencedSegmentsGraceMs int64    `json:"unreferencedSegmentsGraceMs" s-cli:"unreferenced-segments-grace-ms" s-def:"3600000" s-desc:"How long to keep segments no longer referenced by any feature flag before dropping them from memory & disk (0 = never)"`
 }
 
 // Healthcheck configuration options
@@ -98,6 +177,17 @@ type HealthcheckDependecines struct {
 
 // Observability configuration options
 type Observability struct {
-	TimeSliceWidthSecs int64 `json:"timeSliceWidthSecs" s-cli:"observability-time-slice-width-secs" s-def:"300" s-desc:"time slice size in seconds"`
-	MaxTimeSliceCount  int64 `json:"maxTimeSliceCount" s-cli:"observability-time-slice-max-count" s-def:"100" s-desc:"max time slices to keep in memory before rotating"`
+	TimeSliceWidthSecs int64  `json:"timeSliceWidthSecs" s-cli:"observability-time-slice-width-secs" s-def:"300" s-desc:"time slice size in seconds"`
+	MaxTimeSliceCount  int64  `json:"maxTimeSliceCount" s-cli:"observability-time-slice-max-count" s-def:"100" s-desc:"max time slices to keep in memory before rotating (up to 10000)"`
+	Granularity        string `json:"granularity" s-cli:"observability-time-slice-granularity" s-def:"" s-desc:"Timeslice width preset (minute/hour/day). Overrides the explicit width if set"`
+	StatsD             StatsD `json:"statsd" s-nested:"true"`
+}
+
+// StatsD exporter configuration options
+type StatsD struct {
+	Enabled    bool     `json:"enabled" s-cli:"statsd-enabled" s-def:"false" s-desc:"Periodically push proxy endpoint & storage metrics to a StatsD/DogStatsD agent"`
+	Address    string   `json:"address" s-cli:"statsd-address" s-def:"127.0.0.1:8125" s-desc:"host:port of the StatsD agent (UDP)"`
+	Prefix     string   `json:"prefix" s-cli:"statsd-prefix" s-def:"split.proxy" s-desc:"Prefix prepended to every metric name"`
+	Tags       []string `json:"tags" s-cli:"statsd-tags" s-def:"" s-desc:"DogStatsD tags added to every metric (ie: env:prod,region:us-east-1)"`
+	PushRateMs int64    `json:"pushRateMs" s-cli:"statsd-push-rate-ms" s-def:"10000" s-desc:"How often to push metrics (must be a whole number of seconds)"`
 }
@@ -0,0 +1,28 @@
+package conf
+
+import (
+	"testing"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/conf"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/conf/conftest"
+)
+
+func TestRedactedConfigDoesNotLeakSecrets(t *testing.T) {
+	var cfg Main
+	conf.PopulateDefaults(&cfg)
+	cfg.Apikey = "someapikey0000000000000000000001"
+	cfg.Admin.Password = "adminpassword123"
+	cfg.Admin.Token = "admintoken123"
+	cfg.Integrations.Slack.Webhook = "https://hooks.slack.com/services/secret"
+	cfg.Integrations.ImpressionListener.Endpoint = "https://listener.local/impressions?token=secret"
+	cfg.Server.ClientApikeys = []string{"clientapikey000000000000000000001"}
+	cfg.Server.StreamingTokenSecret = "streamingsecret123"
+
+	conftest.AssertSecretsNotLogged(t, cfg.Redacted(), "someapikey0000000000000000000001", "clientapikey000000000000000000001",
+		"adminpassword123", "admintoken123", "https://hooks.slack.com/services/secret", "https://listener.local/impressions?token=secret",
+		"streamingsecret123")
+
+	if cfg.Apikey != "someapikey0000000000000000000001" {
+		t.Error("the original config should not be modified")
+	}
+}
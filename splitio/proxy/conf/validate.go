@@ -0,0 +1,87 @@
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gin-contrib/cors"
+	cconf "github.com/splitio/go-split-commons/v6/conf"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/conf"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+)
+
+// ErrCORSCredentialsWithAnyOrigin is returned when credentials are allowed for every origin, which browsers reject
+var ErrCORSCredentialsWithAnyOrigin = errors.New("CORS credentials cannot be allowed when all origins ('*') are")
+
+// Validate checks the settings that cannot be enforced by the parser alone, so that an invalid config is
+// reported both by --check-config & on startup
+func (m *Main) Validate() error {
+	if err := conf.ValidateUpstreamURLs(&m.Upstream); err != nil {
+		return err
+	}
+
+	if m.Replica.Enabled && m.Replica.SourceFile == "" {
+		return errors.New("a replicated file must be supplied when running in replica mode")
+	}
+
+	if m.Replica.Enabled && m.Initialization.SeedFile != "" {
+		return errors.New("a seed file cannot be used in replica mode")
+	}
+
+	if m.Storage.Persistent.Disabled && (m.Replica.Enabled || m.Initialization.Snapshot != "" || m.Storage.Persistent.Filename != "") {
+		return errors.New("persistent storage cannot be disabled when running in replica mode, restoring a snapshot or using a persistent storage file")
+	}
+
+	if err := m.Server.CORS.Validate(); err != nil {
+		return fmt.Errorf("invalid CORS config: %w", err)
+	}
+
+	if level := strings.ToLower(m.Server.AccessLogLevel); level != "info" && level != "debug" {
+		return fmt.Errorf("invalid access log level '%s'. Must be one of: info, debug", m.Server.AccessLogLevel)
+	}
+
+	if m.Server.GRPC.Enabled && (m.Server.GRPC.Port <= 0 || m.Server.GRPC.Port == m.Server.Port || m.Server.GRPC.Port == m.Admin.Port) {
+		return fmt.Errorf("invalid gRPC ingest port %d. Must be positive & differ from the proxy & admin ones", m.Server.GRPC.Port)
+	}
+
+	if _, err := storage.ResolveTimeSliceConfig(m.Observability.Granularity, m.Observability.TimeSliceWidthSecs, m.Observability.MaxTimeSliceCount); err != nil {
+		return fmt.Errorf("invalid observability config: %w", err)
+	}
+
+	switch m.Sync.ImpressionsMode {
+	case "", cconf.ImpressionsModeOptimized, cconf.ImpressionsModeDebug:
+	default:
+		return fmt.Errorf("invalid impressions mode '%s'. Must be one of: optimized, debug", m.Sync.ImpressionsMode)
+	}
+
+	return nil
+}
+
+// Validate returns an error if the allowed origins cannot be applied
+func (c *CORS) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	origins, allOrigins := CORSOrigins(c.AllowedOrigins)
+	if allOrigins && c.AllowCredentials {
+		return ErrCORSCredentialsWithAnyOrigin
+	}
+	return cors.Config{AllowAllOrigins: allOrigins, AllowOrigins: origins}.Validate()
+}
+
+// CORSOrigins drops empty origins (which is what an empty comma-separated list is parsed into) and reports
+// whether every origin is allowed ('*')
+func CORSOrigins(origins []string) ([]string, bool) {
+	toRet := make([]string, 0, len(origins))
+	for _, origin := range origins {
+		if origin == "*" {
+			return nil, true
+		}
+		if origin != "" {
+			toRet = append(toRet, origin)
+		}
+	}
+	return toRet, false
+}
@@ -0,0 +1,66 @@
+package conf
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/conf"
+)
+
+func TestValidate(t *testing.T) {
+	cases := map[string]func(cfg *Main){
+		"upstream url":         func(cfg *Main) { cfg.Upstream.SdkURL = "not-a-url" },
+		"replica without file": func(cfg *Main) { cfg.Replica.Enabled = true },
+		"replica with seed": func(cfg *Main) {
+			cfg.Replica.Enabled, cfg.Replica.SourceFile, cfg.Initialization.SeedFile = true, "replica.db", "seed.json"
+		},
+		"no persistence & replica": func(cfg *Main) {
+			cfg.Storage.Persistent.Disabled, cfg.Replica.Enabled, cfg.Replica.SourceFile = true, true, "replica.db"
+		},
+		"no persistence & file": func(cfg *Main) { cfg.Storage.Persistent.Disabled, cfg.Storage.Persistent.Filename = true, "proxy.db" },
+		"cors": func(cfg *Main) {
+			cfg.Server.CORS.AllowedOrigins, cfg.Server.CORS.AllowCredentials = []string{"*"}, true
+		},
+		"access log level": func(cfg *Main) { cfg.Server.AccessLogLevel = "warning" },
+		"grpc port":        func(cfg *Main) { cfg.Server.GRPC.Enabled, cfg.Server.GRPC.Port = true, cfg.Server.Port },
+		"observability":    func(cfg *Main) { cfg.Observability.Granularity = "week" },
+		"impressions mode": func(cfg *Main) { cfg.Sync.ImpressionsMode = "none" },
+	}
+
+	var cfg Main
+	conf.PopulateDefaults(&cfg)
+	if err := cfg.Validate(); err != nil {
+		t.Error("the default config should be valid. got: ", err)
+	}
+
+	for name, mutate := range cases {
+		var cfg Main
+		conf.PopulateDefaults(&cfg)
+		mutate(&cfg)
+		if cfg.Validate() == nil {
+			t.Errorf("%s: an error should have been returned", name)
+		}
+	}
+}
+
+func TestValidateCORS(t *testing.T) {
+	cors := CORS{Enabled: true, AllowedOrigins: []string{"*"}, AllowCredentials: true}
+	if err := cors.Validate(); !errors.Is(err, ErrCORSCredentialsWithAnyOrigin) {
+		t.Error("credentials should not be allowed along with every origin. got: ", err)
+	}
+
+	cors.AllowedOrigins = []string{"https://app.example.com", ""}
+	if err := cors.Validate(); err != nil {
+		t.Error("explicit origins should allow credentials. got: ", err)
+	}
+
+	cors.AllowedOrigins = []string{"app.example.com"}
+	if cors.Validate() == nil {
+		t.Error("origins without a scheme should be rejected")
+	}
+
+	cors.Enabled = false
+	if err := cors.Validate(); err != nil {
+		t.Error("a disabled CORS config should not be validated. got: ", err)
+	}
+}
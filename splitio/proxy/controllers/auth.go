@@ -4,14 +4,21 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/streaming"
 )
 
 // AuthServerController bundles all request handler for sdk-server apis
-type AuthServerController struct{}
+type AuthServerController struct {
+	logger logging.LoggerInterface
+	tokens *streaming.TokenIssuer
+}
 
-// NewAuthServerController instantiates a new sdk server controller
-func NewAuthServerController() *AuthServerController {
-	return &AuthServerController{}
+// NewAuthServerController instantiates a new sdk server controller.
+// If a token issuer is supplied, SDKs get a token to connect to the proxy's streaming endpoint
+func NewAuthServerController(logger logging.LoggerInterface, tokens *streaming.TokenIssuer) *AuthServerController {
+	return &AuthServerController{logger: logger, tokens: tokens}
 }
 
 // Register mounts the sdk-server endpoints onto the supplied router
@@ -20,7 +27,19 @@ func (c *AuthServerController) Register(router gin.IRouter) {
 	router.GET("/v2/auth", c.AuthV1)
 }
 
-// AuthV1 always returns pushEnabled = false and no token
+// AuthV1 returns pushEnabled = false and no token, unless streaming is enabled. Client-side SDKs send their
+// user keys, for which mySegments channels are included in the token
 func (c *AuthServerController) AuthV1(ctx *gin.Context) {
-	ctx.JSON(http.StatusOK, gin.H{"pushEnabled": false, "token": ""})
+	if c.tokens == nil {
+		ctx.JSON(http.StatusOK, gin.H{"pushEnabled": false, "token": ""})
+		return
+	}
+
+	token, err := c.tokens.Issue(ctx.QueryArray("users"))
+	if err != nil {
+		c.logger.Error("error issuing streaming token. Push will be disabled for this SDK: ", err)
+		ctx.JSON(http.StatusOK, gin.H{"pushEnabled": false, "token": ""})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"pushEnabled": true, "token": token})
 }
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-split-commons/v6/provisional/strategy"
+	"github.com/splitio/go-split-commons/v6/util"
+)
+
+// impressionsDeduplicator collapses impressions already posted (by any SDK) within the same hour, the same way
+// the optimized impressions mode does within a single SDK instance. Dropped impressions are counted instead
+type impressionsDeduplicator struct {
+	observer strategy.ImpressionObserver
+}
+
+func newImpressionsDeduplicator(observer strategy.ImpressionObserver) *impressionsDeduplicator {
+	if observer == nil {
+		return nil
+	}
+	return &impressionsDeduplicator{observer: observer}
+}
+
+// apply returns the impressions payload without duplicates (nil if all of them were dropped),
+// and the count payload for the dropped ones (nil if none was dropped)
+func (d *impressionsDeduplicator) apply(raw []byte) ([]byte, []byte, error) {
+	var groups []dtos.ImpressionsDTO
+	if err := json.Unmarshal(raw, &groups); err != nil {
+		return nil, nil, fmt.Errorf("error parsing impressions payload: %w", err)
+	}
+
+	type countKey struct {
+		feature   string
+		timeFrame int64
+	}
+	counts := make(map[countKey]int64)
+	var countOrder []countKey
+
+	kept := make([]dtos.ImpressionsDTO, 0, len(groups))
+	for _, group := range groups {
+		impressions := make([]dtos.ImpressionDTO, 0, len(group.KeyImpressions))
+		for _, impression := range group.KeyImpressions {
+			previous, _ := d.observer.TestAndSet(group.TestName, &dtos.Impression{
+				KeyName:      impression.KeyName,
+				BucketingKey: impression.BucketingKey,
+				FeatureName:  group.TestName,
+				Treatment:    impression.Treatment,
+				Label:        impression.Label,
+				ChangeNumber: impression.ChangeNumber,
+				Time:         impression.Time,
+			})
+
+			timeFrame := util.TruncateTimeFrame(impression.Time * int64(time.Millisecond))
+			if previous != 0 && util.TruncateTimeFrame(previous*int64(time.Millisecond)) == timeFrame {
+				key := countKey{feature: group.TestName, timeFrame: timeFrame}
+				if _, ok := counts[key]; !ok {
+					countOrder = append(countOrder, key)
+				}
+				counts[key]++
+				continue
+			}
+
+			if impression.Pt == 0 {
+				impression.Pt = previous
+			}
+			impressions = append(impressions, impression)
+		}
+
+		if len(impressions) > 0 {
+			kept = append(kept, dtos.ImpressionsDTO{TestName: group.TestName, KeyImpressions: impressions})
+		}
+	}
+
+	if len(countOrder) == 0 {
+		return raw, nil, nil
+	}
+
+	perFeature := make([]dtos.ImpressionsInTimeFrameDTO, 0, len(countOrder))
+	for _, key := range countOrder {
+		perFeature = append(perFeature, dtos.ImpressionsInTimeFrameDTO{FeatureName: key.feature, TimeFrame: key.timeFrame, RawCount: counts[key]})
+	}
+
+	countsPayload, err := json.Marshal(dtos.ImpressionsCountDTO{PerFeature: perFeature})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error serializing impression counts: %w", err)
+	}
+
+	if len(kept) == 0 {
+		return nil, countsPayload, nil
+	}
+
+	impressionsPayload, err := json.Marshal(kept)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error serializing deduplicated impressions: %w", err)
+	}
+	return impressionsPayload, countsPayload, nil
+}
@@ -2,15 +2,22 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"sync/atomic"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v6/conf"
 	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-split-commons/v6/provisional/strategy"
 	"github.com/splitio/go-toolkit/v5/logging"
 
 	"github.com/splitio/split-synchronizer/v5/splitio/common/impressionlistener"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/internal"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/tasks"
 )
 
@@ -21,7 +28,12 @@ type EventsServerController struct {
 	impressionCountSink tasks.DeferredRecordingTask
 	eventsSink          tasks.DeferredRecordingTask
 	listener            impressionlistener.ImpressionBulkListener
+	listenerSlots       chan struct{}
+	listenerDrops       listenerDropsLog
+	listenerTelemetry   storage.ListenerDropsTelemetry
 	apikeyValidator     func(string) bool
+	dedup               *impressionsDeduplicator
+	impressionsMode     string
 }
 
 // NewEventsServerController returns a new events server controller
@@ -32,14 +44,26 @@ func NewEventsServerController(
 	eventsSink tasks.DeferredRecordingTask,
 	listener impressionlistener.ImpressionBulkListener,
 	apikeyValidator func(string) bool,
+	listenerConcurrency int,
+	impressionObserver strategy.ImpressionObserver,
+	impressionsMode string,
+	listenerTelemetry storage.ListenerDropsTelemetry,
 ) *EventsServerController {
+	if listenerConcurrency < 1 {
+		listenerConcurrency = 1
+	}
+
 	return &EventsServerController{
 		logger:              logger,
 		impressionsSink:     impressionsSink,
 		impressionCountSink: impressionCountSink,
 		eventsSink:          eventsSink,
 		listener:            listener,
+		listenerSlots:       make(chan struct{}, listenerConcurrency),
 		apikeyValidator:     apikeyValidator,
+		dedup:               newImpressionsDeduplicator(impressionObserver),
+		impressionsMode:     impressionsMode,
+		listenerTelemetry:   listenerTelemetry,
 	}
 }
 
@@ -65,24 +89,22 @@ func (c *EventsServerController) Register(regular gin.IRouter, beacon gin.IRoute
 // TestImpressionsBulk endpoint accepts impression bulks
 func (c *EventsServerController) TestImpressionsBulk(ctx *gin.Context) {
 	metadata := metadataFromHeaders(ctx)
-	impressionsMode := parseImpressionsMode(ctx.Request.Header.Get("SplitSDKImpressionsMode"))
 	data, err := ioutil.ReadAll(ctx.Request.Body)
 	if err != nil {
 		c.logger.Error(err)
 		ctx.JSON(http.StatusInternalServerError, nil)
 		return
 	}
-	if c.listener != nil {
-		// if we have a listener, schedule a goroutine to convert these impressions and
-		// push them into the channel.
-		go c.submitImpressionsToListener(data, &metadata)
-	}
 
-	err = c.impressionsSink.Stage(internal.NewRawImpressions(metadata, impressionsMode, data))
+	err = c.StageImpressions(metadata, ctx.Request.Header.Get("SplitSDKImpressionsMode"), data)
 	if err != nil {
-		if err == tasks.ErrQueueFull {
+		var perr *PayloadError
+		switch {
+		case errors.As(err, &perr):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case err == tasks.ErrQueueFull:
 			ctx.AbortWithStatusJSON(500, "Impressions queue is full, please retry later.")
-		} else {
+		default:
 			ctx.AbortWithStatusJSON(500, "Unknown error when trying to push impressions into the staging queue")
 		}
 		return
@@ -90,6 +112,33 @@ func (c *EventsServerController) TestImpressionsBulk(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, nil)
 }
 
+// StageImpressions validates an impressions bulk, forwards it to the listener (if any) & pushes whatever is left after
+// deduplication into the staging queue. Errors caused by the contents of the bulk are returned as *PayloadError
+func (c *EventsServerController) StageImpressions(metadata dtos.Metadata, sdkImpressionsMode string, data []byte) error {
+	if err := validateImpressionsPayload(data, time.Now()); err != nil {
+		c.logger.Debug(fmt.Sprintf("rejecting malformed impressions bulk from SDK [%s]: %s", metadata.SDKVersion, err))
+		return &PayloadError{Err: err}
+	}
+
+	if c.listener != nil {
+		// if we have a listener, schedule a goroutine to convert these impressions and
+		// push them into the channel, as long as we're not already at the max number of concurrent submissions
+		c.scheduleListenerSubmission(data, &metadata)
+	}
+
+	return c.stageImpressions(metadata, c.resolveImpressionsMode(sdkImpressionsMode), data)
+}
+
+// stageImpressions pushes a validated impressions bulk into the staging queue, once deduplicated.
+// It's shared by every ingest path (bulk, beacon & grpc), so that none of them bypasses deduplication
+func (c *EventsServerController) stageImpressions(metadata dtos.Metadata, impressionsMode string, data []byte) error {
+	if data = c.deduplicate(data, metadata, impressionsMode); data == nil {
+		return nil // all impressions were duplicates
+	}
+
+	return c.impressionsSink.Stage(internal.NewRawImpressions(metadata, impressionsMode, data))
+}
+
 // TestImpressionsBeacon accepts beacon style posts with impressions payload
 func (c *EventsServerController) TestImpressionsBeacon(ctx *gin.Context) {
 	if ctx.Request.Body == nil {
@@ -119,7 +168,15 @@ func (c *EventsServerController) TestImpressionsBeacon(ctx *gin.Context) {
 		return
 	}
 
-	err = c.impressionsSink.Stage(internal.NewRawImpressions(dtos.Metadata{SDKVersion: body.Sdk, MachineIP: "NA", MachineName: "NA"}, "", body.Entries))
+	if err := validateImpressionsPayload(body.Entries, time.Now()); err != nil {
+		c.logger.Debug(fmt.Sprintf("rejecting malformed impressions beacon from SDK [%s]: %s", body.Sdk, err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// beacons don't include the sdk impressions mode, so they're only deduplicated if a mode is enforced
+	metadata := dtos.Metadata{SDKVersion: body.Sdk, MachineIP: "NA", MachineName: "NA"}
+	err = c.stageImpressions(metadata, c.impressionsMode, body.Entries)
 	if err != nil {
 		if err == tasks.ErrQueueFull {
 			ctx.AbortWithStatusJSON(500, "Impressions queue is full, please retry later.")
@@ -206,7 +263,7 @@ func (c *EventsServerController) EventsBulk(ctx *gin.Context) {
 		return
 	}
 
-	err = c.eventsSink.Stage(internal.NewRawEvents(metadata, data))
+	err = c.StageEvents(metadata, data)
 	if err != nil {
 		if err == tasks.ErrQueueFull {
 			ctx.AbortWithStatusJSON(500, "Events queue is full, please retry later.")
@@ -218,6 +275,11 @@ func (c *EventsServerController) EventsBulk(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, nil)
 }
 
+// StageEvents pushes an events bulk into the staging queue
+func (c *EventsServerController) StageEvents(metadata dtos.Metadata, data []byte) error {
+	return c.eventsSink.Stage(internal.NewRawEvents(metadata, data))
+}
+
 // EventsBulkBeacon accepts incoming event bulks in a beacon-style request
 func (c *EventsServerController) EventsBulkBeacon(ctx *gin.Context) {
 	if ctx.Request.Body == nil {
@@ -260,6 +322,50 @@ func (c *EventsServerController) EventsBulkBeacon(ctx *gin.Context) {
 // This is meant to be used with legacy telemetry endpoints
 func (c *EventsServerController) DummyAlwaysOk(ctx *gin.Context) {}
 
+func (c *EventsServerController) scheduleListenerSubmission(raw []byte, metadata *dtos.Metadata) {
+	select {
+	case c.listenerSlots <- struct{}{}:
+	default:
+		c.recordListenerDrop("too many concurrent submissions")
+		return
+	}
+
+	go func() {
+		defer func() { <-c.listenerSlots }()
+		c.submitImpressionsToListener(raw, metadata)
+	}()
+}
+
+func (c *EventsServerController) recordListenerDrop(reason string) {
+	if c.listenerTelemetry != nil {
+		c.listenerTelemetry.IncrListenerDrops()
+	}
+
+	if dropped, ok := c.listenerDrops.add(time.Now()); ok {
+		c.logger.Warning(fmt.Sprintf("%d impression bulks not forwarded to the listener in the last %s. Last reason: %s",
+			dropped, listenerDropsLogPeriod, reason))
+	}
+}
+
+// drops are logged at most once per period, to avoid flooding the logs when the listener cannot keep up
+const listenerDropsLogPeriod = time.Minute
+
+// listenerDropsLog aggregates the impression bulks dropped since the last time they were logged
+type listenerDropsLog struct {
+	dropped int64
+	lastLog int64 // unix nanos
+}
+
+// add accounts for a dropped bulk & returns the number of drops to be logged if it's time to do so
+func (l *listenerDropsLog) add(now time.Time) (int64, bool) {
+	atomic.AddInt64(&l.dropped, 1)
+	last := atomic.LoadInt64(&l.lastLog)
+	if now.UnixNano()-last < int64(listenerDropsLogPeriod) || !atomic.CompareAndSwapInt64(&l.lastLog, last, now.UnixNano()) {
+		return 0, false
+	}
+	return atomic.SwapInt64(&l.dropped, 0), true
+}
+
 func (c *EventsServerController) submitImpressionsToListener(raw []byte, metadata *dtos.Metadata) {
 	var parsed []dtos.ImpressionsDTO
 	err := json.Unmarshal(raw, &parsed)
@@ -288,7 +394,82 @@ func (c *EventsServerController) submitImpressionsToListener(raw []byte, metadat
 		})
 	}
 
-	c.listener.Submit(forListener, metadata)
+	if err := c.listener.Submit(forListener, metadata); err != nil {
+		c.recordListenerDrop(err.Error())
+	}
+}
+
+// impressions timestamped further than this in the future are considered malformed
+const maxImpressionClockSkew = 24 * time.Hour
+
+// resolveImpressionsMode returns the impressions mode enforced by the proxy if any, or the one reported by the sdk
+func (c *EventsServerController) resolveImpressionsMode(sdkMode string) string {
+	if c.impressionsMode != "" {
+		return c.impressionsMode
+	}
+	return parseImpressionsMode(sdkMode)
+}
+
+// deduplicate drops impressions already seen when in optimized mode, staging their counts instead.
+// Returns the payload to forward, or nil if there's nothing left. Impressions in debug mode are all forwarded
+func (c *EventsServerController) deduplicate(data []byte, metadata dtos.Metadata, impressionsMode string) []byte {
+	if c.dedup == nil || impressionsMode != conf.ImpressionsModeOptimized {
+		return data
+	}
+
+	deduped, counts, err := c.dedup.apply(data)
+	if err != nil {
+		c.logger.Error("error deduplicating impressions. forwarding them as is: ", err)
+		return data
+	}
+
+	if counts != nil {
+		if err := c.impressionCountSink.Stage(internal.NewRawImpressionCounts(metadata, counts)); err != nil {
+			c.logger.Error("error staging counts of deduplicated impressions: ", err)
+		}
+	}
+	return deduped
+}
+
+// validateImpressionsPayload checks that an impressions bulk has the expected structure before accepting it,
+// so that malformed payloads are rejected upfront instead of failing when being posted to Split servers
+func validateImpressionsPayload(raw []byte, now time.Time) error {
+	var parsed []dtos.ImpressionsDTO
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return fmt.Errorf("invalid impressions payload: %w", err)
+	}
+
+	maxTime := now.Add(maxImpressionClockSkew).UnixMilli()
+	for gIdx, group := range parsed {
+		if group.TestName == "" {
+			return fmt.Errorf("impressions group #%d has no feature flag name", gIdx)
+		}
+
+		for iIdx, impression := range group.KeyImpressions {
+			switch {
+			case impression.KeyName == "":
+				return fmt.Errorf("impression #%d for feature flag '%s' has no key", iIdx, group.TestName)
+			case impression.Time <= 0 || impression.Time > maxTime:
+				return fmt.Errorf("impression #%d for feature flag '%s' has an invalid timestamp: %d", iIdx, group.TestName, impression.Time)
+			}
+		}
+	}
+	return nil
+}
+
+// PayloadError is returned when a bulk is rejected because of its contents
+type PayloadError struct {
+	Err error
+}
+
+// Error returns the reason why the bulk was rejected
+func (e *PayloadError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *PayloadError) Unwrap() error {
+	return e.Err
 }
 
 // private dtos
@@ -3,18 +3,24 @@ package controllers
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-split-commons/v6/provisional/strategy"
 	"github.com/splitio/go-toolkit/v5/logging"
 	"github.com/splitio/split-synchronizer/v5/splitio/common/impressionlistener"
 	ilMock "github.com/splitio/split-synchronizer/v5/splitio/common/impressionlistener/mocks"
 	mw "github.com/splitio/split-synchronizer/v5/splitio/proxy/controllers/middleware"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/internal"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/tasks/mocks"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestPostImpressionsbulk(t *testing.T) {
@@ -80,6 +86,10 @@ func TestPostImpressionsbulk(t *testing.T) {
 			},
 		},
 		apikeyValidator.IsValid,
+		10,
+		nil,
+		"",
+		nil,
 	)
 	controller.Register(group, group)
 
@@ -154,6 +164,10 @@ func TestPostEventsBulk(t *testing.T) {
 		}, // events
 		&ilMock.ImpressionBulkListenerMock{},
 		apikeyValidator.IsValid,
+		10,
+		nil,
+		"",
+		nil,
 	)
 	controller.Register(group, group)
 
@@ -213,6 +227,10 @@ func TestPostImpressionsCounts(t *testing.T) {
 		&mocks.MockDeferredRecordingTask{}, // events
 		&ilMock.ImpressionBulkListenerMock{},
 		apikeyValidator.IsValid,
+		10,
+		nil,
+		"",
+		nil,
 	)
 	controller.Register(group, group)
 
@@ -255,6 +273,10 @@ func TestPostLegacyMetrics(t *testing.T) {
 		&mocks.MockDeferredRecordingTask{}, // events
 		&ilMock.ImpressionBulkListenerMock{},
 		apikeyValidator.IsValid,
+		10,
+		nil,
+		"",
+		nil,
 	)
 	controller.Register(group, group)
 
@@ -368,6 +390,10 @@ func TestPostBeaconImpressionsbulk(t *testing.T) {
 			},
 		},
 		apikeyValidator.IsValid,
+		10,
+		nil,
+		"",
+		nil,
 	)
 	controller.Register(group, group)
 
@@ -443,6 +469,10 @@ func TestPostBeaconEventsBulk(t *testing.T) {
 		}, // events
 		&ilMock.ImpressionBulkListenerMock{},
 		apikeyValidator.IsValid,
+		10,
+		nil,
+		"",
+		nil,
 	)
 	controller.Register(group, group)
 
@@ -503,6 +533,10 @@ func TestPostBeaconImpressionsCounts(t *testing.T) {
 		&mocks.MockDeferredRecordingTask{}, // events
 		&ilMock.ImpressionBulkListenerMock{},
 		apikeyValidator.IsValid,
+		10,
+		nil,
+		"",
+		nil,
 	)
 	controller.Register(group, group)
 
@@ -529,3 +563,262 @@ func TestPostBeaconImpressionsCounts(t *testing.T) {
 		t.Error("Status code should be 200 and is ", resp.Code)
 	}
 }
+
+func TestImpressionListenerSubmissionsAreBounded(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	release := make(chan struct{})
+	var submitted int64
+	listener := &ilMock.ImpressionBulkListenerMock{
+		SubmitCall: func(imps []impressionlistener.ImpressionsForListener, metadata *dtos.Metadata) error {
+			<-release
+			atomic.AddInt64(&submitted, 1)
+			return nil
+		},
+	}
+
+	var drops storage.ListenerDrops
+	group := router.Group("/api")
+	controller := NewEventsServerController(
+		logging.NewLogger(nil),
+		&mocks.MockDeferredRecordingTask{StageCall: func(rawData interface{}) error { return nil }},
+		&mocks.MockDeferredRecordingTask{},
+		&mocks.MockDeferredRecordingTask{},
+		listener,
+		func(string) bool { return true },
+		1,
+		nil,
+		"",
+		&drops,
+	)
+	controller.Register(group, group)
+
+	post := func() {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/testImpressions/bulk", bytes.NewBufferString(`[{"f":"f1","i":[]}]`))
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, 200, resp.Code)
+	}
+
+	post() // occupies the only slot until released
+	post() // dropped
+	assert.Equal(t, int64(1), drops.PeekListenerDrops())
+
+	close(release)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&submitted))
+
+	post() // the slot is free again
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&submitted))
+	assert.Equal(t, int64(1), drops.PeekListenerDrops())
+}
+
+func TestMalformedImpressionsAreRejected(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	var staged int
+	group := router.Group("/api")
+	controller := NewEventsServerController(
+		logging.NewLogger(nil),
+		&mocks.MockDeferredRecordingTask{StageCall: func(rawData interface{}) error { staged++; return nil }},
+		&mocks.MockDeferredRecordingTask{},
+		&mocks.MockDeferredRecordingTask{},
+		nil,
+		func(string) bool { return true },
+		1,
+		nil,
+		"",
+		nil,
+	)
+	controller.Register(group, group)
+
+	post := func(body string) int {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/testImpressions/bulk", bytes.NewBufferString(body))
+		router.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	future := time.Now().Add(48 * time.Hour).UnixMilli()
+	assert.Equal(t, 400, post(`{"f":"f1"}`))
+	assert.Equal(t, 400, post(`[{"f":"","i":[]}]`))
+	assert.Equal(t, 400, post(`[{"f":"f1","i":[{"k":"","t":"on","m":1}]}]`))
+	assert.Equal(t, 400, post(`[{"f":"f1","i":[{"k":"k1","t":"on","m":0}]}]`))
+	assert.Equal(t, 400, post(fmt.Sprintf(`[{"f":"f1","i":[{"k":"k1","t":"on","m":%d}]}]`, future)))
+	assert.Equal(t, 0, staged)
+
+	assert.Equal(t, 200, post(`[{"f":"f1","i":[{"k":"k1","t":"on","m":1}]}]`))
+	assert.Equal(t, 1, staged)
+}
+
+func TestPostImpressionsBulkDeduplication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logging.NewLogger(nil)
+	apikeyValidator := mw.NewAPIKeyValidator([]string{"someApiKey"})
+
+	var impressions []*internal.RawImpressions
+	var counts []*internal.RawImpressionCount
+	observer, err := strategy.NewImpressionObserver(100)
+	assert.Nil(t, err)
+
+	router := gin.New()
+	group := router.Group("/api")
+	controller := NewEventsServerController(
+		logger,
+		&mocks.MockDeferredRecordingTask{StageCall: func(raw interface{}) error {
+			impressions = append(impressions, raw.(*internal.RawImpressions))
+			return nil
+		}},
+		&mocks.MockDeferredRecordingTask{StageCall: func(raw interface{}) error {
+			counts = append(counts, raw.(*internal.RawImpressionCount))
+			return nil
+		}},
+		&mocks.MockDeferredRecordingTask{},
+		nil,
+		apikeyValidator.IsValid,
+		10,
+		observer,
+		"",
+		nil,
+	)
+	controller.Register(group, group)
+
+	now := time.Now().UnixMilli()
+	post := func(mode string, imps ...dtos.ImpressionDTO) int {
+		serialized, _ := json.Marshal([]dtos.ImpressionsDTO{{TestName: "test1", KeyImpressions: imps}})
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/testImpressions/bulk", bytes.NewBuffer(serialized))
+		req.Header.Set("Authorization", "Bearer someApiKey")
+		req.Header.Set("SplitSDKImpressionsMode", mode)
+		router.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	k1 := dtos.ImpressionDTO{KeyName: "k1", Treatment: "on", Time: now, ChangeNumber: 1, Label: "l1"}
+	k2 := dtos.ImpressionDTO{KeyName: "k2", Treatment: "on", Time: now, ChangeNumber: 1, Label: "l1"}
+	assert.Equal(t, 200, post("optimized", k1))
+	assert.Len(t, impressions, 1)
+	assert.Len(t, counts, 0)
+
+	// another sdk posts the same impression along with a new one
+	assert.Equal(t, 200, post("optimized", k1, k2))
+	assert.Len(t, impressions, 2)
+	var forwarded []dtos.ImpressionsDTO
+	assert.Nil(t, json.Unmarshal(impressions[1].Payload, &forwarded))
+	assert.Len(t, forwarded, 1)
+	assert.Equal(t, []dtos.ImpressionDTO{k2}, forwarded[0].KeyImpressions)
+
+	assert.Len(t, counts, 1)
+	var parsedCounts dtos.ImpressionsCountDTO
+	assert.Nil(t, json.Unmarshal(counts[0].Payload, &parsedCounts))
+	assert.Equal(t, []dtos.ImpressionsInTimeFrameDTO{{FeatureName: "test1", TimeFrame: now - now%3600000, RawCount: 1}}, parsedCounts.PerFeature)
+
+	// a bulk with duplicates only is just counted
+	assert.Equal(t, 200, post("optimized", k1, k2))
+	assert.Len(t, impressions, 2)
+	assert.Len(t, counts, 2)
+
+	// impressions posted in debug mode are always forwarded
+	assert.Equal(t, 200, post("debug", k1))
+	assert.Len(t, impressions, 3)
+	assert.Len(t, counts, 2)
+
+	// bulks received over grpc are staged directly & go through the same deduplication
+	serialized, _ := json.Marshal([]dtos.ImpressionsDTO{{TestName: "test1", KeyImpressions: []dtos.ImpressionDTO{k2}}})
+	assert.Nil(t, controller.StageImpressions(dtos.Metadata{SDKVersion: "go-1.2.3"}, "optimized", serialized))
+	assert.Len(t, impressions, 3)
+	assert.Len(t, counts, 3)
+}
+
+func TestPostImpressionsEnforcedMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logging.NewLogger(nil)
+	apikeyValidator := mw.NewAPIKeyValidator([]string{"someApiKey"})
+
+	var impressions []*internal.RawImpressions
+	var counts []*internal.RawImpressionCount
+	setup := func(mode string) *gin.Engine {
+		observer, err := strategy.NewImpressionObserver(100)
+		assert.Nil(t, err)
+		router := gin.New()
+		group := router.Group("/api")
+		NewEventsServerController(
+			logger,
+			&mocks.MockDeferredRecordingTask{StageCall: func(raw interface{}) error {
+				impressions = append(impressions, raw.(*internal.RawImpressions))
+				return nil
+			}},
+			&mocks.MockDeferredRecordingTask{StageCall: func(raw interface{}) error {
+				counts = append(counts, raw.(*internal.RawImpressionCount))
+				return nil
+			}},
+			&mocks.MockDeferredRecordingTask{},
+			nil,
+			apikeyValidator.IsValid,
+			10,
+			observer,
+			mode,
+			nil,
+		).Register(group, group)
+		return router
+	}
+
+	now := time.Now().UnixMilli()
+	serialized, _ := json.Marshal([]dtos.ImpressionsDTO{{TestName: "test1", KeyImpressions: []dtos.ImpressionDTO{
+		{KeyName: "k1", Treatment: "on", Time: now, ChangeNumber: 1, Label: "l1"},
+	}}})
+	post := func(router *gin.Engine, sdkMode string) int {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/testImpressions/bulk", bytes.NewBuffer(serialized))
+		req.Header.Set("Authorization", "Bearer someApiKey")
+		req.Header.Set("SplitSDKImpressionsMode", sdkMode)
+		router.ServeHTTP(resp, req)
+		return resp.Code
+	}
+	beacon := func(router *gin.Engine) int {
+		body, _ := json.Marshal(beaconMessage{Entries: serialized, Sdk: "js-1.2.3", Token: "someApiKey"})
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/testImpressions/beacon", bytes.NewBuffer(body))
+		router.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	// impressions posted in debug mode are deduplicated when optimized mode is enforced, beacons included
+	router := setup("optimized")
+	assert.Equal(t, 200, post(router, "debug"))
+	assert.Equal(t, 200, post(router, "debug"))
+	assert.Equal(t, 204, beacon(router))
+	assert.Len(t, impressions, 1)
+	assert.Equal(t, "optimized", impressions[0].Mode)
+	assert.Len(t, counts, 2)
+
+	// impressions posted in optimized mode are all forwarded when debug mode is enforced
+	impressions, counts = nil, nil
+	router = setup("debug")
+	assert.Equal(t, 200, post(router, "optimized"))
+	assert.Equal(t, 200, post(router, "optimized"))
+	assert.Len(t, impressions, 2)
+	assert.Equal(t, "debug", impressions[1].Mode)
+	assert.Len(t, counts, 0)
+}
+
+func TestListenerDropsLog(t *testing.T) {
+	var log listenerDropsLog
+	now := time.Now()
+
+	dropped, ok := log.add(now)
+	assert.True(t, ok, "the first drop should be logged right away")
+	assert.Equal(t, int64(1), dropped)
+
+	for i := 0; i < 10; i++ {
+		_, ok = log.add(now.Add(time.Second))
+		assert.False(t, ok, "drops should not be logged more than once per period")
+	}
+
+	dropped, ok = log.add(now.Add(listenerDropsLogPeriod))
+	assert.True(t, ok)
+	assert.Equal(t, int64(11), dropped, "drops within the period should be aggregated")
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+// AccessLogger is a middleware that logs one line per request (method, path, status, latency & SDK headers).
+// Only a sample of the requests is logged, to keep the noise bounded at scale
+type AccessLogger struct {
+	logger     logging.LoggerInterface
+	sampleRate float64
+	debug      bool
+	random     func() float64
+}
+
+// NewAccessLogger constructs an access logger that logs `samplePercent`% of the requests (clamped to 0-100),
+// at debug level if `debug` is set or at info level otherwise
+func NewAccessLogger(logger logging.LoggerInterface, samplePercent int, debug bool) *AccessLogger {
+	return &AccessLogger{
+		logger:     logger,
+		sampleRate: float64(min(max(samplePercent, 0), 100)) / 100,
+		debug:      debug,
+		random:     rand.Float64,
+	}
+}
+
+// Handle is the function to be used as a gin middleware
+func (a *AccessLogger) Handle(ctx *gin.Context) {
+	before := time.Now()
+	ctx.Next()
+
+	if a.random() >= a.sampleRate {
+		return
+	}
+
+	line := fmt.Sprintf("access: %s %s status=%d latency=%s sdkVersion=%s machineIP=%s requestId=%s",
+		ctx.Request.Method,
+		ctx.Request.URL.Path,
+		ctx.Writer.Status(),
+		time.Since(before),
+		headerOrNA(ctx, "SplitSDKVersion"),
+		headerOrNA(ctx, "SplitSDKMachineIP"),
+		RequestID(ctx),
+	)
+
+	if a.debug {
+		a.logger.Debug(line)
+		return
+	}
+	a.logger.Info(line)
+}
+
+func headerOrNA(ctx *gin.Context, name string) string {
+	if value := ctx.Request.Header.Get(name); value != "" {
+		return value
+	}
+	return "NA"
+}
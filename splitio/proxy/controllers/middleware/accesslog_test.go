@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+func TestAccessLogger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buffer bytes.Buffer
+	logger := logging.NewLogger(&logging.LoggerOptions{LogLevel: logging.LevelDebug, InfoWriter: &buffer, DebugWriter: &buffer})
+
+	accessLogger := NewAccessLogger(logger, 50, false)
+	var next float64
+	accessLogger.random = func() float64 { return next }
+
+	router := gin.New()
+	router.Use(SetRequestID)
+	router.Use(accessLogger.Handle)
+	router.GET("/api/splitChanges", func(ctx *gin.Context) { ctx.Status(http.StatusNotModified) })
+
+	get := func() {
+		req, _ := http.NewRequest(http.MethodGet, "/api/splitChanges?since=-1", nil)
+		req.Header.Set("SplitSDKVersion", "go-6.0.0")
+		req.Header.Set(RequestIDHeader, "some-id")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	next = 0.49 // sampled
+	get()
+	line := buffer.String()
+	for _, expected := range []string{"INFO", "access: GET /api/splitChanges status=304", "sdkVersion=go-6.0.0", "machineIP=NA", "requestId=some-id"} {
+		if !strings.Contains(line, expected) {
+			t.Errorf("'%s' should be present in the access log line. Got: %s", expected, line)
+		}
+	}
+
+	buffer.Reset()
+	next = 0.5 // not sampled
+	get()
+	if buffer.Len() != 0 {
+		t.Error("no line should be logged for requests not sampled. Got: ", buffer.String())
+	}
+}
+
+func TestAccessLoggerSampleRateBounds(t *testing.T) {
+	if rate := NewAccessLogger(nil, 250, false).sampleRate; rate != 1 {
+		t.Error("sample rate should be capped at 100%. Got: ", rate)
+	}
+	if rate := NewAccessLogger(nil, -5, false).sampleRate; rate != 0 {
+		t.Error("sample rate should not be negative. Got: ", rate)
+	}
+}
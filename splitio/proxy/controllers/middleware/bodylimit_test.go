@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBodySizeLimiter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := NewBodySizeLimiter(10)
+
+	var received string
+	router := gin.New()
+	router.POST("/api/events/bulk", limiter.Handle, func(ctx *gin.Context) {
+		body, _ := io.ReadAll(ctx.Request.Body)
+		received = string(body)
+	})
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/events/bulk", strings.NewReader("0123456789"))
+	router.ServeHTTP(resp, req)
+	if resp.Code != 200 || received != "0123456789" {
+		t.Error("bodies within the limit should be passed through. Got: ", resp.Code, received)
+	}
+
+	received = ""
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/api/events/bulk", strings.NewReader("0123456789a"))
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusRequestEntityTooLarge || received != "" {
+		t.Error("bodies with a content-length over the limit should be rejected. Got: ", resp.Code, received)
+	}
+
+	// unknown content-length (ie: chunked)
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/api/events/bulk", io.NopCloser(strings.NewReader("0123456789a")))
+	req.ContentLength = -1
+	router.ServeHTTP(resp, req)
+	if resp.Code != http.StatusRequestEntityTooLarge || received != "" {
+		t.Error("bodies over the limit should be rejected. Got: ", resp.Code, received)
+	}
+}
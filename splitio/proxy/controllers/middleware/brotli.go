@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// BrotliCompressor is a middleware that compresses responses with brotli for clients that prefer it over gzip.
+// It's meant to be placed before the gzip middleware: when brotli is picked, `gzip` is removed from the request's
+// Accept-Encoding header so that the response isn't compressed twice. As with gzip, responses smaller than the
+// configured minimum size are sent as-is, and so are the ones with a non-2xx status.
+type BrotliCompressor struct {
+	minSize int
+	pool    sync.Pool
+}
+
+// NewBrotliCompressor constructs a brotli middleware with the supplied compression level & minimum response size
+func NewBrotliCompressor(level int, minSize int) *BrotliCompressor {
+	if level < brotli.BestSpeed || level > brotli.BestCompression {
+		level = brotli.DefaultCompression
+	}
+
+	if minSize < 0 {
+		minSize = 0
+	}
+
+	return &BrotliCompressor{
+		minSize: minSize,
+		pool:    sync.Pool{New: func() interface{} { return brotli.NewWriterLevel(io.Discard, level) }},
+	}
+}
+
+// Handle is the function to be used as a gin middleware
+func (b *BrotliCompressor) Handle(ctx *gin.Context) {
+	accepted := ctx.Request.Header.Get("Accept-Encoding")
+	if !AcceptsBrotli(accepted) ||
+		strings.Contains(ctx.Request.Header.Get("Connection"), "Upgrade") ||
+		strings.Contains(ctx.Request.Header.Get("Accept"), "text/event-stream") {
+		return
+	}
+
+	// prevent the gzip middleware from compressing this response as well
+	ctx.Request.Header.Set("Accept-Encoding", withoutGzip(accepted))
+
+	writer := &brotliWriter{ResponseWriter: ctx.Writer, compressor: b}
+	ctx.Writer = writer
+	defer writer.finish()
+	ctx.Next()
+}
+
+// AcceptsBrotli returns true if the supplied Accept-Encoding header value accepts brotli with at least the same
+// preference as gzip, which is when brotli is used (if enabled)
+func AcceptsBrotli(acceptEncoding string) bool {
+	br := encodingQuality(acceptEncoding, "br")
+	return br > 0 && br >= encodingQuality(acceptEncoding, "gzip")
+}
+
+// AcceptsGzip returns true if the supplied Accept-Encoding header value accepts gzip
+func AcceptsGzip(acceptEncoding string) bool {
+	return acceptsEncoding(acceptEncoding, "gzip")
+}
+
+func acceptsEncoding(acceptEncoding string, target string) bool {
+	return encodingQuality(acceptEncoding, target) > 0
+}
+
+// encodingQuality returns the q-value given to an encoding in an Accept-Encoding header value, 0 meaning not acceptable.
+// Encodings listed without a q-value get 1, and `*` applies to the ones that are not listed (RFC 9110 §12.5.3)
+func encodingQuality(acceptEncoding string, target string) float64 {
+	wildcard := 0.0
+	for _, encoding := range strings.Split(acceptEncoding, ",") {
+		name, quality := parseEncoding(encoding)
+		switch {
+		case strings.EqualFold(name, target):
+			return quality
+		case name == "*":
+			wildcard = quality
+		}
+	}
+	return wildcard
+}
+
+// parseEncoding splits an Accept-Encoding item into its name & q-value. Invalid q-values are treated as 0
+func parseEncoding(encoding string) (string, float64) {
+	name, params, _ := strings.Cut(encoding, ";")
+	quality := 1.0
+	for _, param := range strings.Split(params, ";") {
+		key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+		if !found || !strings.EqualFold(strings.TrimSpace(key), "q") {
+			continue
+		}
+
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			parsed = 0
+		}
+		quality = parsed
+	}
+	return strings.TrimSpace(name), quality
+}
+
+func withoutGzip(acceptEncoding string) string {
+	encodings := strings.Split(acceptEncoding, ",")
+	filtered := encodings[:0]
+	for _, encoding := range encodings {
+		if name, _ := parseEncoding(encoding); !strings.EqualFold(name, "gzip") {
+			filtered = append(filtered, encoding)
+		}
+	}
+	return strings.Join(filtered, ",")
+}
+
+// brotliWriter buffers the response until it reaches the minimum size, and only then starts compressing it.
+// Responses with a non-2xx status are written untouched
+type brotliWriter struct {
+	gin.ResponseWriter
+	compressor  *BrotliCompressor
+	buffer      []byte
+	bw          *brotli.Writer
+	passthrough bool
+}
+
+func (w *brotliWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *brotliWriter) Write(data []byte) (int, error) {
+	switch {
+	case w.bw != nil:
+		return w.bw.Write(data)
+	case w.passthrough:
+		return w.ResponseWriter.Write(data)
+	}
+
+	if status := w.Status(); status < 200 || status >= 300 {
+		w.passthrough = true
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buffer = append(w.buffer, data...)
+	if len(w.buffer) < w.compressor.minSize {
+		return len(data), nil
+	}
+
+	w.Header().Set("Content-Encoding", "br")
+	w.Header().Set("Vary", "Accept-Encoding")
+	w.Header().Del("Content-Length")
+	w.bw = w.compressor.pool.Get().(*brotli.Writer)
+	w.bw.Reset(w.ResponseWriter)
+
+	buffered := w.buffer
+	w.buffer = nil
+	if _, err := w.bw.Write(buffered); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+func (w *brotliWriter) finish() {
+	if w.bw == nil {
+		if len(w.buffer) > 0 {
+			w.ResponseWriter.Write(w.buffer)
+		}
+		return
+	}
+
+	w.bw.Close()
+	w.bw.Reset(io.Discard)
+	w.compressor.pool.Put(w.bw)
+}
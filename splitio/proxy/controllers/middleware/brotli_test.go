@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	ginGzip "github.com/gin-contrib/gzip"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBrotliMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(NewBrotliCompressor(4, 0).Handle, ginGzip.Gzip(ginGzip.DefaultCompression))
+	router.GET("/api/test", func(ctx *gin.Context) { ctx.String(200, "some payload") })
+
+	doRequest := func(acceptEncoding string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/test", nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// brotli is preferred over gzip when both are accepted
+	resp := doRequest("gzip, deflate, br")
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, "br", resp.Header().Get("Content-Encoding"))
+	body, err := io.ReadAll(brotli.NewReader(resp.Body))
+	assert.Nil(t, err)
+	assert.Equal(t, "some payload", string(body))
+
+	// gzip is used when brotli is not supported by the client
+	resp = doRequest("gzip")
+	assert.Equal(t, "gzip", resp.Header().Get("Content-Encoding"))
+	gzReader, err := gzip.NewReader(resp.Body)
+	assert.Nil(t, err)
+	body, err = io.ReadAll(gzReader)
+	assert.Nil(t, err)
+	assert.Equal(t, "some payload", string(body))
+
+	// gzip is used when the client refuses brotli or prefers gzip
+	for _, acceptEncoding := range []string{"br;q=0, gzip", "gzip;q=1.0, br;q=0.8"} {
+		resp = doRequest(acceptEncoding)
+		assert.Equal(t, "gzip", resp.Header().Get("Content-Encoding"), acceptEncoding)
+	}
+
+	// identity otherwise
+	resp = doRequest("")
+	assert.Equal(t, "", resp.Header().Get("Content-Encoding"))
+	assert.Equal(t, "some payload", resp.Body.String())
+}
+
+func TestBrotliMiddlewareSkippedResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(NewBrotliCompressor(4, 10).Handle)
+	router.GET("/api/large", func(ctx *gin.Context) { ctx.String(200, "a payload larger than the minimum size") })
+	router.GET("/api/small", func(ctx *gin.Context) { ctx.String(200, "small") })
+	router.GET("/api/error", func(ctx *gin.Context) { ctx.JSON(500, gin.H{"error": "some error larger than the minimum size"}) })
+	router.GET("/api/empty", func(ctx *gin.Context) { ctx.Status(204) })
+
+	doRequest := func(path string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Encoding", "br")
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := doRequest("/api/large")
+	assert.Equal(t, "br", resp.Result().Header.Get("Content-Encoding"))
+	body, err := io.ReadAll(brotli.NewReader(resp.Body))
+	assert.Nil(t, err)
+	assert.Equal(t, "a payload larger than the minimum size", string(body))
+
+	resp = doRequest("/api/small")
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, "", resp.Result().Header.Get("Content-Encoding"))
+	assert.Equal(t, "small", resp.Body.String())
+
+	resp = doRequest("/api/error")
+	assert.Equal(t, 500, resp.Code)
+	assert.Equal(t, "", resp.Result().Header.Get("Content-Encoding"))
+	assert.JSONEq(t, `{"error": "some error larger than the minimum size"}`, resp.Body.String())
+
+	resp = doRequest("/api/empty")
+	assert.Equal(t, 204, resp.Code)
+	assert.Equal(t, "", resp.Result().Header.Get("Content-Encoding"))
+	assert.Equal(t, 0, resp.Body.Len())
+}
+
+func TestAcceptsBrotli(t *testing.T) {
+	assert.True(t, AcceptsBrotli("br"))
+	assert.True(t, AcceptsBrotli("gzip, deflate, br"))
+	assert.True(t, AcceptsBrotli("gzip;q=0.8, br;q=0.9"))
+	assert.True(t, AcceptsBrotli("gzip, *"))
+	assert.False(t, AcceptsBrotli("gzip;q=1.0, br;q=0.8"))
+	assert.False(t, AcceptsBrotli("br;q=0, gzip"))
+	assert.False(t, AcceptsBrotli("BR;Q=0"))
+	assert.False(t, AcceptsBrotli("*, br;q=0"))
+	assert.False(t, AcceptsBrotli("brotli, x-br"))
+	assert.False(t, AcceptsBrotli("gzip, deflate"))
+	assert.False(t, AcceptsBrotli(""))
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	assert.True(t, AcceptsGzip("gzip"))
+	assert.True(t, AcceptsGzip("br, gzip;q=0.5"))
+	assert.True(t, AcceptsGzip("*"))
+	assert.False(t, AcceptsGzip("gzip;q=0, br"))
+	assert.False(t, AcceptsGzip("x-gzip, br"))
+	assert.False(t, AcceptsGzip(""))
+}
+
+func TestWithoutGzip(t *testing.T) {
+	assert.Equal(t, " br", withoutGzip("gzip, br"))
+	assert.Equal(t, "br, x-gzip", withoutGzip("br, gzip;q=0.5, x-gzip"))
+	assert.Equal(t, "br", withoutGzip("br"))
+}
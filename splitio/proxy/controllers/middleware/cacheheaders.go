@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// ExcludeHeadersFromCache wraps the http cache middleware so that the supplied headers, which are set per request by
+// previous middlewares (ie: the request id), are neither stored in cached entries nor added again when replaying them.
+// They're taken out of the response before the cache runs, and set back right before the response is written
+func ExcludeHeadersFromCache(cache gin.HandlerFunc, headers ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		writer := &perRequestHeadersWriter{ResponseWriter: ctx.Writer, headers: make(map[string][]string, len(headers))}
+		for _, header := range headers {
+			if values := ctx.Writer.Header().Values(header); len(values) > 0 {
+				writer.headers[header] = values
+				ctx.Writer.Header().Del(header)
+			}
+		}
+
+		original := ctx.Writer
+		ctx.Writer = writer
+		cache(ctx)
+		writer.restore() // in case nothing was written
+		ctx.Writer = original
+	}
+}
+
+type perRequestHeadersWriter struct {
+	gin.ResponseWriter
+	headers  map[string][]string
+	restored bool
+}
+
+func (w *perRequestHeadersWriter) restore() {
+	if w.restored {
+		return
+	}
+
+	w.restored = true
+	for header, values := range w.headers {
+		w.Header()[header] = values
+	}
+}
+
+func (w *perRequestHeadersWriter) WriteHeaderNow() {
+	w.restore()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *perRequestHeadersWriter) Write(data []byte) (int, error) {
+	w.restore()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *perRequestHeadersWriter) WriteString(s string) (int, error) {
+	w.restore()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *perRequestHeadersWriter) Flush() {
+	w.restore()
+	w.ResponseWriter.Flush()
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleConditionalRequests turns successful responses into `304 Not Modified` when the ETag set by the handler
+// matches one of the values sent by the client in the `If-None-Match` header.
+// It should be placed before the cache middleware, so that cached responses (which keep the ETag header) are
+// checked as well, and the full response is still stored in cache for unconditional requests.
+func HandleConditionalRequests(ctx *gin.Context) {
+	ifNoneMatch := ctx.Request.Header.Get("If-None-Match")
+	if ifNoneMatch == "" || (ctx.Request.Method != http.MethodGet && ctx.Request.Method != http.MethodHead) {
+		return
+	}
+
+	writer := &conditionalWriter{ResponseWriter: ctx.Writer, ifNoneMatch: ifNoneMatch}
+	ctx.Writer = writer
+	ctx.Next()
+
+	if writer.notModified {
+		// headers are only sent now, since inner middlewares (ie: compressors) may set entity headers after the status
+		// is written (or even once the handler returns), and those must not make it into the 304
+		for _, header := range notModifiedStrippedHeaders {
+			writer.Header().Del(header)
+		}
+		writer.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// ETagMatches returns true if the supplied etag is included in the value of an `If-None-Match` header
+func ETagMatches(ifNoneMatch string, etag string) bool {
+	if etag == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || weakETag(candidate) == weakETag(etag) {
+			return true
+		}
+	}
+	return false
+}
+
+func weakETag(etag string) string {
+	return strings.TrimPrefix(etag, "W/")
+}
+
+// entity headers describing a body, which 304 responses don't have
+var notModifiedStrippedHeaders = []string{"Content-Length", "Content-Type", "Content-Encoding", "Content-Range"}
+
+type conditionalWriter struct {
+	gin.ResponseWriter
+	ifNoneMatch string
+	notModified bool
+}
+
+func (w *conditionalWriter) WriteHeader(code int) {
+	if code == http.StatusOK && ETagMatches(w.ifNoneMatch, w.Header().Get("ETag")) {
+		w.notModified = true
+		code = http.StatusNotModified
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *conditionalWriter) WriteHeaderNow() {
+	if w.notModified {
+		return // sent by the middleware once the rest of the chain is done
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *conditionalWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *conditionalWriter) Write(data []byte) (int, error) {
+	if w.notModified {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
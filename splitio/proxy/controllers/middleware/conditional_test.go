@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETagMatches(t *testing.T) {
+	assert.True(t, ETagMatches(`W/"1"`, `W/"1"`))
+	assert.True(t, ETagMatches(`"1"`, `W/"1"`))
+	assert.True(t, ETagMatches(`W/"0", W/"1"`, `W/"1"`))
+	assert.True(t, ETagMatches(`*`, `W/"1"`))
+	assert.False(t, ETagMatches(`W/"0"`, `W/"1"`))
+	assert.False(t, ETagMatches(`W/"1"`, ""))
+}
+
+func TestNotModifiedResponsesHaveNoEntityHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	large := strings.Repeat("some payload ", 100)
+	router := gin.New()
+	router.Use(HandleConditionalRequests, NewBrotliCompressor(brotli.BestSpeed, 0).Handle, NewGzipCompressor(gzip.BestSpeed, 0).Handle)
+	router.GET("/api/changes", func(ctx *gin.Context) {
+		ctx.Header("ETag", `W/"1"`)
+		ctx.String(200, large)
+	})
+
+	for _, acceptEncoding := range []string{"gzip", "br", ""} {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/changes", nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		req.Header.Set("If-None-Match", `W/"1"`)
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, 304, resp.Code, acceptEncoding)
+		assert.Empty(t, resp.Body.String(), acceptEncoding)
+		headers := resp.Result().Header // the ones sent, rather than the (still writable) header map
+		assert.Equal(t, `W/"1"`, headers.Get("ETag"), acceptEncoding)
+		assert.Empty(t, headers.Get("Content-Encoding"), acceptEncoding)
+		assert.Empty(t, headers.Get("Content-Length"), acceptEncoding)
+		assert.Empty(t, headers.Get("Content-Type"), acceptEncoding)
+	}
+
+	// non matching etags get the full (compressed) response
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/changes", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("If-None-Match", `W/"0"`)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, "gzip", resp.Header().Get("Content-Encoding"))
+	assert.NotEmpty(t, resp.Body.Bytes())
+}
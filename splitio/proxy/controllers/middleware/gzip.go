@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GzipCompressor is a middleware that compresses responses with gzip for clients that accept it.
+// Responses smaller than the configured minimum size are sent as-is, since compressing them wastes cpu
+// without providing any meaningful bandwidth savings.
+type GzipCompressor struct {
+	minSize int
+	pool    sync.Pool
+}
+
+// NewGzipCompressor constructs a gzip middleware with the supplied compression level & minimum response size
+func NewGzipCompressor(level int, minSize int) *GzipCompressor {
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		level = gzip.DefaultCompression
+	}
+
+	if minSize < 0 {
+		minSize = 0
+	}
+
+	return &GzipCompressor{
+		minSize: minSize,
+		pool: sync.Pool{New: func() interface{} {
+			gz, _ := gzip.NewWriterLevel(io.Discard, level) // level is validated above
+			return gz
+		}},
+	}
+}
+
+// Handle is the function to be used as a gin middleware
+func (g *GzipCompressor) Handle(ctx *gin.Context) {
+	if !acceptsEncoding(ctx.Request.Header.Get("Accept-Encoding"), "gzip") ||
+		strings.Contains(ctx.Request.Header.Get("Connection"), "Upgrade") ||
+		strings.Contains(ctx.Request.Header.Get("Accept"), "text/event-stream") {
+		return
+	}
+
+	writer := &gzipWriter{ResponseWriter: ctx.Writer, compressor: g}
+	ctx.Writer = writer
+	defer writer.finish()
+	ctx.Next()
+}
+
+// gzipWriter buffers the response until it reaches the minimum size, and only then starts compressing it
+type gzipWriter struct {
+	gin.ResponseWriter
+	compressor *GzipCompressor
+	buffer     []byte
+	gz         *gzip.Writer
+}
+
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *gzipWriter) Write(data []byte) (int, error) {
+	if w.gz != nil {
+		return w.gz.Write(data)
+	}
+
+	w.buffer = append(w.buffer, data...)
+	if len(w.buffer) < w.compressor.minSize {
+		return len(data), nil
+	}
+
+	w.Header().Set("Content-Encoding", "gzip")
+	w.Header().Set("Vary", "Accept-Encoding")
+	w.Header().Del("Content-Length")
+	w.gz = w.compressor.pool.Get().(*gzip.Writer)
+	w.gz.Reset(w.ResponseWriter)
+
+	buffered := w.buffer
+	w.buffer = nil
+	if _, err := w.gz.Write(buffered); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+func (w *gzipWriter) finish() {
+	if w.gz == nil {
+		if len(w.buffer) > 0 {
+			w.ResponseWriter.Write(w.buffer)
+		}
+		return
+	}
+
+	w.gz.Close()
+	w.gz.Reset(io.Discard)
+	w.compressor.pool.Put(w.gz)
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGzipMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	large := strings.Repeat("some payload ", 100)
+	router := gin.New()
+	router.Use(NewGzipCompressor(gzip.BestSpeed, 1024).Handle)
+	router.GET("/api/small", func(ctx *gin.Context) { ctx.String(200, "some payload") })
+	router.GET("/api/large", func(ctx *gin.Context) { ctx.String(200, large) })
+
+	doRequest := func(path string, acceptEncoding string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// large responses are compressed
+	resp := doRequest("/api/large", "gzip, deflate")
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, "gzip", resp.Header().Get("Content-Encoding"))
+	gzReader, err := gzip.NewReader(resp.Body)
+	assert.Nil(t, err)
+	body, err := io.ReadAll(gzReader)
+	assert.Nil(t, err)
+	assert.Equal(t, large, string(body))
+
+	// small ones are sent as-is
+	resp = doRequest("/api/small", "gzip, deflate")
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, "", resp.Header().Get("Content-Encoding"))
+	assert.Equal(t, "some payload", resp.Body.String())
+
+	// as well as any response for clients not accepting gzip
+	resp = doRequest("/api/large", "")
+	assert.Equal(t, "", resp.Header().Get("Content-Encoding"))
+	assert.Equal(t, large, resp.Body.String())
+}
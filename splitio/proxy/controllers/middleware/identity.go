@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// Headers used to identify the proxy instance that served a request
+const (
+	ProxyVersionHeader  = "X-Split-Proxy-Version"
+	ProxyInstanceHeader = "X-Split-Proxy-Instance"
+)
+
+// IdentityHeaders is a middleware that adds the proxy version & instance id to every response,
+// to tell apart which instance served a request when running several of them behind a load balancer
+type IdentityHeaders struct {
+	version  string
+	instance string
+}
+
+// NewIdentityHeaders constructs a new identity headers middleware
+func NewIdentityHeaders(version string, instance string) *IdentityHeaders {
+	return &IdentityHeaders{version: version, instance: instance}
+}
+
+// Handle is the function to be used as a gin middleware
+func (m *IdentityHeaders) Handle(ctx *gin.Context) {
+	ctx.Header(ProxyVersionHeader, m.version)
+	if m.instance != "" {
+		ctx.Header(ProxyInstanceHeader, m.instance)
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIdentityHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(NewIdentityHeaders("1.2.3", "proxy-1").Handle)
+	router.GET("/api/splitChanges", func(ctx *gin.Context) {})
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/splitChanges", nil)
+	router.ServeHTTP(resp, req)
+	if v := resp.Header().Get(ProxyVersionHeader); v != "1.2.3" {
+		t.Error("wrong version header: ", v)
+	}
+	if i := resp.Header().Get(ProxyInstanceHeader); i != "proxy-1" {
+		t.Error("wrong instance header: ", i)
+	}
+
+	// unknown routes get them as well
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/nonexistent", nil)
+	router.ServeHTTP(resp, req)
+	if v := resp.Header().Get(ProxyVersionHeader); v != "1.2.3" {
+		t.Error("wrong version header: ", v)
+	}
+}
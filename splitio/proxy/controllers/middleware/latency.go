@@ -26,5 +26,10 @@ func (m *MetricsMiddleware) Track(ctx *gin.Context) {
 	if asInt, ok := endpoint.(int); exists && ok {
 		m.tracker.RecordEndpointLatency(asInt, time.Now().Sub(before))
 		m.tracker.IncrEndpointStatus(asInt, ctx.Writer.Status())
+
+		// tracked here rather than in the controller, so that requests answered from the http cache are accounted for
+		if specTracker, ok := m.tracker.(storage.SpecVersionTelemetry); ok && asInt == storage.SplitChangesEndpoint {
+			specTracker.IncrSpecVersionRequests(ctx.Query("s"))
+		}
 	}
 }
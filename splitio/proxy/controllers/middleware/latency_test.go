@@ -3,6 +3,7 @@ package middleware
 import (
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -33,3 +34,35 @@ func TestLatencyMiddleWare(t *testing.T) {
 		t.Error("there should be one latency recorded for impressions bulk posting")
 	}
 }
+
+func TestLatencyMiddleWareTracksSpecVersions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tStorage := storage.NewProxyTelemetryFacade()
+	tMw := NewProxyMetricsMiddleware(tStorage)
+
+	router := gin.New()
+	router.GET("/api/splitChanges", tMw.Track, func(ctx *gin.Context) { ctx.Set(EndpointKey, storage.SplitChangesEndpoint) })
+	router.GET("/api/segmentChanges", tMw.Track, func(ctx *gin.Context) { ctx.Set(EndpointKey, storage.SegmentChangesEndpoint) })
+
+	for _, path := range []string{
+		"/api/splitChanges?s=1.1",
+		"/api/splitChanges?s=1.1",
+		"/api/splitChanges?s=1.0",
+		"/api/splitChanges",
+		"/api/splitChanges?s=9.9",
+		"/api/segmentChanges?s=1.1", // only splitChanges requests are tracked
+	} {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	expected := map[string]int64{"1.1": 2, "1.0": 1, storage.SpecVersionNone: 1, storage.SpecVersionUnsupported: 1}
+	if requests := tStorage.PeekSpecVersionRequests(); !reflect.DeepEqual(requests, expected) {
+		t.Error("unexpected spec version requests: ", requests)
+	}
+
+	tStorage.ResetEndpointTelemetry()
+	if requests := tStorage.PeekSpecVersionRequests(); len(requests) != 0 {
+		t.Error("spec version requests should be reset. Got: ", requests)
+	}
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// how often buckets that have been refilled completely are forgotten, to keep memory bounded
+const rateLimitPurgePeriod = time.Minute
+
+// RateLimiter is a middleware that applies a token-bucket rate limit to each client, identified by the connection's
+// address & the SDK key used (if any). Client-supplied headers such as `SplitSDKMachineIP` are not taken into account,
+// since they can be trivially spoofed to get a fresh bucket on every request.
+// Rejected requests get a `429 Too Many Requests` status, which is tracked by the proxy metrics middleware
+// like any other status code.
+type RateLimiter struct {
+	rate      float64
+	burst     float64
+	buckets   map[string]*tokenBucket
+	lastPurge time.Time
+	mutex     sync.Mutex
+	now       func() time.Time
+}
+
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// NewRateLimiter constructs a rate limiter allowing `ratePerSec` requests per second per client, with bursts of up to
+// `burst` requests. A burst lower than 1 is set to the rate (rounded up). A rate of 0 lets every request through
+func NewRateLimiter(ratePerSec float64, burst int) *RateLimiter {
+	limiter := &RateLimiter{
+		buckets:   make(map[string]*tokenBucket),
+		lastPurge: time.Now(),
+		now:       time.Now,
+	}
+	limiter.SetLimits(ratePerSec, burst)
+	return limiter
+}
+
+// SetLimits updates the rate & burst on a running limiter. Clients keep their current tokens, capped to the new burst
+func (r *RateLimiter) SetLimits(ratePerSec float64, burst int) {
+	if burst < 1 {
+		burst = int(ratePerSec + 0.999)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.rate = ratePerSec
+	r.burst = float64(burst)
+	if r.rate <= 0 { // buckets are not looked at (nor purged) while the limit is disabled
+		r.buckets = make(map[string]*tokenBucket)
+	}
+}
+
+// Handle is the function to be used as a gin middleware on endpoints where the sdk key has already been validated
+func (r *RateLimiter) Handle(ctx *gin.Context) {
+	client := ctx.RemoteIP()
+	if apikey := ctx.Request.Header.Get("Authorization"); apikey != "" {
+		client = apikey + "|" + client
+	}
+	r.limit(ctx, client)
+}
+
+// HandleByAddress is the function to be used as a gin middleware on beacon endpoints. Those carry the sdk key in the body
+// and nothing validates the Authorization header, so it's ignored (otherwise rotating it would get a fresh bucket each time)
+func (r *RateLimiter) HandleByAddress(ctx *gin.Context) {
+	r.limit(ctx, ctx.RemoteIP())
+}
+
+func (r *RateLimiter) limit(ctx *gin.Context, client string) {
+	if !r.Allow(client) {
+		ctx.AbortWithStatus(http.StatusTooManyRequests)
+	}
+}
+
+// Allow consumes a token from the client's bucket, returning false if there are none left
+func (r *RateLimiter) Allow(client string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.rate <= 0 {
+		return true
+	}
+
+	now := r.now()
+	r.purge(now)
+
+	bucket, ok := r.buckets[client]
+	if !ok {
+		bucket = &tokenBucket{tokens: r.burst, lastRefill: now}
+		r.buckets[client] = bucket
+	}
+
+	bucket.tokens += now.Sub(bucket.lastRefill).Seconds() * r.rate
+	if bucket.tokens > r.burst {
+		bucket.tokens = r.burst
+	}
+	bucket.lastRefill = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+
+	bucket.tokens--
+	return true
+}
+
+// purge removes the buckets of clients that have been idle long enough for them to be full again. Those are equivalent to
+// the ones created for new clients, so they can be dropped without affecting the limits. Must be called with the lock held
+func (r *RateLimiter) purge(now time.Time) {
+	if now.Sub(r.lastPurge) < rateLimitPurgePeriod {
+		return
+	}
+
+	for client, bucket := range r.buckets {
+		if bucket.tokens+now.Sub(bucket.lastRefill).Seconds()*r.rate >= r.burst {
+			delete(r.buckets, client)
+		}
+	}
+	r.lastPurge = now
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimiter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := NewRateLimiter(1, 2)
+	current := time.Now()
+	limiter.now = func() time.Time { return current }
+
+	router := gin.New()
+	router.POST("/api/testImpressions/bulk", limiter.Handle, func(ctx *gin.Context) {})
+
+	post := func(remoteIP string) int {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/testImpressions/bulk", nil)
+		req.RemoteAddr = remoteIP + ":12345"
+		req.Header.Set("Authorization", "Bearer someApikey")
+		router.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	if c1, c2 := post("1.1.1.1"), post("1.1.1.1"); c1 != 200 || c2 != 200 {
+		t.Error("requests within the burst should be accepted. Got: ", c1, c2)
+	}
+
+	if c := post("1.1.1.1"); c != http.StatusTooManyRequests {
+		t.Error("requests exceeding the burst should be rejected. Got: ", c)
+	}
+
+	if c := post("2.2.2.2"); c != 200 {
+		t.Error("each client should have its own bucket. Got: ", c)
+	}
+
+	current = current.Add(time.Second)
+	if c1, c2 := post("1.1.1.1"), post("1.1.1.1"); c1 != 200 || c2 != http.StatusTooManyRequests {
+		t.Error("a single token should be refilled after a second. Got: ", c1, c2)
+	}
+
+	current = current.Add(rateLimitPurgePeriod)
+	post("3.3.3.3")
+	if len(limiter.buckets) != 1 {
+		t.Error("clients with a full bucket should be purged. Remaining: ", len(limiter.buckets))
+	}
+}
+
+func TestRateLimiterClientIdentity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := NewRateLimiter(1, 1)
+	current := time.Now()
+	limiter.now = func() time.Time { return current }
+
+	router := gin.New()
+	router.POST("/api/testImpressions/bulk", limiter.Handle, func(ctx *gin.Context) {})
+
+	post := func(apikey string, headers map[string]string) int {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/testImpressions/bulk", nil)
+		req.RemoteAddr = "1.1.1.1:12345"
+		if apikey != "" {
+			req.Header.Set("Authorization", "Bearer "+apikey)
+		}
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		router.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	if c1, c2 := post("apikey1", nil), post("apikey1", map[string]string{"SplitSDKMachineIP": "2.2.2.2"}); c1 != 200 || c2 != http.StatusTooManyRequests {
+		t.Error("the SplitSDKMachineIP header should not be used to identify clients. Got: ", c1, c2)
+	}
+
+	if c := post("apikey1", map[string]string{"X-Forwarded-For": "3.3.3.3"}); c != http.StatusTooManyRequests {
+		t.Error("forwarding headers should not be used to identify clients. Got: ", c)
+	}
+
+	if c1, c2 := post("apikey2", nil), post("", nil); c1 != 200 || c2 != 200 {
+		t.Error("each sdk key should have its own bucket per address. Got: ", c1, c2)
+	}
+}
+
+func TestRateLimiterBeaconClientIdentity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := NewRateLimiter(1, 1)
+	current := time.Now()
+	limiter.now = func() time.Time { return current }
+
+	router := gin.New()
+	router.POST("/api/testImpressions/beacon", limiter.HandleByAddress, func(ctx *gin.Context) {})
+
+	post := func(remoteIP string, authorization string) int {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/testImpressions/beacon", nil)
+		req.RemoteAddr = remoteIP + ":12345"
+		req.Header.Set("Authorization", authorization)
+		router.ServeHTTP(resp, req)
+		return resp.Code
+	}
+
+	if c := post("1.1.1.1", "random1"); c != 200 {
+		t.Error("the first request should be accepted. Got: ", c)
+	}
+
+	for _, authorization := range []string{"random2", "random3", ""} {
+		if c := post("1.1.1.1", authorization); c != http.StatusTooManyRequests {
+			t.Error("rotating the Authorization header should not get a fresh bucket on beacon endpoints. Got: ", c)
+		}
+	}
+
+	if c := post("2.2.2.2", "random1"); c != 200 {
+		t.Error("each address should have its own bucket. Got: ", c)
+	}
+}
+
+func TestRateLimiterSetLimits(t *testing.T) {
+	limiter := NewRateLimiter(0, 0)
+	current := time.Now()
+	limiter.now = func() time.Time { return current }
+
+	for i := 0; i < 100; i++ {
+		if !limiter.Allow("1.1.1.1") {
+			t.Error("every request should be accepted when the rate is 0")
+		}
+	}
+
+	limiter.SetLimits(1, 1)
+	if !limiter.Allow("1.1.1.1") || limiter.Allow("1.1.1.1") {
+		t.Error("the new limits should be enforced right away")
+	}
+
+	limiter.SetLimits(0, 0)
+	if !limiter.Allow("1.1.1.1") {
+		t.Error("requests should be accepted after disabling the limit")
+	}
+
+	if len(limiter.buckets) != 0 {
+		t.Error("buckets should be dropped when disabling the limit. Remaining: ", len(limiter.buckets))
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readinessRetryAfterSecs is sent along with 503 responses, so that SDKs retry shortly instead of backing off for long
+const readinessRetryAfterSecs = "5"
+
+// Readiness gates sdk-serving endpoints until the proxy holds usable data (ie: the initial synchronization completed),
+// so that SDKs don't get an empty payload & end up serving control treatments
+type Readiness struct {
+	ready int32
+}
+
+// NewReadiness constructs a new readiness gate in a not-ready state
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+// SetReady lets requests through from now on
+func (r *Readiness) SetReady() {
+	atomic.StoreInt32(&r.ready, 1)
+}
+
+// IsReady returns whether the proxy is ready to serve sdk requests
+func (r *Readiness) IsReady() bool {
+	return atomic.LoadInt32(&r.ready) == 1
+}
+
+// Handle is the function to be used as a gin middleware
+func (r *Readiness) Handle(ctx *gin.Context) {
+	if r.IsReady() {
+		return
+	}
+
+	ctx.Header("Retry-After", readinessRetryAfterSecs)
+	ctx.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "proxy is not ready yet. initial synchronization in progress"})
+}
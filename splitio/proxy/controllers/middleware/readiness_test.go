@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadiness(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	readiness := NewReadiness()
+	router := gin.New()
+	router.GET("/api/splitChanges", readiness.Handle, func(ctx *gin.Context) { ctx.String(http.StatusOK, "{}") })
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/splitChanges", nil)
+	router.ServeHTTP(resp, req)
+	assert.False(t, readiness.IsReady())
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+	assert.Equal(t, "5", resp.Header().Get("Retry-After"))
+
+	readiness.SetReady()
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.True(t, readiness.IsReady())
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// RequestIDHeader is the header used to receive & echo the request id
+	RequestIDHeader = "X-Request-ID"
+
+	// RequestIDKey is the context key where the request id is stored
+	RequestIDKey = "requestId"
+
+	// incoming ids longer than this are replaced by a generated one
+	maxRequestIDLength = 128
+)
+
+// SetRequestID is a middleware that takes the request id sent by the client (or generates a new one if absent/invalid),
+// stores it in the context & echoes it back in the response, so that requests can be correlated with log lines
+func SetRequestID(ctx *gin.Context) {
+	id := ctx.Request.Header.Get(RequestIDHeader)
+	if !validRequestID(id) {
+		id = uuid.New().String()
+	}
+
+	ctx.Set(RequestIDKey, id)
+	ctx.Header(RequestIDHeader, id)
+}
+
+// RequestID returns the id of the request being handled, or an empty string if none was set
+func RequestID(ctx *gin.Context) string {
+	return ctx.GetString(RequestIDKey)
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e { // printable ascii only, to avoid tampering with log lines
+			return false
+		}
+	}
+	return true
+}
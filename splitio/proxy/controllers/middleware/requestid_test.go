@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSetRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var fromContext string
+	router := gin.New()
+	router.Use(SetRequestID)
+	router.GET("/api/splitChanges", func(ctx *gin.Context) { fromContext = RequestID(ctx) })
+
+	get := func(id string) string {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/splitChanges", nil)
+		if id != "" {
+			req.Header.Set(RequestIDHeader, id)
+		}
+		router.ServeHTTP(resp, req)
+		if echoed := resp.Header().Get(RequestIDHeader); echoed != fromContext {
+			t.Error("the request id should be echoed back. Got: ", echoed, fromContext)
+		}
+		return fromContext
+	}
+
+	if id := get("some-request-id"); id != "some-request-id" {
+		t.Error("the incoming request id should be used. Got: ", id)
+	}
+
+	generated := get("")
+	if len(generated) != 36 {
+		t.Error("a uuid should be generated when no request id is sent. Got: ", generated)
+	}
+
+	if id := get("invalid id\n"); id == "invalid id\n" || len(id) != 36 {
+		t.Error("invalid request ids should be replaced. Got: ", id)
+	}
+
+	if id := get(strings.Repeat("a", 200)); len(id) != 36 {
+		t.Error("too long request ids should be replaced. Got: ", id)
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing is a middleware that wraps every request in a server span, continuing the trace propagated by the caller (if any).
+// The span context is attached to the request's context so that handlers can create child spans
+type Tracing struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// NewTracing constructs a new tracing middleware
+func NewTracing(tracer trace.Tracer, propagator propagation.TextMapPropagator) *Tracing {
+	return &Tracing{tracer: tracer, propagator: propagator}
+}
+
+// Handle is the function to be used as a gin middleware
+func (t *Tracing) Handle(ctx *gin.Context) {
+	route := ctx.FullPath() // route template, so that span names don't include keys or segment names
+	if route == "" {
+		route = "unmatched"
+	}
+
+	parent := t.propagator.Extract(ctx.Request.Context(), propagation.HeaderCarrier(ctx.Request.Header))
+	spanCtx, span := t.tracer.Start(parent, ctx.Request.Method+" "+route,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.request.method", ctx.Request.Method),
+			attribute.String("http.route", route),
+			attribute.String("split.sdk.version", ctx.Request.Header.Get("SplitSDKVersion")),
+			attribute.String("split.request.id", RequestID(ctx)),
+		))
+	defer span.End()
+
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+	ctx.Next()
+
+	status := ctx.Writer.Status()
+	span.SetAttributes(attribute.Int("http.response.status_code", status))
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+}
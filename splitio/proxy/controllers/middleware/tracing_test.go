@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracing := NewTracing(provider.Tracer("test"), propagation.TraceContext{})
+
+	var handlerSpan trace.SpanContext
+	router := gin.New()
+	router.Use(SetRequestID, tracing.Handle)
+	router.GET("/api/segmentChanges/:name", func(ctx *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(ctx.Request.Context())
+		ctx.Status(http.StatusInternalServerError)
+	})
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/segmentChanges/segment1", nil)
+	req.Header.Set("SplitSDKVersion", "go-6.0.0")
+	req.Header.Set(RequestIDHeader, "someRequestId")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(resp, req)
+
+	spans := recorder.Ended()
+	assert.Equal(t, 1, len(spans))
+	span := spans[0]
+	assert.Equal(t, "GET /api/segmentChanges/:name", span.Name())
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	assert.Equal(t, span.SpanContext(), handlerSpan)
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Subset(t, span.Attributes(), []attribute.KeyValue{
+		attribute.String("http.route", "/api/segmentChanges/:name"),
+		attribute.String("split.sdk.version", "go-6.0.0"),
+		attribute.String("split.request.id", "someRequestId"),
+		attribute.Int("http.response.status_code", 500),
+	})
+}
@@ -1,21 +1,26 @@
 package controllers
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/splitio/go-split-commons/v6/dtos"
 	"github.com/splitio/go-split-commons/v6/engine/validator"
 	"github.com/splitio/go-split-commons/v6/service"
 	"github.com/splitio/go-split-commons/v6/service/api/specs"
+	"github.com/splitio/go-toolkit/v5/hasher"
 	"github.com/splitio/go-toolkit/v5/logging"
 	"golang.org/x/exp/slices"
 
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/breaker"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/caching"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/controllers/middleware"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/flagsets"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
 )
@@ -28,8 +33,18 @@ type SdkServerController struct {
 	proxySegmentStorage storage.ProxySegmentStorage
 	fsmatcher           flagsets.FlagSetMatcher
 	versionFilter       specs.SplitVersionFilter
+	maxBulkKeys         int
+	cacheControl        string
+	telemetry           storage.DegradedResponsesTelemetry
 }
 
+// bulk mySegments requests with more keys than this are rejected, unless a different limit is configured
+const defaultMySegmentsBulkMaxKeys = 1000
+
+// body size allowed for each key in a bulk mySegments request. Keys are at most 250 characters long, this leaves
+// room for json escaping & separators
+const mySegmentsBulkMaxBytesPerKey = 1024
+
 // NewSdkServerController instantiates a new sdk server controller
 func NewSdkServerController(
 	logger logging.LoggerInterface,
@@ -37,8 +52,19 @@ func NewSdkServerController(
 	proxySplitStorage storage.ProxySplitStorage,
 	proxySegmentStorage storage.ProxySegmentStorage,
 	fsmatcher flagsets.FlagSetMatcher,
-
+	mySegmentsBulkMaxKeys int,
+	cacheMaxAge time.Duration,
+	telemetry storage.DegradedResponsesTelemetry,
 ) *SdkServerController {
+	if mySegmentsBulkMaxKeys <= 0 {
+		mySegmentsBulkMaxKeys = defaultMySegmentsBulkMaxKeys
+	}
+
+	var cacheControl string
+	if seconds := int64(cacheMaxAge / time.Second); seconds > 0 {
+		cacheControl = fmt.Sprintf("public, max-age=%d", seconds)
+	}
+
 	return &SdkServerController{
 		logger:              logger,
 		fetcher:             fetcher,
@@ -46,19 +72,24 @@ func NewSdkServerController(
 		proxySegmentStorage: proxySegmentStorage,
 		fsmatcher:           fsmatcher,
 		versionFilter:       specs.NewSplitVersionFilter(),
+		maxBulkKeys:         mySegmentsBulkMaxKeys,
+		cacheControl:        cacheControl,
+		telemetry:           telemetry,
 	}
 }
 
-// Register mounts the sdk-server endpoints onto the supplied router
-func (c *SdkServerController) Register(router gin.IRouter) {
-	router.GET("/splitChanges", c.SplitChanges)
-	router.GET("/segmentChanges/:name", c.SegmentChanges)
-	router.GET("/mySegments/:key", c.MySegments)
+// Register mounts the sdk-server endpoints onto the supplied routers.
+// POST endpoints are mounted on the uncached router, since their response depends on the request body
+func (c *SdkServerController) Register(cacheable gin.IRouter, uncached gin.IRouter) {
+	cacheable.GET("/splitChanges", c.SplitChanges)
+	cacheable.GET("/segmentChanges/:name", c.SegmentChanges)
+	cacheable.GET("/mySegments/:key", c.MySegments)
+	uncached.POST("/mySegmentsBulk", c.MySegmentsBulk)
 }
 
 // SplitChanges Returns a diff containing changes in feature flags from a certain point in time until now.
 func (c *SdkServerController) SplitChanges(ctx *gin.Context) {
-	c.logger.Debug(fmt.Sprintf("Headers: %v", ctx.Request.Header))
+	c.logger.Debug(fmt.Sprintf("[%s] Headers: %v", middleware.RequestID(ctx), loggableHeaders(ctx.Request.Header)))
 	since, err := strconv.ParseInt(ctx.DefaultQuery("since", "-1"), 10, 64)
 	if err != nil {
 		since = -1
@@ -73,36 +104,81 @@ func (c *SdkServerController) SplitChanges(ctx *gin.Context) {
 		c.logger.Warning(fmt.Sprintf("SDK [%s] is sending flagsets unordered or with duplicates.", ctx.Request.Header.Get("SplitSDKVersion")))
 	}
 
-	c.logger.Debug(fmt.Sprintf("SDK Fetches Feature Flags Since: %d", since))
+	c.logger.Debug(fmt.Sprintf("[%s] SDK Fetches Feature Flags Since: %d", middleware.RequestID(ctx), since))
+
+	spec, _ := ctx.GetQuery("s")
+	if spec != specs.FLAG_V1_1 {
+		spec = specs.FLAG_V1_0
+	}
+
+	if serialized, ok := c.serializedSplitChangesSince(since, sets, spec); ok {
+		c.writeSplitChangesHeaders(ctx, since, sets, spec, serialized.Till)
+		ctx.Data(http.StatusOK, "application/json; charset=utf-8", serialized.Body)
+		return
+	}
 
 	splits, err := c.fetchSplitChangesSince(since, sets)
+	if errors.Is(err, breaker.ErrCircuitOpen) {
+		c.logger.Warning("cannot serve splitChanges payload: ", err)
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.logger.Error("error fetching splitChanges payload from storage: ", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	spec, _ := ctx.GetQuery("s")
-	if spec != specs.FLAG_V1_1 {
-		spec = specs.FLAG_V1_0
-	}
 	splits.Splits = c.patchUnsupportedMatchers(splits.Splits, spec)
-
+	c.writeSplitChangesHeaders(ctx, since, sets, spec, splits.Till)
 	ctx.JSON(http.StatusOK, splits)
+}
+
+// serializedSplitChangesSince returns a pre-serialized payload if the storage supports it and has the required data
+func (c *SdkServerController) serializedSplitChangesSince(since int64, sets []string, spec string) (*storage.SerializedChanges, bool) {
+	provider, ok := c.proxySplitStorage.(storage.SerializedSplitChangesProvider)
+	if !ok {
+		return nil, false
+	}
+
+	serialized, err := provider.SerializedChangesSince(since, sets, spec, func(changes *dtos.SplitChangesDTO) {
+		changes.Splits = c.patchUnsupportedMatchers(changes.Splits, spec)
+	})
+	if err != nil {
+		if !errors.Is(err, storage.ErrSinceParamTooOld) {
+			c.logger.Error("error fetching serialized splitChanges payload from storage: ", err)
+		}
+		return nil, false
+	}
+	return serialized, true
+}
+
+func (c *SdkServerController) writeSplitChangesHeaders(ctx *gin.Context, since int64, sets []string, spec string, till int64) {
+	// `304 Not Modified` responses are handled by the conditional requests middleware
+	ctx.Header("ETag", splitChangesETag(since, sets, spec, till))
+	c.setCacheHeaders(ctx)
 	ctx.Set(caching.SurrogateContextKey, []string{caching.SplitSurrogate})
 	ctx.Set(caching.StickyContextKey, true)
 }
 
+// splitChangesETag builds the etag of a splitChanges payload. Payloads are deterministic for a given query & `till`,
+// but the query must be part of the etag as well: otherwise a request with different sets (or spec version) carrying
+// the etag of a previous response would get a 304 for data it doesn't have
+func splitChangesETag(since int64, sets []string, spec string, till int64) string {
+	query := fmt.Sprintf("%d|%s|%s", since, strings.Join(sets, ","), spec)
+	return fmt.Sprintf(`W/"%d-%x"`, till, hasher.NewMurmur332Hasher(0).Hash([]byte(query)))
+}
+
 // SegmentChanges Returns a diff containing changes in feature flags from a certain point in time until now.
 func (c *SdkServerController) SegmentChanges(ctx *gin.Context) {
-	c.logger.Debug(fmt.Sprintf("Headers: %v", ctx.Request.Header))
+	c.logger.Debug(fmt.Sprintf("[%s] Headers: %v", middleware.RequestID(ctx), loggableHeaders(ctx.Request.Header)))
 	since, err := strconv.ParseInt(ctx.DefaultQuery("since", "-1"), 10, 64)
 	if err != nil {
 		since = -1
 	}
 
 	segmentName := ctx.Param("name")
-	c.logger.Debug(fmt.Sprintf("SDK Fetches Segment: %s Since: %d", segmentName, since))
+	c.logger.Debug(fmt.Sprintf("[%s] SDK Fetches Segment: %s Since: %d", middleware.RequestID(ctx), segmentName, since))
 	payload, err := c.proxySegmentStorage.ChangesSince(segmentName, since)
 	if err != nil {
 		if errors.Is(err, storage.ErrSegmentNotFound) {
@@ -116,6 +192,7 @@ func (c *SdkServerController) SegmentChanges(ctx *gin.Context) {
 		return
 	}
 
+	c.setCacheHeaders(ctx)
 	ctx.JSON(http.StatusOK, payload)
 	ctx.Set(caching.SurrogateContextKey, []string{caching.MakeSurrogateForSegmentChanges(segmentName)})
 	ctx.Set(caching.StickyContextKey, true)
@@ -123,7 +200,7 @@ func (c *SdkServerController) SegmentChanges(ctx *gin.Context) {
 
 // MySegments Returns a diff containing changes in feature flags from a certain point in time until now.
 func (c *SdkServerController) MySegments(ctx *gin.Context) {
-	c.logger.Debug(fmt.Sprintf("Headers: %v", ctx.Request.Header))
+	c.logger.Debug(fmt.Sprintf("[%s] Headers: %v", middleware.RequestID(ctx), loggableHeaders(ctx.Request.Header)))
 	key := ctx.Param("key")
 	segmentList, err := c.proxySegmentStorage.SegmentsFor(key)
 	if err != nil {
@@ -140,6 +217,57 @@ func (c *SdkServerController) MySegments(ctx *gin.Context) {
 	ctx.Set(caching.SurrogateContextKey, caching.MakeSurrogateForMySegments(mySegments))
 }
 
+// MySegmentsBulk returns the list of segments each of the keys in the body (a JSON array of strings) belongs to
+func (c *SdkServerController) MySegmentsBulk(ctx *gin.Context) {
+	c.logger.Debug(fmt.Sprintf("[%s] Headers: %v", middleware.RequestID(ctx), loggableHeaders(ctx.Request.Header)))
+	// cap the body before decoding it, so that the key limit cannot be bypassed by sending a huge payload
+	maxBytes := int64(c.maxBulkKeys) * mySegmentsBulkMaxBytesPerKey
+	var keys []string
+	if err := json.NewDecoder(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBytes)).Decode(&keys); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("payload too large (max %d bytes)", maxBytes)})
+			return
+		}
+		c.logger.Debug("error parsing mySegmentsBulk payload: ", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "expected a JSON array of keys"})
+		return
+	}
+
+	if len(keys) > c.maxBulkKeys {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("too many keys: %d (max %d)", len(keys), c.maxBulkKeys)})
+		return
+	}
+
+	result := make(map[string][]string, len(keys))
+	for _, key := range keys {
+		segmentList, err := c.proxySegmentStorage.SegmentsFor(key)
+		if err != nil {
+			c.logger.Error(fmt.Sprintf("error fetching segments for user '%s': %s", key, err.Error()))
+			ctx.JSON(http.StatusInternalServerError, gin.H{})
+			return
+		}
+
+		if segmentList == nil {
+			segmentList = []string{}
+		}
+		result[key] = segmentList
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// setCacheHeaders allows shared caches (ie: a CDN in front of the proxy) to store the response for a short time.
+// The `since` param is part of the URL so it's already taken into account by caches. Responses vary on the
+// apikey used and the negotiated compression
+func (c *SdkServerController) setCacheHeaders(ctx *gin.Context) {
+	if c.cacheControl == "" {
+		return
+	}
+	ctx.Header("Cache-Control", c.cacheControl)
+	ctx.Header("Vary", "Accept-Encoding, Authorization")
+}
+
 func (c *SdkServerController) fetchSplitChangesSince(since int64, sets []string) (*dtos.SplitChangesDTO, error) {
 	splits, err := c.proxySplitStorage.ChangesSince(since, sets)
 	if err == nil {
@@ -152,7 +280,23 @@ func (c *SdkServerController) fetchSplitChangesSince(since int64, sets []string)
 	// perform a fetch to the BE using the supplied `since`, have the storage process it's response &, retry
 	// TODO(mredolatti): implement basic collapsing here to avoid flooding the BE with requests
 	fetchOptions := service.MakeFlagRequestParams().WithChangeNumber(since).WithFlagSetsFilter(strings.Join(sets, ",")) // at this point the sets have been sanitized & sorted
-	return c.fetcher.Fetch(fetchOptions)
+	splits, fetchErr := c.fetcher.Fetch(fetchOptions)
+	if fetchErr == nil {
+		return splits, nil
+	}
+
+	// serve the whole set of cached feature flags rather than failing, so that SDKs keep a usable (if not minimal) payload
+	splits, err = c.proxySplitStorage.ChangesSince(-1, sets)
+	if err != nil {
+		return nil, fetchErr
+	}
+
+	c.logger.Warning(fmt.Sprintf("error fetching feature flag changes since %d from Split servers (%s). serving cached snapshot instead", since, fetchErr))
+	if c.telemetry != nil {
+		c.telemetry.IncrDegradedResponses()
+	}
+	splits.Since = since
+	return splits, nil
 }
 
 func (c *SdkServerController) shouldOverrideSplitCondition(split *dtos.SplitDTO, version string) bool {
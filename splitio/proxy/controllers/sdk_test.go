@@ -3,10 +3,13 @@ package controllers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/splitio/go-split-commons/v6/dtos"
@@ -19,6 +22,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/breaker"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/flagsets"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
 	psmocks "github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/mocks"
@@ -44,8 +48,11 @@ func TestSplitChangesRecentSince(t *testing.T) {
 		&splitStorage,
 		nil,
 		flagsets.NewMatcher(false, nil),
+		0,
+		0,
+		nil,
 	)
-	controller.Register(group)
+	controller.Register(group, group)
 
 	ctx.Request, _ = http.NewRequest(http.MethodGet, "/api/splitChanges?since=-1", nil)
 	ctx.Request.Header.Set("Authorization", "Bearer someApiKey")
@@ -95,8 +102,11 @@ func TestSplitChangesOlderSince(t *testing.T) {
 		&splitStorage,
 		nil,
 		flagsets.NewMatcher(false, nil),
+		0,
+		0,
+		nil,
 	)
-	controller.Register(group)
+	controller.Register(group, group)
 
 	ctx.Request, _ = http.NewRequest(http.MethodGet, "/api/splitChanges?since=-1", nil)
 	ctx.Request.Header.Set("Authorization", "Bearer someApiKey")
@@ -127,7 +137,7 @@ func TestSplitChangesOlderSinceFetchFails(t *testing.T) {
 	var splitStorage psmocks.ProxySplitStorageMock
 	splitStorage.On("ChangesSince", int64(-1), []string(nil)).
 		Return((*dtos.SplitChangesDTO)(nil), storage.ErrSinceParamTooOld).
-		Once()
+		Twice() // the cached snapshot fallback cannot be built either
 
 	var splitFetcher splitFetcherMock
 	splitFetcher.On("Fetch", ref(*service.MakeFlagRequestParams().WithChangeNumber(-1))).
@@ -146,8 +156,11 @@ func TestSplitChangesOlderSinceFetchFails(t *testing.T) {
 		&splitStorage,
 		nil,
 		flagsets.NewMatcher(false, nil),
+		0,
+		0,
+		nil,
 	)
-	controller.Register(group)
+	controller.Register(group, group)
 
 	ctx.Request, _ = http.NewRequest(http.MethodGet, "/api/splitChanges?since=-1", nil)
 	ctx.Request.Header.Set("Authorization", "Bearer someApiKey")
@@ -162,6 +175,97 @@ func TestSplitChangesOlderSinceFetchFails(t *testing.T) {
 	splitFetcher.AssertExpectations(t)
 }
 
+func TestSplitChangesOlderSinceBreakerOpen(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var splitStorage psmocks.ProxySplitStorageMock
+	splitStorage.On("ChangesSince", int64(-1), []string(nil)).
+		Return((*dtos.SplitChangesDTO)(nil), storage.ErrSinceParamTooOld).
+		Twice() // the cached snapshot fallback cannot be built either
+
+	var splitFetcher splitFetcherMock
+	splitFetcher.On("Fetch", ref(*service.MakeFlagRequestParams().WithChangeNumber(-1))).
+		Return((*dtos.SplitChangesDTO)(nil), fmt.Errorf("%w: no cached data", breaker.ErrCircuitOpen)).
+		Once()
+
+	resp := httptest.NewRecorder()
+	ctx, router := gin.CreateTestContext(resp)
+
+	logger := logging.NewLogger(nil)
+
+	group := router.Group("/api")
+	controller := NewSdkServerController(
+		logger,
+		&splitFetcher,
+		&splitStorage,
+		nil,
+		flagsets.NewMatcher(false, nil),
+		0,
+		0,
+		nil,
+	)
+	controller.Register(group, group)
+
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/api/splitChanges?since=-1", nil)
+	ctx.Request.Header.Set("Authorization", "Bearer someApiKey")
+	router.ServeHTTP(resp, ctx.Request)
+
+	assert.Equal(t, 503, resp.Code)
+
+	splitStorage.AssertExpectations(t)
+	splitFetcher.AssertExpectations(t)
+}
+
+func TestSplitChangesOlderSinceFetchFailsServesSnapshot(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var splitStorage psmocks.ProxySplitStorageMock
+	splitStorage.On("ChangesSince", int64(5), []string(nil)).
+		Return((*dtos.SplitChangesDTO)(nil), storage.ErrSinceParamTooOld).
+		Once()
+	splitStorage.On("ChangesSince", int64(-1), []string(nil)).
+		Return(&dtos.SplitChangesDTO{Since: -1, Till: 10, Splits: []dtos.SplitDTO{{Name: "s1", Status: "ACTIVE"}}}, nil).
+		Once()
+
+	var splitFetcher splitFetcherMock
+	splitFetcher.On("Fetch", ref(*service.MakeFlagRequestParams().WithChangeNumber(5))).
+		Return((*dtos.SplitChangesDTO)(nil), errors.New("something")).
+		Once()
+
+	resp := httptest.NewRecorder()
+	ctx, router := gin.CreateTestContext(resp)
+
+	telemetry := storage.NewProxyTelemetryFacade()
+	group := router.Group("/api")
+	controller := NewSdkServerController(
+		logging.NewLogger(nil),
+		&splitFetcher,
+		&splitStorage,
+		nil,
+		flagsets.NewMatcher(false, nil),
+		0,
+		0,
+		telemetry,
+	)
+	controller.Register(group, group)
+
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/api/splitChanges?since=5", nil)
+	ctx.Request.Header.Set("Authorization", "Bearer someApiKey")
+	router.ServeHTTP(resp, ctx.Request)
+
+	assert.Equal(t, 200, resp.Code)
+
+	var s dtos.SplitChangesDTO
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &s))
+	assert.Equal(t, int64(5), s.Since)
+	assert.Equal(t, int64(10), s.Till)
+	assert.Equal(t, 1, len(s.Splits))
+	assert.Equal(t, int64(1), telemetry.PeekDegradedResponses())
+
+	splitStorage.AssertExpectations(t)
+	splitFetcher.AssertExpectations(t)
+}
+
 func TestSplitChangesWithFlagSets(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -184,8 +288,11 @@ func TestSplitChangesWithFlagSets(t *testing.T) {
 		&splitStorage,
 		nil,
 		flagsets.NewMatcher(false, nil),
+		0,
+		0,
+		nil,
 	)
-	controller.Register(group)
+	controller.Register(group, group)
 
 	ctx.Request, _ = http.NewRequest(http.MethodGet, "/api/splitChanges?since=-1&sets=c,b,b,a", nil)
 	ctx.Request.Header.Set("Authorization", "Bearer someApiKey")
@@ -231,8 +338,11 @@ func TestSplitChangesWithFlagSetsStrict(t *testing.T) {
 		&splitStorage,
 		nil,
 		flagsets.NewMatcher(true, []string{"a", "c"}),
+		0,
+		0,
+		nil,
 	)
-	controller.Register(group)
+	controller.Register(group, group)
 
 	ctx.Request, _ = http.NewRequest(http.MethodGet, "/api/splitChanges?since=-1&sets=c,b,b,a", nil)
 	ctx.Request.Header.Set("Authorization", "Bearer someApiKey")
@@ -296,8 +406,11 @@ func TestSplitChangesNewMatcherOldSpec(t *testing.T) {
 		&splitStorage,
 		nil,
 		flagsets.NewMatcher(false, nil),
+		0,
+		0,
+		nil,
 	)
-	controller.Register(group)
+	controller.Register(group, group)
 
 	ctx.Request, _ = http.NewRequest(http.MethodGet, "/api/splitChanges?since=-1", nil)
 	ctx.Request.Header.Set("Authorization", "Bearer someApiKey")
@@ -364,8 +477,11 @@ func TestSplitChangesNewMatcherNewSpec(t *testing.T) {
 		&splitStorage,
 		nil,
 		flagsets.NewMatcher(false, nil),
+		0,
+		0,
+		nil,
 	)
-	controller.Register(group)
+	controller.Register(group, group)
 
 	ctx.Request, _ = http.NewRequest(http.MethodGet, "/api/splitChanges?since=-1", nil)
 	ctx.Request.Header.Set("Authorization", "Bearer someApiKey")
@@ -414,8 +530,8 @@ func TestSegmentChanges(t *testing.T) {
 	logger := logging.NewLogger(nil)
 
 	group := router.Group("/api")
-	controller := NewSdkServerController(logger, &splitFetcher, &splitStorage, &segmentStorage, flagsets.NewMatcher(false, nil))
-	controller.Register(group)
+	controller := NewSdkServerController(logger, &splitFetcher, &splitStorage, &segmentStorage, flagsets.NewMatcher(false, nil), 0, 0, nil)
+	controller.Register(group, group)
 
 	ctx.Request, _ = http.NewRequest(http.MethodGet, "/api/segmentChanges/someSegment?since=-1", nil)
 	ctx.Request.Header.Set("Authorization", "Bearer someApiKey")
@@ -440,6 +556,45 @@ func TestSegmentChanges(t *testing.T) {
 	segmentStorage.AssertExpectations(t)
 }
 
+func TestSdkCacheControlHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var splitFetcher splitFetcherMock
+	var splitStorage psmocks.ProxySplitStorageMock
+	splitStorage.On("ChangesSince", int64(-1), []string(nil)).
+		Return(&dtos.SplitChangesDTO{Since: -1, Till: 1, Splits: []dtos.SplitDTO{}}, nil).
+		Once()
+	var segmentStorage psmocks.ProxySegmentStorageMock
+	segmentStorage.On("ChangesSince", "someSegment", int64(-1)).
+		Return(&dtos.SegmentChangesDTO{Name: "someSegment", Added: []string{}, Removed: []string{}, Since: -1, Till: 1}, nil).
+		Once()
+	segmentStorage.On("SegmentsFor", "someKey").Return([]string{}, nil).Once()
+
+	router := gin.New()
+	group := router.Group("/api")
+	controller := NewSdkServerController(logging.NewLogger(nil), &splitFetcher, &splitStorage, &segmentStorage, flagsets.NewMatcher(false, nil), 0, 30*time.Second, nil)
+	controller.Register(group, group)
+
+	for _, path := range []string{"/api/splitChanges?since=-1", "/api/segmentChanges/someSegment?since=-1"} {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, 200, resp.Code)
+		assert.Equal(t, "public, max-age=30", resp.Header().Get("Cache-Control"))
+		assert.Equal(t, "Accept-Encoding, Authorization", resp.Header().Get("Vary"))
+	}
+
+	// mySegments responses are per-key & not affected
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/mySegments/someKey", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, "", resp.Header().Get("Cache-Control"))
+
+	splitStorage.AssertExpectations(t)
+	segmentStorage.AssertExpectations(t)
+}
+
 func TestSegmentChangesNotFound(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -456,8 +611,8 @@ func TestSegmentChangesNotFound(t *testing.T) {
 	logger := logging.NewLogger(nil)
 
 	group := router.Group("/api")
-	controller := NewSdkServerController(logger, &splitFetcher, &splitStorage, &segmentStorage, flagsets.NewMatcher(false, nil))
-	controller.Register(group)
+	controller := NewSdkServerController(logger, &splitFetcher, &splitStorage, &segmentStorage, flagsets.NewMatcher(false, nil), 0, 0, nil)
+	controller.Register(group, group)
 
 	ctx.Request, _ = http.NewRequest(http.MethodGet, "/api/segmentChanges/someSegment?since=-1", nil)
 	ctx.Request.Header.Set("Authorization", "Bearer someApiKey")
@@ -488,8 +643,8 @@ func TestMySegments(t *testing.T) {
 	logger := logging.NewLogger(nil)
 
 	group := router.Group("/api")
-	controller := NewSdkServerController(logger, &splitFetcher, &splitStorage, &segmentStorage, flagsets.NewMatcher(false, nil))
-	controller.Register(group)
+	controller := NewSdkServerController(logger, &splitFetcher, &splitStorage, &segmentStorage, flagsets.NewMatcher(false, nil), 0, 0, nil)
+	controller.Register(group, group)
 
 	ctx.Request, _ = http.NewRequest(http.MethodGet, "/api/mySegments/someKey", nil)
 	ctx.Request.Header.Set("Authorization", "Bearer someApiKey")
@@ -529,8 +684,8 @@ func TestMySegmentsError(t *testing.T) {
 	logger := logging.NewLogger(nil)
 
 	group := router.Group("/api")
-	controller := NewSdkServerController(logger, &splitFetcher, &splitStorage, &segmentStorage, flagsets.NewMatcher(false, nil))
-	controller.Register(group)
+	controller := NewSdkServerController(logger, &splitFetcher, &splitStorage, &segmentStorage, flagsets.NewMatcher(false, nil), 0, 0, nil)
+	controller.Register(group, group)
 
 	ctx.Request, _ = http.NewRequest(http.MethodGet, "/api/mySegments/someKey", nil)
 	ctx.Request.Header.Set("Authorization", "Bearer someApiKey")
@@ -545,6 +700,56 @@ func TestMySegmentsError(t *testing.T) {
 	segmentStorage.AssertExpectations(t)
 }
 
+func TestMySegmentsBulk(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var splitFetcher splitFetcherMock
+	var splitStorage psmocks.ProxySplitStorageMock
+	var segmentStorage psmocks.ProxySegmentStorageMock
+	segmentStorage.On("SegmentsFor", "k1").Return([]string{"segment1", "segment2"}, nil).Once()
+	segmentStorage.On("SegmentsFor", "k2").Return([]string(nil), nil).Once()
+
+	resp := httptest.NewRecorder()
+	ctx, router := gin.CreateTestContext(resp)
+
+	logger := logging.NewLogger(nil)
+
+	group := router.Group("/api")
+	controller := NewSdkServerController(logger, &splitFetcher, &splitStorage, &segmentStorage, flagsets.NewMatcher(false, nil), 2, 0, nil)
+	controller.Register(group, group)
+
+	ctx.Request, _ = http.NewRequest(http.MethodPost, "/api/mySegmentsBulk", strings.NewReader(`["k1", "k2"]`))
+	ctx.Request.Header.Set("Authorization", "Bearer someApiKey")
+	router.ServeHTTP(resp, ctx.Request)
+	assert.Equal(t, 200, resp.Code)
+
+	var result map[string][]string
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Equal(t, map[string][]string{"k1": {"segment1", "segment2"}, "k2": {}}, result)
+
+	// too many keys
+	resp = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/mySegmentsBulk", strings.NewReader(`["k1", "k2", "k3"]`))
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, 400, resp.Code)
+
+	// body too large, regardless of the number of keys
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/api/mySegmentsBulk", strings.NewReader(`["`+strings.Repeat("k", 3*mySegmentsBulkMaxBytesPerKey)+`"]`))
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, 400, resp.Code)
+
+	// not an array of keys
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/api/mySegmentsBulk", strings.NewReader(`{"keys": ["k1"]}`))
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, 400, resp.Code)
+
+	splitStorage.AssertExpectations(t)
+	splitFetcher.AssertExpectations(t)
+	segmentStorage.AssertExpectations(t)
+}
+
 type splitFetcherMock struct {
 	mock.Mock
 }
@@ -564,3 +769,55 @@ type MSC struct {
 }
 
 var _ service.SplitFetcher = (*splitFetcherMock)(nil)
+
+type serializedSplitStorageMock struct {
+	psmocks.ProxySplitStorageMock
+}
+
+func (s *serializedSplitStorageMock) SerializedChangesSince(since int64, flagSets []string, variant string, prepare func(*dtos.SplitChangesDTO)) (*storage.SerializedChanges, error) {
+	args := s.Called(since, flagSets, variant)
+	return args.Get(0).(*storage.SerializedChanges), args.Error(1)
+}
+
+func TestSplitChangesSerialized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var splitFetcher splitFetcherMock
+	var splitStorage serializedSplitStorageMock
+	splitStorage.On("SerializedChangesSince", int64(-1), []string(nil), specs.FLAG_V1_1).
+		Return(&storage.SerializedChanges{Till: 1, Body: []byte(`{"splits":[],"since":-1,"till":1}`)}, nil).
+		Once()
+
+	// when the storage doesn't have the data, the upstream fetch is used
+	splitStorage.On("SerializedChangesSince", int64(1), []string(nil), specs.FLAG_V1_0).
+		Return((*storage.SerializedChanges)(nil), storage.ErrSinceParamTooOld).
+		Once()
+	splitStorage.On("ChangesSince", int64(1), []string(nil)).Return((*dtos.SplitChangesDTO)(nil), storage.ErrSinceParamTooOld).Once()
+	splitFetcher.On("Fetch", ref(*service.MakeFlagRequestParams().WithChangeNumber(1))).
+		Return(&dtos.SplitChangesDTO{Since: 1, Till: 2, Splits: []dtos.SplitDTO{}}, nil).
+		Once()
+
+	router := gin.New()
+	group := router.Group("/api")
+	controller := NewSdkServerController(logging.NewLogger(nil), &splitFetcher, &splitStorage, nil, flagsets.NewMatcher(false, nil), 0, 0, nil)
+	controller.Register(group, group)
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/splitChanges?since=-1&s="+specs.FLAG_V1_1, nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, `{"splits":[],"since":-1,"till":1}`, resp.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header().Get("Content-Type"))
+	assert.Equal(t, splitChangesETag(-1, nil, specs.FLAG_V1_1, 1), resp.Header().Get("ETag"))
+
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/splitChanges?since=1", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, 200, resp.Code)
+	var s dtos.SplitChangesDTO
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &s))
+	assert.Equal(t, int64(2), s.Till)
+
+	splitStorage.AssertExpectations(t)
+	splitFetcher.AssertExpectations(t)
+}
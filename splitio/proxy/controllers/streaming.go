@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/streaming"
+)
+
+// SDKs drop the connection after 70 seconds without receiving anything
+const streamingKeepAlivePeriod = 30 * time.Second
+
+// StreamingServerController serves the SSE endpoint SDKs connect to in order to get notified of changes
+type StreamingServerController struct {
+	logger      logging.LoggerInterface
+	broadcaster *streaming.Broadcaster
+	tokens      *streaming.TokenIssuer
+	keepAlive   time.Duration
+}
+
+// NewStreamingServerController instantiates a new streaming controller
+func NewStreamingServerController(
+	logger logging.LoggerInterface,
+	broadcaster *streaming.Broadcaster,
+	tokens *streaming.TokenIssuer,
+) *StreamingServerController {
+	return &StreamingServerController{
+		logger:      logger,
+		broadcaster: broadcaster,
+		tokens:      tokens,
+		keepAlive:   streamingKeepAlivePeriod,
+	}
+}
+
+// Register mounts the streaming endpoint onto the supplied router
+func (c *StreamingServerController) Register(router gin.IRouter) {
+	router.GET("/sse", c.SSE)
+}
+
+// SSE validates the token issued in the auth response (and that it grants access to every requested channel) & keeps the connection open, pushing notifications as they arrive
+func (c *StreamingServerController) SSE(ctx *gin.Context) {
+	granted, err := c.tokens.Validate(ctx.Query("accessToken"))
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var channels []string
+	if raw := ctx.Query("channels"); raw != "" {
+		channels = strings.Split(raw, ",")
+	}
+	for _, channel := range channels {
+		if _, ok := granted[streaming.ChannelName(channel)]; !ok {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": fmt.Sprintf("channel '%s' is not granted by the token", channel)})
+			return
+		}
+	}
+
+	// this connection is meant to outlive the server's read & write timeouts
+	rc := http.NewResponseController(ctx.Writer)
+	if err := rc.SetReadDeadline(time.Time{}); err != nil {
+		c.logger.Debug("could not clear read deadline for streaming connection: ", err)
+	}
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		c.logger.Debug("could not clear write deadline for streaming connection: ", err)
+	}
+
+	events, unsubscribe := c.broadcaster.Subscribe(channels)
+	defer unsubscribe()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	for _, event := range c.broadcaster.Occupancy() {
+		ctx.Writer.Write(event)
+	}
+	ctx.Writer.Flush()
+
+	keepAlive := time.NewTicker(c.keepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok { // too slow to keep up, the SDK will reconnect
+				return
+			}
+			if _, err := ctx.Writer.Write(event); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := ctx.Writer.Write([]byte(":keepalive\n\n")); err != nil {
+				return
+			}
+		}
+		ctx.Writer.Flush()
+	}
+}
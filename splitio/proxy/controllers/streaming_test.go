@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v6/conf"
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-split-commons/v6/push"
+	"github.com/splitio/go-split-commons/v6/service/api/sse"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/streaming"
+)
+
+func TestAuthWithStreaming(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tokens, err := streaming.NewTokenIssuer("")
+	assert.Nil(t, err)
+
+	router := gin.New()
+	NewAuthServerController(logging.NewLogger(nil), tokens).Register(router.Group("/api"))
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v2/auth", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, 200, resp.Code)
+
+	var token dtos.Token
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &token))
+	assert.True(t, token.PushEnabled)
+	_, err = tokens.Validate(token.Token)
+	assert.Nil(t, err)
+
+	channels, err := token.ChannelList()
+	assert.Nil(t, err)
+	assert.NotContains(t, channels, streaming.MySegmentsChannel("someKey"))
+
+	// client-side sdks get channels for their keys
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/v2/auth?users=someKey&users=otherKey", nil)
+	router.ServeHTTP(resp, req)
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &token))
+	assert.True(t, token.PushEnabled)
+	channels, err = token.ChannelList()
+	assert.Nil(t, err)
+	assert.Contains(t, channels, streaming.MySegmentsChannel("someKey"))
+	assert.Contains(t, channels, streaming.MySegmentsChannel("otherKey"))
+
+	// push is disabled if streaming is not
+	router = gin.New()
+	NewAuthServerController(logging.NewLogger(nil), nil).Register(router.Group("/api"))
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/v2/auth", nil)
+	router.ServeHTTP(resp, req)
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &token))
+	assert.False(t, token.PushEnabled)
+	assert.Equal(t, "", token.Token)
+}
+
+func TestStreamingEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logging.NewLogger(nil)
+	tokens, err := streaming.NewTokenIssuer("")
+	assert.Nil(t, err)
+	broadcaster := streaming.NewBroadcaster(logger)
+
+	router := gin.New()
+	NewStreamingServerController(logger, broadcaster, tokens).Register(router)
+	server := httptest.NewUnstartedServer(router)
+	server.Config.ReadTimeout = 200 * time.Millisecond
+	server.Config.WriteTimeout = 200 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	// invalid tokens are rejected
+	resp, err := http.Get(server.URL + "/sse?accessToken=invalid")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp.Body.Close()
+
+	// channels not granted by the token are rejected
+	otherKeyToken, err := tokens.Issue([]string{"someKey"})
+	assert.Nil(t, err)
+	resp, err = http.Get(server.URL + "/sse?accessToken=" + otherKeyToken + "&channels=" + streaming.MySegmentsChannel("otherKey"))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp.Body.Close()
+
+	var mutex sync.Mutex
+	var splitUpdates []int64
+	var kills []string
+	var segmentUpdates []string
+	var publishers []int64
+	parser := push.NewNotificationParserImpl(
+		logger,
+		func(u *dtos.SplitChangeUpdate) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			splitUpdates = append(splitUpdates, u.ChangeNumber())
+			return nil
+		},
+		func(u *dtos.SplitKillUpdate) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			kills = append(kills, u.SplitName()+":"+u.DefaultTreatment())
+			return nil
+		},
+		func(u *dtos.SegmentChangeUpdate) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			segmentUpdates = append(segmentUpdates, u.SegmentName())
+			return nil
+		},
+		func(*dtos.ControlUpdate) *int64 { return nil },
+		func(o *dtos.OccupancyMessage) *int64 {
+			mutex.Lock()
+			defer mutex.Unlock()
+			publishers = append(publishers, o.Publishers())
+			return nil
+		},
+		func(*dtos.AblyError) *int64 { return nil },
+	)
+
+	cfg := conf.GetDefaultAdvancedConfig()
+	cfg.StreamingServiceURL = server.URL + "/sse"
+	client := sse.NewStreamingClient(&cfg, logger, dtos.Metadata{}, nil)
+	token, err := tokens.Issue(nil)
+	assert.Nil(t, err)
+	channels, err := (&dtos.Token{Token: token, PushEnabled: true}).ChannelList()
+	assert.Nil(t, err)
+
+	status := make(chan int, 10)
+	client.ConnectStreaming(token, status, channels, func(m sse.IncomingMessage) {
+		_, err := parser.ParseAndForward(m)
+		assert.Nil(t, err)
+	})
+	defer client.StopStreaming()
+
+	assert.Equal(t, sse.StatusFirstEventOk, <-status)
+
+	// notifications must keep flowing after the server's read & write timeouts have elapsed
+	time.Sleep(500 * time.Millisecond)
+	broadcaster.NotifySplitUpdate(123)
+	broadcaster.NotifySplitKill("someSplit", "off", 124)
+	broadcaster.NotifySegmentUpdate("someSegment", 125)
+
+	assert.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(segmentUpdates) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, []int64{1, 1}, publishers)
+	assert.Equal(t, []int64{123}, splitUpdates)
+	assert.Equal(t, []string{"someSplit:off"}, kills)
+	assert.Equal(t, []string{"someSegment"}, segmentUpdates)
+	assert.Equal(t, 1, broadcaster.Subscribers())
+}
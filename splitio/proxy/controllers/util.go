@@ -1,17 +1,27 @@
 package controllers
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/splitio/go-split-commons/v6/conf"
 	"github.com/splitio/go-split-commons/v6/dtos"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/util"
 )
 
+// metadataFromHeaders returns the metadata sent by the SDK, which is forwarded as is to Split servers.
+// The machine name is only derived from the ip if the SDK didn't send one
 func metadataFromHeaders(ctx *gin.Context) dtos.Metadata {
-	return dtos.Metadata{
+	metadata := dtos.Metadata{
 		SDKVersion:  ctx.Request.Header.Get("SplitSDKVersion"),
 		MachineIP:   ctx.Request.Header.Get("SplitSDKMachineIP"),
 		MachineName: ctx.Request.Header.Get("SplitSDKMachineName"),
 	}
+	if metadata.MachineName == "" && metadata.MachineIP != "" && metadata.MachineIP != "NA" {
+		metadata.MachineName = util.MachineNameFromIP(metadata.MachineIP)
+	}
+	return metadata
 }
 
 func parseImpressionsMode(mode string) string {
@@ -20,3 +30,17 @@ func parseImpressionsMode(mode string) string {
 	}
 	return conf.ImpressionsModeDebug
 }
+
+// headers that carry credentials & must not be logged
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// loggableHeaders returns a copy of the supplied headers with the credentials masked
+func loggableHeaders(headers http.Header) http.Header {
+	toRet := headers.Clone()
+	for _, name := range redactedHeaders {
+		if _, ok := toRet[name]; ok {
+			toRet[name] = []string{"<redacted>"}
+		}
+	}
+	return toRet
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataFromHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	metadataFor := func(headers map[string]string) dtos.Metadata {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request, _ = http.NewRequest(http.MethodPost, "/api/events/bulk", nil)
+		for name, value := range headers {
+			ctx.Request.Header.Set(name, value)
+		}
+		return metadataFromHeaders(ctx)
+	}
+
+	// the machine name sent by the sdk is kept
+	assert.Equal(t,
+		dtos.Metadata{SDKVersion: "go-1.1.1", MachineIP: "1.2.3.4", MachineName: "some-host"},
+		metadataFor(map[string]string{"SplitSDKVersion": "go-1.1.1", "SplitSDKMachineIP": "1.2.3.4", "SplitSDKMachineName": "some-host"}))
+
+	// and derived from the ip only if missing
+	assert.Equal(t,
+		dtos.Metadata{SDKVersion: "go-1.1.1", MachineIP: "1.2.3.4", MachineName: "ip-1-2-3-4"},
+		metadataFor(map[string]string{"SplitSDKVersion": "go-1.1.1", "SplitSDKMachineIP": "1.2.3.4"}))
+
+	assert.Equal(t,
+		dtos.Metadata{SDKVersion: "js-1.1.1", MachineIP: "NA"},
+		metadataFor(map[string]string{"SplitSDKVersion": "js-1.1.1", "SplitSDKMachineIP": "NA"}))
+}
+
+func TestLoggableHeaders(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("Authorization", "Bearer someApiKey")
+	headers.Set("SplitSDKVersion", "go-1.1.1")
+
+	loggable := loggableHeaders(headers)
+	assert.Equal(t, "<redacted>", loggable.Get("Authorization"))
+	assert.Equal(t, "go-1.1.1", loggable.Get("SplitSDKVersion"))
+	assert.Equal(t, "Bearer someApiKey", headers.Get("Authorization"), "the original headers should not be modified")
+}
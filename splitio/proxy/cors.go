@@ -0,0 +1,65 @@
+package proxy
+
+import (
+	"time"
+
+	"github.com/gin-contrib/cors"
+
+	pconf "github.com/splitio/split-synchronizer/v5/splitio/proxy/conf"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/controllers/middleware"
+)
+
+// headers sent by split SDKs, always allowed when CORS is enabled
+var sdkHeaders = []string{
+	"Origin",
+	"Content-Length",
+	"Content-Type",
+	"SplitSDKMachineName",
+	"SplitSDKMachineIP",
+	"SplitSDKVersion",
+	"SplitSDKImpressionsMode",
+	"Authorization",
+	middleware.RequestIDHeader,
+}
+
+// ErrCORSCredentialsWithAnyOrigin is returned when credentials are allowed for every origin, which browsers reject
+var ErrCORSCredentialsWithAnyOrigin = pconf.ErrCORSCredentialsWithAnyOrigin
+
+// CORSOptions bundles the cross-origin settings applied to the proxy endpoints
+type CORSOptions struct {
+	Enabled          bool
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string // on top of the ones sent by split SDKs
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// Validate returns an error if the settings cannot be applied
+func (o *CORSOptions) Validate() error {
+	return (&pconf.CORS{Enabled: o.Enabled, AllowedOrigins: o.AllowedOrigins, AllowCredentials: o.AllowCredentials}).Validate()
+}
+
+func (o *CORSOptions) corsConfig() cors.Config {
+	config := cors.Config{
+		AllowMethods:     nonEmpty(o.AllowedMethods),
+		AllowHeaders:     append(append([]string{}, sdkHeaders...), nonEmpty(o.AllowedHeaders)...),
+		ExposeHeaders:    []string{middleware.RequestIDHeader},
+		AllowCredentials: o.AllowCredentials,
+		MaxAge:           o.MaxAge,
+	}
+
+	config.AllowOrigins, config.AllowAllOrigins = pconf.CORSOrigins(o.AllowedOrigins)
+	return config
+}
+
+// nonEmpty drops empty items, which is what an empty comma-separated list is parsed into
+func nonEmpty(items []string) []string {
+	toRet := make([]string, 0, len(items))
+	for _, item := range items {
+		if item != "" {
+			toRet = append(toRet, item)
+		}
+	}
+	return toRet
+}
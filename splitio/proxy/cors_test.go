@@ -0,0 +1,75 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSOptionsValidation(t *testing.T) {
+	assert.Nil(t, (&CORSOptions{}).Validate()) // disabled
+	assert.Nil(t, (&CORSOptions{Enabled: true, AllowedOrigins: []string{"*"}}).Validate())
+	assert.Nil(t, (&CORSOptions{Enabled: true, AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true}).Validate())
+	assert.NotNil(t, (&CORSOptions{Enabled: true, AllowedOrigins: []string{""}}).Validate())
+	assert.NotNil(t, (&CORSOptions{Enabled: true, AllowedOrigins: []string{"app.example.com"}}).Validate())
+
+	err := (&CORSOptions{Enabled: true, AllowedOrigins: []string{"*"}, AllowCredentials: true}).Validate()
+	assert.True(t, errors.Is(err, ErrCORSCredentialsWithAnyOrigin))
+}
+
+func TestCORSPreflight(t *testing.T) {
+	opts := makeOpts()
+	opts.CORS = CORSOptions{
+		Enabled:          true,
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"X-Custom"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}
+	proxy := New(opts)
+	go proxy.Start()
+	time.Sleep(1 * time.Second) // Let the scheduler switch the current thread/gr and start the server
+
+	preflight := func(origin string) *http.Response {
+		req, _ := http.NewRequest(http.MethodOptions, fmt.Sprintf("http://localhost:%d/api/splitChanges", opts.Port), nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "GET")
+		resp, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := preflight("https://app.example.com")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET,POST", resp.Header.Get("Access-Control-Allow-Methods"))
+	assert.Contains(t, resp.Header.Get("Access-Control-Allow-Headers"), "X-Custom")
+	assert.Contains(t, resp.Header.Get("Access-Control-Allow-Headers"), "Splitsdkversion")
+	assert.Equal(t, "true", resp.Header.Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "3600", resp.Header.Get("Access-Control-Max-Age"))
+
+	resp = preflight("https://evil.example.com")
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSDisabled(t *testing.T) {
+	opts := makeOpts()
+	proxy := New(opts)
+	go proxy.Start()
+	time.Sleep(1 * time.Second) // Let the scheduler switch the current thread/gr and start the server
+
+	req, _ := http.NewRequest(http.MethodOptions, fmt.Sprintf("http://localhost:%d/api/splitChanges", opts.Port), nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	resp, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+}
@@ -0,0 +1,4 @@
+// Package ingestpb contains the protobuf messages accepted by the gRPC ingest server
+package ingestpb
+
+//go:generate protoc -I ../../../.. --go_out=../../../.. --go_opt=paths=source_relative splitio/proxy/grpcingest/ingestpb/ingest.proto
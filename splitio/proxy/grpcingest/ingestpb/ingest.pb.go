@@ -0,0 +1,724 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.1
+// 	protoc        (unknown)
+// source: splitio/proxy/grpcingest/ingestpb/ingest.proto
+
+package ingestpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Metadata identifies the sdk or service submitting data, as the SplitSDK* headers do over HTTP
+type Metadata struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SdkVersion  string `protobuf:"bytes,1,opt,name=sdk_version,json=sdkVersion,proto3" json:"sdk_version,omitempty"`
+	MachineIp   string `protobuf:"bytes,2,opt,name=machine_ip,json=machineIp,proto3" json:"machine_ip,omitempty"`
+	MachineName string `protobuf:"bytes,3,opt,name=machine_name,json=machineName,proto3" json:"machine_name,omitempty"`
+}
+
+func (x *Metadata) Reset() {
+	*x = Metadata{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Metadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Metadata) ProtoMessage() {}
+
+func (x *Metadata) ProtoReflect() protoreflect.Message {
+	mi := &file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Metadata.ProtoReflect.Descriptor instead.
+func (*Metadata) Descriptor() ([]byte, []int) {
+	return file_splitio_proxy_grpcingest_ingestpb_ingest_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Metadata) GetSdkVersion() string {
+	if x != nil {
+		return x.SdkVersion
+	}
+	return ""
+}
+
+func (x *Metadata) GetMachineIp() string {
+	if x != nil {
+		return x.MachineIp
+	}
+	return ""
+}
+
+func (x *Metadata) GetMachineName() string {
+	if x != nil {
+		return x.MachineName
+	}
+	return ""
+}
+
+type KeyImpression struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	KeyName      string `protobuf:"bytes,1,opt,name=key_name,json=keyName,proto3" json:"key_name,omitempty"`
+	Treatment    string `protobuf:"bytes,2,opt,name=treatment,proto3" json:"treatment,omitempty"`
+	Time         int64  `protobuf:"varint,3,opt,name=time,proto3" json:"time,omitempty"`
+	ChangeNumber int64  `protobuf:"varint,4,opt,name=change_number,json=changeNumber,proto3" json:"change_number,omitempty"`
+	Label        string `protobuf:"bytes,5,opt,name=label,proto3" json:"label,omitempty"`
+	BucketingKey string `protobuf:"bytes,6,opt,name=bucketing_key,json=bucketingKey,proto3" json:"bucketing_key,omitempty"`
+	PreviousTime int64  `protobuf:"varint,7,opt,name=previous_time,json=previousTime,proto3" json:"previous_time,omitempty"`
+}
+
+func (x *KeyImpression) Reset() {
+	*x = KeyImpression{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KeyImpression) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyImpression) ProtoMessage() {}
+
+func (x *KeyImpression) ProtoReflect() protoreflect.Message {
+	mi := &file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyImpression.ProtoReflect.Descriptor instead.
+func (*KeyImpression) Descriptor() ([]byte, []int) {
+	return file_splitio_proxy_grpcingest_ingestpb_ingest_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *KeyImpression) GetKeyName() string {
+	if x != nil {
+		return x.KeyName
+	}
+	return ""
+}
+
+func (x *KeyImpression) GetTreatment() string {
+	if x != nil {
+		return x.Treatment
+	}
+	return ""
+}
+
+func (x *KeyImpression) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *KeyImpression) GetChangeNumber() int64 {
+	if x != nil {
+		return x.ChangeNumber
+	}
+	return 0
+}
+
+func (x *KeyImpression) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *KeyImpression) GetBucketingKey() string {
+	if x != nil {
+		return x.BucketingKey
+	}
+	return ""
+}
+
+func (x *KeyImpression) GetPreviousTime() int64 {
+	if x != nil {
+		return x.PreviousTime
+	}
+	return 0
+}
+
+type FeatureImpressions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Feature     string           `protobuf:"bytes,1,opt,name=feature,proto3" json:"feature,omitempty"`
+	Impressions []*KeyImpression `protobuf:"bytes,2,rep,name=impressions,proto3" json:"impressions,omitempty"`
+}
+
+func (x *FeatureImpressions) Reset() {
+	*x = FeatureImpressions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FeatureImpressions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FeatureImpressions) ProtoMessage() {}
+
+func (x *FeatureImpressions) ProtoReflect() protoreflect.Message {
+	mi := &file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FeatureImpressions.ProtoReflect.Descriptor instead.
+func (*FeatureImpressions) Descriptor() ([]byte, []int) {
+	return file_splitio_proxy_grpcingest_ingestpb_ingest_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *FeatureImpressions) GetFeature() string {
+	if x != nil {
+		return x.Feature
+	}
+	return ""
+}
+
+func (x *FeatureImpressions) GetImpressions() []*KeyImpression {
+	if x != nil {
+		return x.Impressions
+	}
+	return nil
+}
+
+// ImpressionsRequest is the gRPC counterpart of a POST /api/testImpressions/bulk
+type ImpressionsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Metadata *Metadata `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// same as the SplitSDKImpressionsMode header (optimized if empty)
+	ImpressionsMode string                `protobuf:"bytes,2,opt,name=impressions_mode,json=impressionsMode,proto3" json:"impressions_mode,omitempty"`
+	Impressions     []*FeatureImpressions `protobuf:"bytes,3,rep,name=impressions,proto3" json:"impressions,omitempty"`
+}
+
+func (x *ImpressionsRequest) Reset() {
+	*x = ImpressionsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImpressionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImpressionsRequest) ProtoMessage() {}
+
+func (x *ImpressionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImpressionsRequest.ProtoReflect.Descriptor instead.
+func (*ImpressionsRequest) Descriptor() ([]byte, []int) {
+	return file_splitio_proxy_grpcingest_ingestpb_ingest_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ImpressionsRequest) GetMetadata() *Metadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *ImpressionsRequest) GetImpressionsMode() string {
+	if x != nil {
+		return x.ImpressionsMode
+	}
+	return ""
+}
+
+func (x *ImpressionsRequest) GetImpressions() []*FeatureImpressions {
+	if x != nil {
+		return x.Impressions
+	}
+	return nil
+}
+
+type Event struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key             string           `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	TrafficTypeName string           `protobuf:"bytes,2,opt,name=traffic_type_name,json=trafficTypeName,proto3" json:"traffic_type_name,omitempty"`
+	EventTypeId     string           `protobuf:"bytes,3,opt,name=event_type_id,json=eventTypeId,proto3" json:"event_type_id,omitempty"`
+	Value           *float64         `protobuf:"fixed64,4,opt,name=value,proto3,oneof" json:"value,omitempty"`
+	Timestamp       int64            `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Properties      *structpb.Struct `protobuf:"bytes,6,opt,name=properties,proto3" json:"properties,omitempty"`
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_splitio_proxy_grpcingest_ingestpb_ingest_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Event) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Event) GetTrafficTypeName() string {
+	if x != nil {
+		return x.TrafficTypeName
+	}
+	return ""
+}
+
+func (x *Event) GetEventTypeId() string {
+	if x != nil {
+		return x.EventTypeId
+	}
+	return ""
+}
+
+func (x *Event) GetValue() float64 {
+	if x != nil && x.Value != nil {
+		return *x.Value
+	}
+	return 0
+}
+
+func (x *Event) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *Event) GetProperties() *structpb.Struct {
+	if x != nil {
+		return x.Properties
+	}
+	return nil
+}
+
+// EventsRequest is the gRPC counterpart of a POST /api/events/bulk
+type EventsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Metadata *Metadata `protobuf:"bytes,1,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Events   []*Event  `protobuf:"bytes,2,rep,name=events,proto3" json:"events,omitempty"`
+}
+
+func (x *EventsRequest) Reset() {
+	*x = EventsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventsRequest) ProtoMessage() {}
+
+func (x *EventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventsRequest.ProtoReflect.Descriptor instead.
+func (*EventsRequest) Descriptor() ([]byte, []int) {
+	return file_splitio_proxy_grpcingest_ingestpb_ingest_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *EventsRequest) GetMetadata() *Metadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *EventsRequest) GetEvents() []*Event {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+// Ack is returned once a bulk has been accepted
+type Ack struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *Ack) Reset() {
+	*x = Ack{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Ack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ack) ProtoMessage() {}
+
+func (x *Ack) ProtoReflect() protoreflect.Message {
+	mi := &file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ack.ProtoReflect.Descriptor instead.
+func (*Ack) Descriptor() ([]byte, []int) {
+	return file_splitio_proxy_grpcingest_ingestpb_ingest_proto_rawDescGZIP(), []int{6}
+}
+
+var File_splitio_proxy_grpcingest_ingestpb_ingest_proto protoreflect.FileDescriptor
+
+var file_splitio_proxy_grpcingest_ingestpb_ingest_proto_rawDesc = []byte{
+	0x0a, 0x2e, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x69, 0x6f, 0x2f, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x2f,
+	0x67, 0x72, 0x70, 0x63, 0x69, 0x6e, 0x67, 0x65, 0x73, 0x74, 0x2f, 0x69, 0x6e, 0x67, 0x65, 0x73,
+	0x74, 0x70, 0x62, 0x2f, 0x69, 0x6e, 0x67, 0x65, 0x73, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x0d, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x69, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x1a,
+	0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2f, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x6d, 0x0a,
+	0x08, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x64, 0x6b,
+	0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x73, 0x64, 0x6b, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x61,
+	0x63, 0x68, 0x69, 0x6e, 0x65, 0x5f, 0x69, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x49, 0x70, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x61, 0x63,
+	0x68, 0x69, 0x6e, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0xe1, 0x01, 0x0a,
+	0x0d, 0x4b, 0x65, 0x79, 0x49, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x19,
+	0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x6b, 0x65, 0x79, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x72, 0x65,
+	0x61, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x74, 0x72,
+	0x65, 0x61, 0x74, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x63,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0c, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72,
+	0x12, 0x14, 0x0a, 0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x12, 0x23, 0x0a, 0x0d, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74,
+	0x69, 0x6e, 0x67, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x62,
+	0x75, 0x63, 0x6b, 0x65, 0x74, 0x69, 0x6e, 0x67, 0x4b, 0x65, 0x79, 0x12, 0x23, 0x0a, 0x0d, 0x70,
+	0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0c, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x54, 0x69, 0x6d, 0x65,
+	0x22, 0x6e, 0x0a, 0x12, 0x46, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x49, 0x6d, 0x70, 0x72, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x66, 0x65, 0x61, 0x74, 0x75, 0x72,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x66, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65,
+	0x12, 0x3e, 0x0a, 0x0b, 0x69, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x69, 0x6f, 0x2e,
+	0x70, 0x72, 0x6f, 0x78, 0x79, 0x2e, 0x4b, 0x65, 0x79, 0x49, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x69, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73,
+	0x22, 0xb9, 0x01, 0x0a, 0x12, 0x49, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x33, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x73, 0x70, 0x6c, 0x69,
+	0x74, 0x69, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61,
+	0x74, 0x61, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x29, 0x0a, 0x10,
+	0x69, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x5f, 0x6d, 0x6f, 0x64, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x69, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x73, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x43, 0x0a, 0x0b, 0x69, 0x6d, 0x70, 0x72, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x73,
+	0x70, 0x6c, 0x69, 0x74, 0x69, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x2e, 0x46, 0x65, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x49, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52,
+	0x0b, 0x69, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0xe5, 0x01, 0x0a,
+	0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x2a, 0x0a, 0x11, 0x74, 0x72, 0x61, 0x66,
+	0x66, 0x69, 0x63, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0f, 0x74, 0x72, 0x61, 0x66, 0x66, 0x69, 0x63, 0x54, 0x79, 0x70, 0x65,
+	0x4e, 0x61, 0x6d, 0x65, 0x12, 0x22, 0x0a, 0x0d, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79,
+	0x70, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x65, 0x76, 0x65,
+	0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x49, 0x64, 0x12, 0x19, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x88, 0x01, 0x01, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x12, 0x37, 0x0a, 0x0a, 0x70, 0x72, 0x6f, 0x70, 0x65, 0x72, 0x74, 0x69, 0x65, 0x73, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x52, 0x0a,
+	0x70, 0x72, 0x6f, 0x70, 0x65, 0x72, 0x74, 0x69, 0x65, 0x73, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x22, 0x72, 0x0a, 0x0d, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x33, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74,
+	0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x69,
+	0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61,
+	0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x2c, 0x0a, 0x06, 0x65, 0x76,
+	0x65, 0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x73, 0x70, 0x6c,
+	0x69, 0x74, 0x69, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x52, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x22, 0x05, 0x0a, 0x03, 0x41, 0x63, 0x6b, 0x32,
+	0x96, 0x01, 0x0a, 0x06, 0x49, 0x6e, 0x67, 0x65, 0x73, 0x74, 0x12, 0x4a, 0x0a, 0x11, 0x53, 0x75,
+	0x62, 0x6d, 0x69, 0x74, 0x49, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12,
+	0x21, 0x2e, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x69, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x2e,
+	0x49, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x12, 0x2e, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x69, 0x6f, 0x2e, 0x70, 0x72, 0x6f,
+	0x78, 0x79, 0x2e, 0x41, 0x63, 0x6b, 0x12, 0x40, 0x0a, 0x0c, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74,
+	0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x1c, 0x2e, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x69, 0x6f,
+	0x2e, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x69, 0x6f, 0x2e, 0x70,
+	0x72, 0x6f, 0x78, 0x79, 0x2e, 0x41, 0x63, 0x6b, 0x42, 0x4c, 0x5a, 0x4a, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x69, 0x6f, 0x2f, 0x73,
+	0x70, 0x6c, 0x69, 0x74, 0x2d, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x65,
+	0x72, 0x2f, 0x76, 0x35, 0x2f, 0x73, 0x70, 0x6c, 0x69, 0x74, 0x69, 0x6f, 0x2f, 0x70, 0x72, 0x6f,
+	0x78, 0x79, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x69, 0x6e, 0x67, 0x65, 0x73, 0x74, 0x2f, 0x69, 0x6e,
+	0x67, 0x65, 0x73, 0x74, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_splitio_proxy_grpcingest_ingestpb_ingest_proto_rawDescOnce sync.Once
+	file_splitio_proxy_grpcingest_ingestpb_ingest_proto_rawDescData = file_splitio_proxy_grpcingest_ingestpb_ingest_proto_rawDesc
+)
+
+func file_splitio_proxy_grpcingest_ingestpb_ingest_proto_rawDescGZIP() []byte {
+	file_splitio_proxy_grpcingest_ingestpb_ingest_proto_rawDescOnce.Do(func() {
+		file_splitio_proxy_grpcingest_ingestpb_ingest_proto_rawDescData = protoimpl.X.CompressGZIP(file_splitio_proxy_grpcingest_ingestpb_ingest_proto_rawDescData)
+	})
+	return file_splitio_proxy_grpcingest_ingestpb_ingest_proto_rawDescData
+}
+
+var file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_splitio_proxy_grpcingest_ingestpb_ingest_proto_goTypes = []interface{}{
+	(*Metadata)(nil),           // 0: splitio.proxy.Metadata
+	(*KeyImpression)(nil),      // 1: splitio.proxy.KeyImpression
+	(*FeatureImpressions)(nil), // 2: splitio.proxy.FeatureImpressions
+	(*ImpressionsRequest)(nil), // 3: splitio.proxy.ImpressionsRequest
+	(*Event)(nil),              // 4: splitio.proxy.Event
+	(*EventsRequest)(nil),      // 5: splitio.proxy.EventsRequest
+	(*Ack)(nil),                // 6: splitio.proxy.Ack
+	(*structpb.Struct)(nil),    // 7: google.protobuf.Struct
+}
+var file_splitio_proxy_grpcingest_ingestpb_ingest_proto_depIdxs = []int32{
+	1, // 0: splitio.proxy.FeatureImpressions.impressions:type_name -> splitio.proxy.KeyImpression
+	0, // 1: splitio.proxy.ImpressionsRequest.metadata:type_name -> splitio.proxy.Metadata
+	2, // 2: splitio.proxy.ImpressionsRequest.impressions:type_name -> splitio.proxy.FeatureImpressions
+	7, // 3: splitio.proxy.Event.properties:type_name -> google.protobuf.Struct
+	0, // 4: splitio.proxy.EventsRequest.metadata:type_name -> splitio.proxy.Metadata
+	4, // 5: splitio.proxy.EventsRequest.events:type_name -> splitio.proxy.Event
+	3, // 6: splitio.proxy.Ingest.SubmitImpressions:input_type -> splitio.proxy.ImpressionsRequest
+	5, // 7: splitio.proxy.Ingest.SubmitEvents:input_type -> splitio.proxy.EventsRequest
+	6, // 8: splitio.proxy.Ingest.SubmitImpressions:output_type -> splitio.proxy.Ack
+	6, // 9: splitio.proxy.Ingest.SubmitEvents:output_type -> splitio.proxy.Ack
+	8, // [8:10] is the sub-list for method output_type
+	6, // [6:8] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_splitio_proxy_grpcingest_ingestpb_ingest_proto_init() }
+func file_splitio_proxy_grpcingest_ingestpb_ingest_proto_init() {
+	if File_splitio_proxy_grpcingest_ingestpb_ingest_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Metadata); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KeyImpression); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FeatureImpressions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ImpressionsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Event); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EventsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Ack); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes[4].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_splitio_proxy_grpcingest_ingestpb_ingest_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_splitio_proxy_grpcingest_ingestpb_ingest_proto_goTypes,
+		DependencyIndexes: file_splitio_proxy_grpcingest_ingestpb_ingest_proto_depIdxs,
+		MessageInfos:      file_splitio_proxy_grpcingest_ingestpb_ingest_proto_msgTypes,
+	}.Build()
+	File_splitio_proxy_grpcingest_ingestpb_ingest_proto = out.File
+	file_splitio_proxy_grpcingest_ingestpb_ingest_proto_rawDesc = nil
+	file_splitio_proxy_grpcingest_ingestpb_ingest_proto_goTypes = nil
+	file_splitio_proxy_grpcingest_ingestpb_ingest_proto_depIdxs = nil
+}
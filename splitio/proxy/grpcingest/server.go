@@ -0,0 +1,137 @@
+package grpcingest
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/grpcingest/ingestpb"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+)
+
+// Options for the gRPC ingest server
+type Options struct {
+	Logger logging.LoggerInterface
+
+	// Host/IP to listen for incoming gRPC connections on
+	Host string
+
+	// Port to listen for incoming gRPC connections
+	Port int
+
+	// Where accepted impressions & events are pushed
+	Stager Stager
+
+	// Validates the apikey sent by clients
+	APIKeyValidator func(string) bool
+
+	// TLS configuration (plaintext if nil)
+	TLSConfig *tls.Config
+
+	// Max size (in bytes) of incoming messages. As with the HTTP endpoints, 0 means unlimited (rather than grpc's 4MB default)
+	MaxMessageSize int
+
+	// Applies the ingest rate limit to each client (no limit if nil)
+	RateLimiter RateLimiter
+
+	// Tracks latencies & statuses of incoming calls (not tracked if nil)
+	Telemetry storage.ProxyEndpointTelemetry
+}
+
+// Server accepts impressions & events over gRPC
+type Server struct {
+	logger logging.LoggerInterface
+	addr   string
+	server *grpc.Server
+}
+
+// New constructs a new gRPC ingest server
+func New(options *Options) *Server {
+	// same order as the http middlewares: telemetry, auth & rate limit
+	var interceptors []grpc.UnaryServerInterceptor
+	if options.Telemetry != nil {
+		interceptors = append(interceptors, newTelemetryInterceptor(options.Telemetry))
+	}
+	interceptors = append(interceptors, newAuthInterceptor(options.APIKeyValidator))
+	if options.RateLimiter != nil {
+		interceptors = append(interceptors, newRateLimitInterceptor(options.RateLimiter))
+	}
+
+	maxMessageSize := options.MaxMessageSize
+	if maxMessageSize <= 0 {
+		maxMessageSize = math.MaxInt32
+	}
+
+	serverOptions := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(interceptors...),
+		grpc.MaxRecvMsgSize(maxMessageSize),
+	}
+	if options.TLSConfig != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(options.TLSConfig)))
+	}
+
+	server := grpc.NewServer(serverOptions...)
+	server.RegisterService(&serviceDesc, &ingestService{stager: options.Stager})
+	return &Server{
+		logger: options.Logger,
+		addr:   net.JoinHostPort(options.Host, strconv.Itoa(options.Port)),
+		server: server,
+	}
+}
+
+// Start binds the gRPC port & serves incoming calls in the background
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	s.serve(listener)
+	return nil
+}
+
+// Stop waits for in-flight calls to complete & shuts the server down
+func (s *Server) Stop() {
+	s.server.GracefulStop()
+}
+
+func (s *Server) serve(listener net.Listener) {
+	s.logger.Info(fmt.Sprintf("gRPC ingest server listening on %s", listener.Addr()))
+	go func() {
+		if err := s.server.Serve(listener); err != nil {
+			s.logger.Error("gRPC ingest server stopped: ", err)
+		}
+	}()
+}
+
+// Client submits impressions & events to a proxy's gRPC ingest server
+type Client struct {
+	conn   grpc.ClientConnInterface
+	apikey string
+}
+
+// NewClient wraps a connection to the ingest server. The apikey is sent along every call
+func NewClient(conn grpc.ClientConnInterface, apikey string) *Client {
+	return &Client{conn: conn, apikey: apikey}
+}
+
+// SubmitImpressions sends an impressions bulk
+func (c *Client) SubmitImpressions(ctx context.Context, req *ingestpb.ImpressionsRequest, opts ...grpc.CallOption) error {
+	return c.conn.Invoke(c.withAPIKey(ctx), SubmitImpressionsMethod, req, &ingestpb.Ack{}, opts...)
+}
+
+// SubmitEvents sends an events bulk
+func (c *Client) SubmitEvents(ctx context.Context, req *ingestpb.EventsRequest, opts ...grpc.CallOption) error {
+	return c.conn.Invoke(c.withAPIKey(ctx), SubmitEventsMethod, req, &ingestpb.Ack{}, opts...)
+}
+
+func (c *Client) withAPIKey(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+c.apikey)
+}
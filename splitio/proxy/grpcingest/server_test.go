@@ -0,0 +1,194 @@
+package grpcingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/controllers"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/controllers/middleware"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/grpcingest/ingestpb"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/tasks"
+)
+
+type stagerMock struct {
+	stageImpressions func(metadata dtos.Metadata, sdkImpressionsMode string, data []byte) error
+	stageEvents      func(metadata dtos.Metadata, data []byte) error
+}
+
+func (s *stagerMock) StageImpressions(metadata dtos.Metadata, sdkImpressionsMode string, data []byte) error {
+	return s.stageImpressions(metadata, sdkImpressionsMode, data)
+}
+
+func (s *stagerMock) StageEvents(metadata dtos.Metadata, data []byte) error {
+	return s.stageEvents(metadata, data)
+}
+
+func setupServer(t *testing.T, stager Stager) *grpc.ClientConn {
+	t.Helper()
+	return setupServerWithOptions(t, &Options{Stager: stager})
+}
+
+func setupServerWithOptions(t *testing.T, options *Options) *grpc.ClientConn {
+	t.Helper()
+	options.Logger = logging.NewLogger(nil)
+	options.APIKeyValidator = func(apikey string) bool { return apikey == "someApikey" }
+	server := New(options)
+	listener := bufconn.Listen(1 << 20)
+	server.serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.Nil(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestSubmitImpressions(t *testing.T) {
+	var staged []dtos.ImpressionsDTO
+	var stagedMetadata dtos.Metadata
+	var stagedMode string
+	conn := setupServer(t, &stagerMock{
+		stageImpressions: func(metadata dtos.Metadata, sdkImpressionsMode string, data []byte) error {
+			stagedMetadata, stagedMode = metadata, sdkImpressionsMode
+			return json.Unmarshal(data, &staged)
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := NewClient(conn, "someApikey").SubmitImpressions(ctx, &ingestpb.ImpressionsRequest{
+		Metadata:        &ingestpb.Metadata{SdkVersion: "go-1.1.1", MachineIp: "1.2.3.4", MachineName: "ip-1-2-3-4"},
+		ImpressionsMode: "debug",
+		Impressions: []*ingestpb.FeatureImpressions{{
+			Feature:     "feature1",
+			Impressions: []*ingestpb.KeyImpression{{KeyName: "key1", Treatment: "on", Time: 123, ChangeNumber: 1, Label: "l1", PreviousTime: 100}},
+		}},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, dtos.Metadata{SDKVersion: "go-1.1.1", MachineIP: "1.2.3.4", MachineName: "ip-1-2-3-4"}, stagedMetadata)
+	assert.Equal(t, "debug", stagedMode)
+	assert.Equal(t, 1, len(staged))
+	assert.Equal(t, "feature1", staged[0].TestName)
+	assert.Equal(t, dtos.ImpressionDTO{KeyName: "key1", Treatment: "on", Time: 123, ChangeNumber: 1, Label: "l1", Pt: 100}, staged[0].KeyImpressions[0])
+}
+
+func TestSubmitEvents(t *testing.T) {
+	var staged []dtos.EventDTO
+	conn := setupServer(t, &stagerMock{
+		stageEvents: func(metadata dtos.Metadata, data []byte) error {
+			assert.Equal(t, "python-1.2.3", metadata.SDKVersion)
+			return json.Unmarshal(data, &staged)
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	properties, err := structpb.NewStruct(map[string]interface{}{"plan": "pro", "items": 3})
+	assert.Nil(t, err)
+	err = NewClient(conn, "someApikey").SubmitEvents(ctx, &ingestpb.EventsRequest{
+		Metadata: &ingestpb.Metadata{SdkVersion: "python-1.2.3"},
+		Events: []*ingestpb.Event{
+			{Key: "key1", TrafficTypeName: "user", EventTypeId: "checkout", Value: proto.Float64(1.5), Timestamp: 123, Properties: properties},
+			{Key: "key2", TrafficTypeName: "user", EventTypeId: "signup", Timestamp: 124},
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(staged))
+	assert.Equal(t, "checkout", staged[0].EventTypeID)
+	assert.Equal(t, 1.5, staged[0].Value)
+	assert.Equal(t, map[string]interface{}{"plan": "pro", "items": float64(3)}, staged[0].Properties)
+	assert.Nil(t, staged[1].Value)
+	assert.Nil(t, staged[1].Properties)
+}
+
+func TestSubmitErrors(t *testing.T) {
+	var stageErr error
+	conn := setupServer(t, &stagerMock{
+		stageImpressions: func(dtos.Metadata, string, []byte) error { return stageErr },
+		stageEvents:      func(dtos.Metadata, []byte) error { return stageErr },
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	impressions := &ingestpb.ImpressionsRequest{Impressions: []*ingestpb.FeatureImpressions{{Feature: "feature1"}}}
+	events := &ingestpb.EventsRequest{Events: []*ingestpb.Event{{Key: "key1"}}}
+
+	err := NewClient(conn, "wrongApikey").SubmitImpressions(ctx, impressions)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	client := NewClient(conn, "someApikey")
+	assert.Equal(t, codes.InvalidArgument, status.Code(client.SubmitImpressions(ctx, &ingestpb.ImpressionsRequest{})))
+	assert.Equal(t, codes.InvalidArgument, status.Code(client.SubmitEvents(ctx, &ingestpb.EventsRequest{})))
+
+	stageErr = &controllers.PayloadError{Err: errors.New("impression #0 for feature flag 'feature1' has no key")}
+	err = client.SubmitImpressions(ctx, impressions)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, err.Error(), "has no key")
+
+	stageErr = tasks.ErrQueueFull
+	assert.Equal(t, codes.ResourceExhausted, status.Code(client.SubmitImpressions(ctx, impressions)))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(client.SubmitEvents(ctx, events)))
+
+	stageErr = errors.New("something")
+	assert.Equal(t, codes.Internal, status.Code(client.SubmitEvents(ctx, events)))
+}
+
+func TestRateLimitAndTelemetry(t *testing.T) {
+	telemetry := storage.NewProxyTelemetryFacade()
+	conn := setupServerWithOptions(t, &Options{
+		Stager: &stagerMock{
+			stageImpressions: func(dtos.Metadata, string, []byte) error { return nil },
+			stageEvents:      func(dtos.Metadata, []byte) error { return nil },
+		},
+		RateLimiter: middleware.NewRateLimiter(1, 1),
+		Telemetry:   telemetry,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := NewClient(conn, "someApikey")
+	events := &ingestpb.EventsRequest{Events: []*ingestpb.Event{{Key: "key1"}}}
+	assert.Nil(t, client.SubmitEvents(ctx, events))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(client.SubmitEvents(ctx, events)))
+	assert.Equal(t, codes.Unauthenticated, status.Code(NewClient(conn, "wrongApikey").SubmitEvents(ctx, events)))
+
+	assert.Equal(t, map[int]int64{200: 1, 429: 1, 401: 1}, telemetry.PeekEndpointStatus(storage.EventsBulkEndpoint))
+	assert.Empty(t, telemetry.PeekEndpointStatus(storage.ImpressionsBulkEndpoint))
+}
+
+func TestMaxMessageSize(t *testing.T) {
+	var staged int
+	stager := &stagerMock{stageEvents: func(dtos.Metadata, []byte) error { staged++; return nil }}
+	events := &ingestpb.EventsRequest{Events: []*ingestpb.Event{{Key: strings.Repeat("k", 5<<20)}}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 0 means unlimited, rather than grpc's 4MB default
+	conn := setupServerWithOptions(t, &Options{Stager: stager})
+	assert.Nil(t, NewClient(conn, "someApikey").SubmitEvents(ctx, events))
+
+	conn = setupServerWithOptions(t, &Options{Stager: stager, MaxMessageSize: 1 << 20})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(NewClient(conn, "someApikey").SubmitEvents(ctx, events)))
+	assert.Equal(t, 1, staged)
+}
@@ -0,0 +1,258 @@
+package grpcingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/controllers"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/grpcingest/ingestpb"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/tasks"
+)
+
+// ServiceName is the fully qualified name of the ingest service
+const ServiceName = "splitio.proxy.Ingest"
+
+// Full method names, as invoked by clients
+const (
+	SubmitImpressionsMethod = "/" + ServiceName + "/SubmitImpressions"
+	SubmitEventsMethod      = "/" + ServiceName + "/SubmitEvents"
+)
+
+var errRateLimited = status.Error(codes.ResourceExhausted, "rate limit exceeded, please retry later")
+
+// Stager pushes incoming bulks into the same pipeline used by the HTTP endpoints
+type Stager interface {
+	StageImpressions(metadata dtos.Metadata, sdkImpressionsMode string, data []byte) error
+	StageEvents(metadata dtos.Metadata, data []byte) error
+}
+
+// RateLimiter decides whether a client can make another call
+type RateLimiter interface {
+	Allow(client string) bool
+}
+
+// ingestServer is the interface the registered service must implement
+type ingestServer interface {
+	SubmitImpressions(ctx context.Context, req *ingestpb.ImpressionsRequest) (*ingestpb.Ack, error)
+	SubmitEvents(ctx context.Context, req *ingestpb.EventsRequest) (*ingestpb.Ack, error)
+}
+
+type ingestService struct {
+	stager Stager
+}
+
+func (s *ingestService) SubmitImpressions(ctx context.Context, req *ingestpb.ImpressionsRequest) (*ingestpb.Ack, error) {
+	if len(req.GetImpressions()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no impressions in request")
+	}
+
+	data, err := json.Marshal(impressionsToDTOs(req.GetImpressions()))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.stager.StageImpressions(metadataToDTO(req.GetMetadata()), req.GetImpressionsMode(), data); err != nil {
+		return nil, toStatus("impressions", err)
+	}
+	return &ingestpb.Ack{}, nil
+}
+
+func (s *ingestService) SubmitEvents(ctx context.Context, req *ingestpb.EventsRequest) (*ingestpb.Ack, error) {
+	if len(req.GetEvents()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no events in request")
+	}
+
+	data, err := json.Marshal(eventsToDTOs(req.GetEvents()))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.stager.StageEvents(metadataToDTO(req.GetMetadata()), data); err != nil {
+		return nil, toStatus("events", err)
+	}
+	return &ingestpb.Ack{}, nil
+}
+
+func metadataToDTO(m *ingestpb.Metadata) dtos.Metadata {
+	return dtos.Metadata{SDKVersion: m.GetSdkVersion(), MachineIP: m.GetMachineIp(), MachineName: m.GetMachineName()}
+}
+
+func impressionsToDTOs(features []*ingestpb.FeatureImpressions) []dtos.ImpressionsDTO {
+	bulk := make([]dtos.ImpressionsDTO, 0, len(features))
+	for _, feature := range features {
+		impressions := make([]dtos.ImpressionDTO, 0, len(feature.GetImpressions()))
+		for _, impression := range feature.GetImpressions() {
+			impressions = append(impressions, dtos.ImpressionDTO{
+				KeyName:      impression.GetKeyName(),
+				Treatment:    impression.GetTreatment(),
+				Time:         impression.GetTime(),
+				ChangeNumber: impression.GetChangeNumber(),
+				Label:        impression.GetLabel(),
+				BucketingKey: impression.GetBucketingKey(),
+				Pt:           impression.GetPreviousTime(),
+			})
+		}
+		bulk = append(bulk, dtos.ImpressionsDTO{TestName: feature.GetFeature(), KeyImpressions: impressions})
+	}
+	return bulk
+}
+
+func eventsToDTOs(events []*ingestpb.Event) []dtos.EventDTO {
+	bulk := make([]dtos.EventDTO, 0, len(events))
+	for _, event := range events {
+		dto := dtos.EventDTO{
+			Key:             event.GetKey(),
+			TrafficTypeName: event.GetTrafficTypeName(),
+			EventTypeID:     event.GetEventTypeId(),
+			Timestamp:       event.GetTimestamp(),
+		}
+		if event.Value != nil {
+			dto.Value = event.GetValue()
+		}
+		if event.GetProperties() != nil {
+			dto.Properties = event.GetProperties().AsMap()
+		}
+		bulk = append(bulk, dto)
+	}
+	return bulk
+}
+
+// toStatus maps staging errors to the gRPC codes equivalent to the statuses returned by the HTTP endpoints
+func toStatus(kind string, err error) error {
+	var perr *controllers.PayloadError
+	switch {
+	case errors.As(err, &perr):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case err == tasks.ErrQueueFull:
+		return status.Errorf(codes.ResourceExhausted, "%s queue is full, please retry later", kind)
+	default:
+		return status.Errorf(codes.Internal, "unknown error when trying to push %s into the staging queue", kind)
+	}
+}
+
+// newAuthInterceptor rejects calls without a valid apikey in the authorization metadata ("Bearer <apikey>")
+func newAuthInterceptor(apikeyValidator func(string) bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, value := range md.Get("authorization") {
+			if apikeyValidator(strings.TrimSpace(strings.TrimPrefix(value, "Bearer "))) {
+				return handler(ctx, req)
+			}
+		}
+		return nil, status.Error(codes.Unauthenticated, "missing or invalid apikey")
+	}
+}
+
+// newRateLimitInterceptor applies the ingest rate limit to each client, identified by its address, as the HTTP endpoints do
+func newRateLimitInterceptor(limiter RateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !limiter.Allow(clientAddress(ctx)) {
+			return nil, errRateLimited
+		}
+		return handler(ctx, req)
+	}
+}
+
+// newTelemetryInterceptor tracks latencies & statuses like the HTTP bulk endpoints, mapping gRPC codes to the equivalent
+// HTTP status codes
+func newTelemetryInterceptor(telemetry storage.ProxyEndpointTelemetry) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var endpoint int
+		switch info.FullMethod {
+		case SubmitImpressionsMethod:
+			endpoint = storage.ImpressionsBulkEndpoint
+		case SubmitEventsMethod:
+			endpoint = storage.EventsBulkEndpoint
+		default:
+			return handler(ctx, req)
+		}
+
+		before := time.Now()
+		resp, err := handler(ctx, req)
+		telemetry.RecordEndpointLatency(endpoint, time.Since(before))
+		telemetry.IncrEndpointStatus(endpoint, httpStatus(err))
+		return resp, err
+	}
+}
+
+func httpStatus(err error) int {
+	if err == errRateLimited {
+		return http.StatusTooManyRequests
+	}
+
+	switch status.Code(err) {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	default: // the http endpoints answer with a 500 when the queues are full
+		return http.StatusInternalServerError
+	}
+}
+
+func clientAddress(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*ingestServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "SubmitImpressions", Handler: submitImpressionsHandler},
+		{MethodName: "SubmitEvents", Handler: submitEventsHandler},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "splitio/proxy/grpcingest/ingestpb/ingest.proto",
+}
+
+func submitImpressionsHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	var req ingestpb.ImpressionsRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ingestServer).SubmitImpressions(ctx, req.(*ingestpb.ImpressionsRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, &req)
+	}
+	return interceptor(ctx, &req, &grpc.UnaryServerInfo{Server: srv, FullMethod: SubmitImpressionsMethod}, handler)
+}
+
+func submitEventsHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	var req ingestpb.EventsRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ingestServer).SubmitEvents(ctx, req.(*ingestpb.EventsRequest))
+	}
+	if interceptor == nil {
+		return handler(ctx, &req)
+	}
+	return interceptor(ctx, &req, &grpc.UnaryServerInfo{Server: srv, FullMethod: SubmitEventsMethod}, handler)
+}
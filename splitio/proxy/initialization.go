@@ -4,40 +4,71 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"net/url"
+	"os"
 	"time"
 
 	"strings"
 
+	"github.com/gin-gonic/gin"
 	"github.com/splitio/go-split-commons/v6/conf"
 	"github.com/splitio/go-split-commons/v6/flagsets"
-	"github.com/splitio/go-split-commons/v6/service/api"
+	"github.com/splitio/go-split-commons/v6/provisional/strategy"
+	"github.com/splitio/go-split-commons/v6/service"
 	"github.com/splitio/go-split-commons/v6/synchronizer"
+	"github.com/splitio/go-split-commons/v6/synchronizer/worker/segment"
+	"github.com/splitio/go-split-commons/v6/synchronizer/worker/split"
 	"github.com/splitio/go-split-commons/v6/tasks"
 	"github.com/splitio/go-split-commons/v6/telemetry"
+	"github.com/splitio/go-toolkit/v5/asynctask"
 	"github.com/splitio/go-toolkit/v5/backoff"
 	"github.com/splitio/go-toolkit/v5/logging"
 
+	"github.com/splitio/split-synchronizer/v5/splitio"
 	"github.com/splitio/split-synchronizer/v5/splitio/admin"
 	adminCommon "github.com/splitio/split-synchronizer/v5/splitio/admin/common"
+	adminControllers "github.com/splitio/split-synchronizer/v5/splitio/admin/controllers"
 	"github.com/splitio/split-synchronizer/v5/splitio/common"
+	commonConf "github.com/splitio/split-synchronizer/v5/splitio/common/conf"
 	"github.com/splitio/split-synchronizer/v5/splitio/common/impressionlistener"
 	"github.com/splitio/split-synchronizer/v5/splitio/common/snapshot"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/stats"
+	cstorage "github.com/splitio/split-synchronizer/v5/splitio/common/storage"
 	ssync "github.com/splitio/split-synchronizer/v5/splitio/common/sync"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/upstream"
 	hcApplication "github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/application"
 	hcAppCounter "github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/application/counter"
 	hcServices "github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/services"
 	hcServicesCounter "github.com/splitio/split-synchronizer/v5/splitio/provisional/healthcheck/services/counter"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/breaker"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/caching"
 	pconf "github.com/splitio/split-synchronizer/v5/splitio/proxy/conf"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/controllers/middleware"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/replica"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/seed"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/statsd"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/persistent"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/streaming"
 	pTasks "github.com/splitio/split-synchronizer/v5/splitio/proxy/tasks"
 	"github.com/splitio/split-synchronizer/v5/splitio/util"
+
+	bolt "go.etcd.io/bbolt"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 // Start initialize in proxy mode
-func Start(logger logging.LoggerInterface, cfg *pconf.Main) error {
+func Start(logger logging.LoggerInterface, cfg *pconf.Main, reloader *commonConf.Reloader) error {
+	if err := cfg.Validate(); err != nil {
+		return common.NewInitError(err, common.ExitInvalidConfiguration)
+	}
+
+	upstreamTransport, err := util.NewUpstreamTransport(&cfg.Upstream)
+	if err != nil {
+		return common.NewInitError(fmt.Errorf("error setting up upstream http transport: %w", err), common.ExitTLSError)
+	}
 
 	clientKey, err := util.GetClientKey(cfg.Apikey)
 	if err != nil {
@@ -46,7 +77,13 @@ func Start(logger logging.LoggerInterface, cfg *pconf.Main) error {
 
 	// Initialization of DB
 	var dbpath = persistent.BoltInMemoryMode
+	var dbOptions *bolt.Options
+	var restoreFromDisk bool
 	if snapFile := cfg.Initialization.Snapshot; snapFile != "" {
+		if cfg.Storage.Persistent.Filename != "" {
+			logger.Warning("A snapshot was supplied. The configured persistent storage file will be ignored")
+		}
+
 		snap, err := snapshot.DecodeFromFile(snapFile)
 		if err != nil {
 			return fmt.Errorf("error parsing snapshot file: %w", err)
@@ -58,13 +95,30 @@ func Start(logger logging.LoggerInterface, cfg *pconf.Main) error {
 		}
 
 		logger.Debug("Database created from snapshot at", dbpath)
+		restoreFromDisk = true
+	} else if filename := cfg.Storage.Persistent.Filename; filename != "" {
+		_, statErr := os.Stat(filename)
+		restoreFromDisk = statErr == nil // only restore data if the file was already there
+		dbpath = filename
+
+		// fail fast instead of waiting forever if another instance holds the file
+		dbOptions = &bolt.Options{Timeout: boltOpenTimeout}
+		logger.Info(fmt.Sprintf("Using persistent storage file '%s'", filename))
 	}
 
-	dbInstance, err := persistent.NewBoltWrapper(dbpath, nil)
+	dbInstance, err := persistent.NewBoltWrapper(dbpath, dbOptions)
 	if err != nil {
 		return common.NewInitError(fmt.Errorf("error instantiating boltdb: %w", err), common.ExitErrorDB)
 	}
 
+	if cfg.Storage.Persistent.CompactOnStartup {
+		before, after, err := dbInstance.Compact()
+		if err != nil {
+			return common.NewInitError(fmt.Errorf("error compacting boltdb: %w", err), common.ExitErrorDB)
+		}
+		logger.Info(fmt.Sprintf("Persistent storage compacted. File size went from %d to %d bytes", before, after))
+	}
+
 	// Set up the http proxy caching.
 	// We need it fairly early since it's passed to the synchronizers, so that they can evict entries when a change is processed
 	httpCache := caching.MakeProxyCache()
@@ -75,53 +129,166 @@ func Start(logger logging.LoggerInterface, cfg *pconf.Main) error {
 	advanced.AuthSpecVersion = cfg.FlagSpecVersion
 	advanced.FlagsSpecVersion = cfg.FlagSpecVersion
 	metadata := util.GetMetadata(cfg.IPAddressEnabled, true)
+	outboundHeaders := util.GetOutboundHeaders(cfg.EnvironmentLabel)
+	logger.Info(fmt.Sprintf("Config summary: sdk key %s, environment label %q (sent along with requests posted to Split)",
+		commonConf.RedactSecret(cfg.Apikey), cfg.EnvironmentLabel))
+
+	util.WarnUnscopedUpstreamSettings(&cfg.Upstream, advanced.StreamingEnabled, logger)
+
+	// Failover to secondary upstream urls. Set up before any fetcher/recorder so that all of them go through it
+	var upstreamFailover *util.UpstreamFailover
+	var failoverStatus adminControllers.FailoverStatusProvider // left nil when disabled, so that it's not reported
+	if cfg.Upstream.Failover.SecondarySdkURL != "" {
+		var err error
+		upstreamFailover, err = util.StartUpstreamFailover(&cfg.Upstream.Failover, advanced.SdkURL, advanced.EventsURL, upstreamTransport, logger)
+		if err != nil {
+			return common.NewInitError(fmt.Errorf("error setting up upstream failover: %w", err), common.ExitInvalidConfiguration)
+		}
+		failoverStatus = upstreamFailover
+	}
+
+	// OpenTelemetry tracing. Set up before any fetcher/recorder so that all calls to Split servers are traced
+	var tracerProvider *sdktrace.TracerProvider
+	if cfg.Tracing.Enabled {
+		provider, err := util.StartTracing(&cfg.Tracing, "split-proxy")
+		if err != nil {
+			return common.NewInitError(fmt.Errorf("error setting up tracing: %w", err), common.ExitInvalidConfiguration)
+		}
+		upstreamTransport.SetTracer(provider.Tracer(util.TracerName))
+		tracerProvider = provider
+		logger.Info(fmt.Sprintf("Tracing enabled, exporting spans through %s (sampling %d%% of new traces)", cfg.Tracing.Exporter, cfg.Tracing.SamplePercent))
+	}
 
 	// FlagSetsFilter
 	flagSetsFilter := flagsets.NewFlagSetFilter(cfg.FlagSetsFilter)
 
 	// Setup fetchers & recorders
-	splitAPI := api.NewSplitAPI(cfg.Apikey, *advanced, logger, metadata)
+	splitAPI, err := upstream.NewSplitAPI(cfg.Apikey, advanced, upstreamTransport, logger, metadata)
+	if err != nil {
+		return common.NewInitError(fmt.Errorf("error setting up fetchers & recorders: %w", err), common.ExitTaskInitialization)
+	}
+
+	// fail fast on a wrong SDK key instead of serving empty data. replicas never talk to Split servers
+	if !cfg.Replica.Enabled {
+		if err := util.VerifyApikey(splitAPI.SplitFetcher); errors.Is(err, util.ErrApikeyRejected) {
+			return common.NewInitError(err, common.ExitInvalidApikey)
+		} else if err != nil {
+			logger.Warning("Could not verify the SDK key with Split servers: ", err)
+		}
+	}
 
 	// Proxy storages already implement the observable interface, so no need to wrap them
-	splitStorage := storage.NewProxySplitStorage(dbInstance, logger, flagsets.NewFlagSetFilter(cfg.FlagSetsFilter), cfg.Initialization.Snapshot != "")
-	segmentStorage := storage.NewProxySegmentStorage(dbInstance, logger, cfg.Initialization.Snapshot != "")
+	var matcherWarner *storage.UnsupportedMatcherWarner
+	if cfg.Sync.Advanced.WarnUnsupportedMatchers {
+		matcherWarner = storage.NewUnsupportedMatcherWarner(logger, cfg.Sync.Advanced.KnownMatchers)
+	}
+	var splitsDB persistent.DBWrapper = dbInstance
+	var snapshotter cstorage.Snapshotter = dbInstance
+	if cfg.Storage.Persistent.Disabled {
+		logger.Info("Persistent storage disabled. Feature flags will be kept in memory only")
+		splitsDB = nil
+		snapshotter = nil // snapshots would not include feature flags
+	}
+	splitStorage := storage.NewProxySplitStorage(splitsDB, logger, flagsets.NewFlagSetFilter(cfg.FlagSetsFilter), restoreFromDisk,
+		matcherWarner, int(cfg.Storage.Volatile.SplitChangesCacheSize), int(cfg.Storage.Volatile.MaxSplitChangesRecipes))
+	segmentStorage := storage.NewProxySegmentStorage(dbInstance, logger, restoreFromDisk)
+	segmentStorage.SetMaxKeysPerResponse(int(cfg.Server.SegmentChangesMaxKeys))
+
+	var seeded bool
+	if seedFile := cfg.Initialization.SeedFile; seedFile != "" {
+		stats, err := seed.LoadFromFile(seedFile, splitStorage, segmentStorage)
+		if err != nil {
+			return common.NewInitError(fmt.Errorf("error loading seed file: %w", err), common.ExitErrorDB)
+		}
+		logger.Info(fmt.Sprintf("Preloaded %d feature flags & %d segments from seed file", stats.Splits, stats.Segments))
+		cn, _ := splitStorage.ChangeNumber()
+		seeded = cn > -1
+	}
 
 	// Local telemetry
 	tbufferSize := int(cfg.Sync.Advanced.TelemetryBuffer)
 	tworkers := int(cfg.Sync.Advanced.TelemetryWorkers)
 
+	// already validated along with the rest of the config
+	timeSliceWidth, _ := storage.ResolveTimeSliceConfig(
+		cfg.Observability.Granularity,
+		cfg.Observability.TimeSliceWidthSecs,
+		cfg.Observability.MaxTimeSliceCount,
+	)
+
 	localTelemetryStorage := storage.NewTimeslicedProxyEndpointTelemetry(
 		storage.NewProxyTelemetryFacade(),
-		cfg.Observability.TimeSliceWidthSecs,
+		timeSliceWidth,
 		int(cfg.Observability.MaxTimeSliceCount),
 	)
 
 	// Healcheck Monitor
 	splitsConfig, segmentsConfig := getAppCounterConfigs()
 	appMonitor := hcApplication.NewMonitorImp(splitsConfig, segmentsConfig, nil, logger)
-	servicesMonitor := hcServices.NewMonitorImp(getServicesCountersConfig(*advanced), logger)
+	servicesMonitor := hcServices.NewMonitorImp(getServicesCountersConfig(*advanced, upstreamTransport), logger)
 
 	// Creating Workers and Tasks
-	telemetryRecorder := api.NewHTTPTelemetryRecorder(cfg.Apikey, *advanced, logger)
-	telemetryConfigTask := pTasks.NewTelemetryConfigFlushTask(telemetryRecorder, logger, 1, tbufferSize, tworkers)
-	telemetryUsageTask := pTasks.NewTelemetryUsageFlushTask(telemetryRecorder, logger, 1, tbufferSize, tworkers)
-	telemetryKeysClientSideTask := pTasks.NewTelemetryKeysClientSideFlushTask(telemetryRecorder, logger, 1, tbufferSize, tworkers)
-	telemetryKeysServerSideTask := pTasks.NewTelemetryKeysServerSideFlushTask(telemetryRecorder, logger, 1, tbufferSize, tworkers)
+	// a single deadline is shared by all tasks, so that flushing everything on shutdown takes at most the grace period
+	shutdownDeadline := util.NewShutdownDeadline(time.Duration(cfg.Sync.Advanced.ShutdownGracePeriodMs) * time.Millisecond)
+	telemetryRecorder, err := upstream.NewTelemetryRecorder(cfg.Apikey, advanced, upstreamTransport, logger)
+	if err != nil {
+		return common.NewInitError(fmt.Errorf("error setting up telemetry recorder: %w", err), common.ExitTaskInitialization)
+	}
+	telemetryConfigTask := pTasks.NewTelemetryConfigFlushTask(telemetryRecorder, logger, 1, tbufferSize, tworkers, outboundHeaders, shutdownDeadline)
+	telemetryUsageTask := pTasks.NewTelemetryUsageFlushTask(telemetryRecorder, logger, 1, tbufferSize, tworkers, outboundHeaders, shutdownDeadline)
+	telemetryKeysClientSideTask := pTasks.NewTelemetryKeysClientSideFlushTask(telemetryRecorder, logger, 1, tbufferSize, tworkers, outboundHeaders, shutdownDeadline)
+	telemetryKeysServerSideTask := pTasks.NewTelemetryKeysServerSideFlushTask(telemetryRecorder, logger, 1, tbufferSize, tworkers, outboundHeaders, shutdownDeadline)
 
 	// impression bulks & counts - events
 	ibufferSize := int(cfg.Sync.Advanced.ImpressionsBuffer)
 	iworkers := int(cfg.Sync.Advanced.ImpressionsWorkers)
-	impressionRecorder := api.NewHTTPImpressionRecorder(cfg.Apikey, *advanced, logger)
-	impressionTask := pTasks.NewImpressionsFlushTask(impressionRecorder, logger, 1, ibufferSize, iworkers)
-	impressionCountTask := pTasks.NewImpressionCountFlushTask(impressionRecorder, logger, 1, ibufferSize, iworkers)
-	eventsRecorder := api.NewHTTPEventsRecorder(cfg.Apikey, *advanced, logger)
-	eventsTask := pTasks.NewEventsFlushTask(eventsRecorder, logger, 1, int(cfg.Sync.Advanced.EventsBuffer), int(cfg.Sync.Advanced.EventsWorkers))
+	impressionRecorder, err := upstream.NewImpressionRecorder(cfg.Apikey, advanced, upstreamTransport, logger)
+	if err != nil {
+		return common.NewInitError(fmt.Errorf("error setting up impressions recorder: %w", err), common.ExitTaskInitialization)
+	}
+	iflushPeriod := int(cfg.Sync.Advanced.ImpressionsFlushRateMs / 1000)
+	if iflushPeriod < 1 {
+		iflushPeriod = 1
+	}
+	impressionTask := pTasks.NewImpressionsFlushTask(impressionRecorder, logger, iflushPeriod, ibufferSize, int(cfg.Sync.Advanced.ImpressionsFlushSize),
+		iworkers, outboundHeaders, shutdownDeadline)
+	impressionCountTask := pTasks.NewImpressionCountFlushTask(impressionRecorder, logger, 1, ibufferSize, iworkers, outboundHeaders, shutdownDeadline)
+	eventsRecorder, err := upstream.NewEventsRecorder(cfg.Apikey, advanced, upstreamTransport, logger)
+	if err != nil {
+		return common.NewInitError(fmt.Errorf("error setting up events recorder: %w", err), common.ExitTaskInitialization)
+	}
+	eventsTask := pTasks.NewEventsFlushTask(eventsRecorder, logger, 1, int(cfg.Sync.Advanced.EventsBuffer), int(cfg.Sync.Advanced.EventsWorkers), outboundHeaders, shutdownDeadline)
+
+	// SDKs connected to the proxy's streaming endpoint are notified of changes once they've been applied locally
+	var broadcaster *streaming.Broadcaster
+	var streamingTokens *streaming.TokenIssuer
+	var changeNotifier caching.ChangeNotifier
+	if cfg.Server.StreamingEnabled {
+		if cfg.Replica.Enabled {
+			return common.NewInitError(errors.New("streaming to SDKs is not available in replica mode"), common.ExitInvalidConfiguration)
+		}
+		if streamingTokens, err = streaming.NewTokenIssuer(cfg.Server.StreamingTokenSecret); err != nil {
+			return common.NewInitError(fmt.Errorf("error setting up streaming tokens: %w", err), common.ExitTaskInitialization)
+		}
+		if cfg.Server.StreamingTokenSecret == "" {
+			logger.Info("No streaming token secret set. If running several proxy replicas behind a load balancer, " +
+				"set the same one on all of them so that SDKs can connect to any")
+		}
+		if !cfg.Sync.Advanced.StreamingEnabled {
+			logger.Warning("Streaming from Split servers is disabled. SDKs will only be notified of changes after periodic fetches")
+		}
+		broadcaster = streaming.NewBroadcaster(logger)
+		changeNotifier = broadcaster
+	}
 
 	// setup feature flags, segments & local telemetry API interactions
+	segmentSync := caching.NewCacheAwareSegmentSync(splitStorage, segmentStorage, splitAPI.SegmentFetcher, logger, localTelemetryStorage, httpCache,
+		appMonitor, changeNotifier)
+	segmentSync.SetConcurrency(int(cfg.Sync.Advanced.SegmentSyncConcurrency))
 	workers := synchronizer.Workers{
-		SplitUpdater: caching.NewCacheAwareSplitSync(splitStorage, splitAPI.SplitFetcher, logger, localTelemetryStorage, httpCache, appMonitor, flagSetsFilter),
-		SegmentUpdater: caching.NewCacheAwareSegmentSync(splitStorage, segmentStorage, splitAPI.SegmentFetcher, logger, localTelemetryStorage, httpCache,
-			appMonitor),
+		SplitUpdater: caching.NewCacheAwareSplitSync(splitStorage, splitAPI.SplitFetcher, logger, localTelemetryStorage, httpCache, appMonitor,
+			flagSetsFilter, changeNotifier),
+		SegmentUpdater: segmentSync,
 		TelemetryRecorder: telemetry.NewTelemetrySynchronizer(localTelemetryStorage, telemetryRecorder, splitStorage, segmentStorage, logger,
 			metadata, localTelemetryStorage),
 	}
@@ -140,57 +307,94 @@ func Start(logger logging.LoggerInterface, cfg *pconf.Main) error {
 	// Creating Synchronizer for tasks
 	sync := ssync.NewSynchronizer(*advanced, stasks, workers, logger, nil, []tasks.Task{telemetryConfigTask, telemetryUsageTask, telemetryKeysClientSideTask, telemetryKeysServerSideTask})
 
-	mstatus := make(chan int, 1)
-	syncManager, err := synchronizer.NewSynchronizerManager(
-		sync,
-		logger,
-		*advanced,
-		splitAPI.AuthClient,
-		splitStorage,
-		mstatus,
-		localTelemetryStorage,
-		metadata,
-		&clientKey,
-		appMonitor,
-	)
-	if err != nil {
-		return common.NewInitError(fmt.Errorf("error instantiating sync manager: %w", err), common.ExitTaskInitialization)
-	}
-
-	// Try to start bg sync in BG with unlimited retries (when a snapshot is provided),
-	// the passed function is invoked upon initialization completion
-	// If no snapshot is provided and init fails, `errUnrecoverable` is returned and application execution is aborted
-	// health monitors are only started after successful init (otherwise they'll fail if the app doesn't sync correctly within the
-	/// specified refresh period)
-	before := time.Now()
-	err = startBGSyng(syncManager, mstatus, cfg.Initialization.Snapshot != "", func() {
-		logger.Info("Synchronizer tasks started")
-		appMonitor.Start()
-		servicesMonitor.Start()
-		flagSetsAfterSanitize, _ := flagsets.SanitizeMany(cfg.FlagSetsFilter)
-		workers.TelemetryRecorder.SynchronizeConfig(
-			telemetry.InitConfig{
-				AdvancedConfig: *advanced,
-				TaskPeriods: conf.TaskPeriods{
-					SplitSync:     int(cfg.Sync.SplitRefreshRateMs / 1000),
-					SegmentSync:   int(cfg.Sync.SegmentRefreshRateMs / 1000),
-					TelemetrySync: int(cfg.Sync.Advanced.InternalMetricsRateMs / 1000),
-				},
-				ListenerEnabled: cfg.Integrations.ImpressionListener.Endpoint != "",
-				FlagSetsTotal:   int64(len(cfg.FlagSetsFilter)),
-				FlagSetsInvalid: int64(len(cfg.FlagSetsFilter) - len(flagSetsAfterSanitize)),
-			},
-			time.Since(before).Milliseconds(),
-			map[string]int64{cfg.Apikey: 1},
-			nil,
+	// sdk endpoints return 503 until there's usable data to serve
+	readiness := middleware.NewReadiness()
+
+	var syncManager synchronizer.Manager
+	if cfg.Replica.Enabled {
+		loader := replica.NewLoader(cfg.Replica.SourceFile, splitStorage, segmentStorage, httpCache, logger,
+			time.Duration(cfg.Replica.ReloadRateMs)*time.Millisecond)
+		if err := loader.Load(); err != nil {
+			return common.NewInitError(fmt.Errorf("error loading replicated file: %w", err), common.ExitErrorDB)
+		}
+		logger.Info("Running in replica mode. Feature flags & segments will be loaded from the replicated file only")
+		// health monitors are intentionally not started on replicas: the application counters are fed by the upstream sync
+		// (and would time out on a replicated file that doesn't change within the threshold), and the services ones probe
+		// Split servers, which replicas never talk to. Both are reported as healthy with no hits in the health endpoints
+		readiness.SetReady()
+		loader.Start()
+		syncManager = loader
+	} else {
+		mstatus := make(chan int, 1)
+		syncManager, err = synchronizer.NewSynchronizerManager(
+			sync,
+			logger,
+			*advanced,
+			splitAPI.AuthClient,
+			splitStorage,
+			mstatus,
+			localTelemetryStorage,
+			metadata,
+			&clientKey,
+			appMonitor,
 		)
-	})
-	switch err {
-	case errRetrying:
-		logger.Warning("Failed to perform initial sync with Split servers but continuing from snapshot. Will keep retrying in BG")
-	case errUnrecoverable:
-		logger.Error("Initial synchronization failed. Either Split is unreachable or the SDK key is incorrect. Aborting execution.")
-		return common.NewInitError(fmt.Errorf("error instantiating sync manager: %w", err), common.ExitTaskInitialization)
+		if err != nil {
+			return common.NewInitError(fmt.Errorf("error instantiating sync manager: %w", err), common.ExitTaskInitialization)
+		}
+
+		// Try to start bg sync in BG with unlimited retries (when a snapshot is provided),
+		// the passed function is invoked upon initialization completion
+		// If no snapshot is provided and init fails, `errUnrecoverable` is returned and application execution is aborted
+		// health monitors are only started after successful init (otherwise they'll fail if the app doesn't sync correctly within the
+		/// specified refresh period)
+		var segmentCleanup *asynctask.AsyncTask
+		if grace := cfg.Sync.Advanced.UnreferencedSegmentsGraceMs; grace > 0 {
+			segmentCleanup = pTasks.NewSegmentCleanupTask(splitStorage, segmentStorage, time.Duration(grace)*time.Millisecond,
+				int(cfg.Sync.SegmentRefreshRateMs/1000), logger)
+		}
+
+		before := time.Now()
+		err = startBGSyng(syncManager, mstatus, cfg.Initialization.Snapshot != "", seeded, func() {
+			logger.Info("Synchronizer tasks started")
+			readiness.SetReady()
+			appMonitor.Start()
+			servicesMonitor.Start()
+			if segmentCleanup != nil {
+				segmentCleanup.Start()
+			}
+			flagSetsAfterSanitize, _ := flagsets.SanitizeMany(cfg.FlagSetsFilter)
+			workers.TelemetryRecorder.SynchronizeConfig(
+				telemetry.InitConfig{
+					AdvancedConfig: *advanced,
+					TaskPeriods: conf.TaskPeriods{
+						SplitSync:     int(cfg.Sync.SplitRefreshRateMs / 1000),
+						SegmentSync:   int(cfg.Sync.SegmentRefreshRateMs / 1000),
+						TelemetrySync: int(cfg.Sync.Advanced.InternalMetricsRateMs / 1000),
+					},
+					ListenerEnabled: cfg.Integrations.ImpressionListener.Endpoint != "",
+					FlagSetsTotal:   int64(len(cfg.FlagSetsFilter)),
+					FlagSetsInvalid: int64(len(cfg.FlagSetsFilter) - len(flagSetsAfterSanitize)),
+				},
+				time.Since(before).Milliseconds(),
+				map[string]int64{cfg.Apikey: 1},
+				nil,
+			)
+		})
+		switch err {
+		case errInBackground:
+			logger.Info("Serving seeded data while the initial sync runs in background")
+			readiness.SetReady()
+		case errRetrying:
+			logger.Warning("Failed to perform initial sync with Split servers but continuing from snapshot. Will keep retrying in BG")
+			if changes, err := splitStorage.ChangesSince(-1, nil); err == nil && changes.Till > -1 {
+				readiness.SetReady()
+			} else {
+				logger.Warning("The snapshot contains no feature flags. SDK endpoints will return 503 until the initial sync succeeds")
+			}
+		case errUnrecoverable:
+			logger.Error("Initial synchronization failed. Either Split is unreachable or the SDK key is incorrect. Aborting execution.")
+			return common.NewInitError(fmt.Errorf("error instantiating sync manager: %w", err), common.ExitUpstreamUnreachable)
+		}
 	}
 
 	rtm := common.NewRuntime(false, syncManager, logger, "Split Proxy", nil, nil, appMonitor, servicesMonitor)
@@ -201,35 +405,95 @@ func Start(logger logging.LoggerInterface, cfg *pconf.Main) error {
 	}
 
 	// --------------------------- ADMIN DASHBOARD ------------------------------
-	cfgForAdmin := *cfg
-	cfgForAdmin.Apikey = logging.ObfuscateAPIKey(cfgForAdmin.Apikey)
+	cfgForAdmin := cfg.Redacted()
 
 	adminTLSConfig, err := util.TLSConfigForServer(&cfg.Admin.TLS)
 	if err != nil {
 		return common.NewInitError(fmt.Errorf("error setting up proxy TLS config: %w", err), common.ExitTLSError)
 	}
 
+	instanceID := cfg.Server.InstanceID
+	if instanceID == "" {
+		instanceID, _ = os.Hostname()
+	}
+	identityHeaders := middleware.NewIdentityHeaders(splitio.Version, instanceID)
+
+	// replicas don't synchronize with Split servers, so on-demand refreshes are not available
+	var splitUpdater split.Updater
+	var segmentUpdater segment.Updater
+	if !cfg.Replica.Enabled {
+		splitUpdater, segmentUpdater = workers.SplitUpdater, workers.SegmentUpdater
+	}
+
+	// fetches issued on cache misses are short-circuited while Split servers keep failing
+	var splitFetcher service.SplitFetcher = splitAPI.SplitFetcher
+	var upstreamBreaker adminControllers.BreakerStatusProvider
+	if threshold := cfg.Sync.Advanced.UpstreamBreakerThreshold; threshold > 0 && !cfg.Replica.Enabled {
+		breakerFetcher := breaker.NewSplitFetcher(
+			splitAPI.SplitFetcher,
+			splitStorage,
+			int(threshold),
+			time.Duration(cfg.Sync.Advanced.UpstreamBreakerCooldownMs)*time.Millisecond,
+			logger,
+		)
+		splitFetcher, upstreamBreaker = breakerFetcher, breakerFetcher
+	}
+
 	adminServer, err := admin.NewServer(&admin.Options{
-		Host:              cfg.Admin.Host,
-		Port:              int(cfg.Admin.Port),
-		Name:              "Split Proxy dashboard",
-		Proxy:             true,
-		Username:          cfg.Admin.Username,
-		Password:          cfg.Admin.Password,
-		Logger:            logger,
-		Storages:          storages,
-		Runtime:           rtm,
-		Snapshotter:       dbInstance,
-		HcAppMonitor:      appMonitor,
-		HcServicesMonitor: servicesMonitor,
-		FullConfig:        cfgForAdmin,
-		TLS:               adminTLSConfig,
-		FlagSpecVersion:   cfg.FlagSpecVersion,
+		Host:               cfg.Admin.Host,
+		Port:               int(cfg.Admin.Port),
+		Name:               "Split Proxy dashboard",
+		Proxy:              true,
+		Username:           cfg.Admin.Username,
+		Password:           cfg.Admin.Password,
+		Token:              cfg.Admin.Token,
+		SecureHealthcheck:  cfg.Admin.SecureHC,
+		Logger:             logger,
+		Storages:           storages,
+		Runtime:            rtm,
+		Snapshotter:        snapshotter,
+		Compactor:          dbInstance,
+		DBMetrics:          dbInstance,
+		HcAppMonitor:       appMonitor,
+		HcServicesMonitor:  servicesMonitor,
+		SplitUpdater:       splitUpdater,
+		SegmentUpdater:     segmentUpdater,
+		FullConfig:         cfgForAdmin,
+		TLS:                adminTLSConfig,
+		FlagSpecVersion:    cfg.FlagSpecVersion,
+		ExposeSegmentUsage: cfg.Admin.ExposeSegmentUsage,
+		ExposeStatusCodes:  cfg.Admin.ExposeStatusCodes,
+		ExposePrometheus:   cfg.Admin.ExposePrometheus,
+		Middlewares:        []gin.HandlerFunc{identityHeaders.Handle},
+		SinkStats:          map[string]adminControllers.SinkStatsProvider{"events": deferredSinkStats{task: eventsTask}},
+		UpstreamBreaker:    upstreamBreaker,
+		UpstreamFailover:   failoverStatus,
+		Readiness:          readiness,
 	})
 	if err != nil {
 		return common.NewInitError(fmt.Errorf("error starting admin server: %w", err), common.ExitAdminError)
 	}
-	go adminServer.Start()
+	if err := adminServer.Start(); err != nil {
+		return common.NewInitError(fmt.Errorf("error binding admin port: %w", err), common.ExitPortBindFailed)
+	}
+
+	var statsdExporter *statsd.Exporter
+	if scfg := cfg.Observability.StatsD; scfg.Enabled {
+		var err error
+		statsdExporter, err = statsd.NewExporter(&statsd.Options{
+			Logger:    logger,
+			Address:   scfg.Address,
+			Prefix:    scfg.Prefix,
+			Tags:      scfg.Tags,
+			Period:    time.Duration(scfg.PushRateMs) * time.Millisecond,
+			Telemetry: localTelemetryStorage,
+			DBMetrics: dbInstance,
+		})
+		if err != nil {
+			return common.NewInitError(fmt.Errorf("error setting up statsd exporter: %w", err), common.ExitTaskInitialization)
+		}
+		statsdExporter.Start()
+	}
 
 	tlsConfig, err := util.TLSConfigForServer(&cfg.Server.TLS)
 	if err != nil {
@@ -242,8 +506,9 @@ func Start(logger logging.LoggerInterface, cfg *pconf.Main) error {
 		Port:                        int(cfg.Server.Port),
 		APIKeys:                     cfg.Server.ClientApikeys,
 		ImpressionListener:          nil,
+		IdentityHeaders:             identityHeaders,
 		DebugOn:                     strings.ToLower(cfg.Logging.Level) == "debug" || strings.ToLower(cfg.Logging.Level) == "verbose",
-		SplitFetcher:                splitAPI.SplitFetcher,
+		SplitFetcher:                splitFetcher,
 		ProxySplitStorage:           splitStorage,
 		ProxySegmentStorage:         segmentStorage,
 		ImpressionsSink:             impressionTask,
@@ -256,33 +521,144 @@ func Start(logger logging.LoggerInterface, cfg *pconf.Main) error {
 		Telemetry:                   localTelemetryStorage,
 		Cache:                       httpCache,
 		TLSConfig:                   tlsConfig,
+		BrotliEnabled:               cfg.Server.BrotliEnabled,
+		BrotliLevel:                 int(cfg.Server.BrotliLevel),
+		GzipLevel:                   int(cfg.Server.GzipLevel),
+		GzipMinSize:                 int(cfg.Server.GzipMinSizeBytes),
+		IngestRateLimit:             int(cfg.Server.IngestRateLimit),
+		IngestRateBurst:             int(cfg.Server.IngestRateBurst),
+		ReadTimeout:                 time.Duration(cfg.Server.ReadTimeoutMs) * time.Millisecond,
+		WriteTimeout:                time.Duration(cfg.Server.WriteTimeoutMs) * time.Millisecond,
+		IdleTimeout:                 time.Duration(cfg.Server.IdleTimeoutMs) * time.Millisecond,
+		MaxIngestBodySize:           cfg.Server.MaxIngestBodySizeBytes,
+		MySegmentsBulkMaxKeys:       int(cfg.Server.MySegmentsBulkMaxKeys),
+		CacheControlMaxAge:          time.Duration(cfg.Server.CacheControlMaxAgeSecs) * time.Second,
+		StreamingBroadcaster:        broadcaster,
+		StreamingTokens:             streamingTokens,
 		FlagSets:                    cfg.FlagSetsFilter,
 		FlagSetsStrictMatching:      cfg.FlagSetStrictMatching,
+		Readiness:                   readiness,
+		CORS: CORSOptions{
+			Enabled:          cfg.Server.CORS.Enabled,
+			AllowedOrigins:   cfg.Server.CORS.AllowedOrigins,
+			AllowedMethods:   cfg.Server.CORS.AllowedMethods,
+			AllowedHeaders:   cfg.Server.CORS.AllowedHeaders,
+			AllowCredentials: cfg.Server.CORS.AllowCredentials,
+			MaxAge:           time.Duration(cfg.Server.CORS.MaxAgeSecs) * time.Second,
+		},
+	}
+
+	if tracerProvider != nil {
+		proxyOptions.Tracing = middleware.NewTracing(tracerProvider.Tracer(util.TracerName), otel.GetTextMapPropagator())
+	}
+
+	if cfg.Server.GRPC.Enabled {
+		proxyOptions.GRPCIngestPort = int(cfg.Server.GRPC.Port)
+	}
+
+	if cfg.Server.AccessLogEnabled {
+		proxyOptions.AccessLogger = middleware.NewAccessLogger(logger, int(cfg.Server.AccessLogSamplePercent), strings.ToLower(cfg.Server.AccessLogLevel) == "debug")
+	}
+
+	if cfg.Replica.Enabled {
+		logger.Warning("Impressions, events & telemetry posted to a replica are discarded")
+		discard := pTasks.NewDiscardingRecordingTask()
+		proxyOptions.SplitFetcher = replica.NewSplitFetcher(splitStorage)
+		proxyOptions.ImpressionsSink = discard
+		proxyOptions.ImpressionCountSink = discard
+		proxyOptions.EventsSink = discard
+		proxyOptions.TelemetryConfigSink = discard
+		proxyOptions.TelemetryUsageSink = discard
+		proxyOptions.TelemetryKeysClientSideSink = discard
+		proxyOptions.TelemetryKeysServerSideSink = discard
+	}
+
+	proxyOptions.ImpressionsMode = cfg.Sync.ImpressionsMode
+
+	// an enforced optimized mode requires deduplicating impressions even if no observer size was configured
+	observerSize := int(cfg.Sync.Advanced.ImpressionObserverSize)
+	if observerSize == 0 && cfg.Sync.ImpressionsMode == conf.ImpressionsModeOptimized {
+		observerSize = defaultImpressionObserverSize
+	}
+	if observerSize > 0 && !cfg.Replica.Enabled {
+		observer, err := strategy.NewImpressionObserver(observerSize)
+		if err != nil {
+			return common.NewInitError(fmt.Errorf("error instantiating impression observer: %w", err), common.ExitTaskInitialization)
+		}
+		proxyOptions.ImpressionObserver = observer
 	}
 
 	if ilcfg := cfg.Integrations.ImpressionListener; ilcfg.Endpoint != "" {
 		var err error
-		proxyOptions.ImpressionListener, err = impressionlistener.NewImpressionBulkListener(ilcfg.Endpoint, int(ilcfg.QueueSize), nil)
+		proxyOptions.ImpressionListener, err = impressionlistener.NewImpressionBulkListener(
+			ilcfg.Endpoint,
+			int(ilcfg.QueueSize),
+			&http.Client{Timeout: time.Duration(ilcfg.TimeoutMs) * time.Millisecond})
 		if err != nil {
 			return common.NewInitError(fmt.Errorf("error instantiating impression listener: %w", err), common.ExitTaskInitialization)
 		}
+		proxyOptions.ImpressionListenerConcurrency = int(ilcfg.SubmitConcurrency)
 		proxyOptions.ImpressionListener.Start()
 	}
 
 	proxyAPI := New(proxyOptions)
-	go proxyAPI.Start()
+	if err := proxyAPI.Start(); err != nil {
+		return common.NewInitError(fmt.Errorf("error binding proxy port: %w", err), common.ExitPortBindFailed)
+	}
+
+	rateLimit, rateBurst := int(cfg.Server.IngestRateLimit), int(cfg.Server.IngestRateBurst)
+	reloader.OnChange("server.ingestRateLimit", func(value interface{}) {
+		rateLimit = int(value.(int64))
+		proxyAPI.SetIngestRateLimit(rateLimit, rateBurst)
+	})
+	reloader.OnChange("server.ingestRateBurst", func(value interface{}) {
+		rateBurst = int(value.(int64))
+		proxyAPI.SetIngestRateLimit(rateLimit, rateBurst)
+	})
+	reloader.Start() // once every handler is registered
 
 	rtm.RegisterShutdownHandler()
 	rtm.Block()
+
+	proxyAPI.Stop()
+
+	if statsdExporter != nil {
+		statsdExporter.Stop()
+	}
+
+	if upstreamFailover != nil {
+		upstreamFailover.Stop()
+	}
+
+	if tracerProvider != nil {
+		util.StopTracing(tracerProvider, logger)
+	}
 	return nil
 }
 
+// how long to wait for the lock on the persistent storage file before giving up
+const boltOpenTimeout = 5 * time.Second
+
+// same as the producer's, used when optimized mode is enforced without configuring an observer size
+const defaultImpressionObserverSize = 500
+
 var (
 	errRetrying      = errors.New("error but snapshot available")
 	errUnrecoverable = errors.New("error and no snapshot available")
+	errInBackground  = errors.New("seeded data available, initial sync running in background")
 )
 
-func startBGSyng(m synchronizer.Manager, mstatus chan int, haveSnapshot bool, onReady func()) error {
+// deferredSinkStats exposes the outcome of posts made by a proxy recording task alongside the producer ones in the dashboard
+type deferredSinkStats struct {
+	task *pTasks.DeferredRecordingTaskImpl
+}
+
+func (d deferredSinkStats) Stats() stats.SaveStats {
+	posts := d.task.Stats()
+	return stats.SaveStats{Flushed: posts.Posted, Retried: posts.Retried, Dropped: posts.Dropped}
+}
+
+func startBGSyng(m synchronizer.Manager, mstatus chan int, haveSnapshot bool, seeded bool, onReady func()) error {
 
 	attemptInit := func() bool {
 		go m.Start()
@@ -297,6 +673,18 @@ func startBGSyng(m synchronizer.Manager, mstatus chan int, haveSnapshot bool, on
 		return false // should not reach here TODO:LOG!
 	}
 
+	retryInBG := func() {
+		boff := backoff.New(2, 10*time.Minute)
+		for !attemptInit() {
+			time.Sleep(boff.Next())
+		}
+	}
+
+	if seeded { // there's data to serve already, so there's no need to wait for the first attempt
+		go retryInBG()
+		return errInBackground
+	}
+
 	if attemptInit() { // succeeeded at first try
 		return nil
 	}
@@ -305,25 +693,19 @@ func startBGSyng(m synchronizer.Manager, mstatus chan int, haveSnapshot bool, on
 		return errUnrecoverable
 	}
 
-	go func() {
-		boff := backoff.New(2, 10*time.Minute)
-		for !attemptInit() {
-			time.Sleep(boff.Next())
-		}
-	}()
-
+	go retryInBG()
 	return errRetrying
 
 }
 
 func getAppCounterConfigs() (hcAppCounter.ThresholdConfig, hcAppCounter.ThresholdConfig) {
-	splitsConfig := hcAppCounter.DefaultThresholdConfig("Splits")
-	segmentsConfig := hcAppCounter.DefaultThresholdConfig("Segments")
+	splitsConfig := hcAppCounter.DefaultThresholdConfig(hcAppCounter.SplitsName)
+	segmentsConfig := hcAppCounter.DefaultThresholdConfig(hcAppCounter.SegmentsName)
 
 	return splitsConfig, segmentsConfig
 }
 
-func getServicesCountersConfig(advanced conf.AdvancedConfig) []hcServicesCounter.Config {
+func getServicesCountersConfig(advanced conf.AdvancedConfig, transport http.RoundTripper) []hcServicesCounter.Config {
 	var cfgs []hcServicesCounter.Config
 
 	apiConfig := hcServicesCounter.DefaultConfig("API", advanced.SdkURL, "/version")
@@ -342,5 +724,9 @@ func getServicesCountersConfig(advanced conf.AdvancedConfig) []hcServicesCounter
 	}
 	streamingConfig := hcServicesCounter.DefaultConfig("Streaming", fmt.Sprintf("%s://%s", streamingURL.Scheme, streamingURL.Host), "/health")
 
-	return append(cfgs, telemetryConfig, authConfig, apiConfig, eventsConfig, streamingConfig)
+	cfgs = append(cfgs, telemetryConfig, authConfig, apiConfig, eventsConfig, streamingConfig)
+	for idx := range cfgs {
+		cfgs[idx].Transport = transport
+	}
+	return cfgs
 }
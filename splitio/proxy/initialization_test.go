@@ -33,7 +33,7 @@ func TestSyncManagerInitializationRetriesWithSnapshot(t *testing.T) {
 
 	// No snapshot and error
 	complete := make(chan struct{}, 1)
-	err := startBGSyng(sm, sm.c, false, func() { complete <- struct{}{} })
+	err := startBGSyng(sm, sm.c, false, false, func() { complete <- struct{}{} })
 	if err != errUnrecoverable {
 		t.Error("should be an unrecoverable error. Got: ", err)
 	}
@@ -47,7 +47,7 @@ func TestSyncManagerInitializationRetriesWithSnapshot(t *testing.T) {
 
 	// Snapshot and error
 	atomic.StoreInt64(&sm.execCount, 0)
-	err = startBGSyng(sm, sm.c, true, func() { complete <- struct{}{} })
+	err = startBGSyng(sm, sm.c, true, false, func() { complete <- struct{}{} })
 	if err != errRetrying {
 		t.Error("should be a retrying error. Got: ", err)
 	}
@@ -63,3 +63,21 @@ func TestSyncManagerInitializationRetriesWithSnapshot(t *testing.T) {
 		t.Error("there should be 2 executions")
 	}
 }
+
+func TestSyncManagerInitializationInBackgroundWhenSeeded(t *testing.T) {
+	sm := &syncManagerMock{c: make(chan int, 1)}
+	atomic.StoreInt64(&sm.execCount, 1) // succeed at first try
+
+	complete := make(chan struct{}, 1)
+	err := startBGSyng(sm, sm.c, false, true, func() { complete <- struct{}{} })
+	if err != errInBackground {
+		t.Error("should be an in-background error. Got: ", err)
+	}
+
+	select {
+	case <-complete:
+		// all good
+	case <-time.After(2500 * time.Millisecond):
+		t.Error("should not time out")
+	}
+}
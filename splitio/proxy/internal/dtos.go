@@ -8,6 +8,7 @@ import (
 type RawData struct {
 	Metadata dtos.Metadata
 	Payload  []byte
+	Attempts int // failed attempts to post this bulk to Split servers
 }
 
 func newRawData(metadata dtos.Metadata, payload []byte) *RawData {
@@ -4,7 +4,9 @@ import (
 	"crypto/tls"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/splitio/go-split-commons/v6/provisional/strategy"
 	"github.com/splitio/go-split-commons/v6/service"
 	"github.com/splitio/go-toolkit/v5/logging"
 
@@ -12,11 +14,13 @@ import (
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/controllers"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/controllers/middleware"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/flagsets"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/grpcingest"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/streaming"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/tasks"
+	"github.com/splitio/split-synchronizer/v5/splitio/util"
 
 	"github.com/gin-contrib/cors"
-	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
 	"github.com/splitio/gincache"
 )
@@ -38,6 +42,15 @@ type Options struct {
 	// ImpressionListener to forward incoming impression bulks to
 	ImpressionListener impressionlistener.ImpressionBulkListener
 
+	// Max number of incoming impression bulks being converted & forwarded to the listener at once
+	ImpressionListenerConcurrency int
+
+	// Used to drop impressions already posted by other SDKs in optimized mode (no deduplication if nil)
+	ImpressionObserver strategy.ImpressionObserver
+
+	// Impressions mode enforced on impressions posted by SDKs (the one reported by each SDK is used if empty)
+	ImpressionsMode string
+
 	// Whether to do verbose logging in the gin framework
 	DebugOn bool
 
@@ -77,12 +90,72 @@ type Options struct {
 	// HTTP cache
 	Cache *gincache.Middleware
 
+	// Gates sdk-serving endpoints until the initial synchronization completes (always ready if nil)
+	Readiness *middleware.Readiness
+
 	// Proxy TLS configuration
 	TLSConfig *tls.Config
 
+	// Whether to compress responses with brotli for clients that support it
+	BrotliEnabled bool
+
+	// Brotli compression level (0-11)
+	BrotliLevel int
+
+	// Gzip compression level (-2 to 9, -1 being the default level)
+	GzipLevel int
+
+	// Responses smaller than this (in bytes) are not compressed, with either gzip or brotli
+	GzipMinSize int
+
+	// Max requests per second accepted from each client on impressions/events/metrics endpoints (0 = unlimited)
+	IngestRateLimit int
+
+	// Max burst of requests accepted from each client on top of the ingest rate limit
+	IngestRateBurst int
+
+	// Middleware adding the proxy version & instance id to every response (none if nil)
+	IdentityHeaders *middleware.IdentityHeaders
+
+	// Max time to read an entire request, including the body (0 = no timeout)
+	ReadTimeout time.Duration
+
+	// Max time to write a response, starting when the request headers are read (0 = no timeout)
+	WriteTimeout time.Duration
+
+	// Max time to keep an idle keep-alive connection open (0 = use the read timeout)
+	IdleTimeout time.Duration
+
+	// Max size (in bytes) of request bodies accepted on impressions/events/metrics endpoints (0 = unlimited)
+	MaxIngestBodySize int64
+
+	// Max number of keys accepted in a single bulk mySegments request
+	MySegmentsBulkMaxKeys int
+
+	// max-age sent in the Cache-Control header of splitChanges & segmentChanges responses (0 = no header)
+	CacheControlMaxAge time.Duration
+
+	// Notifications pushed to SDKs connected to the streaming endpoint (streaming is disabled if nil)
+	StreamingBroadcaster *streaming.Broadcaster
+
+	// Issues & validates the tokens SDKs use to connect to the streaming endpoint
+	StreamingTokens *streaming.TokenIssuer
+
 	FlagSets []string
 
 	FlagSetsStrictMatching bool
+
+	// Cross-origin settings for browser SDKs
+	CORS CORSOptions
+
+	// Logs a sample of the requests served (no access log if nil)
+	AccessLogger *middleware.AccessLogger
+
+	// Port for the gRPC impressions & events ingest server (disabled if 0)
+	GRPCIngestPort int
+
+	// Wraps every request in an OpenTelemetry span (no spans if nil)
+	Tracing *middleware.Tracing
 }
 
 // API bundles all components required to answer API calls from Split sdks
@@ -91,14 +164,34 @@ type API struct {
 	sdkConroller        *controllers.SdkServerController
 	eventsConroller     *controllers.EventsServerController
 	telemetryController *controllers.TelemetryServerController
+	grpcIngest          *grpcingest.Server
+	rateLimiter         *middleware.RateLimiter
 }
 
-// Start the Proxy service endpoints
+// Start binds the proxy port (and the gRPC ingest one if enabled) & serves the Proxy service endpoints in the background
 func (s *API) Start() error {
-	if s.server.TLSConfig != nil {
-		return s.server.ListenAndServeTLS("", "") // cert & key set in TLSConfig option
+	if err := util.ServeInBackground(s.server); err != nil {
+		return err
+	}
+	if s.grpcIngest != nil {
+		if err := s.grpcIngest.Start(); err != nil {
+			return fmt.Errorf("gRPC ingest server: %w", err)
+		}
 	}
-	return s.server.ListenAndServe()
+	return nil
+}
+
+// Stop waits for in-flight gRPC ingest calls to complete & shuts the gRPC ingest server down (if enabled)
+func (s *API) Stop() {
+	if s.grpcIngest != nil {
+		s.grpcIngest.Stop()
+	}
+}
+
+// SetIngestRateLimit updates the max requests per second (and burst) accepted from each client on
+// impressions/events/metrics endpoints. A rate of 0 disables the limit
+func (s *API) SetIngestRateLimit(ratePerSec int, burst int) {
+	s.rateLimiter.SetLimits(float64(ratePerSec), burst)
 }
 
 // New instantiates a new Server
@@ -108,21 +201,40 @@ func New(options *Options) *API {
 	}
 
 	apikeyValidator := middleware.NewAPIKeyValidator(options.APIKeys)
-	authController := controllers.NewAuthServerController()
+	authController := controllers.NewAuthServerController(options.Logger, options.StreamingTokens)
 	sdkController := setupSdkController(options)
 	eventsController := setupEventsController(options, apikeyValidator)
 	telemetryController := setupTelemetryController(options, apikeyValidator)
 
 	router := gin.New()
 	router.Use(gin.Recovery())
-	router.Use(setupCorsMiddleware())
+	router.Use(middleware.SetRequestID)
+	if options.Tracing != nil {
+		router.Use(options.Tracing.Handle)
+	}
+	if options.AccessLogger != nil {
+		router.Use(options.AccessLogger.Handle)
+	}
+	if options.IdentityHeaders != nil {
+		router.Use(options.IdentityHeaders.Handle)
+	}
+	if options.CORS.Enabled {
+		router.Use(cors.New(options.CORS.corsConfig()))
+	}
 	router.Use(middleware.SetEndpoint)
 	router.Use(middleware.NewProxyMetricsMiddleware(options.Telemetry).Track)
 
+	// brotli (when enabled) must run before gzip, so that it can take precedence
+	compressors := []gin.HandlerFunc{middleware.NewGzipCompressor(options.GzipLevel, options.GzipMinSize).Handle}
+	if options.BrotliEnabled {
+		compressors = append([]gin.HandlerFunc{middleware.NewBrotliCompressor(options.BrotliLevel, options.GzipMinSize).Handle}, compressors...)
+	}
+
 	// split the main router into regular & beacon endpoints
 	regular := router.Group("/api")
 	regular.Use(apikeyValidator.AsMiddleware)
-	regular.Use(gzip.Gzip(gzip.DefaultCompression))
+	regular.Use(middleware.HandleConditionalRequests)
+	regular.Use(compressors...)
 
 	// Beacon endpoints group
 	beacon := router.Group("/api")
@@ -133,23 +245,69 @@ func New(options *Options) *API {
 	if options.Cache != nil {
 		cacheableRouter = router.Group("/api")
 		cacheableRouter.Use(apikeyValidator.AsMiddleware)
-		cacheableRouter.Use(options.Cache.Handle)
-		cacheableRouter.Use(gzip.Gzip(gzip.DefaultCompression))
+		cacheableRouter.Use(middleware.HandleConditionalRequests)
+		cacheableRouter.Use(middleware.ExcludeHeadersFromCache(options.Cache.Handle,
+			middleware.RequestIDHeader, middleware.ProxyVersionHeader, middleware.ProxyInstanceHeader))
+		cacheableRouter.Use(compressors...)
+	}
+	// impressions, events & telemetry endpoints are optionally rate-limited per client & have their body size capped.
+	// the rate limiter is always installed (letting everything through while the rate is 0) so that it can be updated later
+	// beacon endpoints don't validate the Authorization header, so their clients are identified by address only
+	rateLimiter := middleware.NewRateLimiter(float64(options.IngestRateLimit), options.IngestRateBurst)
+	ingest := regular.Group("", rateLimiter.Handle)
+	beaconIngest := beacon.Group("", rateLimiter.HandleByAddress)
+	if options.MaxIngestBodySize > 0 {
+		bodySizeLimiter := middleware.NewBodySizeLimiter(options.MaxIngestBodySize)
+		ingest.Use(bodySizeLimiter.Handle)
+		beaconIngest.Use(bodySizeLimiter.Handle)
+	}
+
+	if options.StreamingBroadcaster != nil {
+		// tokens expire, so auth responses can't be cached when streaming is enabled
+		authController.Register(regular)
+		controllers.NewStreamingServerController(options.Logger, options.StreamingBroadcaster, options.StreamingTokens).Register(router)
+	} else {
+		authController.Register(cacheableRouter)
+	}
+	sdkCacheable, sdkUncached := cacheableRouter, gin.IRouter(regular)
+	if options.Readiness != nil {
+		// the gate runs after the cache middleware, but 503s are not cached since only successful responses are
+		sdkCacheable, sdkUncached = cacheableRouter.Group("", options.Readiness.Handle), regular.Group("", options.Readiness.Handle)
+	}
+	sdkController.Register(sdkCacheable, sdkUncached)
+	eventsController.Register(ingest, beaconIngest)
+	telemetryController.Register(ingest, beaconIngest)
+
+	var grpcIngest *grpcingest.Server
+	if options.GRPCIngestPort > 0 {
+		// shares the http controller, so that data received over grpc goes through the same validation, dedup & sinks
+		grpcIngest = grpcingest.New(&grpcingest.Options{
+			Logger:          options.Logger,
+			Host:            options.Host,
+			Port:            options.GRPCIngestPort,
+			Stager:          eventsController,
+			APIKeyValidator: apikeyValidator.IsValid,
+			TLSConfig:       options.TLSConfig,
+			MaxMessageSize:  int(options.MaxIngestBodySize),
+			RateLimiter:     rateLimiter,
+			Telemetry:       options.Telemetry,
+		})
 	}
-	authController.Register(cacheableRouter)
-	sdkController.Register(cacheableRouter)
-	eventsController.Register(regular, beacon)
-	telemetryController.Register(regular, beacon)
 
 	return &API{
 		server: &http.Server{
-			Addr:      fmt.Sprintf("0.0.0.0:%d", options.Port),
-			Handler:   router,
-			TLSConfig: options.TLSConfig,
+			Addr:         fmt.Sprintf("0.0.0.0:%d", options.Port),
+			Handler:      router,
+			TLSConfig:    options.TLSConfig,
+			ReadTimeout:  options.ReadTimeout,
+			WriteTimeout: options.WriteTimeout,
+			IdleTimeout:  options.IdleTimeout,
 		},
 		sdkConroller:        sdkController,
 		eventsConroller:     eventsController,
 		telemetryController: telemetryController,
+		grpcIngest:          grpcIngest,
+		rateLimiter:         rateLimiter,
 	}
 }
 
@@ -160,9 +318,28 @@ func setupSdkController(options *Options) *controllers.SdkServerController {
 		options.ProxySplitStorage,
 		options.ProxySegmentStorage,
 		flagsets.NewMatcher(options.FlagSetsStrictMatching, options.FlagSets),
+		options.MySegmentsBulkMaxKeys,
+		options.CacheControlMaxAge,
+		degradedResponsesTelemetry(options.Telemetry),
 	)
 }
 
+// degradedResponsesTelemetry returns nil if the supplied telemetry storage doesn't track degraded responses
+func degradedResponsesTelemetry(telemetry storage.ProxyEndpointTelemetry) storage.DegradedResponsesTelemetry {
+	if degraded, ok := telemetry.(storage.DegradedResponsesTelemetry); ok {
+		return degraded
+	}
+	return nil
+}
+
+// listenerDropsTelemetry returns nil if the supplied telemetry storage doesn't track impression listener drops
+func listenerDropsTelemetry(telemetry storage.ProxyEndpointTelemetry) storage.ListenerDropsTelemetry {
+	if drops, ok := telemetry.(storage.ListenerDropsTelemetry); ok {
+		return drops
+	}
+	return nil
+}
+
 func setupEventsController(options *Options, apikeyValidator *middleware.APIKeyValidator) *controllers.EventsServerController {
 	return controllers.NewEventsServerController(
 		options.Logger,
@@ -171,6 +348,10 @@ func setupEventsController(options *Options, apikeyValidator *middleware.APIKeyV
 		options.EventsSink,
 		options.ImpressionListener,
 		apikeyValidator.IsValid,
+		options.ImpressionListenerConcurrency,
+		options.ImpressionObserver,
+		options.ImpressionsMode,
+		listenerDropsTelemetry(options.Telemetry),
 	)
 }
 
@@ -184,19 +365,3 @@ func setupTelemetryController(options *Options, apikeyValidator *middleware.APIK
 		apikeyValidator.IsValid,
 	)
 }
-
-func setupCorsMiddleware() func(*gin.Context) {
-	corsConfig := cors.DefaultConfig()
-	corsConfig.AllowAllOrigins = true
-	corsConfig.AllowHeaders = []string{
-		"Origin",
-		"Content-Length",
-		"Content-Type",
-		"SplitSDKMachineName",
-		"SplitSDKMachineIP",
-		"SplitSDKVersion",
-		"SplitSDKImpressionsMode",
-		"Authorization",
-	}
-	return cors.New(corsConfig)
-}
@@ -1,10 +1,13 @@
 package proxy
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math/rand"
+	"net"
 	"net/http"
 	"testing"
 	"time"
@@ -14,6 +17,7 @@ import (
 	"github.com/splitio/go-toolkit/v5/logging"
 	ilmock "github.com/splitio/split-synchronizer/v5/splitio/common/impressionlistener/mocks"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/caching"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/controllers/middleware"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
 	pstorageMocks "github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/mocks"
 	taskMocks "github.com/splitio/split-synchronizer/v5/splitio/proxy/tasks/mocks"
@@ -73,6 +77,93 @@ func TestSplitChangesEndpoints(t *testing.T) {
 
 }
 
+func TestSplitChangesConditionalRequests(t *testing.T) {
+	opts := makeOpts()
+	var splitStorage pstorageMocks.ProxySplitStorageMock
+	opts.ProxySplitStorage = &splitStorage
+	proxy := New(opts)
+	go proxy.Start()
+	time.Sleep(1 * time.Second) // Let the scheduler switch the current thread/gr and start the server
+
+	splitStorage.On("ChangesSince", int64(-1), []string(nil)).
+		Return(&dtos.SplitChangesDTO{Since: -1, Till: 1, Splits: []dtos.SplitDTO{{Name: "split1"}}}, nil).
+		Once()
+
+	status, body, headers := get("splitChanges?since=-1", opts.Port, map[string]string{"Authorization": "Bearer someApiKey"})
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "split1", toSplitChanges(body).Splits[0].Name)
+	etag := headers.Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	// matching etag (served from cache)
+	status, body, headers = get("splitChanges?since=-1", opts.Port, map[string]string{"Authorization": "Bearer someApiKey", "If-None-Match": etag})
+	assert.Equal(t, 304, status)
+	assert.Empty(t, body)
+	assert.Equal(t, etag, headers.Get("ETag"))
+
+	// same etag for a different query
+	splitStorage.On("ChangesSince", int64(-1), []string{"set1"}).
+		Return(&dtos.SplitChangesDTO{Since: -1, Till: 1, Splits: []dtos.SplitDTO{}}, nil).
+		Once()
+	status, _, headers = get("splitChanges?since=-1&sets=set1", opts.Port, map[string]string{"Authorization": "Bearer someApiKey", "If-None-Match": etag})
+	assert.Equal(t, 200, status)
+	assert.NotEqual(t, etag, headers.Get("ETag"))
+
+	// stale etag
+	status, body, _ = get("splitChanges?since=-1", opts.Port, map[string]string{"Authorization": "Bearer someApiKey", "If-None-Match": `W/"0"`})
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "split1", toSplitChanges(body).Splits[0].Name)
+
+	// matching etag after an eviction (not cached)
+	splitStorage.On("ChangesSince", int64(-1), []string(nil)).
+		Return(&dtos.SplitChangesDTO{Since: -1, Till: 1, Splits: []dtos.SplitDTO{{Name: "split1"}}}, nil).
+		Once()
+	opts.Cache.EvictBySurrogate(caching.SplitSurrogate)
+	status, body, _ = get("splitChanges?since=-1", opts.Port, map[string]string{"Authorization": "Bearer someApiKey", "If-None-Match": etag})
+	assert.Equal(t, 304, status)
+	assert.Empty(t, body)
+
+	// the full response was still cached
+	status, body, _ = get("splitChanges?since=-1", opts.Port, map[string]string{"Authorization": "Bearer someApiKey"})
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "split1", toSplitChanges(body).Splits[0].Name)
+
+	splitStorage.AssertExpectations(t)
+	assert.Equal(t, int64(2), opts.Telemetry.(storage.ProxyTelemetryFacade).PeekEndpointStatus(storage.SplitChangesEndpoint)[304])
+}
+
+func TestPerRequestHeadersOnCachedResponses(t *testing.T) {
+	opts := makeOpts()
+	opts.IdentityHeaders = middleware.NewIdentityHeaders("1.2.3", "instance1")
+	var splitStorage pstorageMocks.ProxySplitStorageMock
+	opts.ProxySplitStorage = &splitStorage
+	proxy := New(opts)
+	go proxy.Start()
+	time.Sleep(1 * time.Second) // Let the scheduler switch the current thread/gr and start the server
+
+	splitStorage.On("ChangesSince", int64(-1), []string(nil)).
+		Return(&dtos.SplitChangesDTO{Since: -1, Till: 1, Splits: []dtos.SplitDTO{{Name: "split1"}}}, nil).
+		Once()
+
+	var etag string
+	for _, id := range []string{"first", "second"} {
+		status, _, headers := get("splitChanges?since=-1", opts.Port, map[string]string{"Authorization": "Bearer someApiKey", "X-Request-ID": id})
+		etag = headers.Get("ETag")
+		assert.Equal(t, 200, status)
+		assert.Equal(t, []string{id}, headers.Values("X-Request-ID"))
+		assert.Equal(t, []string{"1.2.3"}, headers.Values("X-Split-Proxy-Version"))
+		assert.Equal(t, []string{"instance1"}, headers.Values("X-Split-Proxy-Instance"))
+	}
+
+	// 304s served from cache get them too
+	status, _, headers := get("splitChanges?since=-1", opts.Port, map[string]string{"Authorization": "Bearer someApiKey", "X-Request-ID": "third", "If-None-Match": etag})
+	assert.Equal(t, 304, status)
+	assert.Equal(t, []string{"third"}, headers.Values("X-Request-ID"))
+	assert.Equal(t, []string{"1.2.3"}, headers.Values("X-Split-Proxy-Version"))
+
+	splitStorage.AssertExpectations(t)
+}
+
 func TestSplitChangesWithFlagsetsCaching(t *testing.T) {
 	opts := makeOpts()
 	var splitStorage pstorageMocks.ProxySplitStorageMock
@@ -236,6 +327,67 @@ func TestSegmentChangesAndMySegmentsEndpoints(t *testing.T) {
 	assert.Equal(t, "application/json; charset=utf-8", headers.Get("Content-Type"))
 }
 
+func TestIngestRateLimiting(t *testing.T) {
+	opts := makeOpts()
+	opts.IngestRateLimit = 1
+	opts.IngestRateBurst = 2
+	opts.APIKeys = append(opts.APIKeys, "someOtherApiKey")
+	opts.EventsSink = &taskMocks.MockDeferredRecordingTask{StageCall: func(rawData interface{}) error { return nil }}
+	proxy := New(opts)
+	go proxy.Start()
+	time.Sleep(1 * time.Second) // Let the scheduler switch the current thread/gr and start the server
+
+	headers := map[string]string{"Authorization": "Bearer someApiKey", "SplitSDKMachineIP": "1.2.3.4"}
+	assert.Equal(t, 200, post("events/bulk", opts.Port, []byte("[]"), headers))
+	assert.Equal(t, 200, post("events/bulk", opts.Port, []byte("[]"), headers))
+	assert.Equal(t, 429, post("events/bulk", opts.Port, []byte("[]"), headers))
+
+	// a spoofed machine ip doesn't get a new bucket
+	headers["SplitSDKMachineIP"] = "5.6.7.8"
+	assert.Equal(t, 429, post("events/bulk", opts.Port, []byte("[]"), headers))
+
+	// other clients are not affected
+	headers["Authorization"] = "Bearer someOtherApiKey"
+	assert.Equal(t, 200, post("events/bulk", opts.Port, []byte("[]"), headers))
+
+	assert.Equal(t, int64(2), opts.Telemetry.(storage.ProxyTelemetryFacade).PeekEndpointStatus(storage.EventsBulkEndpoint)[429])
+}
+
+func TestIngestBodySizeLimit(t *testing.T) {
+	opts := makeOpts()
+	opts.MaxIngestBodySize = 10
+	opts.EventsSink = &taskMocks.MockDeferredRecordingTask{StageCall: func(rawData interface{}) error { return nil }}
+	proxy := New(opts)
+	go proxy.Start()
+	time.Sleep(1 * time.Second) // Let the scheduler switch the current thread/gr and start the server
+
+	headers := map[string]string{"Authorization": "Bearer someApiKey"}
+	assert.Equal(t, 200, post("events/bulk", opts.Port, []byte("[]"), headers))
+	assert.Equal(t, 413, post("events/bulk", opts.Port, []byte(`[{"key": "someKey"}]`), headers))
+	assert.Equal(t, int64(1), opts.Telemetry.(storage.ProxyTelemetryFacade).PeekEndpointStatus(storage.EventsBulkEndpoint)[413])
+}
+
+func TestServerTimeouts(t *testing.T) {
+	opts := makeOpts()
+	opts.ReadTimeout = 200 * time.Millisecond
+	proxy := New(opts)
+	go proxy.Start()
+	time.Sleep(1 * time.Second) // Let the scheduler switch the current thread/gr and start the server
+
+	// a client that never finishes sending its request should be disconnected
+	conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", opts.Port))
+	assert.Nil(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("GET /api/splitChanges HTTP/1.1\r\nHost: localhost\r\n"))
+	assert.Nil(t, err)
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	before := time.Now()
+	_, err = io.ReadAll(conn)
+	assert.Nil(t, err) // connection closed by the server, not by our deadline
+	assert.Less(t, time.Since(before), 2*time.Second)
+}
+
 func makeOpts() *Options {
 	return &Options{
 		Logger:              logging.NewLogger(nil),
@@ -281,6 +433,24 @@ func get(path string, port int, headers map[string]string) (status int, body []b
 	return resp.StatusCode, body, resp.Header
 }
 
+func post(path string, port int, body []byte, headers map[string]string) int {
+	request, err := http.NewRequest("POST", fmt.Sprintf("http://localhost:%d/api/%s", port, path), bytes.NewReader(body))
+	if err != nil {
+		panic(err.Error())
+	}
+
+	for header, value := range headers {
+		request.Header.Add(header, value)
+	}
+
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		panic(err.Error())
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
 func toSplitChanges(body []byte) dtos.SplitChangesDTO {
 	var c dtos.SplitChangesDTO
 	err := json.Unmarshal(body, &c)
@@ -0,0 +1,47 @@
+package replica
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-split-commons/v6/service"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+)
+
+// SplitFetcher replaces the upstream fetcher used for `since` values older than the ones the storage can build
+// payloads for. Since replicas cannot connect to Split servers, the whole set of feature flags is returned instead
+type SplitFetcher struct {
+	splits storage.ProxySplitStorage
+}
+
+// NewSplitFetcher constructs a new fetcher that serves payloads from the local storage
+func NewSplitFetcher(splits storage.ProxySplitStorage) *SplitFetcher {
+	return &SplitFetcher{splits: splits}
+}
+
+// Fetch returns all the active feature flags (matching the requested flag sets if any)
+func (f *SplitFetcher) Fetch(params *service.FlagRequestParams) (*dtos.SplitChangesDTO, error) {
+	// flag sets are only exposed as part of the query string
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	if err := params.Apply(req); err != nil {
+		return nil, fmt.Errorf("error parsing request params: %w", err)
+	}
+
+	var sets []string
+	if raw := req.URL.Query().Get("sets"); raw != "" {
+		sets = strings.Split(raw, ",")
+	}
+
+	changes, err := f.splits.ChangesSince(-1, sets)
+	if err != nil {
+		return nil, err
+	}
+
+	changes.Since = params.ChangeNumber()
+	return changes, nil
+}
+
+var _ service.SplitFetcher = (*SplitFetcher)(nil)
@@ -0,0 +1,237 @@
+package replica
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/splitio/gincache"
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-split-commons/v6/storage"
+	"github.com/splitio/go-toolkit/v5/datastructures/set"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/conf"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/caching"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/persistent"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// how long to wait for the lock on the replicated file before giving up
+const openTimeout = 5 * time.Second
+
+// Loader keeps the proxy storages in sync with a boltdb file replicated from another proxy instance,
+// without ever connecting to Split servers. It implements the synchronizer.Manager interface so that it can be
+// used in place of the regular sync manager
+type Loader struct {
+	path         string
+	splits       storage.SplitStorage
+	segments     storage.SegmentStorage
+	cacheFlusher gincache.CacheFlusher
+	logger       logging.LoggerInterface
+	watcher      *conf.FileWatcher
+	reloadPeriod time.Duration
+	running      atomic.Bool
+}
+
+// NewLoader constructs a new replica loader
+func NewLoader(
+	path string,
+	splits storage.SplitStorage,
+	segments storage.SegmentStorage,
+	cacheFlusher gincache.CacheFlusher,
+	logger logging.LoggerInterface,
+	reloadPeriod time.Duration,
+) *Loader {
+	return &Loader{
+		path:         path,
+		splits:       splits,
+		segments:     segments,
+		cacheFlusher: cacheFlusher,
+		logger:       logger,
+		reloadPeriod: reloadPeriod,
+	}
+}
+
+// Load reads the replicated file & applies the differences with the current state to the storages
+func (l *Loader) Load() error {
+	db, err := persistent.NewBoltWrapper(l.path, &bolt.Options{ReadOnly: true, Timeout: openTimeout})
+	if err != nil {
+		return fmt.Errorf("error opening replicated file: %w", err)
+	}
+	defer db.Close()
+
+	if err := l.loadSplits(persistent.NewSplitChangesCollection(db, l.logger)); err != nil {
+		return err
+	}
+	return l.loadSegments(persistent.NewSegmentChangesCollection(db, l.logger))
+}
+
+// Start begins watching the replicated file for changes
+func (l *Loader) Start() {
+	watcher, err := conf.NewFileWatcher(l.path, l.reloadPeriod, func() {
+		l.logger.Info("replicated file changed. reloading feature flags & segments")
+		if err := l.Load(); err != nil {
+			l.logger.Error("error reloading replicated file: ", err)
+		}
+	}, l.logger)
+	if err != nil {
+		l.logger.Error("error watching replicated file. Data will not be updated: ", err)
+		return
+	}
+
+	l.watcher = watcher
+	l.watcher.Start()
+	l.running.Store(true)
+}
+
+// Stop stops watching the replicated file
+func (l *Loader) Stop() {
+	if l.watcher != nil {
+		l.watcher.Stop()
+	}
+	l.running.Store(false)
+}
+
+// IsRunning returns whether the replicated file is being watched
+func (l *Loader) IsRunning() bool {
+	return l.running.Load()
+}
+
+func (l *Loader) loadSplits(src *persistent.SplitChangesCollection) error {
+	all, err := src.FetchAll()
+	if err != nil {
+		return fmt.Errorf("error reading feature flags from replicated file: %w", err)
+	}
+
+	current, _ := l.splits.ChangeNumber()
+	cn := current
+	inFile := make(map[string]struct{}, len(all))
+	var toAdd, toRemove []dtos.SplitDTO
+	for idx := range all {
+		if all[idx].ChangeNumber > cn {
+			cn = all[idx].ChangeNumber
+		}
+
+		existing := l.splits.Split(all[idx].Name)
+		if all[idx].Status != "ACTIVE" {
+			if existing != nil {
+				toRemove = append(toRemove, all[idx])
+			}
+			continue
+		}
+
+		inFile[all[idx].Name] = struct{}{}
+		if existing == nil || existing.ChangeNumber != all[idx].ChangeNumber {
+			toAdd = append(toAdd, all[idx])
+		}
+	}
+
+	// feature flags that are no longer present in the file are removed as well
+	for _, split := range l.splits.All() {
+		if _, ok := inFile[split.Name]; !ok && !containsSplit(toRemove, split.Name) {
+			split.Status = "ARCHIVED"
+			split.ChangeNumber = cn
+			toRemove = append(toRemove, split)
+		}
+	}
+
+	if len(toAdd) == 0 && len(toRemove) == 0 && cn == current {
+		return nil
+	}
+
+	l.splits.Update(toAdd, toRemove, cn)
+	l.cacheFlusher.EvictBySurrogate(caching.SplitSurrogate)
+	l.logger.Info(fmt.Sprintf("feature flags loaded from replicated file. changeNumber: %d", cn))
+	return nil
+}
+
+func (l *Loader) loadSegments(src *persistent.SegmentChangesCollectionImpl) error {
+	all, err := src.FetchAll()
+	if err != nil {
+		return fmt.Errorf("error reading segments from replicated file: %w", err)
+	}
+
+	for idx := range all {
+		name := all[idx].Name
+		till := all[idx].ChangeNumber
+		inFile := set.NewSet()
+		for _, key := range all[idx].Keys {
+			if key.ChangeNumber > till {
+				till = key.ChangeNumber
+			}
+			if !key.Removed {
+				inFile.Add(key.Name)
+			}
+		}
+
+		current := set.NewSet()
+		cn, _ := l.segments.ChangeNumber(name)
+		if cn != -1 {
+			current = activeKeys(l.segments.Keys(name))
+		}
+
+		toAdd := difference(inFile, current)
+		toRemove := difference(current, inFile)
+		if toAdd.Size() == 0 && toRemove.Size() == 0 && till <= cn {
+			continue
+		}
+
+		if err := l.segments.Update(name, toAdd, toRemove, till); err != nil {
+			l.logger.Error(fmt.Sprintf("error updating segment %s from replicated file: %s", name, err.Error()))
+			continue
+		}
+
+		l.cacheFlusher.EvictBySurrogate(caching.MakeSurrogateForSegmentChanges(name))
+		for _, changed := range append(toAdd.List(), toRemove.List()...) {
+			if key, ok := changed.(string); ok {
+				for _, entry := range caching.MakeMySegmentsEntries(key) {
+					l.cacheFlusher.Evict(entry)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// activeKeys returns the names of the keys that have not been removed. Proxy segment storages return persisted keys
+func activeKeys(keys *set.ThreadUnsafeSet) *set.ThreadUnsafeSet {
+	toRet := set.NewSet()
+	if keys == nil {
+		return toRet
+	}
+
+	keys.Each(func(item interface{}) bool {
+		switch k := item.(type) {
+		case string:
+			toRet.Add(k)
+		case persistent.SegmentKey:
+			if !k.Removed {
+				toRet.Add(k.Name)
+			}
+		}
+		return true
+	})
+	return toRet
+}
+
+func difference(a *set.ThreadUnsafeSet, b *set.ThreadUnsafeSet) *set.ThreadUnsafeSet {
+	toRet := set.NewSet()
+	a.Each(func(item interface{}) bool {
+		if !b.Has(item) {
+			toRet.Add(item)
+		}
+		return true
+	})
+	return toRet
+}
+
+func containsSplit(splits []dtos.SplitDTO, name string) bool {
+	for idx := range splits {
+		if splits[idx].Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package replica
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-split-commons/v6/flagsets"
+	"github.com/splitio/go-split-commons/v6/service"
+	"github.com/splitio/go-toolkit/v5/datastructures/set"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/caching"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/persistent"
+)
+
+type flusherMock struct {
+	evicted []string
+}
+
+func (f *flusherMock) EvictAll()                         {}
+func (f *flusherMock) Evict(key string)                  { f.evicted = append(f.evicted, key) }
+func (f *flusherMock) EvictBySurrogate(surrogate string) { f.evicted = append(f.evicted, surrogate) }
+
+// writeReplicatedFile simulates the primary proxy writing to its persistent storage
+func writeReplicatedFile(t *testing.T, path string, update func(*persistent.SplitChangesCollection, *persistent.SegmentChangesCollectionImpl)) {
+	t.Helper()
+	db, err := persistent.NewBoltWrapper(path, nil)
+	assert.Nil(t, err)
+	update(persistent.NewSplitChangesCollection(db, logging.NewLogger(nil)), persistent.NewSegmentChangesCollection(db, logging.NewLogger(nil)))
+	assert.Nil(t, db.Close())
+}
+
+func TestReplicaLoader(t *testing.T) {
+	logger := logging.NewLogger(nil)
+	path := filepath.Join(t.TempDir(), "primary.db")
+	writeReplicatedFile(t, path, func(splits *persistent.SplitChangesCollection, segments *persistent.SegmentChangesCollectionImpl) {
+		splits.Update([]dtos.SplitDTO{
+			{Name: "f1", ChangeNumber: 1, Status: "ACTIVE"},
+			{Name: "f2", ChangeNumber: 2, Status: "ACTIVE"},
+		}, nil, 2)
+		segments.Update("s1", set.NewSet("k1", "k2"), set.NewSet(), 5)
+	})
+
+	dbw, err := persistent.NewBoltWrapper(persistent.BoltInMemoryMode, nil)
+	assert.Nil(t, err)
+	splitStorage := storage.NewProxySplitStorage(dbw, logger, flagsets.NewFlagSetFilter(nil), false, nil, 0, 0)
+	segmentStorage := storage.NewProxySegmentStorage(dbw, logger, false)
+
+	flusher := &flusherMock{}
+	loader := NewLoader(path, splitStorage, segmentStorage, flusher, logger, 0)
+	assert.Nil(t, loader.Load())
+
+	changes, err := splitStorage.ChangesSince(-1, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), changes.Till)
+	assert.ElementsMatch(t, []string{"f1", "f2"}, []string{changes.Splits[0].Name, changes.Splits[1].Name})
+
+	cn, _ := segmentStorage.ChangeNumber("s1")
+	assert.Equal(t, int64(5), cn)
+	assert.True(t, activeKeys(segmentStorage.Keys("s1")).IsEqual(set.NewSet("k1", "k2")))
+	assert.Contains(t, flusher.evicted, caching.SplitSurrogate)
+	assert.Contains(t, flusher.evicted, caching.MakeSurrogateForSegmentChanges("s1"))
+
+	// reloading an unchanged file is a no-op
+	flusher.evicted = nil
+	assert.Nil(t, loader.Load())
+	assert.Empty(t, flusher.evicted)
+
+	// the primary archives a feature flag & removes a key
+	writeReplicatedFile(t, path, func(splits *persistent.SplitChangesCollection, segments *persistent.SegmentChangesCollectionImpl) {
+		splits.Update(nil, []dtos.SplitDTO{{Name: "f1", ChangeNumber: 3, Status: "ARCHIVED"}}, 3)
+		segments.Update("s1", set.NewSet(), set.NewSet("k1"), 6)
+	})
+	assert.Nil(t, loader.Load())
+
+	changes, err = splitStorage.ChangesSince(2, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), changes.Till)
+	assert.Len(t, changes.Splits, 1)
+	assert.Equal(t, "ARCHIVED", changes.Splits[0].Status)
+	assert.Nil(t, splitStorage.Split("f1"))
+
+	segmentChanges, err := segmentStorage.ChangesSince("s1", 5)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"k1"}, segmentChanges.Removed)
+	assert.Equal(t, int64(6), segmentChanges.Till)
+}
+
+func TestReplicaSplitFetcher(t *testing.T) {
+	dbw, err := persistent.NewBoltWrapper(persistent.BoltInMemoryMode, nil)
+	assert.Nil(t, err)
+	splitStorage := storage.NewProxySplitStorage(dbw, logging.NewLogger(nil), flagsets.NewFlagSetFilter(nil), false, nil, 0, 0)
+	splitStorage.Update([]dtos.SplitDTO{
+		{Name: "f1", ChangeNumber: 10, Status: "ACTIVE", Sets: []string{"set1"}},
+		{Name: "f2", ChangeNumber: 11, Status: "ACTIVE", Sets: []string{"set2"}},
+	}, nil, 11)
+
+	fetcher := NewSplitFetcher(splitStorage)
+	changes, err := fetcher.Fetch(service.MakeFlagRequestParams().WithChangeNumber(5))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), changes.Since)
+	assert.Equal(t, int64(11), changes.Till)
+	assert.Len(t, changes.Splits, 2)
+
+	changes, err = fetcher.Fetch(service.MakeFlagRequestParams().WithChangeNumber(5).WithFlagSetsFilter("set2"))
+	assert.Nil(t, err)
+	assert.Len(t, changes.Splits, 1)
+	assert.Equal(t, "f2", changes.Splits[0].Name)
+}
@@ -0,0 +1,72 @@
+package seed
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/splitio/go-split-commons/v6/storage"
+)
+
+// Write serializes the current feature flags & referenced segments in the seed file format. Segments are encoded one
+// at a time & written as soon as they're ready, so that large datasets don't need to be buffered in memory
+func Write(w io.Writer, splits storage.SplitStorageConsumer, segments storage.SegmentStorageConsumer) error {
+	cn, err := splits.ChangeNumber()
+	if err != nil {
+		return fmt.Errorf("error fetching feature flags change number: %w", err)
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	if _, err := fmt.Fprintf(bw, `{"version":%d,"splits":`, FormatVersion); err != nil {
+		return err
+	}
+
+	all := splits.All()
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	if err := enc.Encode(Splits{ChangeNumber: cn, Splits: all}); err != nil {
+		return fmt.Errorf("error encoding feature flags: %w", err)
+	}
+
+	if _, err := bw.WriteString(`,"segments":[`); err != nil {
+		return err
+	}
+
+	names := make([]string, 0)
+	splits.SegmentNames().Each(func(item interface{}) bool {
+		if name, ok := item.(string); ok {
+			names = append(names, name)
+		}
+		return true
+	})
+	sort.Strings(names)
+
+	for idx, name := range names {
+		segmentCN, _ := segments.ChangeNumber(name)
+		keys := make([]string, 0)
+		activeKeys(segments.Keys(name)).Each(func(item interface{}) bool {
+			keys = append(keys, item.(string))
+			return true
+		})
+		sort.Strings(keys)
+
+		if idx > 0 {
+			if err := bw.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(Segment{Name: name, ChangeNumber: segmentCN, Keys: keys}); err != nil {
+			return fmt.Errorf("error encoding segment %s: %w", name, err)
+		}
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := bw.WriteString("]}\n"); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,138 @@
+// Package seed implements reading (& writing) pre-baked feature flags & segments files, used to warm up the proxy
+// storages at startup so that SDKs can be served before the initial synchronization with Split servers completes
+package seed
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-split-commons/v6/storage"
+	"github.com/splitio/go-toolkit/v5/datastructures/set"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/persistent"
+)
+
+// FormatVersion is the version of the seed file format
+const FormatVersion = 1
+
+// ErrUnsupportedVersion is returned when loading a seed file with an unknown format version
+var ErrUnsupportedVersion = errors.New("unsupported seed file version")
+
+// File is the seed file format:
+//
+//	{
+//	  "version": 1,
+//	  "splits": {"changeNumber": 123, "splits": [<splitChanges feature flag objects>]},
+//	  "segments": [{"name": "s1", "changeNumber": 456, "keys": ["k1", "k2"]}]
+//	}
+type File struct {
+	Version  int       `json:"version"`
+	Splits   Splits    `json:"splits"`
+	Segments []Segment `json:"segments"`
+}
+
+// Splits holds the feature flags in a seed file
+type Splits struct {
+	ChangeNumber int64           `json:"changeNumber"`
+	Splits       []dtos.SplitDTO `json:"splits"`
+}
+
+// Segment holds the (active) keys of a segment in a seed file
+type Segment struct {
+	Name         string   `json:"name"`
+	ChangeNumber int64    `json:"changeNumber"`
+	Keys         []string `json:"keys"`
+}
+
+// LoadStats summarizes the outcome of loading a seed file
+type LoadStats struct {
+	Splits   int
+	Segments int
+}
+
+// LoadFromFile reads a seed file & applies it to the storages. Data that is not newer than what's already stored
+// (ie: restored from a snapshot or the persistent storage) is skipped
+func LoadFromFile(path string, splits storage.SplitStorage, segments storage.SegmentStorage) (*LoadStats, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening seed file: %w", err)
+	}
+	defer f.Close()
+
+	var seed File
+	if err := json.NewDecoder(f).Decode(&seed); err != nil {
+		return nil, fmt.Errorf("error parsing seed file: %w", err)
+	}
+
+	return Load(&seed, splits, segments)
+}
+
+// Load applies a seed to the storages. Data that is not newer than what's already stored is skipped
+func Load(seed *File, splits storage.SplitStorage, segments storage.SegmentStorage) (*LoadStats, error) {
+	if seed.Version != FormatVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, seed.Version)
+	}
+
+	stats := &LoadStats{}
+	if current, _ := splits.ChangeNumber(); seed.Splits.ChangeNumber > current {
+		toAdd := make([]dtos.SplitDTO, 0, len(seed.Splits.Splits))
+		for idx := range seed.Splits.Splits {
+			if seed.Splits.Splits[idx].Status == "ACTIVE" {
+				toAdd = append(toAdd, seed.Splits.Splits[idx])
+			}
+		}
+		splits.Update(toAdd, nil, seed.Splits.ChangeNumber)
+		stats.Splits = len(toAdd)
+	}
+
+	for _, segment := range seed.Segments {
+		current, _ := segments.ChangeNumber(segment.Name)
+		if segment.ChangeNumber <= current {
+			continue
+		}
+
+		inSeed := set.NewSet()
+		for _, key := range segment.Keys {
+			inSeed.Add(key)
+		}
+
+		toRemove := set.NewSet()
+		activeKeys(segments.Keys(segment.Name)).Each(func(key interface{}) bool {
+			if !inSeed.Has(key) {
+				toRemove.Add(key)
+			}
+			return true
+		})
+
+		if err := segments.Update(segment.Name, inSeed, toRemove, segment.ChangeNumber); err != nil {
+			return stats, fmt.Errorf("error loading segment %s from seed: %w", segment.Name, err)
+		}
+		stats.Segments++
+	}
+
+	return stats, nil
+}
+
+// activeKeys returns the names of the keys that have not been removed. Proxy segment storages return persisted keys
+func activeKeys(keys *set.ThreadUnsafeSet) *set.ThreadUnsafeSet {
+	toRet := set.NewSet()
+	if keys == nil {
+		return toRet
+	}
+
+	keys.Each(func(item interface{}) bool {
+		switch k := item.(type) {
+		case string:
+			toRet.Add(k)
+		case persistent.SegmentKey:
+			if !k.Removed {
+				toRet.Add(k.Name)
+			}
+		}
+		return true
+	})
+	return toRet
+}
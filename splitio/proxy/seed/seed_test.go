@@ -0,0 +1,108 @@
+package seed
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-split-commons/v6/flagsets"
+	"github.com/splitio/go-toolkit/v5/datastructures/set"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/persistent"
+)
+
+func newStorages(t *testing.T) (*storage.ProxySplitStorageImpl, *storage.ProxySegmentStorageImpl) {
+	t.Helper()
+	logger := logging.NewLogger(nil)
+	dbw, err := persistent.NewBoltWrapper(persistent.BoltInMemoryMode, nil)
+	assert.Nil(t, err)
+	return storage.NewProxySplitStorage(dbw, logger, flagsets.NewFlagSetFilter(nil), false, nil, 0, 0),
+		storage.NewProxySegmentStorage(dbw, logger, false)
+}
+
+func TestLoadFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seed.json")
+	raw, _ := json.Marshal(File{
+		Version: FormatVersion,
+		Splits: Splits{ChangeNumber: 10, Splits: []dtos.SplitDTO{
+			{Name: "f1", ChangeNumber: 9, Status: "ACTIVE"},
+			{Name: "f2", ChangeNumber: 10, Status: "ACTIVE"},
+		}},
+		Segments: []Segment{{Name: "s1", ChangeNumber: 5, Keys: []string{"k1", "k2"}}},
+	})
+	assert.Nil(t, os.WriteFile(path, raw, 0644))
+
+	splits, segments := newStorages(t)
+	stats, err := LoadFromFile(path, splits, segments)
+	assert.Nil(t, err)
+	assert.Equal(t, &LoadStats{Splits: 2, Segments: 1}, stats)
+
+	changes, err := splits.ChangesSince(-1, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), changes.Till)
+	assert.Len(t, changes.Splits, 2)
+
+	cn, _ := segments.ChangeNumber("s1")
+	assert.Equal(t, int64(5), cn)
+	assert.ElementsMatch(t, []interface{}{"k1", "k2"}, activeKeys(segments.Keys("s1")).List())
+}
+
+func TestLoadSkipsOlderData(t *testing.T) {
+	splits, segments := newStorages(t)
+	splits.Update([]dtos.SplitDTO{{Name: "f1", ChangeNumber: 20, Status: "ACTIVE"}}, nil, 20)
+	segments.Update("s1", set.NewSet("k1"), set.NewSet(), 20)
+	segments.Update("s2", set.NewSet("k1", "k2"), set.NewSet(), 1)
+
+	stats, err := Load(&File{
+		Version:  FormatVersion,
+		Splits:   Splits{ChangeNumber: 10, Splits: []dtos.SplitDTO{{Name: "f2", ChangeNumber: 10, Status: "ACTIVE"}}},
+		Segments: []Segment{{Name: "s1", ChangeNumber: 5, Keys: []string{"k9"}}, {Name: "s2", ChangeNumber: 5, Keys: []string{"k2", "k3"}}},
+	}, splits, segments)
+	assert.Nil(t, err)
+	assert.Equal(t, &LoadStats{Splits: 0, Segments: 1}, stats)
+
+	assert.Nil(t, splits.Split("f2"))
+	assert.ElementsMatch(t, []interface{}{"k1"}, activeKeys(segments.Keys("s1")).List())
+	assert.ElementsMatch(t, []interface{}{"k2", "k3"}, activeKeys(segments.Keys("s2")).List())
+}
+
+func TestLoadUnsupportedVersion(t *testing.T) {
+	splits, segments := newStorages(t)
+	_, err := Load(&File{Version: 99}, splits, segments)
+	assert.True(t, errors.Is(err, ErrUnsupportedVersion))
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	splits, segments := newStorages(t)
+	splits.Update([]dtos.SplitDTO{
+		{Name: "f1", ChangeNumber: 3, Status: "ACTIVE", Conditions: []dtos.ConditionDTO{{MatcherGroup: dtos.MatcherGroupDTO{
+			Matchers: []dtos.MatcherDTO{{MatcherType: "IN_SEGMENT", UserDefinedSegment: &dtos.UserDefinedSegmentMatcherDataDTO{SegmentName: "s1"}}},
+		}}}},
+		{Name: "f2", ChangeNumber: 4, Status: "ACTIVE"},
+	}, nil, 4)
+	segments.Update("s1", set.NewSet("k1", "k2", "k3"), set.NewSet(), 7)
+	segments.Update("s1", set.NewSet(), set.NewSet("k2"), 8)
+
+	var buf bytes.Buffer
+	assert.Nil(t, Write(&buf, splits, segments))
+
+	var decoded File
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, FormatVersion, decoded.Version)
+	assert.Equal(t, int64(4), decoded.Splits.ChangeNumber)
+	assert.Len(t, decoded.Splits.Splits, 2)
+	assert.Equal(t, []Segment{{Name: "s1", ChangeNumber: 8, Keys: []string{"k1", "k3"}}}, decoded.Segments)
+
+	// the exported file can be used to seed a fresh instance
+	newSplits, newSegments := newStorages(t)
+	stats, err := Load(&decoded, newSplits, newSegments)
+	assert.Nil(t, err)
+	assert.Equal(t, &LoadStats{Splits: 2, Segments: 1}, stats)
+}
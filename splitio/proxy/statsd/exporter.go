@@ -0,0 +1,242 @@
+package statsd
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/splitio/go-toolkit/v5/asynctask"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/persistent"
+)
+
+// datagrams are kept under the usual MTU so that they're not fragmented
+const maxPacketSize = 1432
+
+// TelemetrySource provides the proxy endpoint metrics. Implemented by the timesliced proxy endpoint telemetry
+type TelemetrySource interface {
+	TotalMetricsReport() map[string]storage.ForResource
+	PeekDegradedResponses() int64
+	PeekListenerDrops() int64
+}
+
+// DBMetricsSource provides the persistent storage metrics. Implemented by the boltdb wrapper
+type DBMetricsSource interface {
+	Metrics() map[string]persistent.OperationMetrics
+}
+
+// Options for the statsd exporter
+type Options struct {
+	Logger logging.LoggerInterface
+
+	// host:port of the statsd agent
+	Address string
+
+	// Prepended to every metric name
+	Prefix string
+
+	// DogStatsD tags (ie: "env:prod") added to every metric
+	Tags []string
+
+	// How often to push metrics. Must be a whole number of seconds
+	Period time.Duration
+
+	// Proxy endpoint metrics
+	Telemetry TelemetrySource
+
+	// Persistent storage metrics (not pushed if nil)
+	DBMetrics DBMetricsSource
+}
+
+// Exporter periodically pushes the increments of the proxy counters since the previous push, along with the latency
+// percentiles of the requests & storage operations in that period, to a statsd agent (using DogStatsD tags)
+type Exporter struct {
+	logger        logging.LoggerInterface
+	conn          net.Conn
+	prefix        string
+	tags          []string
+	telemetry     TelemetrySource
+	dbMetrics     DBMetricsSource
+	lastCounters  map[string]int64
+	lastLatencies map[string][]int64
+	task          *asynctask.AsyncTask
+}
+
+// NewExporter constructs a new statsd exporter
+func NewExporter(options *Options) (*Exporter, error) {
+	// metrics are pushed by an async task, which can only run every N seconds
+	if options.Period < time.Second || options.Period%time.Second != 0 {
+		return nil, fmt.Errorf("invalid statsd push period %s: must be a whole number of seconds", options.Period)
+	}
+
+	conn, err := net.Dial("udp", options.Address)
+	if err != nil {
+		return nil, fmt.Errorf("error setting up statsd connection to '%s': %w", options.Address, err)
+	}
+
+	prefix := options.Prefix
+	if prefix != "" && !strings.HasSuffix(prefix, ".") {
+		prefix += "."
+	}
+
+	tags := make([]string, 0, len(options.Tags))
+	for _, tag := range options.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	exporter := &Exporter{
+		logger:        options.Logger,
+		conn:          conn,
+		prefix:        prefix,
+		tags:          tags,
+		telemetry:     options.Telemetry,
+		dbMetrics:     options.DBMetrics,
+		lastCounters:  make(map[string]int64),
+		lastLatencies: make(map[string][]int64),
+	}
+	exporter.task = asynctask.NewAsyncTask("statsd-exporter", func(logging.LoggerInterface) error {
+		if err := exporter.export(); err != nil {
+			exporter.logger.Warning("error pushing metrics to statsd: ", err)
+		}
+		return nil
+	}, int(options.Period/time.Second), nil, func(logging.LoggerInterface) { exporter.conn.Close() }, options.Logger)
+	return exporter, nil
+}
+
+// Start begins pushing metrics periodically
+func (e *Exporter) Start() {
+	e.task.Start()
+}
+
+// Stop stops pushing metrics & closes the connection
+func (e *Exporter) Stop() {
+	e.task.Stop(false)
+}
+
+func (e *Exporter) export() error {
+	var lines []string
+	totals := e.telemetry.TotalMetricsReport()
+	resources := make([]string, 0, len(totals))
+	for resource := range totals {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	for _, resource := range resources {
+		resourceTag := "resource:" + resource
+		codes := make([]int, 0, len(totals[resource].StatusCodes))
+		for code := range totals[resource].StatusCodes {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			lines = e.appendCounter(lines, "endpoint.responses", totals[resource].StatusCodes[code], resourceTag, "code:"+strconv.Itoa(code))
+		}
+		lines = e.appendPercentiles(lines, "endpoint.latency", totals[resource].Latencies, resourceTag)
+	}
+	lines = e.appendCounter(lines, "endpoint.degraded_responses", e.telemetry.PeekDegradedResponses())
+	lines = e.appendCounter(lines, "listener.drops", e.telemetry.PeekListenerDrops())
+
+	if e.dbMetrics != nil {
+		metrics := e.dbMetrics.Metrics()
+		operations := make([]string, 0, len(metrics))
+		for operation := range metrics {
+			operations = append(operations, operation)
+		}
+		sort.Strings(operations)
+
+		for _, operation := range operations {
+			operationTag := "operation:" + operation
+			lines = e.appendCounter(lines, "db.operations", metrics[operation].Count, operationTag)
+			lines = e.appendCounter(lines, "db.errors", metrics[operation].Errors, operationTag)
+			lines = e.appendPercentiles(lines, "db.latency", metrics[operation].Latencies, operationTag)
+		}
+	}
+
+	return e.send(lines)
+}
+
+// appendCounter adds a counter with the increment since the previous push (or the whole value if it's been reset).
+// Counters that didn't change are skipped
+func (e *Exporter) appendCounter(lines []string, name string, total int64, tags ...string) []string {
+	key := name + "|" + strings.Join(tags, ",")
+	delta := total - e.lastCounters[key]
+	if delta < 0 { // stats were reset
+		delta = total
+	}
+	e.lastCounters[key] = total
+	if delta == 0 {
+		return lines
+	}
+	return append(lines, e.line(name, strconv.FormatInt(delta, 10), "c", tags))
+}
+
+// appendPercentiles adds p50/p95/p99 gauges estimated from the latencies recorded since the previous push
+func (e *Exporter) appendPercentiles(lines []string, name string, latencies []int64, tags ...string) []string {
+	key := name + "|" + strings.Join(tags, ",")
+	last := e.lastLatencies[key]
+	e.lastLatencies[key] = append([]int64(nil), latencies...)
+
+	delta := make([]int64, len(latencies))
+	var count int64
+	for idx := range latencies {
+		delta[idx] = latencies[idx]
+		if idx < len(last) && last[idx] <= latencies[idx] {
+			delta[idx] -= last[idx]
+		}
+		count += delta[idx]
+	}
+	if count == 0 {
+		return lines
+	}
+
+	percentiles := storage.NewLatencyPercentiles(delta)
+	for _, p := range []struct {
+		suffix string
+		value  float64
+	}{{"p50", percentiles.P50}, {"p95", percentiles.P95}, {"p99", percentiles.P99}} {
+		lines = append(lines, e.line(name+"."+p.suffix, strconv.FormatFloat(p.value, 'f', 2, 64), "g", tags))
+	}
+	return lines
+}
+
+func (e *Exporter) line(name string, value string, metricType string, tags []string) string {
+	line := e.prefix + name + ":" + value + "|" + metricType
+	if all := append(append([]string(nil), e.tags...), tags...); len(all) > 0 {
+		line += "|#" + strings.Join(all, ",")
+	}
+	return line
+}
+
+// send writes the lines in as few datagrams as possible
+func (e *Exporter) send(lines []string) error {
+	var packet strings.Builder
+	flush := func() error {
+		if packet.Len() == 0 {
+			return nil
+		}
+		_, err := e.conn.Write([]byte(packet.String()))
+		packet.Reset()
+		return err
+	}
+
+	for _, line := range lines {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > maxPacketSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	return flush()
+}
@@ -0,0 +1,141 @@
+package statsd
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage"
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/persistent"
+)
+
+type telemetryMock struct {
+	totals   map[string]storage.ForResource
+	degraded int64
+	drops    int64
+}
+
+func (t *telemetryMock) TotalMetricsReport() map[string]storage.ForResource { return t.totals }
+func (t *telemetryMock) PeekDegradedResponses() int64                       { return t.degraded }
+func (t *telemetryMock) PeekListenerDrops() int64                           { return t.drops }
+
+type dbMetricsMock struct {
+	metrics map[string]persistent.OperationMetrics
+}
+
+func (d *dbMetricsMock) Metrics() map[string]persistent.OperationMetrics { return d.metrics }
+
+func latencies(bucket int, count int64) []int64 {
+	l := make([]int64, 23)
+	l[bucket] = count
+	return l
+}
+
+func receive(t *testing.T, conn net.PacketConn) []string {
+	t.Helper()
+	buffer := make([]byte, 65536)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	var lines []string
+	for {
+		n, _, err := conn.ReadFrom(buffer)
+		if err != nil {
+			return lines
+		}
+		assert.LessOrEqual(t, n, maxPacketSize)
+		lines = append(lines, strings.Split(string(buffer[:n]), "\n")...)
+		conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	}
+}
+
+func TestExporter(t *testing.T) {
+	agent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer agent.Close()
+
+	telemetry := &telemetryMock{
+		totals: map[string]storage.ForResource{
+			"splitChanges": {StatusCodes: map[int]int64{200: 10, 500: 1}, Latencies: latencies(3, 11)},
+		},
+		degraded: 2,
+		drops:    3,
+	}
+	db := &dbMetricsMock{metrics: map[string]persistent.OperationMetrics{
+		persistent.OperationRead: {Count: 5, Errors: 0, Latencies: latencies(0, 5)},
+	}}
+
+	exporter, err := NewExporter(&Options{
+		Logger:    logging.NewLogger(nil),
+		Address:   agent.LocalAddr().String(),
+		Prefix:    "split.proxy",
+		Tags:      []string{"env:test", ""},
+		Period:    time.Second,
+		Telemetry: telemetry,
+		DBMetrics: db,
+	})
+	assert.Nil(t, err)
+	defer exporter.conn.Close()
+
+	assert.Nil(t, exporter.export())
+	assert.Equal(t, []string{
+		"split.proxy.endpoint.responses:10|c|#env:test,resource:splitChanges,code:200",
+		"split.proxy.endpoint.responses:1|c|#env:test,resource:splitChanges,code:500",
+		"split.proxy.endpoint.latency.p50:2.87|g|#env:test,resource:splitChanges",
+		"split.proxy.endpoint.latency.p95:3.38|g|#env:test,resource:splitChanges",
+		"split.proxy.endpoint.latency.p99:3.38|g|#env:test,resource:splitChanges",
+		"split.proxy.endpoint.degraded_responses:2|c|#env:test",
+		"split.proxy.listener.drops:3|c|#env:test",
+		"split.proxy.db.operations:5|c|#env:test,operation:read",
+		"split.proxy.db.latency.p50:0.60|g|#env:test,operation:read",
+		"split.proxy.db.latency.p95:1.00|g|#env:test,operation:read",
+		"split.proxy.db.latency.p99:1.00|g|#env:test,operation:read",
+	}, receive(t, agent))
+
+	// only increments since the previous push are sent, and percentiles are computed on the new latencies only
+	telemetry.totals = map[string]storage.ForResource{
+		"splitChanges": {StatusCodes: map[int]int64{200: 13, 500: 1}, Latencies: func() []int64 { l := latencies(3, 11); l[0] = 3; return l }()},
+	}
+	assert.Nil(t, exporter.export())
+	assert.Equal(t, []string{
+		"split.proxy.endpoint.responses:3|c|#env:test,resource:splitChanges,code:200",
+		"split.proxy.endpoint.latency.p50:0.67|g|#env:test,resource:splitChanges",
+		"split.proxy.endpoint.latency.p95:1.00|g|#env:test,resource:splitChanges",
+		"split.proxy.endpoint.latency.p99:1.00|g|#env:test,resource:splitChanges",
+	}, receive(t, agent))
+
+	// after a stats reset the whole (new) values are sent
+	telemetry.totals = map[string]storage.ForResource{"splitChanges": {StatusCodes: map[int]int64{200: 1}, Latencies: latencies(3, 1)}}
+	telemetry.degraded = 0
+	telemetry.drops = 0
+	assert.Nil(t, exporter.export())
+	lines := receive(t, agent)
+	assert.Contains(t, lines, "split.proxy.endpoint.responses:1|c|#env:test,resource:splitChanges,code:200")
+	assert.Contains(t, lines, "split.proxy.endpoint.latency.p50:3.38|g|#env:test,resource:splitChanges")
+}
+
+func TestExporterSplitsPackets(t *testing.T) {
+	agent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer agent.Close()
+
+	exporter, err := NewExporter(&Options{Logger: logging.NewLogger(nil), Address: agent.LocalAddr().String(), Period: time.Second, Telemetry: &telemetryMock{}})
+	assert.Nil(t, err)
+	defer exporter.conn.Close()
+
+	lines := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		lines = append(lines, "some.metric.with.a.long.enough.name:1|c|#tag:value")
+	}
+	assert.Nil(t, exporter.send(lines))
+	assert.Equal(t, lines, receive(t, agent))
+}
+
+func TestExporterRejectsSubSecondPeriods(t *testing.T) {
+	for _, period := range []time.Duration{0, 500 * time.Millisecond, 1500 * time.Millisecond} {
+		_, err := NewExporter(&Options{Logger: logging.NewLogger(nil), Address: "127.0.0.1:8125", Period: period, Telemetry: &telemetryMock{}})
+		assert.NotNil(t, err, period)
+	}
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-split-commons/v6/engine/grammar/matchers"
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+const unsupportedMatcherWarningPeriod = time.Hour
+
+// DefaultKnownMatchers is the list of matcher types supported by the commons version this proxy is built with
+var DefaultKnownMatchers = []string{
+	matchers.MatcherTypeAllKeys,
+	matchers.MatcherTypeInSegment,
+	matchers.MatcherTypeWhitelist,
+	matchers.MatcherTypeEqualTo,
+	matchers.MatcherTypeGreaterThanOrEqualTo,
+	matchers.MatcherTypeLessThanOrEqualTo,
+	matchers.MatcherTypeBetween,
+	matchers.MatcherTypeEqualToSet,
+	matchers.MatcherTypePartOfSet,
+	matchers.MatcherTypeContainsAllOfSet,
+	matchers.MatcherTypeContainsAnyOfSet,
+	matchers.MatcherTypeStartsWith,
+	matchers.MatcherTypeEndsWith,
+	matchers.MatcherTypeContainsString,
+	matchers.MatcherTypeInSplitTreatment,
+	matchers.MatcherTypeEqualToBoolean,
+	matchers.MatcherTypeMatchesString,
+	matchers.MatcherEqualToSemver,
+	matchers.MatcherTypeGreaterThanOrEqualToSemver,
+	matchers.MatcherTypeLessThanOrEqualToSemver,
+	matchers.MatcherTypeBetweenSemver,
+	matchers.MatcherTypeInListSemver,
+}
+
+// UnsupportedMatcherWarner inspects incoming feature flags and logs a warning when a matcher type
+// outside of the known set is found. Warnings are emitted at most once per matcher type per period
+type UnsupportedMatcherWarner struct {
+	logger   logging.LoggerInterface
+	known    map[string]struct{}
+	lastSeen map[string]time.Time
+	mtx      sync.Mutex
+}
+
+// NewUnsupportedMatcherWarner constructs a warner. If `known` is empty, DefaultKnownMatchers is used
+func NewUnsupportedMatcherWarner(logger logging.LoggerInterface, known []string) *UnsupportedMatcherWarner {
+	toRet := &UnsupportedMatcherWarner{
+		logger:   logger,
+		known:    make(map[string]struct{}, len(DefaultKnownMatchers)),
+		lastSeen: make(map[string]time.Time),
+	}
+
+	for _, matcherType := range known {
+		if matcherType = strings.TrimSpace(matcherType); matcherType != "" {
+			toRet.known[matcherType] = struct{}{}
+		}
+	}
+
+	if len(toRet.known) == 0 {
+		for _, matcherType := range DefaultKnownMatchers {
+			toRet.known[matcherType] = struct{}{}
+		}
+	}
+	return toRet
+}
+
+// Check scans the supplied feature flags and warns about unsupported matchers
+func (w *UnsupportedMatcherWarner) Check(splits []dtos.SplitDTO) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	now := time.Now()
+	for _, split := range splits {
+		for _, condition := range split.Conditions {
+			for _, matcher := range condition.MatcherGroup.Matchers {
+				if _, ok := w.known[matcher.MatcherType]; ok {
+					continue
+				}
+
+				if last, ok := w.lastSeen[matcher.MatcherType]; ok && now.Sub(last) < unsupportedMatcherWarningPeriod {
+					continue
+				}
+
+				w.lastSeen[matcher.MatcherType] = now
+				w.logger.Warning(fmt.Sprintf(
+					"feature flag '%s' uses matcher type '%s' which is not supported by this version. "+
+						"SDKs relying on this proxy may not evaluate it as expected",
+					split.Name, matcher.MatcherType,
+				))
+			}
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-toolkit/v5/logging/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func splitWithMatchers(name string, matcherTypes ...string) dtos.SplitDTO {
+	matchers := make([]dtos.MatcherDTO, 0, len(matcherTypes))
+	for _, mt := range matcherTypes {
+		matchers = append(matchers, dtos.MatcherDTO{MatcherType: mt})
+	}
+	return dtos.SplitDTO{Name: name, Conditions: []dtos.ConditionDTO{{MatcherGroup: dtos.MatcherGroupDTO{Matchers: matchers}}}}
+}
+
+func TestUnsupportedMatcherWarner(t *testing.T) {
+	warnings := 0
+	logger := &mocks.MockLogger{WarningCall: func(msg ...interface{}) { warnings++ }}
+
+	warner := NewUnsupportedMatcherWarner(logger, []string{""}) // empty list, as parsed from the default config
+	warner.Check([]dtos.SplitDTO{splitWithMatchers("f1", "ALL_KEYS", "IN_SEGMENT")})
+	assert.Equal(t, 0, warnings)
+
+	warner.Check([]dtos.SplitDTO{splitWithMatchers("f2", "SOME_NEW_MATCHER"), splitWithMatchers("f3", "SOME_NEW_MATCHER")})
+	assert.Equal(t, 1, warnings) // deduped by matcher type
+
+	warner.Check([]dtos.SplitDTO{splitWithMatchers("f4", "ANOTHER_NEW_MATCHER")})
+	assert.Equal(t, 2, warnings)
+
+	warner.Check([]dtos.SplitDTO{splitWithMatchers("f2", "SOME_NEW_MATCHER")})
+	assert.Equal(t, 2, warnings) // still within the warning period
+}
+
+func TestUnsupportedMatcherWarnerCustomKnownSet(t *testing.T) {
+	warnings := 0
+	logger := &mocks.MockLogger{WarningCall: func(msg ...interface{}) { warnings++ }}
+
+	warner := NewUnsupportedMatcherWarner(logger, []string{"ALL_KEYS"})
+	warner.Check([]dtos.SplitDTO{splitWithMatchers("f1", "ALL_KEYS", "IN_SEGMENT")})
+	assert.Equal(t, 1, warnings)
+}
@@ -12,16 +12,36 @@ import (
 type HistoricChanges interface {
 	GetUpdatedSince(since int64, flagSets []string) []FeatureView
 	Update(toAdd []dtos.SplitDTO, toRemove []dtos.SplitDTO, newCN int64)
+	Covers(since int64) bool
+	Stats() HistoricChangesStats
+}
+
+// HistoricChangesStats contains the number of retained recipes & how many have been evicted so far
+type HistoricChangesStats struct {
+	Size    int
+	Evicted int64
 }
 
 type HistoricChangesImpl struct {
-	data  []FeatureView
-	mutex sync.RWMutex
+	data          []FeatureView
+	maxSize       int
+	evicted       int64
+	evictedUpTo   int64               // most recent change number of an evicted recipe
+	evictedActive map[string]struct{} // active features whose recipe was evicted, needed to build payloads from scratch
+	mutex         sync.RWMutex
 }
 
-func NewHistoricSplitChanges(capacity int) *HistoricChangesImpl {
+// NewHistoricSplitChanges constructs a new historic changes structure. If `maxSize` is greater than 0, only the
+// most recent `maxSize` recipes are retained, and the oldest ones are evicted
+func NewHistoricSplitChanges(capacity int, maxSize int) *HistoricChangesImpl {
+	if maxSize > 0 && capacity > maxSize {
+		capacity = maxSize
+	}
 	return &HistoricChangesImpl{
-		data: make([]FeatureView, 0, capacity),
+		data:          make([]FeatureView, 0, capacity),
+		maxSize:       maxSize,
+		evictedUpTo:   -1,
+		evictedActive: make(map[string]struct{}),
 	}
 }
 
@@ -39,13 +59,54 @@ func (h *HistoricChangesImpl) Update(toAdd []dtos.SplitDTO, toRemove []dtos.Spli
 	h.updateFrom(toAdd)
 	h.updateFrom(toRemove)
 	sort.Slice(h.data, func(i, j int) bool { return h.data[i].LastUpdated < h.data[j].LastUpdated })
+	h.evict()
 	h.mutex.Unlock()
 }
 
+// Covers returns whether the retained recipes are enough to build a payload starting at `since`
+func (h *HistoricChangesImpl) Covers(since int64) bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	if since == -1 {
+		return len(h.evictedActive) == 0
+	}
+	return h.evicted == 0 || since >= h.evictedUpTo
+}
+
+// Stats returns the number of retained recipes & how many have been evicted
+func (h *HistoricChangesImpl) Stats() HistoricChangesStats {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return HistoricChangesStats{Size: len(h.data), Evicted: h.evicted}
+}
+
 // public interface ends here
 
+// evict drops the oldest recipes beyond the configured max size. Must be called with the lock held & data sorted
+func (h *HistoricChangesImpl) evict() {
+	if h.maxSize <= 0 || len(h.data) <= h.maxSize {
+		return
+	}
+
+	toEvict := len(h.data) - h.maxSize
+	for idx := range h.data[:toEvict] {
+		if h.data[idx].LastUpdated > h.evictedUpTo {
+			h.evictedUpTo = h.data[idx].LastUpdated
+		}
+		if h.data[idx].Active {
+			if h.evictedActive == nil {
+				h.evictedActive = make(map[string]struct{})
+			}
+			h.evictedActive[h.data[idx].Name] = struct{}{}
+		}
+	}
+	h.data = append(h.data[:0], h.data[toEvict:]...)
+	h.evicted += int64(toEvict)
+}
+
 func (h *HistoricChangesImpl) updateFrom(source []dtos.SplitDTO) {
 	for idx := range source {
+		delete(h.evictedActive, source[idx].Name) // a new recipe is available, no longer missing
 		if current := h.findByName(source[idx].Name); current != nil {
 			current.updateFrom(&source[idx])
 		} else {
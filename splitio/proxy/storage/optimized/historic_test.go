@@ -342,3 +342,25 @@ func BenchmarkFlagSetProcessing(b *testing.B) {
 		}
 	})
 }
+
+func TestHistoricSplitStorageEviction(t *testing.T) {
+	historic := NewHistoricSplitChanges(10, 2)
+	assert.True(t, historic.Covers(-1))
+	assert.True(t, historic.Covers(0))
+
+	historic.Update([]dtos.SplitDTO{{Name: "f1", Status: "ACTIVE", ChangeNumber: 1}}, nil, 1)
+	historic.Update(nil, []dtos.SplitDTO{{Name: "f2", Status: "ARCHIVED", ChangeNumber: 2}}, 2)
+	historic.Update([]dtos.SplitDTO{{Name: "f3", Status: "ACTIVE", ChangeNumber: 3}}, nil, 3)
+	assert.Equal(t, HistoricChangesStats{Size: 2, Evicted: 1}, historic.Stats())
+	assert.False(t, historic.Covers(-1))
+	assert.False(t, historic.Covers(0))
+	assert.True(t, historic.Covers(1))
+
+	// f1 is updated again, so it's available again for building payloads from scratch
+	historic.Update([]dtos.SplitDTO{{Name: "f1", Status: "ACTIVE", ChangeNumber: 4}}, nil, 4)
+	assert.Equal(t, HistoricChangesStats{Size: 2, Evicted: 2}, historic.Stats())
+	assert.True(t, historic.Covers(-1))
+	assert.False(t, historic.Covers(1))
+	assert.True(t, historic.Covers(2)) // payloads only include recipes updated after `since`
+	assert.Equal(t, []string{"f3", "f1"}, []string{historic.GetUpdatedSince(-1, nil)[0].Name, historic.GetUpdatedSince(-1, nil)[1].Name})
+}
@@ -20,4 +20,14 @@ func (h *HistoricStorageMock) Update(toAdd []dtos.SplitDTO, toRemove []dtos.Spli
 	h.Called(toAdd, toRemove, newCN)
 }
 
+// Covers implements optimized.HistoricChanges
+func (h *HistoricStorageMock) Covers(since int64) bool {
+	return h.Called(since).Bool(0)
+}
+
+// Stats implements optimized.HistoricChanges
+func (h *HistoricStorageMock) Stats() optimized.HistoricChangesStats {
+	return h.Called().Get(0).(optimized.HistoricChangesStats)
+}
+
 var _ optimized.HistoricChanges = (*HistoricStorageMock)(nil)
@@ -0,0 +1,101 @@
+package optimized
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+)
+
+// PayloadCacheStats contains the number of hits & misses of a payload cache
+type PayloadCacheStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+	Size   int   `json:"size"`
+}
+
+// PayloadCache is a size-bounded LRU cache of computed payloads, meant to be cleared every time the data used
+// to build them changes. Each clear starts a new generation, and values computed during a previous one are discarded,
+// so that payloads built concurrently with an update never make it into the cache
+type PayloadCache struct {
+	maxSize    int
+	items      map[string]*list.Element
+	lru        *list.List
+	generation uint64
+	hits       int64
+	misses     int64
+	mutex      sync.Mutex
+}
+
+type payloadEntry struct {
+	key   string
+	value interface{}
+}
+
+// NewPayloadCache constructs a new payload cache. A size lower than 1 disables it
+func NewPayloadCache(maxSize int) *PayloadCache {
+	return &PayloadCache{
+		maxSize: maxSize,
+		items:   make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Get returns the payload cached for a key (if any), along with the current generation, to be used when setting it
+func (c *PayloadCache) Get(key string) (interface{}, uint64, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	node, ok := c.items[key]
+	if !ok {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, c.generation, false
+	}
+
+	atomic.AddInt64(&c.hits, 1)
+	c.lru.MoveToFront(node)
+	return node.Value.(payloadEntry).value, c.generation, true
+}
+
+// Set caches a payload, unless the cache has been cleared since the supplied generation was obtained
+func (c *PayloadCache) Set(key string, value interface{}, generation uint64) {
+	if c.maxSize < 1 {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	if node, ok := c.items[key]; ok {
+		node.Value = payloadEntry{key: key, value: value}
+		c.lru.MoveToFront(node)
+		return
+	}
+
+	if c.lru.Len() >= c.maxSize {
+		oldest := c.lru.Back()
+		delete(c.items, oldest.Value.(payloadEntry).key)
+		c.lru.Remove(oldest)
+	}
+	c.items[key] = c.lru.PushFront(payloadEntry{key: key, value: value})
+}
+
+// Clear drops every cached payload & starts a new generation
+func (c *PayloadCache) Clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.generation++
+	c.items = make(map[string]*list.Element)
+	c.lru.Init()
+}
+
+// Stats returns the number of hits, misses & items currently cached
+func (c *PayloadCache) Stats() PayloadCacheStats {
+	c.mutex.Lock()
+	size := c.lru.Len()
+	c.mutex.Unlock()
+	return PayloadCacheStats{Hits: atomic.LoadInt64(&c.hits), Misses: atomic.LoadInt64(&c.misses), Size: size}
+}
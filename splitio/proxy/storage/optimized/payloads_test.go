@@ -0,0 +1,47 @@
+package optimized
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPayloadCache(t *testing.T) {
+	cache := NewPayloadCache(2)
+
+	_, gen, ok := cache.Get("a")
+	assert.False(t, ok)
+	cache.Set("a", 1, gen)
+	cache.Set("b", 2, gen)
+
+	value, _, ok := cache.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	// "b" is the least recently used one
+	cache.Set("c", 3, gen)
+	_, _, ok = cache.Get("b")
+	assert.False(t, ok)
+	_, _, ok = cache.Get("c")
+	assert.True(t, ok)
+
+	assert.Equal(t, PayloadCacheStats{Hits: 2, Misses: 2, Size: 2}, cache.Stats())
+
+	// payloads computed before a clear are discarded
+	_, staleGen, _ := cache.Get("d")
+	cache.Clear()
+	cache.Set("d", 4, staleGen)
+	_, _, ok = cache.Get("d")
+	assert.False(t, ok)
+	_, _, ok = cache.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, cache.Stats().Size)
+}
+
+func TestPayloadCacheDisabled(t *testing.T) {
+	cache := NewPayloadCache(0)
+	_, gen, _ := cache.Get("a")
+	cache.Set("a", 1, gen)
+	_, _, ok := cache.Get("a")
+	assert.False(t, ok)
+}
@@ -0,0 +1,107 @@
+package optimized
+
+import (
+	"sort"
+	"sync"
+)
+
+// KeyChange represents the latest update of a key in a segment
+type KeyChange struct {
+	Name         string
+	ChangeNumber int64
+	Removed      bool
+}
+
+// SegmentChangesSummaries keeps, for each segment, a log of key updates sorted by change number,
+// so that incremental segmentChanges payloads can be built without scanning every key in the segment
+type SegmentChangesSummaries struct {
+	segments map[string]*segmentChangeLog
+	mutex    sync.RWMutex
+}
+
+type segmentChangeLog struct {
+	latest  map[string]int64 // latest change number of each key, used to tell apart stale log entries
+	changes []KeyChange      // sorted by change number
+	stale   int
+}
+
+// NewSegmentChangesSummaries constructs a new (empty) segment changes summaries structure
+func NewSegmentChangesSummaries() *SegmentChangesSummaries {
+	return &SegmentChangesSummaries{segments: make(map[string]*segmentChangeLog)}
+}
+
+// Update records the keys added & removed from a segment in a specific change number
+func (s *SegmentChangesSummaries) Update(name string, added []string, removed []string, cn int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	log, ok := s.segments[name]
+	if !ok {
+		log = &segmentChangeLog{latest: make(map[string]int64, len(added))}
+		s.segments[name] = log
+	}
+
+	needsSorting := len(log.changes) > 0 && log.changes[len(log.changes)-1].ChangeNumber > cn
+	log.record(removed, cn, true)
+	log.record(added, cn, false)
+	if needsSorting { // updates are expected in ascending order, this should only happen when populating from disk
+		sort.SliceStable(log.changes, func(i, j int) bool { return log.changes[i].ChangeNumber < log.changes[j].ChangeNumber })
+	}
+
+	if log.stale > len(log.changes)/2 {
+		log.compact()
+	}
+}
+
+// Remove stops tracking a segment
+func (s *SegmentChangesSummaries) Remove(name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.segments, name)
+}
+
+// ChangesSince returns the latest update of every key in the segment changed after `since`, sorted by change number.
+// The second return value is false if the segment is not tracked
+func (s *SegmentChangesSummaries) ChangesSince(name string, since int64) ([]KeyChange, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	log, ok := s.segments[name]
+	if !ok {
+		return nil, false
+	}
+
+	start := sort.Search(len(log.changes), func(i int) bool { return log.changes[i].ChangeNumber > since })
+	toRet := make([]KeyChange, 0, len(log.changes)-start)
+	for _, change := range log.changes[start:] {
+		if log.latest[change.Name] == change.ChangeNumber {
+			toRet = append(toRet, change)
+		}
+	}
+	return toRet, true
+}
+
+func (l *segmentChangeLog) record(keys []string, cn int64, removed bool) {
+	for _, key := range keys {
+		if current, ok := l.latest[key]; ok {
+			if current > cn { // we already have a more recent update for this key
+				continue
+			}
+			l.stale++
+		}
+		l.latest[key] = cn
+		l.changes = append(l.changes, KeyChange{Name: key, ChangeNumber: cn, Removed: removed})
+	}
+}
+
+// compact drops the log entries that have been superseded by a more recent update of the same key
+func (l *segmentChangeLog) compact() {
+	compacted := make([]KeyChange, 0, len(l.latest))
+	for _, change := range l.changes {
+		if l.latest[change.Name] == change.ChangeNumber {
+			compacted = append(compacted, change)
+		}
+	}
+	l.changes = compacted
+	l.stale = 0
+}
@@ -0,0 +1,43 @@
+package optimized
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegmentChangesSummaries(t *testing.T) {
+	summaries := NewSegmentChangesSummaries()
+
+	_, ok := summaries.ChangesSince("some", -1)
+	assert.False(t, ok)
+
+	summaries.Update("some", []string{"k1", "k2", "k3"}, nil, 1)
+	summaries.Update("some", []string{"k4"}, []string{"k2"}, 2)
+	summaries.Update("some", []string{"k2"}, []string{"k3"}, 3)
+
+	changes, ok := summaries.ChangesSince("some", -1)
+	assert.True(t, ok)
+	assert.Equal(t, []KeyChange{
+		{Name: "k1", ChangeNumber: 1},
+		{Name: "k4", ChangeNumber: 2},
+		{Name: "k3", ChangeNumber: 3, Removed: true},
+		{Name: "k2", ChangeNumber: 3},
+	}, changes)
+
+	changes, _ = summaries.ChangesSince("some", 2)
+	assert.Equal(t, []KeyChange{{Name: "k3", ChangeNumber: 3, Removed: true}, {Name: "k2", ChangeNumber: 3}}, changes)
+
+	changes, _ = summaries.ChangesSince("some", 3)
+	assert.Empty(t, changes)
+
+	// superseded entries are dropped once they make up more than half of the log
+	assert.Equal(t, 7, len(summaries.segments["some"].changes))
+	summaries.Update("some", nil, []string{"k1", "k4"}, 4)
+	assert.Equal(t, 4, len(summaries.segments["some"].changes))
+
+	// out of order updates (ie: when populating from disk) don't override more recent ones
+	summaries.Update("some", []string{"k1"}, nil, 2)
+	changes, _ = summaries.ChangesSince("some", 3)
+	assert.Equal(t, []KeyChange{{Name: "k1", ChangeNumber: 4, Removed: true}, {Name: "k4", ChangeNumber: 4, Removed: true}}, changes)
+}
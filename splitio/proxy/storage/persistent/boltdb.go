@@ -15,6 +15,9 @@ import (
 	bolt "go.etcd.io/bbolt" // new fork maintained by etcd
 )
 
+// max size of the transactions used when copying data into a compacted db
+const compactionTxMaxSize = 64 * 1024 * 1024
+
 // BoltInMemoryMode used to store ramdom db into temporal folder
 const BoltInMemoryMode = ":memory:"
 const inMemoryDBName = "splitio_"
@@ -37,17 +40,95 @@ type DBWrapper interface {
 // BoltDBWrapper is a boltdb-based implmentation of a persistent storage wrapper
 type BoltDBWrapper struct {
 	wrapped *bolt.DB
+	options *bolt.Options
 	mutex   sync.Mutex
+	dbMutex sync.RWMutex // guards the `wrapped` reference, which is replaced when compacting
+	metrics dbMetrics
 }
 
 // Update executes a RW function within a transaction
 func (b *BoltDBWrapper) Update(f func(tx *bolt.Tx) error) error {
-	return b.wrapped.Update(f)
+	b.dbMutex.RLock()
+	defer b.dbMutex.RUnlock()
+	start := time.Now()
+	err := b.wrapped.Update(f)
+	b.metrics.write.track(start, err)
+	return err
 }
 
 // View executes a RO function wihtin a transaction
 func (b *BoltDBWrapper) View(f func(tx *bolt.Tx) error) error {
-	return b.wrapped.View(f)
+	b.dbMutex.RLock()
+	defer b.dbMutex.RUnlock()
+	start := time.Now()
+	err := b.wrapped.View(f)
+	b.metrics.read.track(start, err)
+	return err
+}
+
+// Metrics returns the amount of executions, errors & latencies of the read, write & compaction operations on the db
+func (b *BoltDBWrapper) Metrics() map[string]OperationMetrics {
+	return b.metrics.peek()
+}
+
+// Compact rewrites the db into a fresh file, dropping the free pages left behind by deleted items,
+// and returns the file size before & after compacting it
+func (b *BoltDBWrapper) Compact() (int64, int64, error) {
+	start := time.Now()
+	before, after, err := b.compact()
+	b.metrics.compaction.track(start, err)
+	return before, after, err
+}
+
+func (b *BoltDBWrapper) compact() (int64, int64, error) {
+	b.dbMutex.Lock()
+	defer b.dbMutex.Unlock()
+
+	path := b.wrapped.Path()
+	sizeBefore, err := fileSize(path)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	tmpPath := path + ".compact"
+	dst, err := bolt.Open(tmpPath, 0644, b.options)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error opening temporary db for compaction: %w", err)
+	}
+
+	if err = bolt.Compact(dst, b.wrapped, compactionTxMaxSize); err != nil {
+		dst.Close()
+		os.Remove(tmpPath)
+		return 0, 0, fmt.Errorf("error compacting db: %w", err)
+	}
+
+	if err = dst.Close(); err != nil {
+		os.Remove(tmpPath)
+		return 0, 0, fmt.Errorf("error closing compacted db: %w", err)
+	}
+
+	if err = b.wrapped.Close(); err != nil {
+		os.Remove(tmpPath)
+		return 0, 0, fmt.Errorf("error closing db for compaction: %w", err)
+	}
+
+	// from this point on, the original db is closed. Whatever happens, we need to reopen a db in the original path
+	renameErr := os.Rename(tmpPath, path)
+	b.wrapped, err = bolt.Open(path, 0644, b.options)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error reopening db after compaction: %w", err)
+	}
+
+	if renameErr != nil {
+		os.Remove(tmpPath)
+		return 0, 0, fmt.Errorf("error replacing db with compacted one: %w", renameErr)
+	}
+
+	sizeAfter, err := fileSize(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	return sizeBefore, sizeAfter, nil
 }
 
 // Lock grants exclusive access to the referenced db
@@ -60,6 +141,13 @@ func (b *BoltDBWrapper) Unlock() {
 	b.mutex.Unlock()
 }
 
+// Close releases the underlying db file
+func (b *BoltDBWrapper) Close() error {
+	b.dbMutex.Lock()
+	defer b.dbMutex.Unlock()
+	return b.wrapped.Close()
+}
+
 // GetRawSnapshot dumps all the contents of the db into a raw byte buffer
 func (b *BoltDBWrapper) GetRawSnapshot() ([]byte, error) {
 	var buffer bytes.Buffer
@@ -325,10 +413,18 @@ func NewBoltWrapper(path string, options *bolt.Options) (*BoltDBWrapper, error)
 	}
 
 	var err error
-	wrapper := &BoltDBWrapper{}
+	wrapper := &BoltDBWrapper{options: options}
 	wrapper.wrapped, err = bolt.Open(dbpath, 0644, options)
 	if err != nil {
 		return nil, fmt.Errorf("error opening db: %w", err)
 	}
 	return wrapper, nil
 }
+
+func fileSize(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, fmt.Errorf("error reading db file size: %w", err)
+	}
+	return info.Size(), nil
+}
@@ -0,0 +1,108 @@
+package persistent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/splitio/go-toolkit/v5/datastructures/set"
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestBoltDBCompaction(t *testing.T) {
+	dbw, err := NewBoltWrapper(BoltInMemoryMode, nil)
+	if err != nil {
+		t.Fatal("error creating bolt wrapper: ", err)
+	}
+	defer os.Remove(dbw.wrapped.Path())
+
+	segmentC := NewSegmentChangesCollection(dbw, logging.NewLogger(nil))
+	keys := set.NewSet()
+	for i := 0; i < 10000; i++ {
+		keys.Add(fmt.Sprintf("key_%d", i))
+	}
+	segmentC.Update("s1", keys, set.NewSet(), 1)
+	segmentC.Update("s2", set.NewSet("k1"), set.NewSet(), 1)
+	segmentC.Update("s1", set.NewSet(), keys, 2)
+
+	before, after, err := dbw.Compact()
+	if err != nil {
+		t.Fatal("compaction should not fail: ", err)
+	}
+
+	if after >= before {
+		t.Error("file should have shrunk after compacting. before/after: ", before, after)
+	}
+
+	// the db should still be usable & have the same data
+	forS2, err := segmentC.Fetch("s2")
+	if err != nil {
+		t.Fatal("err should be nil: ", err)
+	}
+
+	if _, ok := forS2.Keys["k1"]; !ok {
+		t.Error("k1 should still be present after compaction")
+	}
+
+	segmentC.Update("s3", set.NewSet("k2"), set.NewSet(), 3)
+	if _, err := segmentC.Fetch("s3"); err != nil {
+		t.Error("writes should work after compaction: ", err)
+	}
+}
+
+func TestBoltDBFileInUse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proxy.db")
+	first, err := NewBoltWrapper(path, &bolt.Options{Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal("error creating bolt wrapper: ", err)
+	}
+	defer first.wrapped.Close()
+
+	if _, err := NewBoltWrapper(path, &bolt.Options{Timeout: 100 * time.Millisecond}); err == nil {
+		t.Error("opening a file held by another instance should fail")
+	}
+}
+
+func TestBoltDBMetrics(t *testing.T) {
+	dbw, err := NewBoltWrapper(BoltInMemoryMode, nil)
+	if err != nil {
+		t.Fatal("error creating bolt wrapper: ", err)
+	}
+	defer os.Remove(dbw.wrapped.Path())
+
+	segmentC := NewSegmentChangesCollection(dbw, logging.NewLogger(nil))
+	segmentC.Update("s1", set.NewSet("k1"), set.NewSet(), 1)
+	if _, err := segmentC.Fetch("s1"); err != nil {
+		t.Error("err should be nil: ", err)
+	}
+	if _, err := segmentC.Fetch("nonexistant"); err == nil {
+		t.Error("fetching a missing segment should fail")
+	}
+	dbw.Update(func(tx *bolt.Tx) error { return errors.New("something") })
+	dbw.Compact()
+
+	metrics := dbw.Metrics()
+	count := func(latencies []int64) (total int64) {
+		for _, c := range latencies {
+			total += c
+		}
+		return total
+	}
+
+	if read := metrics[OperationRead]; read.Count < 2 || read.Errors != 0 || count(read.Latencies) != read.Count {
+		t.Error("reads without errors (missing items don't count) should have been tracked. Got: ", read)
+	}
+
+	if write := metrics[OperationWrite]; write.Count < 2 || write.Errors != 1 || count(write.Latencies) != write.Count {
+		t.Error("writes & the failed one should have been tracked. Got: ", write)
+	}
+
+	if compaction := metrics[OperationCompaction]; compaction.Count != 1 || compaction.Errors != 0 {
+		t.Error("1 compaction should have been tracked. Got: ", compaction)
+	}
+}
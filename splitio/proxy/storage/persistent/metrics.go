@@ -0,0 +1,61 @@
+package persistent
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/splitio/go-split-commons/v6/telemetry"
+)
+
+// Operations on the db whose latency & errors are tracked
+const (
+	OperationRead       = "read"
+	OperationWrite      = "write"
+	OperationCompaction = "compaction"
+)
+
+// OperationMetrics holds the amount of executions, failures & latency buckets (as used by the commons telemetry package)
+// of an operation on the db
+type OperationMetrics struct {
+	Count     int64   `json:"count"`
+	Errors    int64   `json:"errors"`
+	Latencies []int64 `json:"latencies"`
+}
+
+type operationCounters struct {
+	count     int64
+	errors    int64
+	latencies [telemetry.LatencyBucketCount]int64
+}
+
+// track records an execution started at `start`. Lookups of missing items are not considered errors
+func (o *operationCounters) track(start time.Time, err error) {
+	atomic.AddInt64(&o.count, 1)
+	atomic.AddInt64(&o.latencies[telemetry.Bucket(time.Since(start).Milliseconds())], 1)
+	if err != nil && !errors.Is(err, ErrorKeyNotFound) && !errors.Is(err, ErrorBucketNotFound) {
+		atomic.AddInt64(&o.errors, 1)
+	}
+}
+
+func (o *operationCounters) peek() OperationMetrics {
+	latencies := make([]int64, len(o.latencies))
+	for idx := range o.latencies {
+		latencies[idx] = atomic.LoadInt64(&o.latencies[idx])
+	}
+	return OperationMetrics{Count: atomic.LoadInt64(&o.count), Errors: atomic.LoadInt64(&o.errors), Latencies: latencies}
+}
+
+type dbMetrics struct {
+	read       operationCounters
+	write      operationCounters
+	compaction operationCounters
+}
+
+func (m *dbMetrics) peek() map[string]OperationMetrics {
+	return map[string]OperationMetrics{
+		OperationRead:       m.read.peek(),
+		OperationWrite:      m.write.peek(),
+		OperationCompaction: m.compaction.peek(),
+	}
+}
@@ -26,3 +26,11 @@ func (s *SegmentChangesCollectionMock) ChangeNumber(segment string) int64 {
 func (s *SegmentChangesCollectionMock) SetChangeNumber(segment string, cn int64) {
 	s.Called(segment, cn)
 }
+
+func (s *SegmentChangesCollectionMock) Names() []string {
+	return s.Called().Get(0).([]string)
+}
+
+func (s *SegmentChangesCollectionMock) Remove(name string) error {
+	return s.Called(name).Error(0)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/splitio/go-toolkit/v5/logging"
 )
 
+// fixed for the same reasons as the feature flags bucket name
 const segmentChangesCollectionName = "SEGMENT_CHANGES_COLLECTION"
 
 // SegmentKey represents a segment key data
@@ -21,8 +22,9 @@ type SegmentKey struct {
 
 // SegmentChangesItem represents an SplitChanges service response
 type SegmentChangesItem struct {
-	Name string
-	Keys map[string]SegmentKey
+	Name         string
+	ChangeNumber int64 // change number of the latest update (0 in items persisted by older versions)
+	Keys         map[string]SegmentKey
 }
 
 type SegmentChangesCollection interface {
@@ -30,6 +32,8 @@ type SegmentChangesCollection interface {
 	Fetch(name string) (*SegmentChangesItem, error)
 	ChangeNumber(segment string) int64
 	SetChangeNumber(segment string, cn int64)
+	Names() []string
+	Remove(name string) error
 }
 
 // SegmentChangesCollectionImpl represents a collection of SplitChangesItem
@@ -92,6 +96,10 @@ func (c *SegmentChangesCollectionImpl) Update(name string, toAdd *set.ThreadUnsa
 		}
 	}
 
+	if cn > segmentItem.ChangeNumber {
+		segmentItem.ChangeNumber = cn
+	}
+
 	err := c.collection.SaveAs([]byte(name), segmentItem)
 	if err != nil {
 		return fmt.Errorf("error saving segment changes to bolt: %w", err)
@@ -173,4 +181,26 @@ func (c *SegmentChangesCollectionImpl) SetChangeNumber(segment string, cn int64)
 	c.segmentsTill[segment] = cn
 }
 
+// Names returns the names of the segments with a known change number
+func (c *SegmentChangesCollectionImpl) Names() []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	names := make([]string, 0, len(c.segmentsTill))
+	for name := range c.segmentsTill {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Remove deletes a segment & its change number
+func (c *SegmentChangesCollectionImpl) Remove(name string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err := c.collection.Delete([]byte(name)); err != nil {
+		return fmt.Errorf("error deleting segment from bolt: %w", err)
+	}
+	delete(c.segmentsTill, name)
+	return nil
+}
+
 var _ SegmentChangesCollection = (*SegmentChangesCollectionImpl)(nil)
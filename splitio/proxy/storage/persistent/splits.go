@@ -10,6 +10,8 @@ import (
 	"github.com/splitio/go-toolkit/v5/logging"
 )
 
+// bucket names are fixed on purpose: files are restored on startup & read by replicas (possibly running a different
+// config), so all of them must agree on where data lives. Instances sharing a host are isolated by using different files
 const splitChangesCollectionName = "SPLIT_CHANGES_COLLECTION"
 
 // SplitChangesItem represents an SplitChanges service response
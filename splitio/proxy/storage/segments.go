@@ -3,6 +3,7 @@ package storage
 import (
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/splitio/go-split-commons/v6/dtos"
 	"github.com/splitio/go-split-commons/v6/storage"
@@ -31,6 +32,9 @@ type ProxySegmentStorageImpl struct {
 	nameCountCache *observability.ActiveSegmentTracker
 	db             persistent.SegmentChangesCollection
 	mysegments     optimized.MySegmentsCache
+	summaries      *optimized.SegmentChangesSummaries
+
+	maxKeysPerResponse int
 }
 
 // NewProxySegmentStorage for proxy
@@ -38,62 +42,87 @@ func NewProxySegmentStorage(db persistent.DBWrapper, logger logging.LoggerInterf
 	cache := optimized.NewMySegmentsCache()
 	disk := persistent.NewSegmentChangesCollection(db, logger)
 	nameCountCache := observability.NewActiveSegmentTracker(100) // just a guess, we don't know the size yet
+	summaries := optimized.NewSegmentChangesSummaries()
 	if restoreFromBackup {
-		populateCachesFromDisk(cache, nameCountCache, disk, logger)
+		populateCachesFromDisk(cache, nameCountCache, summaries, disk, logger)
 	}
 	return &ProxySegmentStorageImpl{
 		db:             disk,
 		mysegments:     cache,
+		summaries:      summaries,
 		logger:         logger,
 		nameCountCache: nameCountCache,
 	}
 }
 
-// ChangesSince returns the `segmentChanges` like payload to from a certain CN to the last snapshot
-// This method has one drawback. ALL the historically removed keys are always returned as part of the `removed` array,
-// regardless whether the `since` parameter is old enough to require such removal or not.
-// We should eventually see if it's worth taking an approach similar to the one in feature flags or not
+// ChangesSince returns the `segmentChanges` like payload to from a certain CN to the last snapshot.
+// Only the keys updated after `since` are returned. They're looked up in the in-memory summaries,
+// falling back to a full scan of the persisted segment if it's not tracked there.
+// When a max number of keys per response is set, larger payloads are split into pages, with `till` set to the
+// last change number included, so that SDKs keep iterating until `since == till`
 func (s *ProxySegmentStorageImpl) ChangesSince(name string, since int64) (*dtos.SegmentChangesDTO, error) {
-	item, err := s.db.Fetch(name)
-	if err != nil {
-		if errors.Is(err, persistent.ErrorBucketNotFound) || errors.Is(err, persistent.ErrorKeyNotFound) {
-			return nil, ErrSegmentNotFound
+	changes, ok := s.changesFromSummaries(name, since)
+	if !ok {
+		item, err := s.db.Fetch(name)
+		if err != nil {
+			if errors.Is(err, persistent.ErrorBucketNotFound) || errors.Is(err, persistent.ErrorKeyNotFound) {
+				return nil, ErrSegmentNotFound
+			}
+			return nil, fmt.Errorf("unexpected error when fetching segment '%s': %w", name, err)
+		}
+
+		changes = make([]optimized.KeyChange, 0, len(item.Keys))
+		for _, skey := range item.Keys {
+			if skey.ChangeNumber > since { // if the key was updated in a previous/current CN, we don't need to return it
+				changes = append(changes, optimized.KeyChange{Name: skey.Name, ChangeNumber: skey.ChangeNumber, Removed: skey.Removed})
+			}
+		}
+
+		if s.maxKeysPerResponse > 0 { // summaries are already sorted by change number, persisted keys are not
+			sort.SliceStable(changes, func(i, j int) bool { return changes[i].ChangeNumber < changes[j].ChangeNumber })
 		}
-		return nil, fmt.Errorf("unexpected error when fetching segment '%s': %w", name, err)
 	}
 
-	added := make([]string, 0)
+	added := make([]string, 0, len(changes))
 	removed := make([]string, 0)
 	till := since
-
-	// Horrible loop borrowed from sdk-api
-	for _, skey := range item.Keys {
-
-		if skey.ChangeNumber <= since { // if the key was updated in a previous/current CN, we don't need to return it
+	for _, change := range changes {
+		if change.Removed && since < 0 { // removed keys should not be returned on initialization payloads
 			continue
 		}
 
-		if skey.Removed && since < 0 {
-			// removed keys should not be returned on initialization payloads
-			continue
+		// keys sharing a change number cannot be split across pages, since the next one will start after it
+		if s.maxKeysPerResponse > 0 && len(added)+len(removed) >= s.maxKeysPerResponse && change.ChangeNumber > till {
+			break
 		}
 
-		// Add the key to the corresponding list
-		if skey.Removed {
-			removed = append(removed, skey.Name)
+		if change.Removed {
+			removed = append(removed, change.Name)
 		} else {
-			added = append(added, skey.Name)
+			added = append(added, change.Name)
 		}
 
-		// Update the till to be returned if necessary
-		if skey.ChangeNumber > till {
-			till = skey.ChangeNumber
+		if change.ChangeNumber > till {
+			till = change.ChangeNumber
 		}
 	}
 
 	return &dtos.SegmentChangesDTO{Name: name, Since: since, Till: till, Added: added, Removed: removed}, nil
 }
 
+// SetMaxKeysPerResponse sets the max number of keys to include in a single ChangesSince payload (0 = unlimited).
+// Keys updated in the same change number are always returned together, so pages may exceed this number
+func (s *ProxySegmentStorageImpl) SetMaxKeysPerResponse(max int) {
+	s.maxKeysPerResponse = max
+}
+
+func (s *ProxySegmentStorageImpl) changesFromSummaries(name string, since int64) ([]optimized.KeyChange, bool) {
+	if s.summaries == nil {
+		return nil, false
+	}
+	return s.summaries.ChangesSince(name, since)
+}
+
 // SegmentsFor returns the list of segments a key belongs to
 func (s *ProxySegmentStorageImpl) SegmentsFor(key string) ([]string, error) {
 	return s.mysegments.SegmentsForUser(key), nil
@@ -143,12 +172,15 @@ func (s *ProxySegmentStorageImpl) SegmentContainsKey(segmentName string, key str
 func (s *ProxySegmentStorageImpl) Update(name string, toAdd *set.ThreadUnsafeSet, toRemove *set.ThreadUnsafeSet, changeNumber int64) error {
 	errCache := s.mysegments.Update(name, toAdd, toRemove)
 	errDB := s.db.Update(name, toAdd, toRemove, changeNumber)
+	if s.summaries != nil {
+		s.summaries.Update(name, toStringSlice(toAdd), toStringSlice(toRemove), changeNumber)
+	}
 	if errCache == nil && errDB == nil {
 		s.nameCountCache.Update(name, toAdd.Size(), toRemove.Size())
 		return nil
 	}
 
-	return fmt.Errorf("errors updating cache: %s || errors updating db: %s", errCache.Error(), errDB.Error())
+	return fmt.Errorf("errors updating cache: %v || errors updating db: %v", errCache, errDB)
 }
 
 // CountRemovedKeys method
@@ -173,6 +205,35 @@ func (s *ProxySegmentStorageImpl) CountRemovedKeys(segmentName string) int {
 	return removedKeys
 }
 
+// KnownSegments returns the names of all the segments in the storage
+func (s *ProxySegmentStorageImpl) KnownSegments() []string {
+	return s.db.Names()
+}
+
+// Remove drops a segment from memory & disk. SDKs that still have its keys will get a 404 if they ask for changes
+func (s *ProxySegmentStorageImpl) Remove(name string) error {
+	item, err := s.db.Fetch(name)
+	if err != nil && !errors.Is(err, persistent.ErrorBucketNotFound) && !errors.Is(err, persistent.ErrorKeyNotFound) {
+		return fmt.Errorf("error fetching segment '%s' for removal: %w", name, err)
+	}
+
+	if item != nil {
+		active := set.NewSet()
+		for _, key := range item.Keys {
+			if !key.Removed {
+				active.Add(key.Name)
+			}
+		}
+		s.mysegments.Update(name, set.NewSet(), active)
+	}
+
+	if s.summaries != nil {
+		s.summaries.Remove(name)
+	}
+	s.nameCountCache.Remove(name)
+	return s.db.Remove(name)
+}
+
 // NamesAndCount returns a map of segment names to key count
 func (s *ProxySegmentStorageImpl) NamesAndCount() map[string]int {
 	return s.nameCountCache.NamesAndCount()
@@ -181,6 +242,7 @@ func (s *ProxySegmentStorageImpl) NamesAndCount() map[string]int {
 func populateCachesFromDisk(
 	dst optimized.MySegmentsCache,
 	names *observability.ActiveSegmentTracker,
+	summaries *optimized.SegmentChangesSummaries,
 	src *persistent.SegmentChangesCollectionImpl,
 	logger logging.LoggerInterface,
 ) {
@@ -193,15 +255,56 @@ func populateCachesFromDisk(
 	for idx := range all {
 		s := set.NewSet()
 		count := 0
+		keys := make([]persistent.SegmentKey, 0, len(all[idx].Keys))
 		for _, k := range all[idx].Keys {
 			if !k.Removed {
 				s.Add(k.Name)
 				count++
 			}
+			keys = append(keys, k)
 		}
 		dst.Update(all[idx].Name, s, set.NewSet())
 		names.Update(all[idx].Name, count, 0)
+		populateSummaries(summaries, all[idx].Name, keys)
+
+		// restore the change number so that synchronization resumes from where it left off
+		till := all[idx].ChangeNumber
+		if len(keys) > 0 && keys[len(keys)-1].ChangeNumber > till {
+			till = keys[len(keys)-1].ChangeNumber
+		}
+		if till > 0 {
+			src.SetChangeNumber(all[idx].Name, till)
+		}
+	}
+}
+
+// populateSummaries feeds the keys of a persisted segment into the summaries, grouped by change number in ascending order.
+// The keys slice is sorted in place
+func populateSummaries(summaries *optimized.SegmentChangesSummaries, name string, keys []persistent.SegmentKey) {
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ChangeNumber < keys[j].ChangeNumber })
+	for start := 0; start < len(keys); {
+		end := start
+		var added, removed []string
+		for ; end < len(keys) && keys[end].ChangeNumber == keys[start].ChangeNumber; end++ {
+			if keys[end].Removed {
+				removed = append(removed, keys[end].Name)
+			} else {
+				added = append(added, keys[end].Name)
+			}
+		}
+		summaries.Update(name, added, removed, keys[start].ChangeNumber)
+		start = end
+	}
+}
+
+func toStringSlice(keys *set.ThreadUnsafeSet) []string {
+	toRet := make([]string, 0, keys.Size())
+	for _, key := range keys.List() {
+		if asStr, ok := key.(string); ok {
+			toRet = append(toRet, asStr)
+		}
 	}
+	return toRet
 }
 
 var _ storage.SegmentStorage = (*ProxySegmentStorageImpl)(nil)
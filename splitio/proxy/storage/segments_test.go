@@ -3,6 +3,7 @@ package storage
 import (
 	"testing"
 
+	"github.com/splitio/go-toolkit/v5/datastructures/set"
 	"github.com/splitio/go-toolkit/v5/logging"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/optimized"
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/persistent"
@@ -73,3 +74,117 @@ func TestSegmentStorage(t *testing.T) {
 	assert.Equal(t, int64(4), changes.Till)
 
 }
+
+func TestSegmentStorageRestoredFromDisk(t *testing.T) {
+	dbw, err := persistent.NewBoltWrapper(persistent.BoltInMemoryMode, nil)
+	assert.Nil(t, err)
+
+	logger := logging.NewLogger(nil)
+	original := NewProxySegmentStorage(dbw, logger, false)
+	assert.Nil(t, original.Update("some", set.NewSet("k1", "k2", "k3"), set.NewSet(), 1))
+	assert.Nil(t, original.Update("some", set.NewSet("k4"), set.NewSet("k2"), 2))
+	assert.Nil(t, original.Update("some", set.NewSet(), set.NewSet("k3"), 3))
+
+	restored := NewProxySegmentStorage(dbw, logger, true)
+	cn, _ := restored.ChangeNumber("some")
+	assert.Equal(t, int64(3), cn)
+
+	for _, since := range []int64{-1, 1, 2, 3} {
+		fromSummaries, err := restored.ChangesSince("some", since)
+		assert.Nil(t, err)
+
+		// a storage without summaries scans the persisted segment, and should yield the same result
+		fromDisk, err := (&ProxySegmentStorageImpl{logger: logger, db: restored.db}).ChangesSince("some", since)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, fromDisk.Added, fromSummaries.Added)
+		assert.ElementsMatch(t, fromDisk.Removed, fromSummaries.Removed)
+		assert.Equal(t, fromDisk.Till, fromSummaries.Till)
+	}
+
+	changes, _ := restored.ChangesSince("some", 1)
+	assert.ElementsMatch(t, []string{"k4"}, changes.Added)
+	assert.ElementsMatch(t, []string{"k2", "k3"}, changes.Removed)
+	assert.Equal(t, int64(3), changes.Till)
+}
+
+func TestSegmentStoragePagination(t *testing.T) {
+	psm := &mocks.SegmentChangesCollectionMock{}
+	psm.On("Fetch", "some").Return(&persistent.SegmentChangesItem{
+		Name: "some",
+		Keys: map[string]persistent.SegmentKey{
+			"k1": {Name: "k1", ChangeNumber: 1, Removed: false},
+			"k2": {Name: "k2", ChangeNumber: 1, Removed: false},
+			"k3": {Name: "k3", ChangeNumber: 1, Removed: false},
+			"k4": {Name: "k4", ChangeNumber: 2, Removed: true},
+			"k5": {Name: "k5", ChangeNumber: 3, Removed: false},
+			"k6": {Name: "k6", ChangeNumber: 4, Removed: false},
+		},
+	}, nil)
+
+	summaries := optimized.NewSegmentChangesSummaries()
+	summaries.Update("tracked", []string{"k1", "k2", "k3"}, nil, 1)
+	summaries.Update("tracked", nil, []string{"k4"}, 2)
+	summaries.Update("tracked", []string{"k5"}, nil, 3)
+	summaries.Update("tracked", []string{"k6"}, nil, 4)
+
+	ss := ProxySegmentStorageImpl{
+		logger:     logging.NewLogger(nil),
+		db:         psm,
+		mysegments: optimized.NewMySegmentsCache(),
+	}
+	ss.SetMaxKeysPerResponse(2)
+
+	for _, name := range []string{"some", "tracked"} {
+		if name == "tracked" {
+			ss.summaries = summaries
+		}
+
+		// keys sharing a change number are never split across pages
+		changes, err := ss.ChangesSince(name, -1)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []string{"k1", "k2", "k3"}, changes.Added)
+		assert.Equal(t, int64(1), changes.Till)
+
+		changes, err = ss.ChangesSince(name, changes.Till)
+		assert.Nil(t, err)
+		assert.Len(t, changes.Added, 1)
+		assert.Len(t, changes.Removed, 1)
+		assert.Equal(t, int64(3), changes.Till)
+
+		changes, err = ss.ChangesSince(name, changes.Till)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"k6"}, changes.Added)
+		assert.Equal(t, int64(4), changes.Till)
+
+		changes, err = ss.ChangesSince(name, changes.Till)
+		assert.Nil(t, err)
+		assert.Empty(t, changes.Added)
+		assert.Equal(t, int64(4), changes.Till) // since == till, sdks stop iterating
+	}
+}
+
+func TestSegmentStorageRemove(t *testing.T) {
+	dbw, err := persistent.NewBoltWrapper(persistent.BoltInMemoryMode, nil)
+	assert.Nil(t, err)
+
+	ss := NewProxySegmentStorage(dbw, logging.NewLogger(nil), false)
+	assert.Nil(t, ss.Update("s1", set.NewSet("k1", "k2"), set.NewSet(), 1))
+	assert.Nil(t, ss.Update("s2", set.NewSet("k1"), set.NewSet(), 1))
+	assert.ElementsMatch(t, []string{"s1", "s2"}, ss.KnownSegments())
+
+	assert.Nil(t, ss.Remove("s1"))
+	assert.ElementsMatch(t, []string{"s2"}, ss.KnownSegments())
+	forK1, _ := ss.SegmentsFor("k1")
+	assert.Equal(t, []string{"s2"}, forK1)
+	forK2, _ := ss.SegmentsFor("k2")
+	assert.Empty(t, forK2)
+	assert.NotContains(t, ss.NamesAndCount(), "s1")
+
+	cn, _ := ss.ChangeNumber("s1")
+	assert.Equal(t, int64(-1), cn)
+	_, err = ss.ChangesSince("s1", -1)
+	assert.ErrorIs(t, err, ErrSegmentNotFound)
+
+	// removing a segment that doesn't exist is not an error
+	assert.Nil(t, ss.Remove("nonexistant"))
+}
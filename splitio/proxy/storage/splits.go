@@ -1,9 +1,13 @@
 package storage
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/splitio/go-split-commons/v6/dtos"
 	"github.com/splitio/go-split-commons/v6/flagsets"
@@ -30,41 +34,171 @@ type ProxySplitStorage interface {
 	ChangesSince(since int64, flagSets []string) (*dtos.SplitChangesDTO, error)
 }
 
+// SerializedChanges is a splitChanges payload already serialized to JSON
+type SerializedChanges struct {
+	Till int64
+	Body []byte
+}
+
+// SerializedSplitChangesProvider is implemented by storages that can serve pre-serialized splitChanges payloads.
+// Since callers may need to adjust the payload before serializing it (ie: depending on the spec version), each
+// variant is built with the supplied `prepare` function & cached separately
+type SerializedSplitChangesProvider interface {
+	SerializedChangesSince(since int64, flagSets []string, variant string, prepare func(*dtos.SplitChangesDTO)) (*SerializedChanges, error)
+}
+
+// PayloadCacheReporter is implemented by storages that memoize computed payloads
+type PayloadCacheReporter interface {
+	PayloadCacheStats() optimized.PayloadCacheStats
+}
+
+// ChangesSummaryStats contains the number of retained change-number recipes, how many have been evicted, and
+// how many times a payload couldn't be built from them (and had to be fetched from upstream)
+type ChangesSummaryStats struct {
+	Recipes   int
+	Evicted   int64
+	Fallbacks int64
+}
+
+// ChangesSummaryReporter is implemented by storages that keep recipes to serve arbitrary `since` values
+type ChangesSummaryReporter interface {
+	ChangesSummaryStats() ChangesSummaryStats
+}
+
+// splitChangesPersister stores feature flag changes so that they can be restored on startup
+type splitChangesPersister interface {
+	Update(toAdd []dtos.SplitDTO, toRemove []dtos.SplitDTO, changeNumber int64)
+}
+
+// noopSplitChangesPersister discards feature flag changes, for proxies running without persistent storage
+type noopSplitChangesPersister struct{}
+
+func (noopSplitChangesPersister) Update([]dtos.SplitDTO, []dtos.SplitDTO, int64) {}
+
 // ProxySplitStorageImpl implements the ProxySplitStorage interface and the SplitProducer interface
 type ProxySplitStorageImpl struct {
 	snapshot      mutexmap.MMSplitStorage
-	db            *persistent.SplitChangesCollection
+	db            splitChangesPersister
 	flagSets      flagsets.FlagSetFilter
 	historic      optimized.HistoricChanges
 	logger        logging.LoggerInterface
 	oldestKnownCN int64
+	matcherWarner *UnsupportedMatcherWarner
+	payloads      *optimized.PayloadCache
+	fallbacks     int64
+	pendingKills  map[string]int64 // flags killed locally, by the change number of the kill, until a fetch covers it
 	mtx           sync.Mutex
 }
 
 // NewProxySplitStorage instantiates a new proxy storage that wraps an in-memory snapshot of the last known,
 // flag configuration, a changes summaries containing recipes to update SDKs with different CNs, and a persistent storage
-// for snapshot purposes
-func NewProxySplitStorage(db persistent.DBWrapper, logger logging.LoggerInterface, flagSets flagsets.FlagSetFilter, restoreBackup bool) *ProxySplitStorageImpl {
-	disk := persistent.NewSplitChangesCollection(db, logger)
+// for snapshot purposes. If a matcher warner is supplied, incoming feature flags are checked for unsupported matchers.
+// Up to `payloadCacheSize` computed splitChanges payloads are memoized until the next update.
+// If `maxRecipesRetained` is greater than 0, the oldest recipes beyond it are evicted.
+// If `db` is nil, feature flags are kept in memory only & `restoreBackup` is ignored.
+func NewProxySplitStorage(
+	db persistent.DBWrapper,
+	logger logging.LoggerInterface,
+	flagSets flagsets.FlagSetFilter,
+	restoreBackup bool,
+	matcherWarner *UnsupportedMatcherWarner,
+	payloadCacheSize int,
+	maxRecipesRetained int,
+) *ProxySplitStorageImpl {
 	snapshot := mutexmap.NewMMSplitStorage(flagSets)
-	historic := optimized.NewHistoricSplitChanges(1000)
+	historic := optimized.NewHistoricSplitChanges(maxRecipes, maxRecipesRetained)
 
 	var initialCN int64 = -1
-	if restoreBackup {
-		initialCN = snapshotFromDisk(snapshot, historic, disk, logger)
+	var persister splitChangesPersister = noopSplitChangesPersister{}
+	if db != nil {
+		disk := persistent.NewSplitChangesCollection(db, logger)
+		if restoreBackup {
+			initialCN = snapshotFromDisk(snapshot, historic, disk, logger)
+		}
+		persister = disk
 	}
+
 	return &ProxySplitStorageImpl{
 		snapshot:      *snapshot,
-		db:            disk,
+		db:            persister,
 		flagSets:      flagSets,
 		historic:      historic,
 		logger:        logger,
 		oldestKnownCN: initialCN,
+		matcherWarner: matcherWarner,
+		payloads:      optimized.NewPayloadCache(payloadCacheSize),
+		pendingKills:  make(map[string]int64),
 	}
 }
 
-// ChangesSince builds a SplitChanges payload to from `since` to the latest known CN
+// ChangesSince builds a SplitChanges payload to from `since` to the latest known CN.
+// Payloads are memoized until the next update. The returned one can be modified without affecting the cached copy
 func (p *ProxySplitStorageImpl) ChangesSince(since int64, flagSets []string) (*dtos.SplitChangesDTO, error) {
+	key := fmt.Sprintf("%d|%s", since, strings.Join(flagSets, ","))
+	cached, generation, ok := p.payloads.Get(key)
+	if ok {
+		return copyChanges(cached.(*dtos.SplitChangesDTO)), nil
+	}
+
+	changes, err := p.buildChangesSince(since, flagSets)
+	if err != nil {
+		return nil, err
+	}
+
+	p.payloads.Set(key, changes, generation)
+	return copyChanges(changes), nil
+}
+
+// SerializedChangesSince returns the JSON serialization of a splitChanges payload, prepared for a specific variant.
+// Serialized payloads are memoized until the next update
+func (p *ProxySplitStorageImpl) SerializedChangesSince(
+	since int64,
+	flagSets []string,
+	variant string,
+	prepare func(*dtos.SplitChangesDTO),
+) (*SerializedChanges, error) {
+	key := fmt.Sprintf("%d|%s|%s", since, strings.Join(flagSets, ","), variant)
+	cached, generation, ok := p.payloads.Get(key)
+	if ok {
+		return cached.(*SerializedChanges), nil
+	}
+
+	changes, err := p.ChangesSince(since, flagSets) // returns a copy that can be safely prepared
+	if err != nil {
+		return nil, err
+	}
+
+	if prepare != nil {
+		prepare(changes)
+	}
+
+	body, err := json.Marshal(changes)
+	if err != nil {
+		return nil, fmt.Errorf("error serializing splitChanges payload: %w", err)
+	}
+
+	serialized := &SerializedChanges{Till: changes.Till, Body: body}
+	p.payloads.Set(key, serialized, generation)
+	return serialized, nil
+}
+
+// ChangesSummaryStats returns the number of retained & evicted recipes, and how many times they were not enough
+// to build a requested payload
+func (p *ProxySplitStorageImpl) ChangesSummaryStats() ChangesSummaryStats {
+	stats := p.historic.Stats()
+	return ChangesSummaryStats{
+		Recipes:   stats.Size,
+		Evicted:   stats.Evicted,
+		Fallbacks: atomic.LoadInt64(&p.fallbacks),
+	}
+}
+
+// PayloadCacheStats returns the hits & misses of the splitChanges payloads cache
+func (p *ProxySplitStorageImpl) PayloadCacheStats() optimized.PayloadCacheStats {
+	return p.payloads.Stats()
+}
+
+func (p *ProxySplitStorageImpl) buildChangesSince(since int64, flagSets []string) (*dtos.SplitChangesDTO, error) {
 
 	// No flagsets and fetching from -1, return the current snapshot
 	if since == -1 && len(flagSets) == 0 {
@@ -76,7 +210,8 @@ func (p *ProxySplitStorageImpl) ChangesSince(since int64, flagSets []string) (*d
 		return &dtos.SplitChangesDTO{Since: since, Till: cn, Splits: all}, nil
 	}
 
-	if p.sinceIsTooOld(since) {
+	if p.sinceIsTooOld(since) || !p.historic.Covers(since) {
+		atomic.AddInt64(&p.fallbacks, 1)
 		return nil, ErrSinceParamTooOld
 	}
 
@@ -95,6 +230,14 @@ func (p *ProxySplitStorageImpl) ChangesSince(since int64, flagSets []string) (*d
 		}
 	}
 
+	// flags killed locally are served as well, but don't move the till, so that the changes in between
+	// are still delivered once the fetch that covers the kill completes
+	for _, name := range p.pendingKillsSince(since, flagSets) {
+		if !slices.Contains(namesToFetch, name) {
+			namesToFetch = append(namesToFetch, name)
+		}
+	}
+
 	for name, split := range p.snapshot.FetchMany(namesToFetch) {
 		if split == nil {
 			p.logger.Warning(fmt.Sprintf(
@@ -109,24 +252,38 @@ func (p *ProxySplitStorageImpl) ChangesSince(since int64, flagSets []string) (*d
 	return &dtos.SplitChangesDTO{Since: since, Till: till, Splits: all}, nil
 }
 
-// KillLocally marks a feature flag as killed in the current storage
+// KillLocally marks a feature flag as killed in the current storage. Until the upstream fetch that covers the kill
+// completes, the killed flag is included in every `since`-based payload older than the kill, without advancing the till
+// (nor the recipes), so that SDKs still get the changes between their change number & the kill's once it's fetched
 func (p *ProxySplitStorageImpl) KillLocally(splitName string, defaultTreatment string, changeNumber int64) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
 	p.snapshot.KillLocally(splitName, defaultTreatment, changeNumber)
+	if killed := p.snapshot.Split(splitName); killed != nil && killed.Killed && killed.ChangeNumber == changeNumber {
+		p.pendingKills[splitName] = changeNumber
+	}
+	p.payloads.Clear()
 }
 
 // Update the storage atomically
 func (p *ProxySplitStorageImpl) Update(toAdd []dtos.SplitDTO, toRemove []dtos.SplitDTO, changeNumber int64) {
 
 	p.setStartingPoint(changeNumber) // will be executed only the first time this method is called
+	p.dropPendingKillsUpTo(changeNumber)
 
 	if len(toAdd) == 0 && len(toRemove) == 0 {
 		return
 	}
 
+	if p.matcherWarner != nil {
+		p.matcherWarner.Check(toAdd)
+	}
+
 	p.mtx.Lock()
 	p.snapshot.Update(toAdd, toRemove, changeNumber)
 	p.historic.Update(toAdd, toRemove, changeNumber)
 	p.db.Update(toAdd, toRemove, changeNumber)
+	p.payloads.Clear()
 	p.mtx.Unlock()
 }
 
@@ -137,12 +294,15 @@ func (p *ProxySplitStorageImpl) ChangeNumber() (int64, error) {
 
 // SetChangeNumber updates the change number
 func (p *ProxySplitStorageImpl) SetChangeNumber(cn int64) error {
+	defer p.payloads.Clear()
+	p.dropPendingKillsUpTo(cn)
 	return p.snapshot.SetChangeNumber(cn)
 }
 
 // Remove deletes a split by name
 func (p *ProxySplitStorageImpl) Remove(name string) {
 	p.snapshot.Remove(name)
+	p.payloads.Clear()
 }
 
 // All call is forwarded to the snapshot
@@ -192,6 +352,41 @@ func (p *ProxySplitStorageImpl) setStartingPoint(cn int64) {
 	p.mtx.Unlock()
 }
 
+// pendingKillsSince returns the names of the flags killed locally after `since` & not fetched yet, filtered by flag sets
+func (p *ProxySplitStorageImpl) pendingKillsSince(since int64, flagSets []string) []string {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if len(p.pendingKills) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(p.pendingKills))
+	for name, cn := range p.pendingKills {
+		if cn <= since {
+			continue
+		}
+		if len(flagSets) > 0 {
+			split := p.snapshot.Split(name)
+			if split == nil || !slices.ContainsFunc(split.Sets, func(s string) bool { return slices.Contains(flagSets, s) }) {
+				continue
+			}
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// dropPendingKillsUpTo forgets the local kills covered by a fetch that reached `cn`
+func (p *ProxySplitStorageImpl) dropPendingKillsUpTo(cn int64) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	for name, killCN := range p.pendingKills {
+		if killCN <= cn {
+			delete(p.pendingKills, name)
+		}
+	}
+}
+
 func (p *ProxySplitStorageImpl) sinceIsTooOld(since int64) bool {
 	if since == -1 {
 		return false
@@ -232,6 +427,15 @@ func snapshotFromDisk(
 	return cn
 }
 
+// copyChanges returns a copy of a payload that can be modified (ie: patching a feature flag in the list) without
+// affecting the original one
+func copyChanges(changes *dtos.SplitChangesDTO) *dtos.SplitChangesDTO {
+	toRet := *changes
+	toRet.Splits = make([]dtos.SplitDTO, len(changes.Splits))
+	copy(toRet.Splits, changes.Splits)
+	return &toRet
+}
+
 func archivedDTOForView(view *optimized.FeatureView) dtos.SplitDTO {
 	return dtos.SplitDTO{
 		ChangeNumber:          view.LastUpdated,
@@ -252,3 +456,6 @@ func archivedDTOForView(view *optimized.FeatureView) dtos.SplitDTO {
 var _ ProxySplitStorage = (*ProxySplitStorageImpl)(nil)
 var _ storage.SplitStorage = (*ProxySplitStorageImpl)(nil)
 var _ observability.ObservableSplitStorage = (*ProxySplitStorageImpl)(nil)
+var _ PayloadCacheReporter = (*ProxySplitStorageImpl)(nil)
+var _ ChangesSummaryReporter = (*ProxySplitStorageImpl)(nil)
+var _ SerializedSplitChangesProvider = (*ProxySplitStorageImpl)(nil)
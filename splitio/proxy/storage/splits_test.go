@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/storage/optimized"
@@ -12,6 +13,7 @@ import (
 	"github.com/splitio/go-toolkit/v5/logging"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestSplitStorage(t *testing.T) {
@@ -35,8 +37,9 @@ func TestSplitStorage(t *testing.T) {
 	var historicMock mocks.HistoricStorageMock
 	historicMock.On("Update", toAdd2, []dtos.SplitDTO(nil), int64(3)).Once()
 	historicMock.On("GetUpdatedSince", int64(2), []string(nil)).Once().Return([]optimized.FeatureView{})
+	historicMock.On("Covers", mock.Anything).Return(true)
 
-	pss := NewProxySplitStorage(dbw, logger, flagsets.NewFlagSetFilter(nil), true)
+	pss := NewProxySplitStorage(dbw, logger, flagsets.NewFlagSetFilter(nil), true, nil, 0, 0)
 
 	// validate initial state of the historic cache & replace it with a mock for the next validations
 	assert.ElementsMatch(t,
@@ -114,7 +117,7 @@ func TestSplitStorageWithFlagsets(t *testing.T) {
 	splitC := persistent.NewSplitChangesCollection(dbw, logger)
 	splitC.Update(nil, []dtos.SplitDTO{{Name: "f0", ChangeNumber: 0, Status: "ARCHIVED", TrafficTypeName: "ttt"}}, 0)
 
-	pss := NewProxySplitStorage(dbw, logger, flagsets.NewFlagSetFilter(nil), true)
+	pss := NewProxySplitStorage(dbw, logger, flagsets.NewFlagSetFilter(nil), true, nil, 0, 0)
 
 	pss.Update([]dtos.SplitDTO{
 		{Name: "f1", ChangeNumber: 1, Status: "ACTIVE", Sets: []string{"s1", "s2"}},
@@ -203,7 +206,7 @@ func TestGetNamesByFlagSets(t *testing.T) {
 	}
 	splitC.Update(flags, nil, 0)
 
-	pss := NewProxySplitStorage(dbw, logger, flagsets.NewFlagSetFilter(nil), true)
+	pss := NewProxySplitStorage(dbw, logger, flagsets.NewFlagSetFilter(nil), true, nil, 0, 0)
 
 	namesBySets := pss.GetNamesByFlagSets([]string{"set_1", "set2"})
 
@@ -239,7 +242,7 @@ func TestGetAllFlagSetNames(t *testing.T) {
 	}
 	splitC.Update(flags, nil, 0)
 
-	pss := NewProxySplitStorage(dbw, logger, flagsets.NewFlagSetFilter(nil), true)
+	pss := NewProxySplitStorage(dbw, logger, flagsets.NewFlagSetFilter(nil), true, nil, 0, 0)
 
 	setNames := pss.GetAllFlagSetNames()
 
@@ -247,3 +250,172 @@ func TestGetAllFlagSetNames(t *testing.T) {
 		t.Errorf("setNames len should be 4. Actual %v", len(setNames))
 	}
 }
+
+func TestSplitStoragePayloadCache(t *testing.T) {
+	dbw, err := persistent.NewBoltWrapper(persistent.BoltInMemoryMode, nil)
+	if err != nil {
+		t.Error("error creating bolt wrapper: ", err)
+	}
+
+	pss := NewProxySplitStorage(dbw, logging.NewLogger(nil), flagsets.NewFlagSetFilter(nil), false, nil, 10, 0)
+	pss.Update([]dtos.SplitDTO{{Name: "f1", ChangeNumber: 1, Status: "ACTIVE"}}, nil, 1)
+
+	first, err := pss.ChangesSince(-1, nil)
+	assert.Nil(t, err)
+	first.Splits[0].Killed = true // callers modifying the payload must not affect the cached one
+
+	second, err := pss.ChangesSince(-1, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []dtos.SplitDTO{{Name: "f1", ChangeNumber: 1, Status: "ACTIVE"}}, second.Splits)
+	assert.Equal(t, optimized.PayloadCacheStats{Hits: 1, Misses: 1, Size: 1}, pss.PayloadCacheStats())
+
+	// updates invalidate cached payloads
+	pss.Update([]dtos.SplitDTO{{Name: "f2", ChangeNumber: 2, Status: "ACTIVE"}}, nil, 2)
+	third, err := pss.ChangesSince(-1, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), third.Till)
+	assert.Len(t, third.Splits, 2)
+	assert.Equal(t, optimized.PayloadCacheStats{Hits: 1, Misses: 2, Size: 1}, pss.PayloadCacheStats())
+}
+
+func TestSplitStorageSerializedChanges(t *testing.T) {
+	dbw, err := persistent.NewBoltWrapper(persistent.BoltInMemoryMode, nil)
+	assert.Nil(t, err)
+
+	pss := NewProxySplitStorage(dbw, logging.NewLogger(nil), flagsets.NewFlagSetFilter(nil), false, nil, 10, 0)
+	pss.Update([]dtos.SplitDTO{{Name: "f1", ChangeNumber: 1, Status: "ACTIVE"}}, nil, 1)
+
+	prepared := 0
+	prepare := func(changes *dtos.SplitChangesDTO) {
+		prepared++
+		changes.Splits[0].Killed = true
+	}
+
+	first, err := pss.SerializedChangesSince(-1, nil, "1.1", prepare)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), first.Till)
+
+	var decoded dtos.SplitChangesDTO
+	assert.Nil(t, json.Unmarshal(first.Body, &decoded))
+	assert.Equal(t, int64(1), decoded.Till)
+	assert.True(t, decoded.Splits[0].Killed)
+
+	// same variant is served from the cache, other variants are built separately
+	second, err := pss.SerializedChangesSince(-1, nil, "1.1", prepare)
+	assert.Nil(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, prepared)
+
+	other, err := pss.SerializedChangesSince(-1, nil, "1.0", nil)
+	assert.Nil(t, err)
+	assert.NotEqual(t, first.Body, other.Body)
+
+	// preparing a payload must not affect the stored feature flags
+	changes, err := pss.ChangesSince(-1, nil)
+	assert.Nil(t, err)
+	assert.False(t, changes.Splits[0].Killed)
+
+	// updates invalidate serialized payloads
+	pss.Update([]dtos.SplitDTO{{Name: "f2", ChangeNumber: 2, Status: "ACTIVE"}}, nil, 2)
+	third, err := pss.SerializedChangesSince(-1, nil, "1.1", prepare)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), third.Till)
+	assert.Equal(t, 2, prepared)
+}
+
+func TestSplitStorageRecipesEviction(t *testing.T) {
+	dbw, err := persistent.NewBoltWrapper(persistent.BoltInMemoryMode, nil)
+	assert.Nil(t, err)
+
+	pss := NewProxySplitStorage(dbw, logging.NewLogger(nil), flagsets.NewFlagSetFilter(nil), false, nil, 0, 2)
+	pss.Update([]dtos.SplitDTO{{Name: "f1", ChangeNumber: 1, Status: "ACTIVE"}}, nil, 1)
+	pss.Update([]dtos.SplitDTO{{Name: "f2", ChangeNumber: 2, Status: "ACTIVE"}}, nil, 2)
+	pss.Update([]dtos.SplitDTO{{Name: "f3", ChangeNumber: 3, Status: "ACTIVE"}}, nil, 3)
+
+	// the recipe for f1 was evicted, so payloads that should include it can't be built
+	_, err = pss.ChangesSince(0, nil)
+	assert.ErrorIs(t, err, ErrSinceParamTooOld)
+	_, err = pss.ChangesSince(-1, []string{"someSet"})
+	assert.ErrorIs(t, err, ErrSinceParamTooOld)
+
+	changes, err := pss.ChangesSince(1, nil)
+	assert.Nil(t, err)
+	assert.Len(t, changes.Splits, 2)
+
+	// without flag sets, payloads from scratch are built from the snapshot
+	changes, err = pss.ChangesSince(-1, nil)
+	assert.Nil(t, err)
+	assert.Len(t, changes.Splits, 3)
+
+	assert.Equal(t, ChangesSummaryStats{Recipes: 2, Evicted: 1, Fallbacks: 2}, pss.ChangesSummaryStats())
+}
+
+func TestSplitStorageKillLocally(t *testing.T) {
+	dbw, err := persistent.NewBoltWrapper(persistent.BoltInMemoryMode, nil)
+	assert.Nil(t, err)
+
+	pss := NewProxySplitStorage(dbw, logging.NewLogger(nil), flagsets.NewFlagSetFilter(nil), false, nil, 10, 0)
+	pss.Update([]dtos.SplitDTO{{Name: "f1", ChangeNumber: 1, Status: "ACTIVE", DefaultTreatment: "on", Sets: []string{"s1"}}}, nil, 1)
+	pss.Update([]dtos.SplitDTO{{Name: "f2", ChangeNumber: 2, Status: "ACTIVE", DefaultTreatment: "on", Sets: []string{"s2"}}}, nil, 2)
+
+	// warm up the payload cache
+	changes, err := pss.ChangesSince(2, nil)
+	assert.Nil(t, err)
+	assert.Empty(t, changes.Splits)
+
+	pss.KillLocally("f1", "off", 5)
+
+	// the next poll must include the killed flag, without moving the till past the last fetched change number
+	changes, err = pss.ChangesSince(2, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), changes.Till)
+	assert.Len(t, changes.Splits, 1)
+	assert.True(t, changes.Splits[0].Killed)
+	assert.Equal(t, "off", changes.Splits[0].DefaultTreatment)
+
+	changes, err = pss.ChangesSince(-1, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), changes.Till)
+	for _, split := range changes.Splits {
+		assert.Equal(t, split.Name == "f1", split.Killed)
+	}
+
+	// flag sets are honored
+	changes, err = pss.ChangesSince(2, []string{"s2"})
+	assert.Nil(t, err)
+	assert.Empty(t, changes.Splits)
+	changes, err = pss.ChangesSince(2, []string{"s1"})
+	assert.Nil(t, err)
+	assert.Len(t, changes.Splits, 1)
+
+	// kills older than the current change number are ignored
+	pss.KillLocally("f2", "off", 1)
+	assert.False(t, pss.Split("f2").Killed)
+
+	// the kill is not persisted until it's fetched
+	persisted, err := persistent.NewSplitChangesCollection(dbw, logging.NewLogger(nil)).FetchAll()
+	assert.Nil(t, err)
+	for _, split := range persisted {
+		assert.False(t, split.Killed)
+	}
+
+	// the fetch covering the kill brings a change in between, which must be served to SDKs that got the local kill
+	pss.Update([]dtos.SplitDTO{
+		{Name: "f3", ChangeNumber: 3, Status: "ACTIVE", DefaultTreatment: "on"},
+		{Name: "f1", ChangeNumber: 5, Status: "ACTIVE", DefaultTreatment: "off", Killed: true, Sets: []string{"s1"}},
+	}, nil, 5)
+
+	changes, err = pss.ChangesSince(2, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), changes.Till)
+	names := make([]string, 0, len(changes.Splits))
+	for _, split := range changes.Splits {
+		names = append(names, split.Name)
+	}
+	assert.ElementsMatch(t, []string{"f1", "f3"}, names)
+
+	changes, err = pss.ChangesSince(5, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), changes.Till)
+	assert.Empty(t, changes.Splits)
+}
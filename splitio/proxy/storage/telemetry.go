@@ -2,8 +2,10 @@ package storage
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/splitio/go-split-commons/v6/service/api/specs"
 	"github.com/splitio/go-split-commons/v6/storage"
 	"github.com/splitio/go-split-commons/v6/storage/inmemory"
 	"github.com/splitio/go-split-commons/v6/telemetry"
@@ -56,6 +58,12 @@ func (s *statusCodeMap) peek() map[int]int64 {
 	return tmp
 }
 
+func (s *statusCodeMap) reset() {
+	s.mutex.Lock()
+	s.codes = make(map[int]int64)
+	s.mutex.Unlock()
+}
+
 func newStatusCodeMap() statusCodeMap {
 	return statusCodeMap{codes: make(map[int]int64)}
 }
@@ -208,6 +216,35 @@ func newEndpointStatusCodes() EndpointStatusCodes {
 	}
 }
 
+// ResetEndpointStatus zeroes the status code counts of every endpoint
+func (e *EndpointStatusCodes) ResetEndpointStatus() {
+	for _, codes := range []*statusCodeMap{
+		&e.auth,
+		&e.splitChanges,
+		&e.segmentChanges,
+		&e.mySegments,
+		&e.impressionsBulk,
+		&e.impressionsBulkBeacon,
+		&e.impressionsCount,
+		&e.impressionsCountBeacon,
+		&e.eventsBulk,
+		&e.eventsBulkBeacon,
+		&e.telemetryConfig,
+		&e.telemetryRuntime,
+		&e.telemetryBeaconRuntime,
+		&e.legacyTime,
+		&e.legacyTimes,
+		&e.legacyCounter,
+		&e.legacyCounters,
+		&e.legacyGauge,
+		&e.telemetryKeysClientSide,
+		&e.telemetryKeysClientSideBeacon,
+		&e.telemetryKeysServerSide,
+	} {
+		codes.reset()
+	}
+}
+
 // ProxyEndpointLatencies defines an interface to access proxy server endpoint latencies numbers
 type ProxyEndpointLatencies interface {
 	PeekEndpointLatency(endpoint int) []int64
@@ -337,6 +374,35 @@ func (p *ProxyEndpointLatenciesImpl) PeekEndpointLatency(endpoint int) []int64 {
 	return nil
 }
 
+// ResetEndpointLatencies zeroes the latency buckets of every endpoint
+func (p *ProxyEndpointLatenciesImpl) ResetEndpointLatencies() {
+	for _, latencies := range []inmemory.AtomicInt64Slice{
+		p.auth,
+		p.splitChanges,
+		p.segmentChanges,
+		p.mySegments,
+		p.impressionsBulk,
+		p.impressionsBulkBeacon,
+		p.impressionsCount,
+		p.impressionsCountBeacon,
+		p.eventsBulk,
+		p.eventsBulkBeacon,
+		p.telemetryConfig,
+		p.telemetryRuntime,
+		p.telemetryBeaconRuntime,
+		p.legacyTime,
+		p.legacyTimes,
+		p.legacyCounter,
+		p.legacyCounters,
+		p.legacyGauge,
+		p.telemetryKeysClientSide,
+		p.telemetryKeysClientSideBeacon,
+		p.telemetryKeysServerSide,
+	} {
+		latencies.FetchAndClearAll()
+	}
+}
+
 // newProxyEndpointLatenciesImpl creates a new latency tracker
 func newProxyEndpointLatenciesImpl() ProxyEndpointLatenciesImpl {
 	init := func() inmemory.AtomicInt64Slice {
@@ -382,17 +448,130 @@ type ProxyEndpointTelemetry interface {
 	IncrEndpointStatus(endpoint int, status int)
 }
 
+// DegradedResponsesTelemetry counts splitChanges responses built from cached data because Split servers could not be reached
+type DegradedResponsesTelemetry interface {
+	IncrDegradedResponses()
+	PeekDegradedResponses() int64
+}
+
+// DegradedResponses is a thread-safe implementation of DegradedResponsesTelemetry
+type DegradedResponses struct {
+	count int64
+}
+
+// IncrDegradedResponses increments the number of degraded responses served
+func (d *DegradedResponses) IncrDegradedResponses() {
+	atomic.AddInt64(&d.count, 1)
+}
+
+// PeekDegradedResponses returns the number of degraded responses served
+func (d *DegradedResponses) PeekDegradedResponses() int64 {
+	return atomic.LoadInt64(&d.count)
+}
+
+// ResetDegradedResponses zeroes the number of degraded responses served
+func (d *DegradedResponses) ResetDegradedResponses() {
+	atomic.StoreInt64(&d.count, 0)
+}
+
+// ListenerDropsTelemetry counts impression bulks that could not be forwarded to the impression listener
+type ListenerDropsTelemetry interface {
+	IncrListenerDrops()
+	PeekListenerDrops() int64
+}
+
+// ListenerDrops is a thread-safe implementation of ListenerDropsTelemetry
+type ListenerDrops struct {
+	count int64
+}
+
+// IncrListenerDrops increments the number of impression bulks not forwarded to the listener
+func (l *ListenerDrops) IncrListenerDrops() {
+	atomic.AddInt64(&l.count, 1)
+}
+
+// PeekListenerDrops returns the number of impression bulks not forwarded to the listener
+func (l *ListenerDrops) PeekListenerDrops() int64 {
+	return atomic.LoadInt64(&l.count)
+}
+
+// ResetListenerDrops zeroes the number of impression bulks not forwarded to the listener
+func (l *ListenerDrops) ResetListenerDrops() {
+	atomic.StoreInt64(&l.count, 0)
+}
+
+// Labels used for splitChanges requests whose spec version is not tracked as is
+const (
+	SpecVersionNone        = "none"
+	SpecVersionUnsupported = "unsupported"
+)
+
+// SpecVersionTelemetry counts splitChanges requests by the flag spec version requested by SDKs
+type SpecVersionTelemetry interface {
+	IncrSpecVersionRequests(spec string)
+	PeekSpecVersionRequests() map[string]int64
+}
+
+// SpecVersionRequests is a thread-safe implementation of SpecVersionTelemetry.
+// Only supported spec versions are tracked as is, to keep the number of labels bounded
+type SpecVersionRequests struct {
+	counts map[string]int64
+	mutex  sync.Mutex
+}
+
+// IncrSpecVersionRequests increments the number of requests made with a spec version
+func (s *SpecVersionRequests) IncrSpecVersionRequests(spec string) {
+	label := spec
+	if spec == "" {
+		label = SpecVersionNone
+	} else if specs.Match(spec) == nil {
+		label = SpecVersionUnsupported
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.counts == nil {
+		s.counts = make(map[string]int64)
+	}
+	s.counts[label]++
+}
+
+// PeekSpecVersionRequests returns the number of requests made with each spec version
+func (s *SpecVersionRequests) PeekSpecVersionRequests() map[string]int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	toRet := make(map[string]int64, len(s.counts))
+	for spec, count := range s.counts {
+		toRet[spec] = count
+	}
+	return toRet
+}
+
+// ResetSpecVersionRequests zeroes the number of requests made with each spec version
+func (s *SpecVersionRequests) ResetSpecVersionRequests() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.counts = nil
+}
+
 // ProxyTelemetryFacade defines the set of methods required to accept local telemetry as well as runtime telemetry
 type ProxyTelemetryFacade interface {
 	storage.TelemetryStorage
 	storage.TelemetryPeeker
 	ProxyEndpointTelemetry
+	DegradedResponsesTelemetry
+	ListenerDropsTelemetry
+	SpecVersionTelemetry
+	ResetEndpointTelemetry()
 }
 
 // ProxyTelemetryFacadeImpl exposes local telemetry functionality
 type ProxyTelemetryFacadeImpl struct {
 	ProxyEndpointLatenciesImpl
 	EndpointStatusCodes
+	DegradedResponses
+	ListenerDrops
+	SpecVersionRequests
 	*inmemory.TelemetryStorage
 }
 
@@ -406,6 +585,16 @@ func NewProxyTelemetryFacade() *ProxyTelemetryFacadeImpl {
 	}
 }
 
+// ResetEndpointTelemetry zeroes the latencies, status codes, degraded responses, listener drops & spec versions tracked
+// for proxy endpoints. Runtime telemetry is left untouched, since it's periodically flushed to Split servers
+func (p *ProxyTelemetryFacadeImpl) ResetEndpointTelemetry() {
+	p.ResetEndpointLatencies()
+	p.ResetEndpointStatus()
+	p.ResetDegradedResponses()
+	p.ResetListenerDrops()
+	p.ResetSpecVersionRequests()
+}
+
 // Ensure interface compliance
 var _ ProxyTelemetryFacade = (*ProxyTelemetryFacadeImpl)(nil)
 var _ storage.TelemetryStorage = (*ProxyTelemetryFacadeImpl)(nil)
@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"fmt"
+	"math"
 	"sort"
 	"sync"
 	"time"
@@ -8,19 +10,53 @@ import (
 	"github.com/splitio/go-split-commons/v6/storage"
 )
 
-// Granularity selection constants to be used upon component instantiation
+// Granularity selection constants to be used upon component instantiation. Operators can select them by name
+// (see GranularityPresets) instead of setting an explicit timeslice width
 const (
 	HistoricTelemetryGranularityMinute = iota
 	HistoricTelemetryGranularityHour
 	HistoricTelemetryGranularityDay
 )
 
+// GranularityPresets maps the preset names accepted in the config to their timeslice width in seconds
+var GranularityPresets = map[string]int64{
+	"minute": 60,
+	"hour":   3600,
+	"day":    86400,
+}
+
+// MaxTimeSlicesLimit is the largest retention accepted, to keep the memory used by historic telemetry bounded
+const MaxTimeSlicesLimit = 10000
+
+// ResolveTimeSliceConfig returns the timeslice width to use (the preset's if one is selected) after validating it
+// along with the number of timeslices to retain
+func ResolveTimeSliceConfig(preset string, widthSecs int64, maxTimeSlices int64) (int64, error) {
+	if preset != "" {
+		presetWidth, ok := GranularityPresets[preset]
+		if !ok {
+			return 0, fmt.Errorf("unknown timeslice granularity '%s'. valid options are: minute, hour, day", preset)
+		}
+		widthSecs = presetWidth
+	}
+
+	if widthSecs <= 0 {
+		return 0, fmt.Errorf("timeslice width must be positive. got %d", widthSecs)
+	}
+
+	if maxTimeSlices <= 0 || maxTimeSlices > MaxTimeSlicesLimit {
+		return 0, fmt.Errorf("max timeslice count must be between 1 and %d. got %d", MaxTimeSlicesLimit, maxTimeSlices)
+	}
+
+	return widthSecs, nil
+}
+
 // TimeslicedProxyEndpointTelemetry is a proxy telemetry facade (yet another) that bundles global data
 // and historic data by timeslice (for observability purposes)
 type TimeslicedProxyEndpointTelemetry interface {
 	ProxyTelemetryFacade
 	TimeslicedReport() TimeSliceData
 	TotalMetricsReport() map[string]ForResource
+	StatusCodesReport() StatusCodesReport
 }
 
 // TimeslicedProxyEndpointTelemetryImpl is an implementation of `TimeslicedProxyEnxpointTelemetry`
@@ -80,6 +116,40 @@ func (t *TimeslicedProxyEndpointTelemetryImpl) TimeslicedReport() TimeSliceData
 	return formatTimeSeriesData(data)
 }
 
+// StatusCodesReport returns the count of each status code served per resource, since startup and by timeslice
+func (t *TimeslicedProxyEndpointTelemetryImpl) StatusCodesReport() StatusCodesReport {
+	t.mutex.Lock()
+	data := make([]*timeSliceTelemetry, 0, len(t.telemetryByTimeSlice))
+	for _, v := range t.telemetryByTimeSlice {
+		if v != nil {
+			data = append(data, v)
+		}
+	}
+	t.mutex.Unlock()
+	sort.Slice(data, func(i, j int) bool { return data[i].timeSlice < data[j].timeSlice })
+
+	byTimeSlice := make([]StatusCodesForTimeSlice, 0, len(data))
+	for _, ts := range data {
+		byTimeSlice = append(byTimeSlice, StatusCodesForTimeSlice{
+			TimeSlice: ts.timeSlice,
+			Resources: statusCodesByResource(ts.statusCodes.PeekEndpointStatus),
+		})
+	}
+
+	return StatusCodesReport{
+		Total:      statusCodesByResource(t.PeekEndpointStatus),
+		TimeSlices: byTimeSlice,
+	}
+}
+
+// ResetEndpointTelemetry zeroes the global endpoint telemetry & drops every timeslice
+func (t *TimeslicedProxyEndpointTelemetryImpl) ResetEndpointTelemetry() {
+	t.mutex.Lock()
+	t.telemetryByTimeSlice = make(telemetryByTimeSlice)
+	t.mutex.Unlock()
+	t.ProxyTelemetryFacade.ResetEndpointTelemetry()
+}
+
 // RecordEndpointLatency increments the latency bucket for a specific endpoint (global + historic records are updated)
 func (t *TimeslicedProxyEndpointTelemetryImpl) RecordEndpointLatency(endpoint int, latency time.Duration) {
 	t.ProxyTelemetryFacade.RecordEndpointLatency(endpoint, latency)
@@ -163,24 +233,154 @@ type ForTimeSlice struct {
 
 // ForResource bundles latencies & status code for a specific timeslice
 type ForResource struct {
-	Latencies    []int64       `json:"latencies"`
-	StatusCodes  map[int]int64 `json:"statusCodes"`
-	RequestCount int           `json:"requestCount"`
+	Latencies    []int64            `json:"latencies"`
+	StatusCodes  map[int]int64      `json:"statusCodes"`
+	RequestCount int                `json:"requestCount"`
+	ErrorRate    float64            `json:"errorRate"` // ratio of 4xx & 5xx responses to total requests (0 if there were none)
+	Percentiles  LatencyPercentiles `json:"percentiles"`
+}
+
+// LatencyPercentiles contains latency percentiles (in milliseconds) estimated from the bucketed latencies
+type LatencyPercentiles struct {
+	P50 float64 `json:"p50"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+}
+
+// LatencyBucketBounds are the upper bounds (in milliseconds) of the latency buckets used by the commons telemetry package.
+// The last bucket holds every latency above the last bound
+var LatencyBucketBounds = []float64{
+	1.00, 1.50, 2.25, 3.38, 5.06, 7.59, 11.39, 17.09, 25.63, 38.44, 57.67, 86.50,
+	129.75, 194.62, 291.93, 437.89, 656.84, 985.26, 1477.89, 2216.84, 3325.26, 4987.89,
+}
+
+// NewLatencyPercentiles estimates percentiles by locating the bucket holding the requested rank & interpolating linearly
+// between its bounds, assuming latencies are evenly spread within it. Since each bound is 1.5x the previous one, the
+// estimate is off by at most a third of the bucket's upper bound (ie: +/-50% of the actual value in the worst case).
+// Ranks falling in the last (unbounded) bucket are reported as its lower bound, so they underestimate the actual value
+func NewLatencyPercentiles(latencies []int64) LatencyPercentiles {
+	var total int64
+	for _, count := range latencies {
+		total += count
+	}
+
+	if total == 0 {
+		return LatencyPercentiles{}
+	}
+
+	return LatencyPercentiles{
+		P50: latencyPercentile(latencies, total, 0.50),
+		P95: latencyPercentile(latencies, total, 0.95),
+		P99: latencyPercentile(latencies, total, 0.99),
+	}
+}
+
+func latencyPercentile(latencies []int64, total int64, percentile float64) float64 {
+	rank := math.Ceil(percentile * float64(total))
+	var cumulative int64
+	for idx, count := range latencies {
+		if count == 0 || float64(cumulative+count) < rank {
+			cumulative += count
+			continue
+		}
+
+		var lower float64
+		if idx > 0 {
+			lower = LatencyBucketBounds[idx-1]
+		}
+
+		if idx >= len(LatencyBucketBounds) {
+			return lower
+		}
+
+		upper := LatencyBucketBounds[idx]
+		return lower + (upper-lower)*(rank-float64(cumulative))/float64(count)
+	}
+	return LatencyBucketBounds[len(LatencyBucketBounds)-1]
+}
+
+// EstimateLatencySum estimates the sum of the bucketed latencies (in milliseconds), taking each one as the middle point
+// of its bucket. As with percentiles, latencies in the last (unbounded) bucket are taken as its lower bound
+func EstimateLatencySum(latencies []int64) float64 {
+	var sum, lower float64
+	for idx, count := range latencies {
+		if idx >= len(LatencyBucketBounds) {
+			sum += float64(count) * lower
+			continue
+		}
+
+		upper := LatencyBucketBounds[idx]
+		sum += float64(count) * (lower + upper) / 2
+		lower = upper
+	}
+	return sum
 }
 
 func newForResource(latencies []int64, statusCodes map[int]int64) ForResource {
-	var count int64
-	for _, partialCount := range statusCodes {
+	var count, errors int64
+	for code, partialCount := range statusCodes {
 		count += partialCount
+		if code >= 400 {
+			errors += partialCount
+		}
+	}
+
+	var errorRate float64
+	if count > 0 {
+		errorRate = float64(errors) / float64(count)
 	}
 
 	return ForResource{
 		Latencies:    latencies,
 		StatusCodes:  statusCodes,
 		RequestCount: int(count),
+		ErrorRate:    errorRate,
+		Percentiles:  NewLatencyPercentiles(latencies),
 	}
 }
 
+// StatusCodesReport bundles the status codes served by each resource, both since startup & by timeslice
+type StatusCodesReport struct {
+	Total      map[string]map[int]int64  `json:"total"`
+	TimeSlices []StatusCodesForTimeSlice `json:"timeslices"`
+}
+
+// StatusCodesForTimeSlice stores the status codes served by each resource in a certain time-slice
+type StatusCodesForTimeSlice struct {
+	TimeSlice int64                    `json:"timeslice"`
+	Resources map[string]map[int]int64 `json:"resources"`
+}
+
+var statusCodeResources = []struct {
+	name     string
+	endpoint int
+}{
+	{"auth", AuthEndpoint},
+	{"splitChanges", SplitChangesEndpoint},
+	{"segmentChanges", SegmentChangesEndpoint},
+	{"mySegments", MySegmentsEndpoint},
+	{"impressionsBulk", ImpressionsBulkEndpoint},
+	{"impressionsBulkBeacon", ImpressionsBulkBeaconEndpoint},
+	{"impressionsCount", ImpressionsCountEndpoint},
+	{"impressionsCountBeacon", ImpressionsCountBeaconEndpoint},
+	{"eventsBulk", EventsBulkEndpoint},
+	{"eventsBulkBeacon", EventsBulkBeaconEndpoint},
+	{"telemetryConfig", TelemetryConfigEndpoint},
+	{"telemetryRuntime", TelemetryRuntimeEndpoint},
+	{"telemetryBeaconRuntime", TelemetryRuntimeBeaconEndpoint},
+	{"telemetryKeysClientSide", TelemetryKeysClientSideEndpoint},
+	{"telemetryKeysClientSideBeacon", TelemetryKeysClientSideBeaconEndpoint},
+	{"telemetryKeysServerSide", TelemetryKeysServerSideEndpoint},
+}
+
+func statusCodesByResource(peek func(endpoint int) map[int]int64) map[string]map[int]int64 {
+	toRet := make(map[string]map[int]int64, len(statusCodeResources))
+	for _, resource := range statusCodeResources {
+		toRet[resource.name] = peek(resource.endpoint)
+	}
+	return toRet
+}
+
 func formatTimeSeriesData(data []*timeSliceTelemetry) TimeSliceData {
 	sort.Slice(data, func(i, j int) bool { return data[i].timeSlice < data[j].timeSlice })
 	toRet := make(TimeSliceData, 0, len(data))
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"encoding/json"
+	"math"
 	"reflect"
 	"testing"
 	"time"
@@ -81,27 +82,28 @@ func TestHistoricProxyTelemetry(t *testing.T) {
 	// manually build the expected report and check it against the generated one
 	expectedStatusCodes := map[int]int64{200: 1, 500: 1}
 	expectedLatencies := []int64{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}
+	expectedPercentiles := LatencyPercentiles{P50: 1, P95: 4987.89, P99: 4987.89}
 	expectedData := TimeSliceData{}
 	for _, ts := range []int64{oldestTs + 60, oldestTs + 120, oldestTs + 180, oldestTs + 240, oldestTs + 300} {
 		expectedData = append(expectedData, ForTimeSlice{
 			TimeSlice: ts,
 			Resources: map[string]ForResource{
-				"auth":                          {expectedLatencies, expectedStatusCodes, 2},
-				"splitChanges":                  {expectedLatencies, expectedStatusCodes, 2},
-				"segmentChanges":                {expectedLatencies, expectedStatusCodes, 2},
-				"mySegments":                    {expectedLatencies, expectedStatusCodes, 2},
-				"impressionsBulk":               {expectedLatencies, expectedStatusCodes, 2},
-				"impressionsBulkBeacon":         {expectedLatencies, expectedStatusCodes, 2},
-				"impressionsCount":              {expectedLatencies, expectedStatusCodes, 2},
-				"impressionsCountBeacon":        {expectedLatencies, expectedStatusCodes, 2},
-				"eventsBulk":                    {expectedLatencies, expectedStatusCodes, 2},
-				"eventsBulkBeacon":              {expectedLatencies, expectedStatusCodes, 2},
-				"telemetryConfig":               {expectedLatencies, expectedStatusCodes, 2},
-				"telemetryRuntime":              {expectedLatencies, expectedStatusCodes, 2},
-				"telemetryBeaconRuntime":        {expectedLatencies, expectedStatusCodes, 2},
-				"telemetryKeysClientSide":       {expectedLatencies, expectedStatusCodes, 2},
-				"telemetryKeysClientSideBeacon": {expectedLatencies, expectedStatusCodes, 2},
-				"telemetryKeysServerSide":       {expectedLatencies, expectedStatusCodes, 2},
+				"auth":                          {expectedLatencies, expectedStatusCodes, 2, 0.5, expectedPercentiles},
+				"splitChanges":                  {expectedLatencies, expectedStatusCodes, 2, 0.5, expectedPercentiles},
+				"segmentChanges":                {expectedLatencies, expectedStatusCodes, 2, 0.5, expectedPercentiles},
+				"mySegments":                    {expectedLatencies, expectedStatusCodes, 2, 0.5, expectedPercentiles},
+				"impressionsBulk":               {expectedLatencies, expectedStatusCodes, 2, 0.5, expectedPercentiles},
+				"impressionsBulkBeacon":         {expectedLatencies, expectedStatusCodes, 2, 0.5, expectedPercentiles},
+				"impressionsCount":              {expectedLatencies, expectedStatusCodes, 2, 0.5, expectedPercentiles},
+				"impressionsCountBeacon":        {expectedLatencies, expectedStatusCodes, 2, 0.5, expectedPercentiles},
+				"eventsBulk":                    {expectedLatencies, expectedStatusCodes, 2, 0.5, expectedPercentiles},
+				"eventsBulkBeacon":              {expectedLatencies, expectedStatusCodes, 2, 0.5, expectedPercentiles},
+				"telemetryConfig":               {expectedLatencies, expectedStatusCodes, 2, 0.5, expectedPercentiles},
+				"telemetryRuntime":              {expectedLatencies, expectedStatusCodes, 2, 0.5, expectedPercentiles},
+				"telemetryBeaconRuntime":        {expectedLatencies, expectedStatusCodes, 2, 0.5, expectedPercentiles},
+				"telemetryKeysClientSide":       {expectedLatencies, expectedStatusCodes, 2, 0.5, expectedPercentiles},
+				"telemetryKeysClientSideBeacon": {expectedLatencies, expectedStatusCodes, 2, 0.5, expectedPercentiles},
+				"telemetryKeysServerSide":       {expectedLatencies, expectedStatusCodes, 2, 0.5, expectedPercentiles},
 			},
 		})
 	}
@@ -124,22 +126,22 @@ func TestHistoricProxyTelemetry(t *testing.T) {
 	expectedStatusCodes = map[int]int64{200: 6, 500: 6}
 	expectedLatencies = []int64{6, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 6}
 	expectedTotalReport := map[string]ForResource{
-		"auth":                          {expectedLatencies, expectedStatusCodes, 12},
-		"splitChanges":                  {expectedLatencies, expectedStatusCodes, 12},
-		"segmentChanges":                {expectedLatencies, expectedStatusCodes, 12},
-		"mySegments":                    {expectedLatencies, expectedStatusCodes, 12},
-		"impressionsBulk":               {expectedLatencies, expectedStatusCodes, 12},
-		"impressionsBulkBeacon":         {expectedLatencies, expectedStatusCodes, 12},
-		"impressionsCount":              {expectedLatencies, expectedStatusCodes, 12},
-		"impressionsCountBeacon":        {expectedLatencies, expectedStatusCodes, 12},
-		"eventsBulk":                    {expectedLatencies, expectedStatusCodes, 12},
-		"eventsBulkBeacon":              {expectedLatencies, expectedStatusCodes, 12},
-		"telemetryConfig":               {expectedLatencies, expectedStatusCodes, 12},
-		"telemetryRuntime":              {expectedLatencies, expectedStatusCodes, 12},
-		"telemetryBeaconRuntime":        {expectedLatencies, expectedStatusCodes, 12},
-		"telemetryKeysClientSide":       {expectedLatencies, expectedStatusCodes, 12},
-		"telemetryKeysClientSideBeacon": {expectedLatencies, expectedStatusCodes, 12},
-		"telemetryKeysServerSide":       {expectedLatencies, expectedStatusCodes, 12},
+		"auth":                          {expectedLatencies, expectedStatusCodes, 12, 0.5, expectedPercentiles},
+		"splitChanges":                  {expectedLatencies, expectedStatusCodes, 12, 0.5, expectedPercentiles},
+		"segmentChanges":                {expectedLatencies, expectedStatusCodes, 12, 0.5, expectedPercentiles},
+		"mySegments":                    {expectedLatencies, expectedStatusCodes, 12, 0.5, expectedPercentiles},
+		"impressionsBulk":               {expectedLatencies, expectedStatusCodes, 12, 0.5, expectedPercentiles},
+		"impressionsBulkBeacon":         {expectedLatencies, expectedStatusCodes, 12, 0.5, expectedPercentiles},
+		"impressionsCount":              {expectedLatencies, expectedStatusCodes, 12, 0.5, expectedPercentiles},
+		"impressionsCountBeacon":        {expectedLatencies, expectedStatusCodes, 12, 0.5, expectedPercentiles},
+		"eventsBulk":                    {expectedLatencies, expectedStatusCodes, 12, 0.5, expectedPercentiles},
+		"eventsBulkBeacon":              {expectedLatencies, expectedStatusCodes, 12, 0.5, expectedPercentiles},
+		"telemetryConfig":               {expectedLatencies, expectedStatusCodes, 12, 0.5, expectedPercentiles},
+		"telemetryRuntime":              {expectedLatencies, expectedStatusCodes, 12, 0.5, expectedPercentiles},
+		"telemetryBeaconRuntime":        {expectedLatencies, expectedStatusCodes, 12, 0.5, expectedPercentiles},
+		"telemetryKeysClientSide":       {expectedLatencies, expectedStatusCodes, 12, 0.5, expectedPercentiles},
+		"telemetryKeysClientSideBeacon": {expectedLatencies, expectedStatusCodes, 12, 0.5, expectedPercentiles},
+		"telemetryKeysServerSide":       {expectedLatencies, expectedStatusCodes, 12, 0.5, expectedPercentiles},
 	}
 
 	if gen := timesliced.TotalMetricsReport(); !reflect.DeepEqual(expectedTotalReport, gen) {
@@ -150,3 +152,111 @@ func TestHistoricProxyTelemetry(t *testing.T) {
 		t.Errorf("expected: %+v", string(jsonExp))
 	}
 }
+
+func TestStatusCodesReport(t *testing.T) {
+	clk := mockClock{base: time.Now()}
+	timesliced := NewTimeslicedProxyEndpointTelemetry(NewProxyTelemetryFacade(), 60, 5)
+	timesliced.clock = &clk
+
+	timesliced.IncrEndpointStatus(SplitChangesEndpoint, 200)
+	timesliced.IncrEndpointStatus(SplitChangesEndpoint, 200)
+	timesliced.IncrEndpointStatus(SplitChangesEndpoint, 500)
+	clk.base = clk.base.Add(60 * time.Second)
+	timesliced.IncrEndpointStatus(SplitChangesEndpoint, 401)
+	timesliced.IncrEndpointStatus(MySegmentsEndpoint, 200)
+
+	report := timesliced.StatusCodesReport()
+	if !reflect.DeepEqual(report.Total["splitChanges"], map[int]int64{200: 2, 500: 1, 401: 1}) {
+		t.Error("unexpected totals for splitChanges: ", report.Total["splitChanges"])
+	}
+
+	if !reflect.DeepEqual(report.Total["mySegments"], map[int]int64{200: 1}) {
+		t.Error("unexpected totals for mySegments: ", report.Total["mySegments"])
+	}
+
+	if len(report.TimeSlices) != 2 {
+		t.Error("there should be 2 timeslices. Got: ", len(report.TimeSlices))
+		return
+	}
+
+	if report.TimeSlices[0].TimeSlice >= report.TimeSlices[1].TimeSlice {
+		t.Error("timeslices should be sorted")
+	}
+
+	if !reflect.DeepEqual(report.TimeSlices[0].Resources["splitChanges"], map[int]int64{200: 2, 500: 1}) {
+		t.Error("unexpected first timeslice for splitChanges: ", report.TimeSlices[0].Resources["splitChanges"])
+	}
+
+	if !reflect.DeepEqual(report.TimeSlices[1].Resources["splitChanges"], map[int]int64{401: 1}) {
+		t.Error("unexpected second timeslice for splitChanges: ", report.TimeSlices[1].Resources["splitChanges"])
+	}
+}
+
+func TestForResourceErrorRate(t *testing.T) {
+	res := newForResource(nil, map[int]int64{200: 2, 304: 1, 404: 1})
+	if res.RequestCount != 4 || res.ErrorRate != 0.25 {
+		t.Error("4xx & 5xx responses should count as errors. Got: ", res.RequestCount, res.ErrorRate)
+	}
+
+	if res := newForResource(nil, map[int]int64{}); res.ErrorRate != 0 {
+		t.Error("error rate should be 0 when no requests were served. Got: ", res.ErrorRate)
+	}
+}
+
+func TestResolveTimeSliceConfig(t *testing.T) {
+	if width, err := ResolveTimeSliceConfig("", 300, 100); width != 300 || err != nil {
+		t.Error("explicit width should be used when no preset is selected. Got: ", width, err)
+	}
+
+	if width, err := ResolveTimeSliceConfig("hour", 300, 100); width != 3600 || err != nil {
+		t.Error("preset width should override the explicit one. Got: ", width, err)
+	}
+
+	if _, err := ResolveTimeSliceConfig("week", 300, 100); err == nil {
+		t.Error("unknown presets should be rejected")
+	}
+
+	if _, err := ResolveTimeSliceConfig("", 0, 100); err == nil {
+		t.Error("non-positive widths should be rejected")
+	}
+
+	if _, err := ResolveTimeSliceConfig("", 300, 0); err == nil {
+		t.Error("non-positive retention should be rejected")
+	}
+
+	if _, err := ResolveTimeSliceConfig("", 300, MaxTimeSlicesLimit+1); err == nil {
+		t.Error("retention above the limit should be rejected")
+	}
+}
+
+func TestLatencyPercentiles(t *testing.T) {
+	if p := NewLatencyPercentiles(make([]int64, 23)); p != (LatencyPercentiles{}) {
+		t.Error("percentiles should be 0 when there are no latencies. Got: ", p)
+	}
+
+	latencies := make([]int64, 23)
+	latencies[2] = 90 // (1.50, 2.25]
+	latencies[5] = 10 // (5.06, 7.59]
+	p := NewLatencyPercentiles(latencies)
+	if math.Abs(p.P50-(1.50+0.75*50/90)) > 1e-9 {
+		t.Error("wrong p50: ", p.P50)
+	}
+
+	if math.Abs(p.P95-(5.06+(7.59-5.06)*5/10)) > 1e-9 || math.Abs(p.P99-(5.06+(7.59-5.06)*9/10)) > 1e-9 {
+		t.Error("wrong p95/p99: ", p.P95, p.P99)
+	}
+}
+
+func TestEstimateLatencySum(t *testing.T) {
+	if sum := EstimateLatencySum(make([]int64, 23)); sum != 0 {
+		t.Error("sum should be 0 when there are no latencies. Got: ", sum)
+	}
+
+	latencies := make([]int64, 23)
+	latencies[0] = 2  // [0, 1]
+	latencies[2] = 4  // (1.50, 2.25]
+	latencies[22] = 1 // > 4987.89
+	if sum := EstimateLatencySum(latencies); math.Abs(sum-(2*0.5+4*(1.50+2.25)/2+4987.89)) > 1e-9 {
+		t.Error("wrong sum: ", sum)
+	}
+}
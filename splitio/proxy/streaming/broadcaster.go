@@ -0,0 +1,217 @@
+package streaming
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+// Channels included in the tokens issued to SDKs
+const (
+	SplitsChannel     = "proxy_splits"
+	SegmentsChannel   = "proxy_segments"
+	ControlPriChannel = "control_pri"
+	ControlSecChannel = "control_sec"
+)
+
+const (
+	occupancyPrefix = "[?occupancy=metrics.publishers]"
+	occupancyName   = "[meta]occupancy"
+
+	// the SDK fetches its segments again when receiving this, since the payload is not included
+	updateTypeMySegments = "MY_SEGMENTS_UPDATE"
+
+	// how many notifications can be pending for a single SDK before it's considered too slow & disconnected
+	subscriberBufferSize = 100
+)
+
+// Broadcaster fans out feature flag & segment change notifications to all the SDKs connected to the proxy,
+// using the same message format as Split's streaming service. Subscribers are indexed by channel, so that publishing
+// only goes through the SDKs subscribed to it
+type Broadcaster struct {
+	logger      logging.LoggerInterface
+	subscribers map[chan []byte][]string
+	byChannel   map[string]map[chan []byte]struct{}
+	mutex       sync.Mutex
+	lastID      int64
+}
+
+// NewBroadcaster constructs a new broadcaster
+func NewBroadcaster(logger logging.LoggerInterface) *Broadcaster {
+	return &Broadcaster{
+		logger:      logger,
+		subscribers: make(map[chan []byte][]string),
+		byChannel:   make(map[string]map[chan []byte]struct{}),
+	}
+}
+
+// ChannelName strips the occupancy prefix SDKs add to the control channels when subscribing
+func ChannelName(channel string) string {
+	return strings.TrimPrefix(channel, occupancyPrefix)
+}
+
+// Subscribe returns a channel where serialized SSE events published to any of the supplied channels will be pushed,
+// and a function to stop receiving them. If the subscriber falls behind, the channel is closed so that the SDK
+// reconnects & fetches the latest changes
+func (b *Broadcaster) Subscribe(channels []string) (<-chan []byte, func()) {
+	ch := make(chan []byte, subscriberBufferSize)
+	b.mutex.Lock()
+	b.subscribers[ch] = channels
+	for _, channel := range channels {
+		subscribed, ok := b.byChannel[channel]
+		if !ok {
+			subscribed = make(map[chan []byte]struct{})
+			b.byChannel[channel] = subscribed
+		}
+		subscribed[ch] = struct{}{}
+	}
+	b.mutex.Unlock()
+	return ch, func() { b.unsubscribe(ch) }
+}
+
+// Subscribers returns the number of SDKs currently connected
+func (b *Broadcaster) Subscribers() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return len(b.subscribers)
+}
+
+// NotifySplitUpdate tells connected SDKs that feature flags have changed
+func (b *Broadcaster) NotifySplitUpdate(changeNumber int64) {
+	b.publish(SplitsChannel, map[string]interface{}{"type": dtos.UpdateTypeSplitChange, "changeNumber": changeNumber})
+}
+
+// NotifySplitKill tells connected SDKs that a feature flag has been killed
+func (b *Broadcaster) NotifySplitKill(splitName string, defaultTreatment string, changeNumber int64) {
+	b.publish(SplitsChannel, map[string]interface{}{
+		"type":             dtos.UpdateTypeSplitKill,
+		"changeNumber":     changeNumber,
+		"splitName":        splitName,
+		"defaultTreatment": defaultTreatment,
+	})
+}
+
+// NotifySegmentUpdate tells connected SDKs that a segment has changed
+func (b *Broadcaster) NotifySegmentUpdate(segmentName string, changeNumber int64) {
+	b.publish(SegmentsChannel, map[string]interface{}{
+		"type":         dtos.UpdateTypeSegmentChange,
+		"changeNumber": changeNumber,
+		"segmentName":  segmentName,
+	})
+}
+
+// NotifyMySegmentsUpdate tells the client-side SDKs tracking any of the supplied keys that their segments have changed.
+// Keys no SDK is subscribed to are skipped without building a notification
+func (b *Broadcaster) NotifyMySegmentsUpdate(keys []string, changeNumber int64) {
+	data := map[string]interface{}{
+		"type":            updateTypeMySegments,
+		"changeNumber":    changeNumber,
+		"includesPayload": false,
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if len(b.subscribers) == 0 {
+		return
+	}
+
+	for _, key := range keys {
+		b.publishLocked(MySegmentsChannel(key), data)
+	}
+}
+
+// Occupancy returns the events to be sent right after an SDK connects, signaling that notifications are available
+func (b *Broadcaster) Occupancy() [][]byte {
+	data := map[string]interface{}{"metrics": map[string]int{"publishers": 1}}
+	return [][]byte{
+		b.format(occupancyPrefix+ControlPriChannel, occupancyName, data),
+		b.format(occupancyPrefix+ControlSecChannel, occupancyName, data),
+	}
+}
+
+func (b *Broadcaster) publish(channel string, data map[string]interface{}) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.publishLocked(channel, data)
+}
+
+// publishLocked must be called with the mutex held
+func (b *Broadcaster) publishLocked(channel string, data map[string]interface{}) {
+	subscribed := b.byChannel[channel]
+	if len(subscribed) == 0 {
+		return
+	}
+
+	event := b.format(channel, "", data)
+	if event == nil {
+		return
+	}
+
+	for ch := range subscribed {
+		select {
+		case ch <- event:
+		default:
+			b.logger.Warning("SDK connected to the streaming endpoint is not keeping up with notifications. Disconnecting it")
+			b.removeLocked(ch)
+		}
+	}
+}
+
+func (b *Broadcaster) unsubscribe(ch chan []byte) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.removeLocked(ch)
+}
+
+// removeLocked drops a subscriber & closes its channel. Must be called with the mutex held
+func (b *Broadcaster) removeLocked(ch chan []byte) {
+	channels, ok := b.subscribers[ch]
+	if !ok {
+		return
+	}
+
+	delete(b.subscribers, ch)
+	for _, channel := range channels {
+		delete(b.byChannel[channel], ch)
+		if len(b.byChannel[channel]) == 0 {
+			delete(b.byChannel, channel)
+		}
+	}
+	close(ch)
+}
+
+// format builds an SSE `message` event, with the notification nested as a json string in the `data` property
+func (b *Broadcaster) format(channel string, name string, data map[string]interface{}) []byte {
+	nested, err := json.Marshal(data)
+	if err != nil {
+		b.logger.Error("error serializing streaming notification: ", err)
+		return nil
+	}
+
+	envelope := map[string]interface{}{
+		"id":        strconv.FormatInt(atomic.AddInt64(&b.lastID, 1), 10),
+		"clientId":  "split-proxy",
+		"timestamp": time.Now().UnixMilli(),
+		"encoding":  "json",
+		"channel":   channel,
+		"data":      string(nested),
+	}
+	if name != "" {
+		envelope["name"] = name
+	}
+
+	message, err := json.Marshal(envelope)
+	if err != nil {
+		b.logger.Error("error serializing streaming notification: ", err)
+		return nil
+	}
+
+	return []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", dtos.SSEEventTypeMessage, message))
+}
@@ -0,0 +1,87 @@
+package streaming
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseEvent(t *testing.T, raw []byte) (map[string]interface{}, map[string]interface{}) {
+	t.Helper()
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	assert.Equal(t, "event: message", lines[0])
+
+	var envelope map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(strings.TrimPrefix(lines[1], "data: ")), &envelope))
+	var nested map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(envelope["data"].(string)), &nested))
+	return envelope, nested
+}
+
+func TestBroadcaster(t *testing.T) {
+	broadcaster := NewBroadcaster(logging.NewLogger(nil))
+	first, unsubscribeFirst := broadcaster.Subscribe([]string{SplitsChannel, SegmentsChannel})
+	second, unsubscribeSecond := broadcaster.Subscribe([]string{SplitsChannel, SegmentsChannel, MySegmentsChannel("key1")})
+	assert.Equal(t, 2, broadcaster.Subscribers())
+
+	broadcaster.NotifySplitUpdate(123)
+	for _, ch := range []<-chan []byte{first, second} {
+		envelope, nested := parseEvent(t, <-ch)
+		assert.Equal(t, SplitsChannel, envelope["channel"])
+		assert.Equal(t, map[string]interface{}{"type": "SPLIT_UPDATE", "changeNumber": float64(123)}, nested)
+	}
+
+	unsubscribeFirst()
+	_, ok := <-first
+	assert.False(t, ok)
+	assert.Equal(t, 1, broadcaster.Subscribers())
+
+	broadcaster.NotifySegmentUpdate("someSegment", 124)
+	envelope, nested := parseEvent(t, <-second)
+	assert.Equal(t, SegmentsChannel, envelope["channel"])
+	assert.Equal(t, "someSegment", nested["segmentName"])
+
+	// notifications for keys are only sent to the SDKs tracking them
+	broadcaster.NotifyMySegmentsUpdate([]string{"key2"}, 125)
+	broadcaster.NotifyMySegmentsUpdate([]string{"key2", "key1", "key3"}, 126)
+	envelope, nested = parseEvent(t, <-second)
+	assert.Equal(t, MySegmentsChannel("key1"), envelope["channel"])
+	assert.Equal(t, map[string]interface{}{"type": "MY_SEGMENTS_UPDATE", "changeNumber": float64(126), "includesPayload": false}, nested)
+	assert.Len(t, second, 0)
+
+	unsubscribeSecond()
+	unsubscribeSecond() // no-op
+	assert.Equal(t, 0, broadcaster.Subscribers())
+	assert.Empty(t, broadcaster.byChannel)
+}
+
+func TestBroadcasterDisconnectsSlowSubscribers(t *testing.T) {
+	broadcaster := NewBroadcaster(logging.NewLogger(nil))
+	ch, unsubscribe := broadcaster.Subscribe([]string{SplitsChannel})
+	defer unsubscribe()
+
+	for i := 0; i <= subscriberBufferSize; i++ {
+		broadcaster.NotifySplitKill("someSplit", "off", int64(i))
+	}
+	assert.Equal(t, 0, broadcaster.Subscribers())
+
+	received := 0
+	for range ch {
+		received++
+	}
+	assert.Equal(t, subscriberBufferSize, received)
+}
+
+func TestBroadcasterOccupancy(t *testing.T) {
+	events := NewBroadcaster(logging.NewLogger(nil)).Occupancy()
+	assert.Len(t, events, 2)
+	for idx, channel := range []string{ControlPriChannel, ControlSecChannel} {
+		envelope, nested := parseEvent(t, events[idx])
+		assert.Equal(t, "[meta]occupancy", envelope["name"])
+		assert.Equal(t, "[?occupancy=metrics.publishers]"+channel, envelope["channel"])
+		assert.Equal(t, map[string]interface{}{"metrics": map[string]interface{}{"publishers": float64(1)}}, nested)
+	}
+}
@@ -0,0 +1,124 @@
+package streaming
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/splitio/go-toolkit/v5/hasher"
+)
+
+// ErrInvalidToken is returned when a token was not signed with this proxy's key or has expired
+var ErrInvalidToken = errors.New("invalid or expired streaming token")
+
+// SDKs reconnect 10 minutes before the token expires, so it must be valid for longer than that
+const tokenTTL = time.Hour
+
+var tokenHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// TokenIssuer creates & validates the JWTs handed to SDKs in auth responses. When running several proxy replicas
+// behind a load balancer, all of them must share the same signing secret, otherwise SDKs will be rejected when
+// their SSE connection lands on a replica other than the one that authenticated them
+type TokenIssuer struct {
+	key []byte
+	ttl time.Duration
+}
+
+// NewTokenIssuer constructs a new token issuer signing tokens with the supplied secret. If it's empty, a random key is
+// generated, and tokens are only valid for this proxy instance
+func NewTokenIssuer(secret string) (*TokenIssuer, error) {
+	if secret != "" {
+		return &TokenIssuer{key: []byte(secret), ttl: tokenTTL}, nil
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("error generating token signing key: %w", err)
+	}
+	return &TokenIssuer{key: key, ttl: tokenTTL}, nil
+}
+
+// MySegmentsChannel returns the channel where segment changes for a key are published. Client-side SDKs take the
+// third underscore-separated part as the base64-encoded murmur3 hash of the key, to tell which client it belongs to
+func MySegmentsChannel(key string) string {
+	hash := strconv.FormatUint(uint64(hasher.Sum32WithSeed([]byte(key), 0)), 10)
+	return "proxy_keys_" + base64.StdEncoding.EncodeToString([]byte(hash)) + "_mySegments"
+}
+
+// Issue returns a new token granting access to the proxy channels, including the mySegments ones for the supplied
+// keys when issued to client-side SDKs
+func (t *TokenIssuer) Issue(keys []string) (string, error) {
+	channels := map[string][]string{
+		SplitsChannel:     {"subscribe"},
+		SegmentsChannel:   {"subscribe"},
+		ControlPriChannel: {"subscribe", "channel-metadata:publishers"},
+		ControlSecChannel: {"subscribe", "channel-metadata:publishers"},
+	}
+	for _, key := range keys {
+		channels[MySegmentsChannel(key)] = []string{"subscribe"}
+	}
+
+	capabilities, err := json.Marshal(channels)
+	if err != nil {
+		return "", fmt.Errorf("error serializing token capabilities: %w", err)
+	}
+
+	now := time.Now()
+	payload, err := json.Marshal(map[string]interface{}{
+		"x-ably-capability": string(capabilities),
+		"iat":               now.Unix(),
+		"exp":               now.Add(t.ttl).Unix(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error serializing token payload: %w", err)
+	}
+
+	unsigned := tokenHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + t.sign(unsigned), nil
+}
+
+// Validate checks that the token was signed with this issuer's key & has not expired, and returns the channels it grants
+// access to
+func (t *TokenIssuer) Validate(token string) (map[string]struct{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || !hmac.Equal([]byte(parts[2]), []byte(t.sign(parts[0]+"."+parts[1]))) {
+		return nil, ErrInvalidToken
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var payload struct {
+		Capability string `json:"x-ably-capability"`
+		Exp        int64  `json:"exp"`
+	}
+	if err := json.Unmarshal(raw, &payload); err != nil || time.Now().Unix() >= payload.Exp {
+		return nil, ErrInvalidToken
+	}
+
+	var capabilities map[string][]string
+	if err := json.Unmarshal([]byte(payload.Capability), &capabilities); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	channels := make(map[string]struct{}, len(capabilities))
+	for channel := range capabilities {
+		channels[channel] = struct{}{}
+	}
+	return channels, nil
+}
+
+func (t *TokenIssuer) sign(unsigned string) string {
+	mac := hmac.New(sha256.New, t.key)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,95 @@
+package streaming
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-toolkit/v5/hasher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenIssuer(t *testing.T) {
+	issuer, err := NewTokenIssuer("")
+	assert.Nil(t, err)
+
+	token, err := issuer.Issue(nil)
+	assert.Nil(t, err)
+	granted, err := issuer.Validate(token)
+	assert.Nil(t, err)
+	assert.Len(t, granted, 4)
+
+	// sdks must be able to parse the token
+	parsed := dtos.Token{Token: token, PushEnabled: true}
+	channels, err := parsed.ChannelList()
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{
+		SplitsChannel,
+		SegmentsChannel,
+		"[?occupancy=metrics.publishers]" + ControlPriChannel,
+		"[?occupancy=metrics.publishers]" + ControlSecChannel,
+	}, channels)
+	expiration, err := parsed.CalculateNextTokenExpiration()
+	assert.Nil(t, err)
+	assert.Greater(t, expiration, time.Duration(0))
+
+	// client-side sdks get a channel for each of their keys
+	token, err = issuer.Issue([]string{"key1", "key2"})
+	assert.Nil(t, err)
+	channels, err = (&dtos.Token{Token: token, PushEnabled: true}).ChannelList()
+	assert.Nil(t, err)
+	assert.Contains(t, channels, MySegmentsChannel("key1"))
+	assert.Contains(t, channels, MySegmentsChannel("key2"))
+	assert.Len(t, channels, 6)
+	granted, err = issuer.Validate(token)
+	assert.Nil(t, err)
+	assert.Contains(t, granted, MySegmentsChannel("key1"))
+	assert.Contains(t, granted, MySegmentsChannel("key2"))
+	assert.NotContains(t, granted, MySegmentsChannel("key3"))
+
+	// tokens issued by other instances or tampered with are rejected
+	other, err := NewTokenIssuer("")
+	assert.Nil(t, err)
+	_, err = other.Validate(token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// replicas sharing a secret accept each other's tokens
+	replica1, err := NewTokenIssuer("someSecret")
+	assert.Nil(t, err)
+	replica2, err := NewTokenIssuer("someSecret")
+	assert.Nil(t, err)
+	shared, err := replica1.Issue([]string{"key1"})
+	assert.Nil(t, err)
+	_, err = replica2.Validate(shared)
+	assert.Nil(t, err)
+	_, err = issuer.Validate(shared)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	parts := strings.Split(token, ".")
+	_, err = issuer.Validate(parts[0] + "." + parts[0] + "." + parts[2])
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = issuer.Validate("")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// expired tokens are rejected
+	issuer.ttl = -time.Second
+	expired, err := issuer.Issue(nil)
+	assert.Nil(t, err)
+	_, err = issuer.Validate(expired)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestMySegmentsChannel(t *testing.T) {
+	channel := MySegmentsChannel("someKey")
+	parts := strings.Split(channel, "_")
+	assert.Len(t, parts, 4)
+	assert.Equal(t, "mySegments", parts[3])
+
+	decoded, err := base64.StdEncoding.DecodeString(parts[2])
+	assert.Nil(t, err)
+	assert.Equal(t, strconv.FormatUint(uint64(hasher.Sum32WithSeed([]byte("someKey"), 0)), 10), string(decoded))
+	assert.NotEqual(t, channel, MySegmentsChannel("otherKey"))
+}
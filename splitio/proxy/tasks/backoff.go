@@ -0,0 +1,84 @@
+package tasks
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/splitio/go-split-commons/v6/dtos"
+)
+
+const (
+	defaultPostBackoffBase = 500 * time.Millisecond
+	defaultPostBackoffMax  = time.Minute
+	maxPostAttempts        = 5
+)
+
+// PostStats contains the number of bulks posted by a deferred recording task, grouped by outcome
+type PostStats struct {
+	Posted  int64 `json:"posted"`  // posted successfully on the first attempt
+	Retried int64 `json:"retried"` // posted successfully after being re-queued one or more times
+	Dropped int64 `json:"dropped"` // discarded after a non-retryable error or exhausting all attempts
+}
+
+// postTracker is shared among the workers of a task. It keeps track of post outcomes & consecutive failures, so that
+// all workers slow down while Split servers are unavailable instead of hammering them with the whole backlog
+type postTracker struct {
+	base     time.Duration
+	max      time.Duration
+	failures int64
+	posted   int64
+	retried  int64
+	dropped  int64
+}
+
+func newPostTracker(base time.Duration, max time.Duration) *postTracker {
+	return &postTracker{base: base, max: max}
+}
+
+func (t *postTracker) success(previousAttempts int) {
+	atomic.StoreInt64(&t.failures, 0)
+	if previousAttempts > 0 {
+		atomic.AddInt64(&t.retried, 1)
+	} else {
+		atomic.AddInt64(&t.posted, 1)
+	}
+}
+
+func (t *postTracker) failure() {
+	atomic.AddInt64(&t.failures, 1)
+}
+
+func (t *postTracker) drop() {
+	atomic.AddInt64(&t.dropped, 1)
+}
+
+// wait returns how long a worker should pause after a failed post. It doubles with each consecutive failure, up to `max`
+func (t *postTracker) wait() time.Duration {
+	wait := t.base
+	for i := int64(1); i < atomic.LoadInt64(&t.failures) && wait < t.max; i++ {
+		wait *= 2
+	}
+	if wait > t.max {
+		return t.max
+	}
+	return wait
+}
+
+func (t *postTracker) stats() PostStats {
+	return PostStats{
+		Posted:  atomic.LoadInt64(&t.posted),
+		Retried: atomic.LoadInt64(&t.retried),
+		Dropped: atomic.LoadInt64(&t.dropped),
+	}
+}
+
+// isRetryable returns false for errors that will happen again no matter how many times the same bulk is posted
+func isRetryable(err error) bool {
+	var httpErr *dtos.HTTPError
+	if errors.As(err, &httpErr) && httpErr.Code >= 400 && httpErr.Code < 500 {
+		return httpErr.Code == http.StatusRequestTimeout || httpErr.Code == http.StatusTooManyRequests
+	}
+	return true
+}
@@ -1,14 +1,20 @@
 package tasks
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"sync"
+	"time"
 
+	"github.com/splitio/go-split-commons/v6/dtos"
 	"github.com/splitio/go-split-commons/v6/tasks"
 	"github.com/splitio/go-toolkit/v5/asynctask"
 	"github.com/splitio/go-toolkit/v5/logging"
 	gtSync "github.com/splitio/go-toolkit/v5/sync"
 	"github.com/splitio/go-toolkit/v5/workerpool"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/util"
 )
 
 // Right now, proxy mode has impressions, events & telemetry refresh rate properties. It's not really clear whether they add value or not
@@ -30,6 +36,11 @@ type DeferredRecordingTask interface {
 	tasks.Task
 }
 
+// RawRecorder defines the interface of a component capable of posting already serialized payloads to Split servers
+type RawRecorder interface {
+	RecordRaw(url string, data []byte, metadata dtos.Metadata, extraHeaders map[string]string) error
+}
+
 // WorkerFactory defines the signature of a function for instantiating workers
 type WorkerFactory = func() workerpool.Worker
 
@@ -42,36 +53,60 @@ type DeferredRecordingTaskImpl struct {
 	drainInProgress *gtSync.AtomicBool
 	pool            *workerpool.WorkerAdmin
 	queue           genericQueue
+	deadline        *util.ShutdownDeadline
 	mutex           sync.Mutex
+	tracker         *postTracker                      // nil if the workers don't report post outcomes
+	flushSize       int                               // staged items that trigger an early flush (queue capacity if 0)
+	merge           func([]interface{}) []interface{} // combines staged items before posting them (nil = post them as they are)
 }
 
-func newDeferredFlushTask(logger logging.LoggerInterface, wfactory WorkerFactory, period int, queueSize int, threads int) *DeferredRecordingTaskImpl {
-	drainFlag := gtSync.NewAtomicBool(false)
-	queue := make(genericQueue, queueSize)
+func newDeferredFlushTask(
+	logger logging.LoggerInterface,
+	wfactory WorkerFactory,
+	period int,
+	queueSize int,
+	threads int,
+	deadline *util.ShutdownDeadline,
+) *DeferredRecordingTaskImpl {
 	pool := workerpool.NewWorkerAdmin(queueSize, logger)
-	trigger := func(loger logging.LoggerInterface) error {
-		if !drainFlag.TestAndSet() {
+	for i := 0; i < threads; i++ {
+		pool.AddWorker(wfactory())
+	}
+
+	t := &DeferredRecordingTaskImpl{
+		logger:          logger,
+		drainInProgress: gtSync.NewAtomicBool(false),
+		pool:            pool,
+		queue:           make(genericQueue, queueSize),
+		deadline:        deadline,
+	}
+
+	trigger := func(logging.LoggerInterface) error {
+		if !t.drainInProgress.TestAndSet() {
 			logger.Warning("Impressions flush requested while another one is in progress. Ignoring.")
 			return nil
 		}
-		defer drainFlag.Unset() // clear the flag after we're done
-		for len(queue) > 0 {
-			pool.QueueMessage(<-queue)
+		defer t.drainInProgress.Unset() // clear the flag after we're done
+		for _, item := range t.drain() {
+			t.pool.QueueMessage(item)
 		}
 		return nil
 	}
+	t.task = asynctask.NewAsyncTask("impressions-recorder", trigger, period, nil, nil, logger)
+	return t
+}
 
-	for i := 0; i < threads; i++ {
-		pool.AddWorker(wfactory())
+// drain pops every staged item, merging them if a merge function is set
+func (t *DeferredRecordingTaskImpl) drain() []interface{} {
+	items := make([]interface{}, 0, len(t.queue))
+	for len(t.queue) > 0 {
+		items = append(items, <-t.queue)
 	}
 
-	return &DeferredRecordingTaskImpl{
-		logger:          logger,
-		task:            asynctask.NewAsyncTask("impressions-recorder", trigger, period, nil, nil, logger),
-		drainInProgress: drainFlag,
-		pool:            pool,
-		queue:           queue,
+	if t.merge != nil && len(items) > 1 {
+		return t.merge(items)
 	}
+	return items
 }
 
 // Stage queues impressions to be sent when the timer expires or the queue is filled.
@@ -84,7 +119,7 @@ func (t *DeferredRecordingTaskImpl) Stage(data interface{}) error {
 		return ErrQueueFull
 	}
 
-	if len(t.queue) == cap(t.queue) { // The queue has become full with this new element we added
+	if len(t.queue) == cap(t.queue) || (t.flushSize > 0 && len(t.queue) >= t.flushSize) { // flush threshold reached with this new element
 		t.task.WakeUp()
 	}
 	return nil
@@ -95,9 +130,39 @@ func (t *DeferredRecordingTaskImpl) Start() {
 	t.task.Start()
 }
 
-// Stop stops the flushing task
+// Stop stops the flushing task. When blocking, staged data is flushed and posted before returning,
+// waiting at most until the shutdown deadline (shared with the rest of the tasks) is reached
 func (t *DeferredRecordingTaskImpl) Stop(blocking bool) error {
-	return t.task.Stop(blocking)
+	if err := t.task.Stop(blocking); err != nil || !blocking {
+		return err
+	}
+
+	t.mutex.Lock()
+	for _, item := range t.drain() {
+		if !t.pool.QueueMessage(item) {
+			t.logger.Warning("worker pool queue full when flushing staged data on shutdown. Some data will be lost")
+			break
+		}
+	}
+	t.mutex.Unlock()
+
+	for t.pool.QueueSize() > 0 && t.deadline.Remaining() > 0 {
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if remaining := t.pool.QueueSize(); remaining > 0 {
+		t.logger.Warning(fmt.Sprintf("grace period expired with %d bulks still pending. They will be lost", remaining))
+	}
+
+	return t.pool.StopAll(true) // waits for in-flight posts to complete
+}
+
+// Stats returns the number of bulks posted & failed since the task was created
+func (t *DeferredRecordingTaskImpl) Stats() PostStats {
+	if t.tracker == nil {
+		return PostStats{}
+	}
+	return t.tracker.stats()
 }
 
 // IsRunning returns whether the task is running
@@ -106,3 +171,31 @@ func (t *DeferredRecordingTaskImpl) IsRunning() bool {
 }
 
 var _ DeferredRecordingTask = (*DeferredRecordingTaskImpl)(nil)
+
+// isEmptyPayload returns true for bodies without any data, which are not worth a request to Split servers
+func isEmptyPayload(payload []byte) bool {
+	trimmed := bytes.TrimSpace(payload)
+	return len(trimmed) == 0 || bytes.Equal(trimmed, []byte("[]")) || bytes.Equal(trimmed, []byte("null"))
+}
+
+// DiscardingRecordingTask accepts & drops every incoming POST. Used when data cannot be forwarded to Split servers
+type DiscardingRecordingTask struct{}
+
+// NewDiscardingRecordingTask constructs a new task that discards all incoming data
+func NewDiscardingRecordingTask() *DiscardingRecordingTask {
+	return &DiscardingRecordingTask{}
+}
+
+// Stage drops the incoming data
+func (t *DiscardingRecordingTask) Stage(data interface{}) error { return nil }
+
+// Start is a no-op
+func (t *DiscardingRecordingTask) Start() {}
+
+// Stop is a no-op
+func (t *DiscardingRecordingTask) Stop(blocking bool) error { return nil }
+
+// IsRunning always returns false
+func (t *DiscardingRecordingTask) IsRunning() bool { return false }
+
+var _ DeferredRecordingTask = (*DiscardingRecordingTask)(nil)
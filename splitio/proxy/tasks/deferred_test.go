@@ -0,0 +1,42 @@
+package tasks
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/splitio/go-toolkit/v5/workerpool"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/util"
+)
+
+type countingWorker struct {
+	processed *int64
+}
+
+func (w *countingWorker) Name() string { return "counting-worker" }
+func (w *countingWorker) DoWork(message interface{}) error {
+	time.Sleep(10 * time.Millisecond)
+	atomic.AddInt64(w.processed, 1)
+	return nil
+}
+func (w *countingWorker) OnError(e error)    {}
+func (w *countingWorker) Cleanup() error     { return nil }
+func (w *countingWorker) FailureTime() int64 { return 0 }
+
+func TestDeferredTaskFlushesOnStop(t *testing.T) {
+	var processed int64
+	factory := func() workerpool.Worker { return &countingWorker{processed: &processed} }
+	task := newDeferredFlushTask(logging.NewLogger(nil), factory, 3600, 100, 1, util.NewShutdownDeadline(time.Second))
+	task.Start()
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, task.Stage(i))
+	}
+	assert.Equal(t, int64(0), atomic.LoadInt64(&processed)) // nothing flushed yet
+
+	assert.Nil(t, task.Stop(true))
+	assert.Equal(t, int64(5), atomic.LoadInt64(&processed))
+}
@@ -3,32 +3,38 @@ package tasks
 import (
 	"fmt"
 
-	"github.com/splitio/go-split-commons/v6/service/api"
 	"github.com/splitio/go-toolkit/v5/common"
 	"github.com/splitio/go-toolkit/v5/logging"
 	"github.com/splitio/go-toolkit/v5/workerpool"
 
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/internal"
+	"github.com/splitio/split-synchronizer/v5/splitio/util"
 )
 
 // EventWorker defines a component capable of recording imrpessions in raw form
 type EventWorker struct {
-	name     string
-	logger   logging.LoggerInterface
-	recorder *api.HTTPEventsRecorder
+	name         string
+	logger       logging.LoggerInterface
+	recorder     RawRecorder
+	extraHeaders map[string]string
+	tracker      *postTracker
+	requeue      func(interface{}) error
 }
 
 // Name returns the name of the worker
 func (w *EventWorker) Name() string { return w.name }
 
 // OnError is called whenever theres an error in the worker function
-func (w *EventWorker) OnError(e error) {}
+func (w *EventWorker) OnError(e error) {
+	w.logger.Error(fmt.Sprintf("[%s] %s. Backing off for %s", w.name, e, w.tracker.wait()))
+}
 
 // Cleanup is called after the worker is shutdown
 func (w *EventWorker) Cleanup() error { return nil }
 
 // FailureTime specifies how long to wait when an errors occurs before executing again
-func (w *EventWorker) FailureTime() int64 { return 1 }
+// It grows with consecutive failures across all workers
+func (w *EventWorker) FailureTime() int64 { return w.tracker.wait().Milliseconds() }
 
 // DoWork is called and passed a message fetched from the work queue
 func (w *EventWorker) DoWork(message interface{}) error {
@@ -38,19 +44,57 @@ func (w *EventWorker) DoWork(message interface{}) error {
 		return nil
 	}
 
-	w.recorder.RecordRaw("/events/bulk", asEvents.Payload, asEvents.Metadata, nil)
+	if isEmptyPayload(asEvents.Payload) {
+		return nil // nothing worth posting
+	}
+
+	if err := w.recorder.RecordRaw("/events/bulk", asEvents.Payload, asEvents.Metadata, w.extraHeaders); err != nil {
+		w.tracker.failure()
+		w.retryLater(asEvents, err)
+		return fmt.Errorf("error posting events to Split servers: %w", err)
+	}
+	w.tracker.success(asEvents.Attempts)
 	return nil
 }
 
-func newEventWorkerFactory(name string, recorder *api.HTTPEventsRecorder, logger logging.LoggerInterface) WorkerFactory {
+// retryLater puts a bulk that failed to be posted back in the staging queue, keeping its metadata,
+// so that it's posted again on a later flush instead of being lost
+func (w *EventWorker) retryLater(events *internal.RawEvents, err error) {
+	events.Attempts++
+	switch {
+	case w.requeue == nil || !isRetryable(err):
+	case events.Attempts >= maxPostAttempts:
+		w.logger.Warning(fmt.Sprintf("[%s] giving up on events bulk from SDK [%s] after %d attempts", w.name, events.Metadata.SDKVersion, events.Attempts))
+	default:
+		if requeueErr := w.requeue(events); requeueErr == nil {
+			return
+		}
+		w.logger.Warning(fmt.Sprintf("[%s] staging queue full, cannot re-queue failed events bulk from SDK [%s]", w.name, events.Metadata.SDKVersion))
+	}
+	w.tracker.drop()
+}
+
+func newEventWorkerFactory(
+	name string,
+	recorder RawRecorder,
+	logger logging.LoggerInterface,
+	extraHeaders map[string]string,
+	tracker *postTracker,
+	requeue func(interface{}) error,
+) WorkerFactory {
 	var i *int = common.IntRef(0)
 	return func() workerpool.Worker {
 		defer func() { *i++ }()
-		return &EventWorker{name: fmt.Sprintf("%s_%d", name, i), logger: logger, recorder: recorder}
+		return &EventWorker{name: fmt.Sprintf("%s_%d", name, *i), logger: logger, recorder: recorder, extraHeaders: extraHeaders, tracker: tracker, requeue: requeue}
 	}
 }
 
 // NewEventsFlushTask creates a new impressions flushing task
-func NewEventsFlushTask(recorder *api.HTTPEventsRecorder, logger logging.LoggerInterface, period int, queueSize int, threads int) *DeferredRecordingTaskImpl {
-	return newDeferredFlushTask(logger, newEventWorkerFactory("events-worker", recorder, logger), period, queueSize, threads)
+func NewEventsFlushTask(recorder RawRecorder, logger logging.LoggerInterface, period int, queueSize int, threads int, extraHeaders map[string]string, deadline *util.ShutdownDeadline) *DeferredRecordingTaskImpl {
+	var task *DeferredRecordingTaskImpl
+	requeue := func(events interface{}) error { return task.Stage(events) }
+	tracker := newPostTracker(defaultPostBackoffBase, defaultPostBackoffMax)
+	task = newDeferredFlushTask(logger, newEventWorkerFactory("events-worker", recorder, logger, extraHeaders, tracker, requeue), period, queueSize, threads, deadline)
+	task.tracker = tracker
+	return task
 }
@@ -0,0 +1,104 @@
+package tasks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/splitio/go-split-commons/v6/conf"
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-split-commons/v6/service/api"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/internal"
+)
+
+func TestEventWorkerBacksOffOnFailures(t *testing.T) {
+	var healthy int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	cfg := conf.GetDefaultAdvancedConfig()
+	cfg.EventsURL = server.URL
+	logger := logging.NewLogger(nil)
+	tracker := newPostTracker(100*time.Millisecond, 300*time.Millisecond)
+	worker := newEventWorkerFactory("test", api.NewHTTPEventsRecorder("someApikey", cfg, logger), logger, nil, tracker, nil)()
+
+	events := func() *internal.RawEvents {
+		return internal.NewRawEvents(dtos.Metadata{SDKVersion: "go-1.1.1"}, []byte(`[{"key":"k1"}]`))
+	}
+	assert.Equal(t, int64(100), worker.FailureTime())
+	assert.NotNil(t, worker.DoWork(events()))
+	assert.Equal(t, int64(100), worker.FailureTime())
+	assert.NotNil(t, worker.DoWork(events()))
+	assert.Equal(t, int64(200), worker.FailureTime())
+	assert.NotNil(t, worker.DoWork(events()))
+	assert.NotNil(t, worker.DoWork(events()))
+	assert.Equal(t, int64(300), worker.FailureTime()) // capped
+
+	// the backoff is reset once Split servers recover
+	atomic.StoreInt32(&healthy, 1)
+	assert.Nil(t, worker.DoWork(events()))
+	assert.Equal(t, int64(100), worker.FailureTime())
+	assert.Equal(t, PostStats{Posted: 1, Dropped: 4}, tracker.stats())
+}
+
+func TestEventWorkerRequeuesFailedBulks(t *testing.T) {
+	var healthy int32
+	var posted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("SplitSDKVersion") == "go-bad":
+			w.WriteHeader(http.StatusBadRequest)
+		case atomic.LoadInt32(&healthy) == 0 && r.Header.Get("SplitSDKVersion") == "go-2.2.2":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			posted = append(posted, r.Header.Get("SplitSDKVersion")+"/"+r.Header.Get("SplitSDKMachineName"))
+		}
+	}))
+	defer server.Close()
+
+	cfg := conf.GetDefaultAdvancedConfig()
+	cfg.EventsURL = server.URL
+	logger := logging.NewLogger(nil)
+	var requeued []*internal.RawEvents
+	requeue := func(events interface{}) error {
+		requeued = append(requeued, events.(*internal.RawEvents))
+		return nil
+	}
+	tracker := newPostTracker(time.Millisecond, time.Millisecond)
+	worker := newEventWorkerFactory("test", api.NewHTTPEventsRecorder("someApikey", cfg, logger), logger, nil, tracker, requeue)()
+
+	// one group fails among several succeeding ones. It's held for a later flush instead of being lost
+	assert.Nil(t, worker.DoWork(internal.NewRawEvents(dtos.Metadata{SDKVersion: "go-1.1.1", MachineName: "m1"}, []byte(`[{"key":"k1"}]`))))
+	assert.NotNil(t, worker.DoWork(internal.NewRawEvents(dtos.Metadata{SDKVersion: "go-2.2.2", MachineName: "m2"}, []byte(`[{"key":"k1"}]`))))
+	assert.Nil(t, worker.DoWork(internal.NewRawEvents(dtos.Metadata{SDKVersion: "go-3.3.3", MachineName: "m3"}, []byte(`[{"key":"k1"}]`))))
+	assert.Equal(t, []string{"go-1.1.1/m1", "go-3.3.3/m3"}, posted)
+	assert.Len(t, requeued, 1)
+	assert.Equal(t, dtos.Metadata{SDKVersion: "go-2.2.2", MachineName: "m2"}, requeued[0].Metadata)
+	assert.Equal(t, 1, requeued[0].Attempts)
+
+	atomic.StoreInt32(&healthy, 1)
+	assert.Nil(t, worker.DoWork(requeued[0]))
+	assert.Equal(t, []string{"go-1.1.1/m1", "go-3.3.3/m3", "go-2.2.2/m2"}, posted)
+
+	// bulks rejected by Split servers are not retried
+	assert.NotNil(t, worker.DoWork(internal.NewRawEvents(dtos.Metadata{SDKVersion: "go-bad"}, []byte(`[{"key":"k1"}]`))))
+	assert.Len(t, requeued, 1)
+
+	// bulks are eventually dropped if Split servers never recover
+	atomic.StoreInt32(&healthy, 0)
+	failing := internal.NewRawEvents(dtos.Metadata{SDKVersion: "go-2.2.2"}, []byte(`[{"key":"k1"}]`))
+	for i := 0; i < maxPostAttempts; i++ {
+		assert.NotNil(t, worker.DoWork(failing))
+	}
+	assert.Len(t, requeued, 1+maxPostAttempts-1)
+	assert.Equal(t, PostStats{Posted: 2, Retried: 1, Dropped: 2}, tracker.stats())
+}
@@ -3,19 +3,20 @@ package tasks
 import (
 	"fmt"
 
-	"github.com/splitio/go-split-commons/v6/service/api"
 	"github.com/splitio/go-toolkit/v5/common"
 	"github.com/splitio/go-toolkit/v5/logging"
 	"github.com/splitio/go-toolkit/v5/workerpool"
 
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/internal"
+	"github.com/splitio/split-synchronizer/v5/splitio/util"
 )
 
 // ImpressionCountWorker defines a component capable of recording imrpessions in raw form
 type ImpressionCountWorker struct {
-	name     string
-	logger   logging.LoggerInterface
-	recorder *api.HTTPImpressionRecorder
+	name         string
+	logger       logging.LoggerInterface
+	recorder     RawRecorder
+	extraHeaders map[string]string
 }
 
 // Name returns the name of the worker
@@ -38,7 +39,11 @@ func (w *ImpressionCountWorker) DoWork(message interface{}) error {
 		return nil
 	}
 
-	err := w.recorder.RecordRaw("/testImpressions/count", asCounts.Payload, asCounts.Metadata, nil)
+	if isEmptyPayload(asCounts.Payload) {
+		return nil // nothing worth posting
+	}
+
+	err := w.recorder.RecordRaw("/testImpressions/count", asCounts.Payload, asCounts.Metadata, w.extraHeaders)
 	if err != nil {
 		return fmt.Errorf("error posting impression counts to Split servers: %w", err)
 	}
@@ -47,29 +52,33 @@ func (w *ImpressionCountWorker) DoWork(message interface{}) error {
 
 func newImpressionCountWorkerFactory(
 	name string,
-	recorder *api.HTTPImpressionRecorder,
+	recorder RawRecorder,
 	logger logging.LoggerInterface,
+	extraHeaders map[string]string,
 ) WorkerFactory {
 	var i *int = common.IntRef(0)
 	return func() workerpool.Worker {
 		defer func() { *i++ }()
-		return &ImpressionCountWorker{name: fmt.Sprintf("%s_%d", name, i), logger: logger, recorder: recorder}
+		return &ImpressionCountWorker{name: fmt.Sprintf("%s_%d", name, i), logger: logger, recorder: recorder, extraHeaders: extraHeaders}
 	}
 }
 
 // NewImpressionCountFlushTask creates a new impressions flushing task
 func NewImpressionCountFlushTask(
-	recorder *api.HTTPImpressionRecorder,
+	recorder RawRecorder,
 	logger logging.LoggerInterface,
 	period int,
 	queueSize int,
 	threads int,
+	extraHeaders map[string]string,
+	deadline *util.ShutdownDeadline,
 ) *DeferredRecordingTaskImpl {
 	return newDeferredFlushTask(
 		logger,
-		newImpressionCountWorkerFactory("impressions-count-worker", recorder, logger),
+		newImpressionCountWorkerFactory("impressions-count-worker", recorder, logger, extraHeaders),
 		period,
 		queueSize,
 		threads,
+		deadline,
 	)
 }
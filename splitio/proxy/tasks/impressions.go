@@ -1,21 +1,27 @@
 package tasks
 
 import (
+	"bytes"
 	"fmt"
 
-	"github.com/splitio/go-split-commons/v6/service/api"
+	"github.com/splitio/go-split-commons/v6/dtos"
 	"github.com/splitio/go-toolkit/v5/common"
 	"github.com/splitio/go-toolkit/v5/logging"
 	"github.com/splitio/go-toolkit/v5/workerpool"
 
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/internal"
+	"github.com/splitio/split-synchronizer/v5/splitio/util"
 )
 
+// max size of the payloads built by merging impression bulks staged by the same SDK
+const maxMergedImpressionsBytes = 1 << 20
+
 // ImpressionWorker defines a component capable of recording imrpessions in raw form
 type ImpressionWorker struct {
-	name     string
-	logger   logging.LoggerInterface
-	recorder *api.HTTPImpressionRecorder
+	name         string
+	logger       logging.LoggerInterface
+	recorder     RawRecorder
+	extraHeaders map[string]string
 }
 
 // Name returns the name of the worker
@@ -38,7 +44,17 @@ func (w *ImpressionWorker) DoWork(message interface{}) error {
 		return nil
 	}
 
-	extraHeaders := map[string]string{"SDKImpressionsMode": asImpressions.Mode}
+	if isEmptyPayload(asImpressions.Payload) {
+		return nil // nothing worth posting
+	}
+
+	extraHeaders := make(map[string]string, len(w.extraHeaders)+1)
+	for name, value := range w.extraHeaders {
+		extraHeaders[name] = value
+	}
+	if asImpressions.Mode != "" {
+		extraHeaders["SplitSDKImpressionsMode"] = asImpressions.Mode
+	}
 	err := w.recorder.RecordRaw("/testImpressions/bulk", asImpressions.Payload, asImpressions.Metadata, extraHeaders)
 
 	if err != nil {
@@ -49,29 +65,84 @@ func (w *ImpressionWorker) DoWork(message interface{}) error {
 
 func newImpressionWorkerFactory(
 	name string,
-	recorder *api.HTTPImpressionRecorder,
+	recorder RawRecorder,
 	logger logging.LoggerInterface,
+	extraHeaders map[string]string,
 ) WorkerFactory {
 	var i *int = common.IntRef(0)
 	return func() workerpool.Worker {
 		defer func() { *i++ }()
-		return &ImpressionWorker{name: fmt.Sprintf("%s_%d", name, i), logger: logger, recorder: recorder}
+		return &ImpressionWorker{name: fmt.Sprintf("%s_%d", name, i), logger: logger, recorder: recorder, extraHeaders: extraHeaders}
 	}
 }
 
-// NewImpressionsFlushTask creates a new impressions flushing task
+// NewImpressionsFlushTask creates a new impressions flushing task. Staged bulks are posted every `period` seconds, or as soon as
+// `flushSize` of them are staged (when the queue is full if 0). Bulks from the same SDK & impressions mode are merged before posting
 func NewImpressionsFlushTask(
-	recorder *api.HTTPImpressionRecorder,
+	recorder RawRecorder,
 	logger logging.LoggerInterface,
 	period int,
 	queueSize int,
+	flushSize int,
 	threads int,
+	extraHeaders map[string]string,
+	deadline *util.ShutdownDeadline,
 ) *DeferredRecordingTaskImpl {
-	return newDeferredFlushTask(
+	task := newDeferredFlushTask(
 		logger,
-		newImpressionWorkerFactory("impressions-worker", recorder, logger),
+		newImpressionWorkerFactory("impressions-worker", recorder, logger, extraHeaders),
 		period,
 		queueSize,
 		threads,
+		deadline,
 	)
+	task.flushSize = flushSize
+	task.merge = mergeImpressionBulks
+	return task
+}
+
+// mergeImpressionBulks combines the impression bulks posted by the same SDK instance with the same impressions mode into a single one,
+// (up to maxMergedImpressionsBytes each) by concatenating their json arrays. Anything else is returned as is
+func mergeImpressionBulks(staged []interface{}) []interface{} {
+	type mergeKey struct {
+		metadata dtos.Metadata
+		mode     string
+	}
+
+	merged := make([]interface{}, 0, len(staged))
+	open := make(map[mergeKey]*internal.RawImpressions)
+	for _, item := range staged {
+		bulk, ok := item.(*internal.RawImpressions)
+		if !ok {
+			merged = append(merged, item)
+			continue
+		}
+
+		payload := bytes.TrimSpace(bulk.Payload)
+		if isEmptyPayload(payload) {
+			continue
+		}
+
+		if len(payload) < 2 || payload[0] != '[' || payload[len(payload)-1] != ']' { // not a json array, post it on its own
+			merged = append(merged, item)
+			continue
+		}
+
+		if len(bytes.TrimSpace(payload[1:len(payload)-1])) == 0 { // empty array with whitespace in between
+			continue
+		}
+
+		key := mergeKey{metadata: bulk.Metadata, mode: bulk.Mode}
+		current, ok := open[key]
+		if !ok || len(current.Payload)+len(payload) > maxMergedImpressionsBytes {
+			current = internal.NewRawImpressions(bulk.Metadata, bulk.Mode, append(make([]byte, 0, len(payload)), payload...))
+			open[key] = current
+			merged = append(merged, current)
+			continue
+		}
+
+		// replace the closing bracket of the current payload with a comma followed by the contents of the new one
+		current.Payload = append(append(current.Payload[:len(current.Payload)-1], ','), payload[1:]...)
+	}
+	return merged
 }
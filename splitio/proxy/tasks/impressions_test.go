@@ -0,0 +1,95 @@
+package tasks
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/splitio/go-split-commons/v6/conf"
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-split-commons/v6/service/api"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/proxy/internal"
+	"github.com/splitio/split-synchronizer/v5/splitio/util"
+)
+
+func TestImpressionWorkerForwardsMode(t *testing.T) {
+	var modes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "application/json", r.Header.Get("Accept"))
+		modes = append(modes, r.Header.Get("SplitSDKImpressionsMode"))
+	}))
+	defer server.Close()
+
+	cfg := conf.GetDefaultAdvancedConfig()
+	cfg.EventsURL = server.URL
+	logger := logging.NewLogger(nil)
+	worker := newImpressionWorkerFactory("test", api.NewHTTPImpressionRecorder("someApikey", cfg, logger), logger, util.GetOutboundHeaders(""))()
+
+	metadata := dtos.Metadata{SDKVersion: "go-1.1.1"}
+	assert.Nil(t, worker.DoWork(internal.NewRawImpressions(metadata, "optimized", []byte(`[{"f":"f1","i":[]}]`))))
+	assert.Nil(t, worker.DoWork(internal.NewRawImpressions(metadata, "debug", []byte(`[{"f":"f1","i":[]}]`))))
+	assert.Nil(t, worker.DoWork(internal.NewRawImpressions(metadata, "", []byte(`[{"f":"f1","i":[]}]`))))
+	assert.Equal(t, []string{"optimized", "debug", ""}, modes)
+
+	// bulks without impressions are not posted
+	assert.Nil(t, worker.DoWork(internal.NewRawImpressions(metadata, "optimized", []byte("[]"))))
+	assert.Nil(t, worker.DoWork(internal.NewRawImpressions(metadata, "optimized", nil)))
+	assert.Len(t, modes, 3)
+}
+
+func TestMergeImpressionBulks(t *testing.T) {
+	m1 := dtos.Metadata{SDKVersion: "go-1.1.1", MachineIP: "1.1.1.1"}
+	m2 := dtos.Metadata{SDKVersion: "go-1.1.1", MachineIP: "2.2.2.2"}
+	counts := internal.NewRawImpressionCounts(m1, []byte(`{"pf":[]}`))
+
+	merged := mergeImpressionBulks([]interface{}{
+		internal.NewRawImpressions(m1, "optimized", []byte(`[{"f":"f1","i":[{"k":"a"}]}]`)),
+		internal.NewRawImpressions(m2, "optimized", []byte(`[{"f":"f1","i":[{"k":"b"}]}]`)),
+		internal.NewRawImpressions(m1, "optimized", []byte(` [{"f":"f2","i":[{"k":"c"}]}] `)),
+		internal.NewRawImpressions(m1, "debug", []byte(`[{"f":"f3","i":[{"k":"d"}]}]`)),
+		internal.NewRawImpressions(m1, "optimized", []byte(`[ ]`)),
+		internal.NewRawImpressions(m1, "optimized", []byte(`{"invalid": true}`)),
+		counts,
+	})
+
+	assert.Len(t, merged, 5)
+	assert.Equal(t, `[{"f":"f1","i":[{"k":"a"}]},{"f":"f2","i":[{"k":"c"}]}]`, string(merged[0].(*internal.RawImpressions).Payload))
+	assert.Equal(t, m1, merged[0].(*internal.RawImpressions).Metadata)
+	assert.Equal(t, `[{"f":"f1","i":[{"k":"b"}]}]`, string(merged[1].(*internal.RawImpressions).Payload))
+	assert.Equal(t, "debug", merged[2].(*internal.RawImpressions).Mode)
+	assert.Equal(t, `{"invalid": true}`, string(merged[3].(*internal.RawImpressions).Payload))
+	assert.Equal(t, counts, merged[4])
+}
+
+func TestImpressionsFlushTaskFlushSize(t *testing.T) {
+	posted := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		posted <- string(body)
+	}))
+	defer server.Close()
+
+	cfg := conf.GetDefaultAdvancedConfig()
+	cfg.EventsURL = server.URL
+	logger := logging.NewLogger(nil)
+	task := NewImpressionsFlushTask(api.NewHTTPImpressionRecorder("someApikey", cfg, logger), logger, 3600, 100, 2, 1, nil, util.NewShutdownDeadline(time.Second))
+	task.Start()
+	defer task.Stop(false)
+
+	metadata := dtos.Metadata{SDKVersion: "go-1.1.1"}
+	assert.Nil(t, task.Stage(internal.NewRawImpressions(metadata, "", []byte(`[{"f":"f1","i":[]}]`))))
+	assert.Nil(t, task.Stage(internal.NewRawImpressions(metadata, "", []byte(`[{"f":"f2","i":[]}]`))))
+
+	select {
+	case body := <-posted:
+		assert.Equal(t, `[{"f":"f1","i":[]},{"f":"f2","i":[]}]`, body)
+	case <-time.After(2 * time.Second):
+		t.Error("reaching the flush size should have triggered a flush")
+	}
+}
@@ -0,0 +1,90 @@
+package tasks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/splitio/go-toolkit/v5/asynctask"
+	"github.com/splitio/go-toolkit/v5/datastructures/set"
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+// ReferencedSegmentsSource is implemented by feature flag storages able to tell which segments their flags use
+type ReferencedSegmentsSource interface {
+	SegmentNames() *set.ThreadUnsafeSet
+}
+
+// RemovableSegmentStorage is implemented by segment storages that can drop segments
+type RemovableSegmentStorage interface {
+	KnownSegments() []string
+	Remove(name string) error
+}
+
+// segmentCleaner drops segments that haven't been referenced by any feature flag for at least `grace`
+type segmentCleaner struct {
+	splits            ReferencedSegmentsSource
+	segments          RemovableSegmentStorage
+	grace             time.Duration
+	logger            logging.LoggerInterface
+	unreferencedSince map[string]time.Time
+	now               func() time.Time
+}
+
+func (c *segmentCleaner) run() {
+	referenced := c.splits.SegmentNames()
+	now := c.now()
+	known := make(map[string]struct{})
+	for _, name := range c.segments.KnownSegments() {
+		known[name] = struct{}{}
+		if referenced.Has(name) {
+			delete(c.unreferencedSince, name)
+			continue
+		}
+
+		since, ok := c.unreferencedSince[name]
+		if !ok {
+			c.unreferencedSince[name] = now
+			continue
+		}
+
+		if now.Sub(since) < c.grace {
+			continue
+		}
+
+		if err := c.segments.Remove(name); err != nil {
+			c.logger.Error(fmt.Sprintf("error removing unreferenced segment '%s': %s", name, err))
+			continue
+		}
+		delete(c.unreferencedSince, name)
+		c.logger.Info(fmt.Sprintf("Removed segment '%s', no longer referenced by any feature flag", name))
+	}
+
+	for name := range c.unreferencedSince { // forget segments removed by other means
+		if _, ok := known[name]; !ok {
+			delete(c.unreferencedSince, name)
+		}
+	}
+}
+
+// NewSegmentCleanupTask constructs a task that periodically drops segments no longer referenced by any feature flag,
+// once they've been unreferenced for at least `grace`
+func NewSegmentCleanupTask(
+	splits ReferencedSegmentsSource,
+	segments RemovableSegmentStorage,
+	grace time.Duration,
+	period int,
+	logger logging.LoggerInterface,
+) *asynctask.AsyncTask {
+	cleaner := &segmentCleaner{
+		splits:            splits,
+		segments:          segments,
+		grace:             grace,
+		logger:            logger,
+		unreferencedSince: make(map[string]time.Time),
+		now:               time.Now,
+	}
+	return asynctask.NewAsyncTask("segment-cleanup", func(logging.LoggerInterface) error {
+		cleaner.run()
+		return nil
+	}, period, nil, nil, logger)
+}
@@ -0,0 +1,69 @@
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/splitio/go-toolkit/v5/datastructures/set"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+type referencedSegmentsMock struct{ names *set.ThreadUnsafeSet }
+
+func (m *referencedSegmentsMock) SegmentNames() *set.ThreadUnsafeSet { return m.names }
+
+type removableSegmentsMock struct {
+	known   []string
+	removed []string
+}
+
+func (m *removableSegmentsMock) KnownSegments() []string { return m.known }
+func (m *removableSegmentsMock) Remove(name string) error {
+	m.removed = append(m.removed, name)
+	remaining := m.known[:0]
+	for _, known := range m.known {
+		if known != name {
+			remaining = append(remaining, known)
+		}
+	}
+	m.known = remaining
+	return nil
+}
+
+func TestSegmentCleaner(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	splits := &referencedSegmentsMock{names: set.NewSet("s1", "s2")}
+	segments := &removableSegmentsMock{known: []string{"s1", "s2", "s3"}}
+	cleaner := &segmentCleaner{
+		splits:            splits,
+		segments:          segments,
+		grace:             10 * time.Minute,
+		logger:            logging.NewLogger(nil),
+		unreferencedSince: make(map[string]time.Time),
+		now:               func() time.Time { return now },
+	}
+
+	cleaner.run()
+	assert.Empty(t, segments.removed)
+	assert.Contains(t, cleaner.unreferencedSince, "s3")
+
+	// s2 stops being referenced, s3 is still within its grace period
+	splits.names = set.NewSet("s1")
+	now = now.Add(5 * time.Minute)
+	cleaner.run()
+	assert.Empty(t, segments.removed)
+
+	// s3 exceeded the grace period
+	now = now.Add(5 * time.Minute)
+	cleaner.run()
+	assert.Equal(t, []string{"s3"}, segments.removed)
+	assert.NotContains(t, cleaner.unreferencedSince, "s3")
+
+	// s2 is referenced again before its grace period expires
+	splits.names = set.NewSet("s1", "s2")
+	now = now.Add(6 * time.Minute)
+	cleaner.run()
+	assert.Equal(t, []string{"s3"}, segments.removed)
+	assert.NotContains(t, cleaner.unreferencedSince, "s2")
+}
@@ -3,21 +3,22 @@ package tasks
 import (
 	"fmt"
 
-	"github.com/splitio/go-split-commons/v6/service/api"
 	"github.com/splitio/go-toolkit/v5/common"
 	"github.com/splitio/go-toolkit/v5/logging"
 	"github.com/splitio/go-toolkit/v5/workerpool"
 
 	"github.com/splitio/split-synchronizer/v5/splitio/proxy/internal"
+	"github.com/splitio/split-synchronizer/v5/splitio/util"
 )
 
 // CONFIG
 
 // TelemetryConfigWorker defines a component capable of recording imrpessions in raw form
 type TelemetryConfigWorker struct {
-	name     string
-	logger   logging.LoggerInterface
-	recorder *api.HTTPTelemetryRecorder
+	name         string
+	logger       logging.LoggerInterface
+	recorder     RawRecorder
+	extraHeaders map[string]string
 }
 
 // Name returns the name of the worker
@@ -40,30 +41,35 @@ func (w *TelemetryConfigWorker) DoWork(message interface{}) error {
 		return nil
 	}
 
-	w.recorder.RecordRaw("/metrics/config", asTelemetryConfig.Payload, asTelemetryConfig.Metadata, nil)
+	if isEmptyPayload(asTelemetryConfig.Payload) {
+		return nil // nothing worth posting
+	}
+
+	w.recorder.RecordRaw("/metrics/config", asTelemetryConfig.Payload, asTelemetryConfig.Metadata, w.extraHeaders)
 	return nil
 }
 
-func newTelemetryConfigWorkerFactory(name string, recorder *api.HTTPTelemetryRecorder, logger logging.LoggerInterface) WorkerFactory {
+func newTelemetryConfigWorkerFactory(name string, recorder RawRecorder, logger logging.LoggerInterface, extraHeaders map[string]string) WorkerFactory {
 	var i *int = common.IntRef(0)
 	return func() workerpool.Worker {
 		defer func() { *i++ }()
-		return &TelemetryConfigWorker{name: fmt.Sprintf("%s_%d", name, i), logger: logger, recorder: recorder}
+		return &TelemetryConfigWorker{name: fmt.Sprintf("%s_%d", name, i), logger: logger, recorder: recorder, extraHeaders: extraHeaders}
 	}
 }
 
 // NewTelemetryConfigFlushTask creates a new impressions flushing task
-func NewTelemetryConfigFlushTask(recorder *api.HTTPTelemetryRecorder, logger logging.LoggerInterface, period int, queueSize int, threads int) *DeferredRecordingTaskImpl {
-	return newDeferredFlushTask(logger, newTelemetryConfigWorkerFactory("telemetry-config-worker", recorder, logger), period, queueSize, threads)
+func NewTelemetryConfigFlushTask(recorder RawRecorder, logger logging.LoggerInterface, period int, queueSize int, threads int, extraHeaders map[string]string, deadline *util.ShutdownDeadline) *DeferredRecordingTaskImpl {
+	return newDeferredFlushTask(logger, newTelemetryConfigWorkerFactory("telemetry-config-worker", recorder, logger, extraHeaders), period, queueSize, threads, deadline)
 }
 
 // USAGE
 
 // TelemetryUsageWorker defines a component capable of recording imrpessions in raw form
 type TelemetryUsageWorker struct {
-	name     string
-	logger   logging.LoggerInterface
-	recorder *api.HTTPTelemetryRecorder
+	name         string
+	logger       logging.LoggerInterface
+	recorder     RawRecorder
+	extraHeaders map[string]string
 }
 
 // Name returns the name of the worker
@@ -86,30 +92,35 @@ func (w *TelemetryUsageWorker) DoWork(message interface{}) error {
 		return nil
 	}
 
-	w.recorder.RecordRaw("/metrics/usage", asTelemetryUsage.Payload, asTelemetryUsage.Metadata, nil)
+	if isEmptyPayload(asTelemetryUsage.Payload) {
+		return nil // nothing worth posting
+	}
+
+	w.recorder.RecordRaw("/metrics/usage", asTelemetryUsage.Payload, asTelemetryUsage.Metadata, w.extraHeaders)
 	return nil
 }
 
-func newTelemetryUsageWorkerFactory(name string, recorder *api.HTTPTelemetryRecorder, logger logging.LoggerInterface) WorkerFactory {
+func newTelemetryUsageWorkerFactory(name string, recorder RawRecorder, logger logging.LoggerInterface, extraHeaders map[string]string) WorkerFactory {
 	var i *int = common.IntRef(0)
 	return func() workerpool.Worker {
 		defer func() { *i++ }()
-		return &TelemetryUsageWorker{name: fmt.Sprintf("%s_%d", name, i), logger: logger, recorder: recorder}
+		return &TelemetryUsageWorker{name: fmt.Sprintf("%s_%d", name, i), logger: logger, recorder: recorder, extraHeaders: extraHeaders}
 	}
 }
 
 // NewTelemetryUsageFlushTask creates a new impressions flushing task
-func NewTelemetryUsageFlushTask(recorder *api.HTTPTelemetryRecorder, logger logging.LoggerInterface, period int, queueSize int, threads int) *DeferredRecordingTaskImpl {
-	return newDeferredFlushTask(logger, newTelemetryUsageWorkerFactory("telemetry-config-worker", recorder, logger), period, queueSize, threads)
+func NewTelemetryUsageFlushTask(recorder RawRecorder, logger logging.LoggerInterface, period int, queueSize int, threads int, extraHeaders map[string]string, deadline *util.ShutdownDeadline) *DeferredRecordingTaskImpl {
+	return newDeferredFlushTask(logger, newTelemetryUsageWorkerFactory("telemetry-config-worker", recorder, logger, extraHeaders), period, queueSize, threads, deadline)
 }
 
 // Keys Client Side
 
 // TelemetryKeysClientSideWorker defines a component capable of recording mtk client side in raw form
 type TelemetryKeysClientSideWorker struct {
-	name     string
-	logger   logging.LoggerInterface
-	recorder *api.HTTPTelemetryRecorder
+	name         string
+	logger       logging.LoggerInterface
+	recorder     RawRecorder
+	extraHeaders map[string]string
 }
 
 // Name returns the name of the worker
@@ -132,30 +143,35 @@ func (w *TelemetryKeysClientSideWorker) DoWork(message interface{}) error {
 		return nil
 	}
 
-	w.recorder.RecordRaw("/keys/cs", asTelemetryKeysClientSide.Payload, asTelemetryKeysClientSide.Metadata, nil)
+	if isEmptyPayload(asTelemetryKeysClientSide.Payload) {
+		return nil // nothing worth posting
+	}
+
+	w.recorder.RecordRaw("/keys/cs", asTelemetryKeysClientSide.Payload, asTelemetryKeysClientSide.Metadata, w.extraHeaders)
 	return nil
 }
 
-func newTelemetryKeysClientSideWorkerFactory(name string, recorder *api.HTTPTelemetryRecorder, logger logging.LoggerInterface) WorkerFactory {
+func newTelemetryKeysClientSideWorkerFactory(name string, recorder RawRecorder, logger logging.LoggerInterface, extraHeaders map[string]string) WorkerFactory {
 	var i *int = common.IntRef(0)
 	return func() workerpool.Worker {
 		defer func() { *i++ }()
-		return &TelemetryKeysClientSideWorker{name: fmt.Sprintf("%s_%d", name, i), logger: logger, recorder: recorder}
+		return &TelemetryKeysClientSideWorker{name: fmt.Sprintf("%s_%d", name, i), logger: logger, recorder: recorder, extraHeaders: extraHeaders}
 	}
 }
 
 // NewTelemetryKeysClientSideFlushTask creates a new flushing task
-func NewTelemetryKeysClientSideFlushTask(recorder *api.HTTPTelemetryRecorder, logger logging.LoggerInterface, period int, queueSize int, threads int) *DeferredRecordingTaskImpl {
-	return newDeferredFlushTask(logger, newTelemetryKeysClientSideWorkerFactory("telemetry-keys-client-side-worker", recorder, logger), period, queueSize, threads)
+func NewTelemetryKeysClientSideFlushTask(recorder RawRecorder, logger logging.LoggerInterface, period int, queueSize int, threads int, extraHeaders map[string]string, deadline *util.ShutdownDeadline) *DeferredRecordingTaskImpl {
+	return newDeferredFlushTask(logger, newTelemetryKeysClientSideWorkerFactory("telemetry-keys-client-side-worker", recorder, logger, extraHeaders), period, queueSize, threads, deadline)
 }
 
 // Keys Server Side
 
 // TelemetryKeysServerSideWorker defines a component capable of recording mtk server side in raw form
 type TelemetryKeysServerSideWorker struct {
-	name     string
-	logger   logging.LoggerInterface
-	recorder *api.HTTPTelemetryRecorder
+	name         string
+	logger       logging.LoggerInterface
+	recorder     RawRecorder
+	extraHeaders map[string]string
 }
 
 // Name returns the name of the worker
@@ -178,19 +194,23 @@ func (w *TelemetryKeysServerSideWorker) DoWork(message interface{}) error {
 		return nil
 	}
 
-	w.recorder.RecordRaw("/keys/ss", asTelemetryKeysServerSide.Payload, asTelemetryKeysServerSide.Metadata, nil)
+	if isEmptyPayload(asTelemetryKeysServerSide.Payload) {
+		return nil // nothing worth posting
+	}
+
+	w.recorder.RecordRaw("/keys/ss", asTelemetryKeysServerSide.Payload, asTelemetryKeysServerSide.Metadata, w.extraHeaders)
 	return nil
 }
 
-func newTelemetryKeysServerSideWorkerWorkerFactory(name string, recorder *api.HTTPTelemetryRecorder, logger logging.LoggerInterface) WorkerFactory {
+func newTelemetryKeysServerSideWorkerWorkerFactory(name string, recorder RawRecorder, logger logging.LoggerInterface, extraHeaders map[string]string) WorkerFactory {
 	var i *int = common.IntRef(0)
 	return func() workerpool.Worker {
 		defer func() { *i++ }()
-		return &TelemetryKeysServerSideWorker{name: fmt.Sprintf("%s_%d", name, i), logger: logger, recorder: recorder}
+		return &TelemetryKeysServerSideWorker{name: fmt.Sprintf("%s_%d", name, i), logger: logger, recorder: recorder, extraHeaders: extraHeaders}
 	}
 }
 
 // NewTelemetryKeysServerSideFlushTask creates a new flushing task
-func NewTelemetryKeysServerSideFlushTask(recorder *api.HTTPTelemetryRecorder, logger logging.LoggerInterface, period int, queueSize int, threads int) *DeferredRecordingTaskImpl {
-	return newDeferredFlushTask(logger, newTelemetryKeysServerSideWorkerWorkerFactory("telemetry-keys-server-side-worker", recorder, logger), period, queueSize, threads)
+func NewTelemetryKeysServerSideFlushTask(recorder RawRecorder, logger logging.LoggerInterface, period int, queueSize int, threads int, extraHeaders map[string]string, deadline *util.ShutdownDeadline) *DeferredRecordingTaskImpl {
+	return newDeferredFlushTask(logger, newTelemetryKeysServerSideWorkerWorkerFactory("telemetry-keys-server-side-worker", recorder, logger, extraHeaders), period, queueSize, threads, deadline)
 }
@@ -0,0 +1,238 @@
+package util
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/conf"
+)
+
+// Upstreams the failover can be pointing to
+const (
+	UpstreamPrimary   = "primary"
+	UpstreamSecondary = "secondary"
+)
+
+// FailoverStatus is a snapshot of the upstream failover state
+type FailoverStatus struct {
+	Active              string     `json:"active"`
+	SdkURL              string     `json:"sdkUrl"`
+	EventsURL           string     `json:"eventsUrl"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	LastSwitch          *time.Time `json:"lastSwitch,omitempty"`
+}
+
+// UpstreamFailover periodically probes the primary SDK API & redirects requests made to the primary SDK/events
+// urls to the secondary ones after `threshold` consecutive failed probes. Requests go back to the primary after
+// `threshold` consecutive successful probes
+type UpstreamFailover struct {
+	primarySdk      string
+	primaryEvents   string
+	secondarySdk    string
+	secondaryEvents string
+	threshold       int
+	period          time.Duration
+	client          *http.Client
+	logger          logging.LoggerInterface
+
+	mutex       sync.RWMutex
+	onSecondary bool
+	failures    int
+	successes   int
+	lastSwitch  *time.Time
+	stop        chan struct{}
+	now         func() time.Time
+}
+
+// NewUpstreamFailover constructs a new failover between a primary & a secondary set of upstream urls.
+// An empty secondary events url means events are always posted to the primary one
+func NewUpstreamFailover(
+	primarySdk string,
+	primaryEvents string,
+	secondarySdk string,
+	secondaryEvents string,
+	period time.Duration,
+	threshold int,
+	probeTransport http.RoundTripper,
+	logger logging.LoggerInterface,
+) *UpstreamFailover {
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	return &UpstreamFailover{
+		primarySdk:      strings.TrimSuffix(primarySdk, "/"),
+		primaryEvents:   strings.TrimSuffix(primaryEvents, "/"),
+		secondarySdk:    strings.TrimSuffix(secondarySdk, "/"),
+		secondaryEvents: strings.TrimSuffix(secondaryEvents, "/"),
+		threshold:       threshold,
+		period:          period,
+		client:          &http.Client{Transport: probeTransport, Timeout: 5 * time.Second},
+		logger:          logger,
+		now:             time.Now,
+	}
+}
+
+// Start begins probing the primary upstream in background
+func (f *UpstreamFailover) Start() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.stop != nil {
+		return
+	}
+
+	f.stop = make(chan struct{})
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(f.period)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				f.RecordProbe(f.probe())
+			}
+		}
+	}(f.stop)
+}
+
+// Stop stops probing the primary upstream
+func (f *UpstreamFailover) Stop() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.stop != nil {
+		close(f.stop)
+		f.stop = nil
+	}
+}
+
+// RecordProbe updates the state with the outcome of a health probe against the primary upstream
+func (f *UpstreamFailover) RecordProbe(healthy bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if healthy {
+		f.failures = 0
+		f.successes++
+		if f.onSecondary && f.successes >= f.threshold {
+			f.switchTo(false)
+			f.logger.Info("primary upstream is healthy again. Switching back to ", f.primarySdk)
+		}
+		return
+	}
+
+	f.successes = 0
+	f.failures++
+	if !f.onSecondary && f.failures >= f.threshold {
+		f.switchTo(true)
+		f.logger.Warning(fmt.Sprintf("primary upstream failed %d consecutive health checks. Failing over to %s", f.failures, f.secondarySdk))
+	}
+}
+
+// Status returns a snapshot of the failover state
+func (f *UpstreamFailover) Status() FailoverStatus {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	status := FailoverStatus{
+		Active:              UpstreamPrimary,
+		SdkURL:              f.primarySdk,
+		EventsURL:           f.primaryEvents,
+		ConsecutiveFailures: f.failures,
+		LastSwitch:          f.lastSwitch,
+	}
+
+	if f.onSecondary {
+		status.Active = UpstreamSecondary
+		status.SdkURL = f.secondarySdk
+		if f.secondaryEvents != "" {
+			status.EventsURL = f.secondaryEvents
+		}
+	}
+	return status
+}
+
+// rewrite points requests made to the primary urls to the secondary ones while failed over
+func (f *UpstreamFailover) rewrite(req *http.Request) *http.Request {
+	f.mutex.RLock()
+	onSecondary := f.onSecondary
+	f.mutex.RUnlock()
+	if !onSecondary {
+		return req
+	}
+
+	raw := req.URL.String()
+	var target string
+	switch {
+	case strings.HasPrefix(raw, f.primarySdk+"/"):
+		target = f.secondarySdk + strings.TrimPrefix(raw, f.primarySdk)
+	case f.secondaryEvents != "" && strings.HasPrefix(raw, f.primaryEvents+"/"):
+		target = f.secondaryEvents + strings.TrimPrefix(raw, f.primaryEvents)
+	default:
+		return req
+	}
+
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return req
+	}
+
+	req = req.Clone(req.Context())
+	req.URL = parsed
+	req.Host = parsed.Host
+	return req
+}
+
+func (f *UpstreamFailover) switchTo(secondary bool) {
+	now := f.now()
+	f.onSecondary = secondary
+	f.failures = 0
+	f.successes = 0
+	f.lastSwitch = &now
+}
+
+// probe hits the primary SDK API health endpoint. Server errors & connection failures are considered unhealthy
+func (f *UpstreamFailover) probe() bool {
+	resp, err := f.client.Get(f.primarySdk + "/version")
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode < http.StatusInternalServerError
+}
+
+// StartUpstreamFailover sets up a failover from the supplied primary urls to the configured secondary ones, hooks it into the
+// upstream transport & starts probing. Must be called before any client talking to Split servers is created
+func StartUpstreamFailover(
+	cfg *conf.Failover,
+	primarySdk string,
+	primaryEvents string,
+	transport *UpstreamTransport,
+	logger logging.LoggerInterface,
+) (*UpstreamFailover, error) {
+	if cfg.ProbePeriodMs <= 0 {
+		return nil, fmt.Errorf("invalid upstream failover probe period: %dms", cfg.ProbePeriodMs)
+	}
+
+	failover := NewUpstreamFailover(
+		primarySdk,
+		primaryEvents,
+		cfg.SecondarySdkURL,
+		cfg.SecondaryEventsURL,
+		time.Duration(cfg.ProbePeriodMs)*time.Millisecond,
+		cfg.Threshold,
+		transport.Tuned(func(*http.Transport) {}), // copied before hooking the failover, so that probes always hit the primary upstream
+		logger,
+	)
+	transport.SetFailover(failover)
+
+	failover.Start()
+	logger.Info(fmt.Sprintf("Upstream failover enabled: %s -> %s", failover.primarySdk, failover.secondarySdk))
+	return failover, nil
+}
@@ -0,0 +1,96 @@
+package util
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+)
+
+func TestUpstreamFailoverSwitchAndFailBack(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("primary " + r.URL.RequestURI())) }))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("secondary " + r.URL.RequestURI())) }))
+	defer secondary.Close()
+
+	failover := NewUpstreamFailover(primary.URL+"/api", primary.URL+"/events", secondary.URL+"/api/", "", 0, 2, nil, logging.NewLogger(nil))
+	client := &http.Client{Transport: &UpstreamTransport{base: http.DefaultTransport.(*http.Transport).Clone(), userAgent: "test", failover: failover}}
+	get := func(url string) string {
+		resp, err := client.Get(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	if body := get(primary.URL + "/api/splitChanges?since=-1"); body != "primary /api/splitChanges?since=-1" {
+		t.Error("should hit the primary upstream. Got: ", body)
+	}
+
+	failover.RecordProbe(false)
+	if status := failover.Status(); status.Active != UpstreamPrimary || status.ConsecutiveFailures != 1 || status.LastSwitch != nil {
+		t.Error("a single failure should not trigger a failover. Got: ", status)
+	}
+
+	failover.RecordProbe(false)
+	status := failover.Status()
+	if status.Active != UpstreamSecondary || status.SdkURL != secondary.URL+"/api" || status.EventsURL != primary.URL+"/events" || status.LastSwitch == nil {
+		t.Error("should have failed over to the secondary upstream. Got: ", status)
+	}
+
+	if body := get(primary.URL + "/api/splitChanges?since=-1"); body != "secondary /api/splitChanges?since=-1" {
+		t.Error("should hit the secondary upstream. Got: ", body)
+	}
+
+	if body := get(primary.URL + "/events/testImpressions/bulk"); body != "primary /events/testImpressions/bulk" {
+		t.Error("events should keep going to the primary upstream when no secondary one is set. Got: ", body)
+	}
+
+	failover.RecordProbe(true)
+	failover.RecordProbe(false)
+	failover.RecordProbe(true)
+	if status := failover.Status(); status.Active != UpstreamSecondary {
+		t.Error("non-consecutive successes should not trigger a fail back. Got: ", status)
+	}
+
+	failover.RecordProbe(true)
+	if status := failover.Status(); status.Active != UpstreamPrimary || status.SdkURL != primary.URL+"/api" {
+		t.Error("should have failed back to the primary upstream. Got: ", status)
+	}
+
+	if body := get(primary.URL + "/api/splitChanges?since=-1"); body != "primary /api/splitChanges?since=-1" {
+		t.Error("should hit the primary upstream again. Got: ", body)
+	}
+}
+
+func TestUpstreamFailoverProbe(t *testing.T) {
+	healthy := true
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/version" {
+			t.Error("unexpected probe path: ", r.URL.Path)
+		}
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer primary.Close()
+
+	failover := NewUpstreamFailover(primary.URL+"/api", primary.URL+"/events", "http://secondary/api", "", 0, 1, nil, logging.NewLogger(nil))
+	if !failover.probe() {
+		t.Error("probe should succeed")
+	}
+
+	healthy = false
+	if failover.probe() {
+		t.Error("probe should fail on 5xx responses")
+	}
+
+	primary.Close()
+	if failover.probe() {
+		t.Error("probe should fail when the primary upstream is unreachable")
+	}
+}
@@ -0,0 +1,24 @@
+package util
+
+import (
+	"net"
+	"net/http"
+)
+
+// ServeInBackground binds the server's address & serves requests in a separate goroutine. Binding errors (ie: port
+// already in use) are returned right away, so that callers can abort startup instead of running without the server
+func ServeInBackground(server *http.Server) error {
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if server.TLSConfig != nil {
+			server.ServeTLS(listener, "", "") // cert & key set in TLSConfig option
+			return
+		}
+		server.Serve(listener)
+	}()
+	return nil
+}
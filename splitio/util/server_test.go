@@ -0,0 +1,25 @@
+package util
+
+import (
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestServeInBackgroundBindFailure(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer taken.Close()
+
+	if err := ServeInBackground(&http.Server{Addr: taken.Addr().String()}); err == nil {
+		t.Error("binding an already used port should fail")
+	}
+
+	server := &http.Server{Addr: "127.0.0.1:0", Handler: http.NotFoundHandler()}
+	if err := ServeInBackground(server); err != nil {
+		t.Error("no error expected. Got: ", err)
+	}
+	server.Close()
+}
@@ -0,0 +1,46 @@
+package util
+
+import (
+	"sync"
+	"time"
+)
+
+// ShutdownDeadline bounds the time spent flushing buffered data on shutdown. A single instance is meant to be shared by
+// every component that flushes data, so that the whole shutdown (rather than each component) takes at most the grace
+// period. The clock starts when the first component begins waiting
+type ShutdownDeadline struct {
+	grace time.Duration
+	once  sync.Once
+	at    time.Time
+}
+
+// NewShutdownDeadline constructs a deadline allowing components to wait up to `grace` in total
+func NewShutdownDeadline(grace time.Duration) *ShutdownDeadline {
+	return &ShutdownDeadline{grace: grace}
+}
+
+// Remaining returns how much time is left before the deadline, starting the clock if it's the first call
+func (d *ShutdownDeadline) Remaining() time.Duration {
+	d.once.Do(func() { d.at = time.Now().Add(d.grace) })
+	if remaining := time.Until(d.at); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Wait blocks until `done` is closed or the deadline is reached. Returns false in the latter case
+func (d *ShutdownDeadline) Wait(done <-chan struct{}) bool {
+	timer := time.NewTimer(d.Remaining())
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		select { // favor completion if both happened at the same time
+		case <-done:
+			return true
+		default:
+			return false
+		}
+	}
+}
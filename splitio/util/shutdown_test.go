@@ -0,0 +1,28 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShutdownDeadlineIsShared(t *testing.T) {
+	deadline := NewShutdownDeadline(200 * time.Millisecond)
+	if deadline.Wait(make(chan struct{})) {
+		t.Error("waiting on something that never completes should time out")
+	}
+
+	before := time.Now()
+	if deadline.Wait(make(chan struct{})) || time.Since(before) > 50*time.Millisecond {
+		t.Error("subsequent waits should not get a new grace period")
+	}
+
+	if deadline.Remaining() != 0 {
+		t.Error("no time should be left. Got: ", deadline.Remaining())
+	}
+
+	done := make(chan struct{})
+	close(done)
+	if !NewShutdownDeadline(time.Second).Wait(done) {
+		t.Error("completed waits should return true")
+	}
+}
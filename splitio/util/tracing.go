@@ -0,0 +1,119 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/splitio/go-toolkit/v5/logging"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/splitio/split-synchronizer/v5/splitio"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/conf"
+)
+
+// TracerName identifies the spans emitted by the synchronizer
+const TracerName = "github.com/splitio/split-synchronizer"
+
+// how long to wait for buffered spans to be exported on shutdown
+const tracingShutdownTimeout = 5 * time.Second
+
+// segmentChanges paths are reported with a placeholder instead of the segment name, to keep span names low-cardinality
+const upstreamSegmentChangesPrefix = "/api/segmentChanges/"
+
+// StartTracing builds a tracer provider exporting spans as configured, and registers it (along with a W3C trace context
+// propagator) as the global one. The provider must be stopped on exit so that buffered spans are flushed
+func StartTracing(cfg *conf.Tracing, defaultServiceName string) (*sdktrace.TracerProvider, error) {
+	if cfg.SamplePercent < 0 || cfg.SamplePercent > 100 {
+		return nil, fmt.Errorf("invalid tracing sample percent %d. Must be between 0 & 100", cfg.SamplePercent)
+	}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch strings.ToLower(cfg.Exporter) {
+	case "otlp":
+		options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(context.Background(), options...)
+	case "stdout":
+		exporter, err = stdouttrace.New()
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter '%s'. Must be one of: otlp, stdout", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error setting up %s span exporter: %w", cfg.Exporter, err)
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(float64(cfg.SamplePercent)/100))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", serviceName),
+			attribute.String("service.version", splitio.Version),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider, nil
+}
+
+// StopTracing flushes the buffered spans & shuts the provider down
+func StopTracing(provider *sdktrace.TracerProvider, logger logging.LoggerInterface) {
+	ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	defer cancel()
+	if err := provider.Shutdown(ctx); err != nil {
+		logger.Error("error flushing spans on shutdown: ", err)
+	}
+}
+
+// traceUpstreamRequest wraps an upstream request in a client span. The span ends once the response headers are received
+func traceUpstreamRequest(tracer trace.Tracer, req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	ctx, span := tracer.Start(req.Context(), req.Method+" "+upstreamRoute(req.URL.Path),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", req.URL.Host),
+			attribute.String("url.path", req.URL.Path),
+		))
+	defer span.End()
+
+	req = req.WithContext(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	resp, err := next(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	return resp, nil
+}
+
+func upstreamRoute(path string) string {
+	if strings.HasPrefix(path, upstreamSegmentChangesPrefix) {
+		return upstreamSegmentChangesPrefix + "{segment}"
+	}
+	return path
+}
@@ -0,0 +1,76 @@
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/splitio/split-synchronizer/v5/splitio/common/conf"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestUpstreamTracing(t *testing.T) {
+	originalPropagator := otel.GetTextMapPropagator()
+	defer otel.SetTextMapPropagator(originalPropagator)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		if r.URL.Path == "/api/segmentChanges/segment1" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	transport, err := NewUpstreamTransport(&conf.Upstream{})
+	assert.Nil(t, err)
+	transport.SetTracer(provider.Tracer(TracerName))
+
+	client := &http.Client{Transport: transport}
+	resp, err := client.Get(server.URL + "/api/splitChanges?since=-1")
+	assert.Nil(t, err)
+	resp.Body.Close()
+	client = &http.Client{Transport: transport.Tuned(func(t *http.Transport) { t.MaxConnsPerHost = 1 })}
+	resp, err = client.Get(server.URL + "/api/segmentChanges/segment1?since=-1")
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	spans := recorder.Ended()
+	assert.Equal(t, 2, len(spans))
+	assert.Equal(t, "GET /api/splitChanges", spans[0].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	assert.Contains(t, spans[0].Attributes(), attribute.Int("http.response.status_code", 200))
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	assert.Equal(t, "GET /api/segmentChanges/{segment}", spans[1].Name())
+	assert.Contains(t, spans[1].Attributes(), attribute.String("url.path", "/api/segmentChanges/segment1"))
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+
+	// the trace context is propagated upstream
+	assert.Contains(t, traceparent, spans[1].SpanContext().TraceID().String())
+
+	// other outbound connections (ie: slack, impression listener) are neither traced nor sent the trace context
+	resp, err = http.Get(server.URL + "/some/webhook")
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 2, len(recorder.Ended()))
+	assert.Empty(t, traceparent)
+}
+
+func TestStartTracingValidation(t *testing.T) {
+	_, err := StartTracing(&conf.Tracing{Exporter: "zipkin", SamplePercent: 100}, "split-proxy")
+	assert.NotNil(t, err)
+
+	_, err = StartTracing(&conf.Tracing{Exporter: "stdout", SamplePercent: 101}, "split-proxy")
+	assert.NotNil(t, err)
+}
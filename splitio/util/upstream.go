@@ -0,0 +1,170 @@
+package util
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/splitio/go-split-commons/v6/dtos"
+	"github.com/splitio/go-split-commons/v6/service"
+	"github.com/splitio/go-toolkit/v5/logging"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/splitio/split-synchronizer/v5/splitio"
+	"github.com/splitio/split-synchronizer/v5/splitio/common/conf"
+)
+
+var (
+	ErrUpstreamCertWithoutKey = errors.New("upstream client certificate & private key must be supplied together")
+	ErrUpstreamInvalidCAs     = errors.New("no valid certificates found in upstream root CAs bundle")
+	ErrApikeyRejected         = errors.New("SDK key rejected by Split servers")
+)
+
+// VerifyApikey performs an authenticated call to Split servers & returns ErrApikeyRejected if the SDK key is refused.
+// Any other failure (ie: Split servers unreachable) is returned as is, since it doesn't say anything about the key
+func VerifyApikey(fetcher service.SplitFetcher) error {
+	_, err := fetcher.Fetch(service.MakeFlagRequestParams().WithCacheControl(false).WithChangeNumber(time.Now().UnixMilli()))
+	var httpErr *dtos.HTTPError
+	if errors.As(err, &httpErr) && (httpErr.Code == http.StatusUnauthorized || httpErr.Code == http.StatusForbidden) {
+		return fmt.Errorf("%w (status %d)", ErrApikeyRejected, httpErr.Code)
+	}
+	return err
+}
+
+// TLSConfigForUpstream builds the TLS config used when connecting to Split servers. Returns nil if no
+// client certificate nor custom CAs are configured
+func TLSConfigForUpstream(cfg *conf.Upstream) (*tls.Config, error) {
+	if cfg.ClientCertFN == "" && cfg.ClientKeyFN == "" && cfg.RootCAsFN == "" {
+		return nil, nil
+	}
+
+	if (cfg.ClientCertFN == "") != (cfg.ClientKeyFN == "") {
+		return nil, ErrUpstreamCertWithoutKey
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.ClientCertFN != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertFN, cfg.ClientKeyFN)
+		if err != nil {
+			return nil, fmt.Errorf("error loading upstream client cert/key pair: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if cfg.RootCAsFN != "" {
+		pemBytes, err := os.ReadFile(cfg.RootCAsFN)
+		if err != nil {
+			return nil, fmt.Errorf("error reading upstream root CAs bundle: %w", err)
+		}
+
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(pemBytes) {
+			return nil, ErrUpstreamInvalidCAs
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
+	return tlsConfig, nil
+}
+
+// UpstreamTransport is the transport used by every client talking to Split servers. It applies the upstream TLS & proxy config
+// and sets the synchronizer's user agent on every request, leaving the SDK metadata headers untouched.
+// If a failover is set, requests to the primary upstream are redirected to the secondary one while failed over.
+// If a tracer is set, every request is wrapped in a client span.
+// The default http transport is left untouched, so other outbound connections (ie: slack, impression listener) aren't affected
+type UpstreamTransport struct {
+	base      *http.Transport
+	userAgent string
+	failover  *UpstreamFailover
+	tracer    trace.Tracer
+}
+
+// NewUpstreamTransport builds the transport used to talk to Split servers from the upstream config
+func NewUpstreamTransport(cfg *conf.Upstream) (*UpstreamTransport, error) {
+	tlsConfig, err := TLSConfigForUpstream(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultTransport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("unexpected default transport type: %T", http.DefaultTransport)
+	}
+
+	base := defaultTransport.Clone()
+	if tlsConfig != nil {
+		base.TLSClientConfig = tlsConfig
+	}
+
+	if cfg.ProxyURL != "" { // otherwise the HTTP_PROXY/HTTPS_PROXY/NO_PROXY env vars are honored, as in the default transport
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid upstream proxy url '%s'", conf.Upstream{ProxyURL: cfg.ProxyURL}.Redacted().ProxyURL)
+		}
+		base.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return &UpstreamTransport{base: base, userAgent: UserAgent(cfg.UserAgentSuffix)}, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *UpstreamTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.failover != nil {
+		req = t.failover.rewrite(req)
+	}
+	req = req.Clone(req.Context()) // round trippers must not modify the request
+	req.Header.Set("User-Agent", t.userAgent)
+	if t.tracer != nil {
+		return traceUpstreamRequest(t.tracer, req, t.base.RoundTrip)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// Tuned returns a copy of the transport with its own connection pool, adjusted by the supplied function. Meant for clients
+// needing their own tuning (ie: max connections per host)
+func (t *UpstreamTransport) Tuned(tune func(*http.Transport)) *UpstreamTransport {
+	base := t.base.Clone()
+	tune(base)
+	return &UpstreamTransport{base: base, userAgent: t.userAgent, failover: t.failover, tracer: t.tracer}
+}
+
+// SetFailover makes the transport honor the supplied failover. Must be called before any client using it (or a tuned copy) is created
+func (t *UpstreamTransport) SetFailover(failover *UpstreamFailover) {
+	t.failover = failover
+}
+
+// SetTracer makes the transport emit a client span for every request, propagating the trace context in the request headers.
+// Must be called before any client using it (or a tuned copy) is created
+func (t *UpstreamTransport) SetTracer(tracer trace.Tracer) {
+	t.tracer = tracer
+}
+
+// WarnUnscopedUpstreamSettings logs the upstream settings that don't apply to the streaming connection. Its client is built by
+// go-split-commons on top of the default http transport, which is left untouched so that other outbound connections aren't affected
+func WarnUnscopedUpstreamSettings(cfg *conf.Upstream, streamingEnabled bool, logger logging.LoggerInterface) {
+	if !streamingEnabled {
+		return
+	}
+
+	if cfg.ClientCertFN != "" || cfg.RootCAsFN != "" {
+		logger.Warning("The upstream client certificate & root CAs are not used by the streaming connection, which relies on the system CAs. " +
+			"Disable streaming if the streaming service can only be reached with them")
+	}
+
+	if cfg.ProxyURL != "" {
+		logger.Warning("The upstream proxy url is not used by the streaming connection, which honors the HTTP_PROXY/HTTPS_PROXY env vars instead")
+	}
+}
+
+// UserAgent returns the user agent sent to Split servers, with the configured suffix (if any)
+func UserAgent(suffix string) string {
+	if suffix == "" {
+		return "SplitSynchronizer/" + splitio.Version
+	}
+	return "SplitSynchronizer/" + splitio.Version + " " + suffix
+}
@@ -25,6 +25,11 @@ func GetClientKey(apikey string) (string, error) {
 	return apikey[len(apikey)-4:], nil
 }
 
+// MachineNameFromIP builds the machine name reported to Split servers when the actual one is not available
+func MachineNameFromIP(ip string) string {
+	return fmt.Sprintf("ip-%s", strings.Replace(ip, ".", "-", -1))
+}
+
 // GetMetadata wrapps metadata
 func GetMetadata(proxy bool, ipAddressEnabled bool) dtos.Metadata {
 	instanceName := "unknown"
@@ -33,7 +38,7 @@ func GetMetadata(proxy bool, ipAddressEnabled bool) dtos.Metadata {
 		ip, err := nethelpers.ExternalIP()
 		if err == nil {
 			ipAddress = ip
-			instanceName = fmt.Sprintf("ip-%s", strings.Replace(ipAddress, ".", "-", -1))
+			instanceName = MachineNameFromIP(ipAddress)
 		}
 	}
 
@@ -48,3 +53,15 @@ func GetMetadata(proxy bool, ipAddressEnabled bool) dtos.Metadata {
 		SDKVersion:  appName + splitio.Version,
 	}
 }
+
+// EnvironmentLabelHeader is the header used to tag outgoing requests with the configured environment label
+const EnvironmentLabelHeader = "SplitSyncEnvironmentLabel"
+
+// GetOutboundHeaders returns the set of extra headers to attach when posting data to Split servers
+func GetOutboundHeaders(environmentLabel string) map[string]string {
+	headers := map[string]string{"Accept": "application/json"}
+	if environmentLabel != "" {
+		headers[EnvironmentLabelHeader] = environmentLabel
+	}
+	return headers
+}